@@ -0,0 +1,33 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AcademicPeriod adalah satu semester. Rentang tanggal antar periode tidak beririsan dan hanya satu
+// yang aktif. Periode yang sudah ditutup (ClosedAt terisi) membekukan prestasi di dalamnya
+type AcademicPeriod struct {
+	ID           uuid.UUID  `json:"id"`
+	Name         string     `json:"name"`
+	StartDate    time.Time  `json:"start_date"`
+	EndDate      time.Time  `json:"end_date"`
+	IsActive     bool       `json:"is_active"`
+	ClosedAt     *time.Time `json:"closed_at"`
+	ClosedBy     *uuid.UUID `json:"closed_by"`
+	Achievements int        `json:"achievements"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+func (p AcademicPeriod) IsClosed() bool {
+	return p.ClosedAt != nil
+}
+
+// AcademicPeriodRequest: tanggal dalam format YYYY-MM-DD, end_date tidak boleh sebelum start_date
+type AcademicPeriodRequest struct {
+	Name      string `json:"name" validate:"required,max=100"`
+	StartDate string `json:"start_date" validate:"required,date"`
+	EndDate   string `json:"end_date" validate:"required,date,gtefield=StartDate"`
+}
@@ -0,0 +1,77 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Faculty, Department, dan ProgramStudy adalah data master unit akademik:
+// fakultas -> department -> program studi. Nama unik tanpa membedakan huruf besar/kecil
+type Faculty struct {
+	ID          uuid.UUID `json:"id"`
+	Code        string    `json:"code"`
+	Name        string    `json:"name"`
+	Departments int       `json:"departments"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+type Department struct {
+	ID             uuid.UUID `json:"id"`
+	FacultyID      uuid.UUID `json:"faculty_id"`
+	FacultyName    string    `json:"faculty_name"`
+	Code           string    `json:"code"`
+	Name           string    `json:"name"`
+	ProgramStudies int       `json:"program_studies"`
+	Lecturers      int       `json:"lecturers"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+type ProgramStudy struct {
+	ID             uuid.UUID `json:"id"`
+	DepartmentID   uuid.UUID `json:"department_id"`
+	DepartmentName string    `json:"department_name"`
+	FacultyID      uuid.UUID `json:"faculty_id"`
+	FacultyName    string    `json:"faculty_name"`
+	Code           string    `json:"code"`
+	Name           string    `json:"name"`
+	Students       int       `json:"students"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+type FacultyRequest struct {
+	Code string `json:"code" validate:"max=20"`
+	Name string `json:"name" validate:"required,max=100"`
+}
+
+type DepartmentRequest struct {
+	FacultyID string `json:"faculty_id" validate:"required,uuid"`
+	Code      string `json:"code" validate:"max=20"`
+	Name      string `json:"name" validate:"required,max=100"`
+}
+
+type ProgramStudyRequest struct {
+	DepartmentID string `json:"department_id" validate:"required,uuid"`
+	Code         string `json:"code" validate:"max=20"`
+	Name         string `json:"name" validate:"required,max=100"`
+}
+
+// Pengelompokan statistik prestasi per unit akademik
+const (
+	GroupByFaculty      = "faculty"
+	GroupByDepartment   = "department"
+	GroupByProgramStudy = "program_study"
+)
+
+// UnitStatistics adalah jumlah prestasi per status untuk satu unit akademik.
+// ID kosong untuk mahasiswa yang belum terhubung ke program studi
+type UnitStatistics struct {
+	ID       *uuid.UUID       `json:"id"`
+	Code     string           `json:"code"`
+	Name     string           `json:"name"`
+	Total    int64            `json:"total_achievements"`
+	ByStatus map[string]int64 `json:"status_breakdown"`
+}
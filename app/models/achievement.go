@@ -7,12 +7,12 @@ import (
 )
 
 type CreateAchievementRequest struct {
-	AchievementType string                 `json:"achievementType" validate:"required"`
-	Title           string                 `json:"title" validate:"required"`
+	AchievementType string                 `json:"achievementType" validate:"required,max=50"`
+	Title           string                 `json:"title" validate:"required,max=200"`
 	Description     string                 `json:"description"`
 	Details         map[string]interface{} `json:"details"`
 	Tags            []string               `json:"tags"`
-	EventDate       time.Time              `json:"eventDate"`
+	EventDate       string                 `json:"eventDate" validate:"omitempty,date"` // YYYY-MM-DD, default hari ini
 }
 
 type AchievementMongo struct {
@@ -25,6 +25,7 @@ type AchievementMongo struct {
 	Tags            []string               `bson:"tags" json:"tags"`
 	Points          int                    `bson:"points" json:"points"`
 	Attachments 		[]Attachment 					 `bson:"attachments" json:"attachments"`
+	EventDate       time.Time              `bson:"eventDate" json:"event_date"`
 	CreatedAt       time.Time              `bson:"createdAt" json:"created_at"`
 	UpdatedAt       time.Time              `bson:"updatedAt" json:"updated_at"`
 }
@@ -34,9 +35,26 @@ type AchievementReference struct {
 	StudentID          string    `json:"student_id"`
 	MongoAchievementID string    `json:"mongo_achievement_id"`
 	Status             string    `json:"status"`
+	EventDate          time.Time `json:"event_date"`
+	PeriodName         string    `json:"period_name"`  // periode akademik dari EventDate, kosong jika di luar periode
+	PeriodClosed       bool      `json:"period_closed"` // periode sudah ditutup: prestasi dibekukan
 	CreatedAt          time.Time `json:"created_at"`
 }
 
+// AchievementFilter membatasi list prestasi: milik mahasiswa tertentu (StudentUserID) atau
+// mahasiswa bimbingan dosen wali tertentu (AdvisorUserID). Keduanya kosong berarti semua prestasi
+type AchievementFilter struct {
+	StudentUserID string
+	AdvisorUserID string
+}
+
+// AchievementOwner adalah identitas mahasiswa pemilik prestasi yang ditampilkan di list
+type AchievementOwner struct {
+	Name     string
+	NIM      string
+	PhotoURL string
+}
+
 // Struct Response untuk List (Admin View)
 type AchievementResponse struct {
 	ID              string                 `json:"id"`
@@ -44,6 +62,7 @@ type AchievementResponse struct {
 	StudentID       string                 `json:"student_id"`
 	StudentName     string                 `json:"student_name"`
 	StudentNIM      string                 `json:"student_nim"`
+	StudentPhotoURL string                 `json:"student_photo_url"`
 	AchievementType string                 `json:"achievement_type"`
 	Title           string                 `json:"title"`
 	Description     string                 `json:"description"`
@@ -51,6 +70,8 @@ type AchievementResponse struct {
 	Points          int                    `json:"points"`
 	Tags            []string               `json:"tags"`
 	Details         map[string]interface{} `json:"details"`
+	EventDate       time.Time              `json:"event_date"`
+	PeriodName      string                 `json:"period_name,omitempty"`
 
 	// Field Tambahan untuk Detail
 	SubmittedAt     *time.Time             `json:"submitted_at,omitempty"`
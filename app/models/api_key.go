@@ -0,0 +1,38 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// APIKey adalah kredensial untuk klien mesin (misalnya exporter SKPI). Key asli hanya ditampilkan sekali saat dibuat
+type APIKey struct {
+	ID          uuid.UUID  `json:"id"`
+	Name        string     `json:"name"`
+	KeyPrefix   string     `json:"key_prefix"`
+	KeyHash     string     `json:"-"`
+	Scopes      []string   `json:"scopes"`
+	IPAllowlist []string   `json:"ip_allowlist"`
+	CreatedBy   *uuid.UUID `json:"created_by,omitempty"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt  *time.Time `json:"last_used_at,omitempty"`
+	LastUsedIP  string     `json:"last_used_ip,omitempty"`
+	RevokedAt   *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+type CreateAPIKeyRequest struct {
+	Name          string   `json:"name" validate:"required,max=100"`
+	Scopes        []string `json:"scopes" validate:"required"`
+	IPAllowlist   []string `json:"ip_allowlist"`
+	ExpiresInDays int      `json:"expires_in_days" validate:"min=0"` // 0 = tidak pernah expired
+}
+
+type CreateAPIKeyResponse struct {
+	APIKey
+	Key string `json:"key"`
+}
+
+// RoleService adalah role semu untuk request yang diautentikasi dengan API key
+const RoleService = "Service"
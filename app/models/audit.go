@@ -0,0 +1,41 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AuditLog mencatat aksi penting. ActorID adalah user yang sebenarnya melakukan aksi (kosong untuk
+// request lewat API key; ID key-nya dicatat di Metadata), SubjectUserID adalah user yang terdampak
+// (misalnya user yang sedang di-impersonate)
+type AuditLog struct {
+	ID            uuid.UUID              `json:"id"`
+	ActorID       *uuid.UUID             `json:"actor_id,omitempty"`
+	SubjectUserID *uuid.UUID             `json:"subject_user_id,omitempty"`
+	Action        string                 `json:"action"`
+	Method        string                 `json:"method,omitempty"`
+	Path          string                 `json:"path,omitempty"`
+	StatusCode    int                    `json:"status_code,omitempty"`
+	IPAddress     string                 `json:"ip_address,omitempty"`
+	Metadata      map[string]interface{} `json:"metadata,omitempty"`
+	CreatedAt     time.Time              `json:"created_at"`
+}
+
+const (
+	AuditImpersonationStart   = "impersonation.start"
+	AuditImpersonationRequest = "impersonation.request"
+	AuditUserPurge            = "user.purge"
+	AuditAPIKeyRequest        = "api_key.request"
+)
+
+type ImpersonateRequest struct {
+	Reason string `json:"reason" validate:"required,max=500"`
+}
+
+type ImpersonationResponse struct {
+	Token          string          `json:"token"`
+	ExpiresIn      int             `json:"expiresIn"`
+	User           UserResponseDTO `json:"user"`
+	ImpersonatorID uuid.UUID       `json:"impersonatorId"`
+}
@@ -9,8 +9,9 @@ import (
 type Lecture struct {
 	ID uuid.UUID `json:"id"`
 	UserID uuid.UUID `json:"user_id"`
-	LectureID string `json:"lecturer_id"`
-	Department string `json:"department"`
+	LectureID string `json:"lecturer_id" validate:"required,max=20"`
+	DepartmentID uuid.UUID `json:"department_id"`
+	Department string `json:"department" validate:"max=100"` // nama department; dipakai jika department_id kosong
 	CreatedAt time.Time `json:"created_at"`
 }
 
@@ -25,11 +26,63 @@ type GetLecture struct {
 	Username   string    `json:"username"`
 	Email      string    `json:"email"`
 	RoleName   string    `json:"role_name"`
+	DepartmentID *uuid.UUID `json:"department_id"`
 	Department string    `json:"department"`
 	IsActive   bool      `json:"is_active"`
+	PhotoURL   string    `json:"photo_url"`
 	CreatedAt  time.Time `json:"created_at"`
 }
 
 type RejectAchievementRequest struct {
-	RejectionNote string `json:"rejection_note" validate:"required"`
-}
\ No newline at end of file
+	RejectionNote string `json:"rejection_note" validate:"required,max=1000"`
+}
+// AdvisorLoad adalah jumlah mahasiswa bimbingan aktif dan kapasitas seorang dosen wali.
+// MaxAdvisees nil berarti tidak dibatasi
+type AdvisorLoad struct {
+	LecturerID   uuid.UUID  `json:"id"`
+	Code         string     `json:"lecturer_id"`
+	FullName     string     `json:"full_name"`
+	DepartmentID *uuid.UUID `json:"department_id"`
+	Department   string     `json:"department"`
+	Advisees     int        `json:"advisees"`
+	MaxAdvisees  *int       `json:"max_advisees"`
+}
+
+// Full bernilai true jika dosen tidak bisa menerima mahasiswa bimbingan baru
+func (l AdvisorLoad) Full() bool {
+	return l.MaxAdvisees != nil && l.Advisees >= *l.MaxAdvisees
+}
+
+type DepartmentLoad struct {
+	DepartmentID *uuid.UUID `json:"department_id"`
+	Department   string     `json:"department"`
+	Lecturers    int        `json:"lecturers"`
+	Advisees     int        `json:"advisees"`
+	Capacity     *int       `json:"capacity"` // nil jika ada dosen tanpa batas
+	AvgAdvisees  float64    `json:"avg_advisees"`
+}
+
+type AdvisorLoadReport struct {
+	DefaultMaxAdvisees *int             `json:"default_max_advisees"`
+	Lecturers          []AdvisorLoad    `json:"lecturers"`
+	Departments        []DepartmentLoad `json:"departments"`
+}
+
+type UpdateLecturerCapacityRequest struct {
+	MaxAdvisees *int `json:"max_advisees" validate:"omitempty,min=0"` // null: kembali ke default
+}
+
+// AchievementSummary adalah rekap prestasi seorang mahasiswa. Poin hanya dihitung dari prestasi verified
+type AchievementSummary struct {
+	Total       int64            `json:"total"`
+	ByStatus    map[string]int64 `json:"status_breakdown"`
+	TotalPoints int              `json:"total_points"`
+}
+
+// Advisee adalah mahasiswa bimbingan beserta rekap prestasinya.
+// VerifiedMongoIDs dipakai service untuk menjumlahkan poin dari MongoDB
+type Advisee struct {
+	GetStudent
+	Achievements     AchievementSummary `json:"achievements"`
+	VerifiedMongoIDs []string           `json:"-"`
+}
@@ -0,0 +1,52 @@
+package models
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+)
+
+// ListQuery adalah parameter pencarian, filter, urutan, dan paginasi yang dipakai bersama oleh endpoint list
+type ListQuery struct {
+	Search  string
+	Filters map[string]string // nama filter (query param) → nilai
+	Sort    string            // kosong: urutan default endpoint
+	Desc    bool
+	Limit   int
+	Page    int         // mode offset, mulai dari 1
+	Cursor  *ListCursor // non-nil berarti mode cursor; Cursor kosong = halaman pertama
+}
+
+// ListCursor menunjuk baris terakhir halaman sebelumnya (keyset pagination)
+type ListCursor struct {
+	Sort  string `json:"s"`
+	Desc  bool   `json:"d,omitempty"`
+	Value string `json:"v"`
+	ID    string `json:"id"`
+}
+
+func (c ListCursor) Encode() string {
+	raw, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func DecodeListCursor(s string) (ListCursor, error) {
+	var c ListCursor
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil || json.Unmarshal(raw, &c) != nil || c.ID == "" {
+		return ListCursor{}, errors.New("cursor tidak valid")
+	}
+	return c, nil
+}
+
+// ListMeta adalah metadata paginasi yang sama untuk semua endpoint list
+type ListMeta struct {
+	Total      int    `json:"total"`
+	Limit      int    `json:"limit"`
+	Page       int    `json:"page,omitempty"`        // mode offset
+	TotalPages int    `json:"total_pages,omitempty"` // mode offset
+	NextCursor string `json:"next_cursor,omitempty"` // mode cursor
+	HasMore    bool   `json:"has_more"`
+	Sort       string `json:"sort"`
+	Order      string `json:"order"`
+}
@@ -0,0 +1,45 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type UserMFA struct {
+	UserID     uuid.UUID  `json:"user_id"`
+	TOTPSecret string     `json:"-"`
+	IsEnabled  bool       `json:"is_enabled"`
+	EnabledAt  *time.Time `json:"enabled_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+type MFAChallengeResponse struct {
+	MFAToken  string `json:"mfaToken"`
+	Purpose   string `json:"purpose"`
+	ExpiresIn int    `json:"expiresIn"`
+}
+
+type MFASetupResponse struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+}
+
+type MFAEnrollRequest struct {
+	MFAToken string `json:"mfaToken" validate:"required"`
+}
+
+type MFAVerifyRequest struct {
+	MFAToken     string `json:"mfaToken"`
+	Code         string `json:"code" validate:"required_without=RecoveryCode"`
+	RecoveryCode string `json:"recovery_code"`
+}
+
+type MFACodeRequest struct {
+	Code string `json:"code" validate:"required"`
+}
+
+const (
+	MFAPurposeLogin  = "login"
+	MFAPurposeEnroll = "enroll"
+)
@@ -0,0 +1,88 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	AuditProfileUpdate        = "profile.update"
+	AuditEmailChangeRequested = "profile.email_change_requested"
+	AuditEmailChanged         = "profile.email_changed"
+)
+
+// Profile adalah data lengkap user yang sedang login beserta profil mahasiswa/dosennya
+type Profile struct {
+	ID              uuid.UUID         `json:"id"`
+	Username        string            `json:"username"`
+	Email           string            `json:"email"`
+	PendingEmail    string            `json:"pending_email,omitempty"` // menunggu konfirmasi
+	FullName        string            `json:"full_name"`
+	RoleName        string            `json:"role_name"`
+	Phone           string            `json:"phone"`
+	PhotoURL        string            `json:"photo_url"`
+	PhotoThumbnails map[string]string `json:"photo_thumbnails,omitempty"` // ukuran sisi (px) -> URL
+	Bio             string            `json:"bio"`
+	Language        string            `json:"language"` // id/en; kosong berarti mengikuti Accept-Language
+	IsActive        bool              `json:"is_active"`
+	CreatedAt       time.Time         `json:"created_at"`
+	Student         *StudentProfile   `json:"student,omitempty"`
+	Lecturer        *LecturerProfile  `json:"lecturer,omitempty"`
+	ImpersonatorID  *uuid.UUID        `json:"impersonator_id,omitempty"`
+}
+
+type StudentProfile struct {
+	ID           uuid.UUID       `json:"id"`
+	NIM          string          `json:"nim"`
+	ProgramStudy string          `json:"program_study"`
+	AcademicYear string          `json:"academy_year"`
+	Advisor      *AdvisorContact `json:"advisor"`
+}
+
+type LecturerProfile struct {
+	ID          uuid.UUID `json:"id"`
+	LecturerID  string    `json:"lecturer_id"`
+	Department  string    `json:"department"`
+	Advisees    int       `json:"advisees"`
+	MaxAdvisees *int      `json:"max_advisees"`
+}
+
+// AdvisorContact adalah kontak dosen wali yang boleh dilihat mahasiswa bimbingannya
+type AdvisorContact struct {
+	ID         uuid.UUID `json:"id"`
+	LecturerID string    `json:"lecturer_id"`
+	FullName   string    `json:"full_name"`
+	Email      string    `json:"email"`
+	Phone      string    `json:"phone"`
+	Department string    `json:"department"`
+}
+
+// UpdateProfileRequest hanya berisi field yang boleh diubah sendiri. Field kosong (nil) tidak diubah
+type UpdateProfileRequest struct {
+	Email    *string `json:"email" validate:"omitempty,email,max=100"`
+	Phone    *string `json:"phone"`
+	PhotoURL *string `json:"photo_url" validate:"omitempty,max=255"`
+	Bio      *string `json:"bio" validate:"omitempty,max=500"`
+	Language *string `json:"language" validate:"omitempty,oneof=id en"` // string kosong menghapus pilihan bahasa
+}
+
+type ProfileFields struct {
+	Phone    string
+	PhotoURL string
+	Bio      string
+	Language string
+}
+
+type EmailChangeRequest struct {
+	ID        uuid.UUID
+	UserID    uuid.UUID
+	NewEmail  string
+	OldEmail  string
+	TokenHash string
+	ExpiresAt time.Time
+}
+
+type ConfirmEmailRequest struct {
+	Token string `json:"token" validate:"required"`
+}
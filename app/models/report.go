@@ -3,17 +3,24 @@ package models
 type DashboardStatistics struct {
 	TotalPrestasi int64            `json:"total_achievements"`
 	ByStatus      map[string]int64 `json:"status_breakdown"`
+	GroupBy       string           `json:"group_by,omitempty"`
+	Groups        []UnitStatistics `json:"groups,omitempty"`
+	Period        *AcademicPeriod  `json:"period,omitempty"`
 }
 
 type StudentReportProfile struct {
-	StudentID   string `json:"student_id"`
-	NIM         string `json:"nim"`
-	FullName    string `json:"full_name"`
-	TotalPoints int    `json:"total_points"`
-	TotalItems  int    `json:"total_items"`
+	StudentID    string `json:"student_id"`
+	NIM          string `json:"nim"`
+	FullName     string `json:"full_name"`
+	ProgramStudy string `json:"program_study"`
+	Department   string `json:"department"`
+	Faculty      string `json:"faculty"`
+	TotalPoints  int    `json:"total_points"`
+	TotalItems   int    `json:"total_items"`
 }
 
 type StudentReportResponse struct {
 	Profile      StudentReportProfile  `json:"profile"`
+	Period       *AcademicPeriod       `json:"period,omitempty"`
 	Achievements []AchievementResponse `json:"achievements"`
 }
\ No newline at end of file
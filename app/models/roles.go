@@ -7,14 +7,34 @@ import (
 )
 
 type Role struct {
-	ID          uuid.UUID
-	Name        string
-	Description string
-	CreatedAt   time.Time
+	ID          uuid.UUID `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	IsSystem    bool      `json:"is_system"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type RoleRequest struct {
+	Name        string `json:"name" validate:"required,max=50"`
+	Description string `json:"description"`
+}
+
+type AssignPermissionsRequest struct {
+	PermissionIDs []string `json:"permission_ids" validate:"dive,uuid"`
+}
+
+type PermissionMatrixRow struct {
+	Permission Permission      `json:"permission"`
+	Roles      map[string]bool `json:"roles"`
+}
+
+type PermissionMatrix struct {
+	Roles []Role                `json:"roles"`
+	Rows  []PermissionMatrixRow `json:"rows"`
 }
 
 const (
 	RoleAdmin     = "Admin"
 	RoleMahasiswa = "Mahasiswa"
 	RoleDosen     = "Dosen Wali"
-)
\ No newline at end of file
+)
@@ -7,28 +7,175 @@ import (
 )
 
 type Student struct {
-	ID           uuid.UUID `json:"id"`
-	UserID       uuid.UUID `json:"user_id"`
-	StudentID    string    `json:"student_id"`
-	ProgramStudy string    `json:"program_study"`
-	AcademicYear string    `json:"academy_year"`
-	AdvisorID    uuid.UUID `json:"advisor_id"`	
-	CreatedAt    time.Time `json:"created_at"`
+	ID             uuid.UUID  `json:"id"`
+	UserID         uuid.UUID  `json:"user_id"`
+	StudentID      string     `json:"student_id" validate:"required,nim"`
+	ProgramStudyID uuid.UUID  `json:"program_study_id"`
+	ProgramStudy   string     `json:"program_study" validate:"max=100"` // nama program studi; dipakai jika program_study_id kosong
+	AcademicYear   string     `json:"academy_year" validate:"omitempty,academic_year"`
+	AdvisorID      uuid.UUID  `json:"advisor_id"`
+	DepartmentID   uuid.UUID  `json:"-"` // department program studi, diisi repository untuk auto-assign
+	CreatedBy      *uuid.UUID `json:"-"` // user yang membuat profil, dicatat sebagai changed_by riwayat pertama
+	CreatedAt      time.Time  `json:"created_at"`
 }
 
 type GetStudent struct {
-	ID           string    `json:"id"`
-	UserID       string    `json:"user_id"`
-	NIM          string    `json:"nim"`
-	FullName     string    `json:"full_name"`
-	Username     string    `json:"username"`
-	Email        string    `json:"email"`
-	RoleName     string    `json:"role_name"`
-	ProgramStudy string    `json:"program_study"`
-	AcademyYear  string    `json:"academy_year"`
-	IsActive     bool      `json:"is_active"`
+	ID                  string     `json:"id"`
+	UserID              string     `json:"user_id"`
+	NIM                 string     `json:"nim"`
+	FullName            string     `json:"full_name"`
+	Username            string     `json:"username"`
+	Email               string     `json:"email"`
+	RoleName            string     `json:"role_name"`
+	ProgramStudyID      *uuid.UUID `json:"program_study_id"`
+	ProgramStudy        string     `json:"program_study"`
+	AcademyYear         string     `json:"academy_year"`
+	IsActive            bool       `json:"is_active"`
+	PhotoURL            string     `json:"photo_url"`
+	AcademicStatus      string     `json:"academic_status"`
+	AcademicStatusSince time.Time  `json:"academic_status_since"`
 }
 
 type UpdateAdvisorRequest struct {
-	AdvisorID string `json:"advisor_id" validate:"required"`
-}
\ No newline at end of file
+	AdvisorID string `json:"advisor_id" validate:"required,uuid"`
+	Reason    string `json:"reason"`
+	Force     bool   `json:"force"` // abaikan batas kapasitas dosen wali
+}
+
+const (
+	DistributionRoundRobin  = "round_robin"
+	DistributionLoadBalance = "load_balanced"
+)
+
+// AdvisorChange adalah satu perpindahan dosen wali; periode sebelumnya ditutup pada waktu yang sama
+type AdvisorChange struct {
+	StudentID uuid.UUID
+	AdvisorID uuid.UUID
+	ChangedBy *uuid.UUID
+	Reason    string
+	At        time.Time
+}
+
+// AdvisorHistory adalah satu periode perwalian di student_advisor_history. EffectiveTo kosong berarti masih berjalan
+type AdvisorHistory struct {
+	ID            uuid.UUID  `json:"id"`
+	StudentID     uuid.UUID  `json:"student_id"`
+	AdvisorID     *uuid.UUID `json:"advisor_id"`
+	AdvisorName   string     `json:"advisor_name"`
+	EffectiveFrom time.Time  `json:"effective_from"`
+	EffectiveTo   *time.Time `json:"effective_to"`
+	ChangedBy     *uuid.UUID `json:"changed_by,omitempty"`
+	Reason        string     `json:"reason,omitempty"`
+}
+
+type ReassignAdviseesRequest struct {
+	ToLecturerIDs []string `json:"to_lecturer_ids" validate:"required,dive,uuid"`
+	Strategy      string   `json:"strategy" validate:"omitempty,oneof=round_robin load_balanced"` // round_robin (default) atau load_balanced
+	Reason        string   `json:"reason"`
+	Force         bool     `json:"force"` // abaikan batas kapasitas dosen tujuan
+}
+
+type AdviseeAssignment struct {
+	StudentID           uuid.UUID `json:"student_id"`
+	AdvisorID           uuid.UUID `json:"advisor_id"`
+	PendingAchievements int       `json:"pending_achievements"`
+}
+
+type ReassignAdviseesResult struct {
+	FromLecturerID      uuid.UUID           `json:"from_lecturer_id"`
+	Strategy            string              `json:"strategy"`
+	Moved               int                 `json:"moved"`
+	PendingAchievements int                 `json:"pending_achievements"` // prestasi 'submitted' yang kini diverifikasi dosen baru
+	Assignments         []AdviseeAssignment `json:"assignments"`
+}
+
+type AutoAssignAdvisorsRequest struct {
+	DryRun bool   `json:"dry_run"`
+	Reason string `json:"reason"`
+}
+
+type AutoAssignment struct {
+	StudentID    uuid.UUID  `json:"student_id"`
+	NIM          string     `json:"student_nim"`
+	ProgramStudy string     `json:"program_study"`
+	AdvisorID    *uuid.UUID `json:"advisor_id,omitempty"`
+	Reason       string     `json:"reason,omitempty"` // alasan jika tidak mendapat dosen wali
+}
+
+type AutoAssignAdvisorsResult struct {
+	DryRun     bool             `json:"dry_run"`
+	Assigned   []AutoAssignment `json:"assigned"`
+	Unassigned []AutoAssignment `json:"unassigned"`
+}
+
+// Status akademik mahasiswa
+const (
+	AcademicStatusActive     = "active"
+	AcademicStatusOnLeave    = "on_leave"
+	AcademicStatusGraduated  = "graduated"
+	AcademicStatusDroppedOut = "dropped_out"
+)
+
+type ChangeAcademicStatusRequest struct {
+	Status        string `json:"status" validate:"required,oneof=active on_leave graduated dropped_out"`
+	EffectiveDate string `json:"effective_date" validate:"omitempty,date"` // YYYY-MM-DD, default hari ini
+	Reason        string `json:"reason"`
+}
+
+// AcademicStatus adalah status akademik mahasiswa yang sedang berlaku
+type AcademicStatus struct {
+	StudentID uuid.UUID `json:"student_id"`
+	Status    string    `json:"academic_status"`
+	Since     time.Time `json:"academic_status_since"`
+}
+
+// AcademicStatusChange adalah satu perubahan status akademik; periode sebelumnya ditutup pada EffectiveFrom
+type AcademicStatusChange struct {
+	StudentID     uuid.UUID
+	Status        string
+	EffectiveFrom time.Time
+	ChangedBy     *uuid.UUID
+	Reason        string
+}
+
+// AcademicStatusHistory adalah satu periode status di student_status_history. EffectiveTo kosong berarti masih berjalan
+type AcademicStatusHistory struct {
+	ID            uuid.UUID  `json:"id"`
+	StudentID     uuid.UUID  `json:"student_id"`
+	Status        string     `json:"status"`
+	EffectiveFrom time.Time  `json:"effective_from"`
+	EffectiveTo   *time.Time `json:"effective_to"`
+	ChangedBy     *uuid.UUID `json:"changed_by,omitempty"`
+	ChangedByName string     `json:"changed_by_name,omitempty"`
+	Reason        string     `json:"reason,omitempty"`
+}
+
+// StudentWithAdvisor adalah profil mahasiswa beserta kontak dosen walinya. Advisor nil jika belum punya dosen wali
+type StudentWithAdvisor struct {
+	GetStudent
+	Advisor *AdvisorContact `json:"advisor"`
+}
+
+// RejectionNote adalah catatan penolakan satu prestasi. Judul diambil service dari MongoDB
+type RejectionNote struct {
+	AchievementID string    `json:"achievement_id"`
+	Title         string    `json:"title"`
+	Note          string    `json:"rejection_note"`
+	RejectedAt    time.Time `json:"rejected_at"`
+	MongoID       string    `json:"-"`
+}
+
+// SKPProgress adalah capaian poin prestasi verified terhadap syarat SKP kelulusan
+type SKPProgress struct {
+	Required  int     `json:"required"`
+	Earned    int     `json:"earned"`
+	Remaining int     `json:"remaining"`
+	Percent   float64 `json:"percent"`
+	Fulfilled bool    `json:"fulfilled"`
+}
+
+type StudentSummary struct {
+	Achievements     AchievementSummary `json:"achievements"`
+	SKP              SKPProgress        `json:"skp"`
+	RecentRejections []RejectionNote    `json:"recent_rejections"`
+}
@@ -0,0 +1,44 @@
+package models
+
+const (
+	ImportModeAtomic     = "atomic"      // semua baris dibuat atau tidak sama sekali
+	ImportModeBestEffort = "best_effort" // baris yang valid tetap dibuat walaupun baris lain gagal
+
+	ImportRowValid   = "valid"   // lolos validasi (dry run)
+	ImportRowInvalid = "invalid" // gagal validasi, tidak disimpan
+	ImportRowCreated = "created"
+	ImportRowFailed  = "failed"  // lolos validasi tapi gagal disimpan
+	ImportRowSkipped = "skipped" // valid, tapi tidak disimpan karena mode atomic dibatalkan
+)
+
+// ImportUserRow adalah satu baris file import mahasiswa
+type ImportUserRow struct {
+	Username     string `json:"username"`
+	Email        string `json:"email"`
+	FullName     string `json:"full_name"`
+	Password     string `json:"-"`
+	NIM          string `json:"nim"`
+	ProgramStudy string `json:"program_study"`
+	AcademicYear string `json:"academic_year"`
+	AdvisorCode  string `json:"advisor_code"`
+}
+
+type ImportUserRowResult struct {
+	Row             int      `json:"row"` // nomor baris di file (header = baris 1)
+	Username        string   `json:"username"`
+	NIM             string   `json:"nim"`
+	Status          string   `json:"status"`
+	Errors          []string `json:"errors,omitempty"`
+	InitialPassword string   `json:"initial_password,omitempty"` // hanya jika kolom password kosong, ditampilkan sekali
+}
+
+type ImportUsersResult struct {
+	Mode    string                `json:"mode"`
+	DryRun  bool                  `json:"dry_run"`
+	Total   int                   `json:"total"`
+	Valid   int                   `json:"valid"`
+	Invalid int                   `json:"invalid"`
+	Created int                   `json:"created"`
+	Failed  int                   `json:"failed"`
+	Rows    []ImportUserRowResult `json:"rows"`
+}
@@ -16,8 +16,12 @@ type User struct {
 	RoleID uuid.UUID `json:"role_id"`
 	RoleName string `json:"role_name"`
 	IsActive bool `json:"is_active"`
+	IsSuperAdmin bool `json:"is_super_admin"`
+	PhotoURL string `json:"photo_url"`
+	Language string `json:"language"` // id/en; kosong berarti mengikuti Accept-Language
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty"` // terisi jika user dihapus (soft delete)
 }
 
 type UserResponseDTO struct {
@@ -27,15 +31,17 @@ type UserResponseDTO struct {
     Role     string `json:"role"`
 }
 
+// CreateUserRequest: role_name harus sesuai role_id; profil student wajib untuk Mahasiswa dan lecture untuk Dosen Wali
 type CreateUserRequest struct {
-	Username string `json:"username"`
-	Email    string `json:"email"`
-	Password string `json:"password"`
-	FullName string `json:"full_name"`
-	RoleID   string `json:"role_id"`
-	RoleName string `json:"role_name"`
-	Student *Student `json:"student"` 
-	Lecture *Lecture `json:"lecture"`
+	Username string `json:"username" validate:"required,nospace,min=3,max=50"`
+	Email    string `json:"email" validate:"required,email,max=100"`
+	Password string `json:"password" validate:"required,min=8"`
+	FullName string `json:"full_name" validate:"required,max=100"`
+	RoleID   string `json:"role_id" validate:"required,uuid"`
+	RoleName string `json:"role_name" validate:"required"`
+	Student *Student `json:"student" validate:"required_if=RoleName Mahasiswa"` 
+	Lecture *Lecture `json:"lecture" validate:"required_if=RoleName Dosen Wali"`
+	Force    bool     `json:"force"` // abaikan kapasitas bimbingan dosen wali student.advisor_id
 }
 
 type CreateUser struct {
@@ -49,20 +55,44 @@ type CreateUser struct {
 }
 
 type UpdateUser struct {
-	Username string `json:"username"`
-	Email string `json:"email"`
-	FullName string `json:"full_name"`
-	RoleID uuid.UUID `json:"role_id"`
-	IsActive bool `json:"is_active"`
+	Username string `json:"username" validate:"required,nospace,min=3,max=50"`
+	Email string `json:"email" validate:"required,email,max=100"`
+	FullName string `json:"full_name" validate:"required,max=100"`
+	IsActive *bool `json:"is_active,omitempty"` // Kosong = tidak diubah. Role diganti lewat PUT /users/:id/role
+}
+
+// UserPurgePlan adalah semua data milik user yang ikut terhapus saat purge
+type UserPurgePlan struct {
+	User                 User
+	StudentIDs           []uuid.UUID
+	LecturerIDs          []uuid.UUID
+	MongoAchievementIDs  []string
+	VerifiedAchievements int
+	ActiveAdvisees       int
+	PhotoURLs            []string
+}
+
+type UserPurgeResult struct {
+	UserID               uuid.UUID `json:"user_id"`
+	Username             string    `json:"username"`
+	Students             int       `json:"students"`
+	Lecturers            int       `json:"lecturers"`
+	Achievements         int       `json:"achievements"`
+	VerifiedAchievements int       `json:"verified_achievements"`
+	FilesDeleted         int       `json:"files_deleted"`
+	Warnings             []string  `json:"warnings,omitempty"` // pembersihan MongoDB/file yang gagal setelah data Postgres terhapus
 }
 
 type UpdateRole struct {
-    RoleID string `json:"role_id"`
+    RoleID string `json:"role_id" validate:"required,uuid"`
+    Student *Student `json:"student"` // Wajib jika role baru Mahasiswa dan user belum pernah punya profil mahasiswa
+    Lecture *Lecture `json:"lecture"`
+    Force bool `json:"force"` // abaikan kapasitas bimbingan dosen wali student.advisor_id
 }
 
 type LoginRequest struct { 
-	Username string `json:"username"` 
-	Password string `json:"password"` 
+	Username string `json:"username" validate:"required"` 
+	Password string `json:"password" validate:"required"` 
 }
 
 type LoginResponse struct { 
@@ -75,9 +105,15 @@ type JWTClaims struct {
 	UserID   uuid.UUID  `json:"user_id"` 
 	Username string `json:"username"` 
 	RoleName string `json:"role_name"` 
+	Permissions []string `json:"permissions,omitempty"`
+	PermissionsExpiresAt *jwt.NumericDate `json:"perm_exp,omitempty"` // Batas waktu Permissions dipercaya; setelahnya middleware memakai resolver
+	ImpersonatorID *uuid.UUID `json:"impersonator_id,omitempty"` // Terisi hanya pada token impersonation
+	SessionID *uuid.UUID `json:"sid,omitempty"` // Sesi login asal token (kosong untuk token impersonation)
+	Language string `json:"lang,omitempty"` // Bahasa pilihan user untuk pesan response
+	TokenType string `json:"type"` // Selalu "access"; token refresh/MFA/SSO memakai type lain
 	jwt.RegisteredClaims
 }
 
 type RefreshTokenRequest struct {
-    RefreshToken string `json:"refreshToken"`
+    RefreshToken string `json:"refreshToken" validate:"required"`
 }
\ No newline at end of file
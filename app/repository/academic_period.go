@@ -0,0 +1,138 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+	"uas/app/models"
+
+	"github.com/google/uuid"
+)
+
+type AcademicPeriodRepository interface {
+	GetPeriods(ctx context.Context) ([]models.AcademicPeriod, error)
+	GetPeriodByID(ctx context.Context, id uuid.UUID) (models.AcademicPeriod, error)
+	CreatePeriod(ctx context.Context, period models.AcademicPeriod) error
+	UpdatePeriod(ctx context.Context, period models.AcademicPeriod) error
+	DeletePeriod(ctx context.Context, id uuid.UUID) error
+	ActivatePeriod(ctx context.Context, id uuid.UUID) error
+	ClosePeriod(ctx context.Context, id uuid.UUID, closedBy *uuid.UUID) error
+	ReopenPeriod(ctx context.Context, id uuid.UUID) error
+}
+
+type academicPeriodRepository struct {
+	db *sql.DB
+}
+
+func NewAcademicPeriodRepository(db *sql.DB) AcademicPeriodRepository {
+	return &academicPeriodRepository{db: db}
+}
+
+// Prestasi masuk ke periode yang rentang tanggalnya memuat event_date
+const academicPeriodSelect = `
+	SELECT p.id, p.name, p.start_date, p.end_date, p.is_active, p.closed_at, p.closed_by,
+		(SELECT count(1) FROM achievement_references ar
+			WHERE ar.deleted_at IS NULL AND ar.event_date BETWEEN p.start_date AND p.end_date),
+		p.created_at, p.updated_at
+	FROM academic_periods p
+`
+
+// achievementPeriodJoin menghubungkan achievement_references (alias ar) ke periode akademiknya (alias ap)
+const achievementPeriodJoin = `
+	LEFT JOIN academic_periods ap ON ar.event_date BETWEEN ap.start_date AND ap.end_date
+`
+
+func scanAcademicPeriod(row interface{ Scan(...interface{}) error }) (models.AcademicPeriod, error) {
+	var p models.AcademicPeriod
+	var closedBy uuid.NullUUID
+	err := row.Scan(&p.ID, &p.Name, &p.StartDate, &p.EndDate, &p.IsActive, &p.ClosedAt, &closedBy,
+		&p.Achievements, &p.CreatedAt, &p.UpdatedAt)
+	if closedBy.Valid {
+		p.ClosedBy = &closedBy.UUID
+	}
+	return p, err
+}
+
+// getAcademicPeriodByDate mencari periode yang memuat tanggal tertentu; sql.ErrNoRows jika di luar semua periode
+func getAcademicPeriodByDate(ctx context.Context, q rowQuerier, date time.Time) (models.AcademicPeriod, error) {
+	return scanAcademicPeriod(q.QueryRowContext(ctx, academicPeriodSelect+`
+		WHERE $1::date BETWEEN p.start_date AND p.end_date
+	`, date.Format("2006-01-02")))
+}
+
+func (r *academicPeriodRepository) GetPeriods(ctx context.Context) ([]models.AcademicPeriod, error) {
+	rows, err := r.db.QueryContext(ctx, academicPeriodSelect+` ORDER BY p.start_date DESC`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var periods []models.AcademicPeriod
+	for rows.Next() {
+		p, err := scanAcademicPeriod(rows)
+		if err != nil {
+			return nil, err
+		}
+		periods = append(periods, p)
+	}
+	return periods, rows.Err()
+}
+
+func (r *academicPeriodRepository) GetPeriodByID(ctx context.Context, id uuid.UUID) (models.AcademicPeriod, error) {
+	return scanAcademicPeriod(r.db.QueryRowContext(ctx, academicPeriodSelect+` WHERE p.id = $1`, id))
+}
+
+func (r *academicPeriodRepository) CreatePeriod(ctx context.Context, period models.AcademicPeriod) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO academic_periods (id, name, start_date, end_date, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $5)
+	`, period.ID, period.Name, period.StartDate, period.EndDate, period.CreatedAt)
+	return err
+}
+
+// UpdatePeriod hanya mengubah periode yang belum ditutup; sql.ErrNoRows jika tidak ada atau sudah ditutup
+func (r *academicPeriodRepository) UpdatePeriod(ctx context.Context, period models.AcademicPeriod) error {
+	return execAffectingOne(ctx, r.db, `
+		UPDATE academic_periods SET name = $1, start_date = $2, end_date = $3, updated_at = NOW()
+		WHERE id = $4 AND closed_at IS NULL
+	`, period.Name, period.StartDate, period.EndDate, period.ID)
+}
+
+func (r *academicPeriodRepository) DeletePeriod(ctx context.Context, id uuid.UUID) error {
+	return execAffectingOne(ctx, r.db, `DELETE FROM academic_periods WHERE id = $1`, id)
+}
+
+// ActivatePeriod menjadikan satu periode aktif dan menonaktifkan yang lain dalam satu transaksi
+func (r *academicPeriodRepository) ActivatePeriod(ctx context.Context, id uuid.UUID) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE academic_periods SET is_active = FALSE, updated_at = NOW() WHERE is_active AND id <> $1
+	`, id); err != nil {
+		return err
+	}
+	if err := execAffectingOne(ctx, tx, `
+		UPDATE academic_periods SET is_active = TRUE, updated_at = NOW() WHERE id = $1 AND closed_at IS NULL
+	`, id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// ClosePeriod menutup periode; periode yang ditutup otomatis tidak lagi aktif
+func (r *academicPeriodRepository) ClosePeriod(ctx context.Context, id uuid.UUID, closedBy *uuid.UUID) error {
+	return execAffectingOne(ctx, r.db, `
+		UPDATE academic_periods SET closed_at = NOW(), closed_by = $2, is_active = FALSE, updated_at = NOW()
+		WHERE id = $1
+	`, id, closedBy)
+}
+
+func (r *academicPeriodRepository) ReopenPeriod(ctx context.Context, id uuid.UUID) error {
+	return execAffectingOne(ctx, r.db, `
+		UPDATE academic_periods SET closed_at = NULL, closed_by = NULL, updated_at = NOW() WHERE id = $1
+	`, id)
+}
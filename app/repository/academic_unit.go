@@ -0,0 +1,270 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"uas/app/models"
+
+	"github.com/google/uuid"
+)
+
+type AcademicUnitRepository interface {
+	GetFaculties(ctx context.Context) ([]models.Faculty, error)
+	GetFacultyByID(ctx context.Context, id uuid.UUID) (models.Faculty, error)
+	CreateFaculty(ctx context.Context, faculty models.Faculty) error
+	UpdateFaculty(ctx context.Context, faculty models.Faculty) error
+	DeleteFaculty(ctx context.Context, id uuid.UUID) error
+
+	GetDepartments(ctx context.Context, facultyID *uuid.UUID) ([]models.Department, error)
+	GetDepartmentByID(ctx context.Context, id uuid.UUID) (models.Department, error)
+	CreateDepartment(ctx context.Context, department models.Department) error
+	UpdateDepartment(ctx context.Context, department models.Department) error
+	DeleteDepartment(ctx context.Context, id uuid.UUID) error
+
+	GetProgramStudies(ctx context.Context, departmentID *uuid.UUID) ([]models.ProgramStudy, error)
+	GetProgramStudyByID(ctx context.Context, id uuid.UUID) (models.ProgramStudy, error)
+	CreateProgramStudy(ctx context.Context, programStudy models.ProgramStudy) error
+	UpdateProgramStudy(ctx context.Context, programStudy models.ProgramStudy) error
+	DeleteProgramStudy(ctx context.Context, id uuid.UUID) error
+}
+
+// Dikembalikan saat profil mahasiswa/dosen merujuk unit akademik lewat nama yang tidak terdaftar
+var (
+	ErrUnknownProgramStudy = errors.New("program studi tidak terdaftar")
+	ErrUnknownDepartment   = errors.New("department tidak terdaftar")
+)
+
+type rowQuerier interface {
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// resolveUnitID memastikan unit akademik ada, dicari lewat id atau (jika id kosong) lewat namanya tanpa
+// membedakan huruf besar/kecil dan spasi berlebih. nil jika keduanya kosong; unknown jika tidak terdaftar
+func resolveUnitID(ctx context.Context, q rowQuerier, table string, id uuid.UUID, name string, unknown error) (interface{}, error) {
+	query, arg := `SELECT id FROM `+table+` WHERE id = $1`, interface{}(id)
+	if id == uuid.Nil {
+		name = strings.Join(strings.Fields(name), " ")
+		if name == "" {
+			return nil, nil
+		}
+		query, arg = `SELECT id FROM `+table+` WHERE LOWER(name) = LOWER($1)`, name
+	}
+
+	var found uuid.UUID
+	err := q.QueryRowContext(ctx, query, arg).Scan(&found)
+	if err == sql.ErrNoRows {
+		return nil, unknown
+	}
+	if err != nil {
+		return nil, err
+	}
+	return found, nil
+}
+
+type rowExecer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+type academicUnitRepository struct {
+	db *sql.DB
+}
+
+func NewAcademicUnitRepository(db *sql.DB) AcademicUnitRepository {
+	return &academicUnitRepository{db: db}
+}
+
+// nullIfEmpty menyimpan kode kosong sebagai NULL agar tidak bentrok dengan constraint UNIQUE
+func nullIfEmpty(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+// execAffectingOne menjalankan UPDATE/DELETE satu baris; sql.ErrNoRows jika barisnya tidak ada
+func execAffectingOne(ctx context.Context, db rowExecer, query string, args ...interface{}) error {
+	result, err := db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+const facultySelect = `
+	SELECT f.id, COALESCE(f.code, ''), f.name,
+		(SELECT count(1) FROM departments d WHERE d.faculty_id = f.id),
+		f.created_at, f.updated_at
+	FROM faculties f
+`
+
+func scanFaculty(row interface{ Scan(...interface{}) error }) (models.Faculty, error) {
+	var f models.Faculty
+	err := row.Scan(&f.ID, &f.Code, &f.Name, &f.Departments, &f.CreatedAt, &f.UpdatedAt)
+	return f, err
+}
+
+func (r *academicUnitRepository) GetFaculties(ctx context.Context) ([]models.Faculty, error) {
+	rows, err := r.db.QueryContext(ctx, facultySelect+` ORDER BY f.name`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var faculties []models.Faculty
+	for rows.Next() {
+		f, err := scanFaculty(rows)
+		if err != nil {
+			return nil, err
+		}
+		faculties = append(faculties, f)
+	}
+	return faculties, rows.Err()
+}
+
+func (r *academicUnitRepository) GetFacultyByID(ctx context.Context, id uuid.UUID) (models.Faculty, error) {
+	return scanFaculty(r.db.QueryRowContext(ctx, facultySelect+` WHERE f.id = $1`, id))
+}
+
+func (r *academicUnitRepository) CreateFaculty(ctx context.Context, faculty models.Faculty) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO faculties (id, code, name, created_at, updated_at) VALUES ($1, $2, $3, $4, $4)
+	`, faculty.ID, nullIfEmpty(faculty.Code), faculty.Name, faculty.CreatedAt)
+	return err
+}
+
+func (r *academicUnitRepository) UpdateFaculty(ctx context.Context, faculty models.Faculty) error {
+	return execAffectingOne(ctx, r.db, `
+		UPDATE faculties SET code = $1, name = $2, updated_at = NOW() WHERE id = $3
+	`, nullIfEmpty(faculty.Code), faculty.Name, faculty.ID)
+}
+
+func (r *academicUnitRepository) DeleteFaculty(ctx context.Context, id uuid.UUID) error {
+	return execAffectingOne(ctx, r.db, `DELETE FROM faculties WHERE id = $1`, id)
+}
+
+const departmentSelect = `
+	SELECT d.id, d.faculty_id, f.name, COALESCE(d.code, ''), d.name,
+		(SELECT count(1) FROM program_studies ps WHERE ps.department_id = d.id),
+		(SELECT count(1) FROM lecturers l WHERE l.department_id = d.id AND l.retired_at IS NULL),
+		d.created_at, d.updated_at
+	FROM departments d
+	JOIN faculties f ON f.id = d.faculty_id
+`
+
+func scanDepartment(row interface{ Scan(...interface{}) error }) (models.Department, error) {
+	var d models.Department
+	err := row.Scan(&d.ID, &d.FacultyID, &d.FacultyName, &d.Code, &d.Name,
+		&d.ProgramStudies, &d.Lecturers, &d.CreatedAt, &d.UpdatedAt)
+	return d, err
+}
+
+// GetDepartments mengambil department, semua atau milik satu fakultas saja
+func (r *academicUnitRepository) GetDepartments(ctx context.Context, facultyID *uuid.UUID) ([]models.Department, error) {
+	rows, err := r.db.QueryContext(ctx, departmentSelect+`
+		WHERE ($1::uuid IS NULL OR d.faculty_id = $1)
+		ORDER BY f.name, d.name
+	`, facultyID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var departments []models.Department
+	for rows.Next() {
+		d, err := scanDepartment(rows)
+		if err != nil {
+			return nil, err
+		}
+		departments = append(departments, d)
+	}
+	return departments, rows.Err()
+}
+
+func (r *academicUnitRepository) GetDepartmentByID(ctx context.Context, id uuid.UUID) (models.Department, error) {
+	return scanDepartment(r.db.QueryRowContext(ctx, departmentSelect+` WHERE d.id = $1`, id))
+}
+
+func (r *academicUnitRepository) CreateDepartment(ctx context.Context, department models.Department) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO departments (id, faculty_id, code, name, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $5)
+	`, department.ID, department.FacultyID, nullIfEmpty(department.Code), department.Name, department.CreatedAt)
+	return err
+}
+
+func (r *academicUnitRepository) UpdateDepartment(ctx context.Context, department models.Department) error {
+	return execAffectingOne(ctx, r.db, `
+		UPDATE departments SET faculty_id = $1, code = $2, name = $3, updated_at = NOW() WHERE id = $4
+	`, department.FacultyID, nullIfEmpty(department.Code), department.Name, department.ID)
+}
+
+func (r *academicUnitRepository) DeleteDepartment(ctx context.Context, id uuid.UUID) error {
+	return execAffectingOne(ctx, r.db, `DELETE FROM departments WHERE id = $1`, id)
+}
+
+const programStudySelect = `
+	SELECT ps.id, ps.department_id, d.name, d.faculty_id, f.name, COALESCE(ps.code, ''), ps.name,
+		(SELECT count(1) FROM students s WHERE s.program_study_id = ps.id AND s.retired_at IS NULL),
+		ps.created_at, ps.updated_at
+	FROM program_studies ps
+	JOIN departments d ON d.id = ps.department_id
+	JOIN faculties f ON f.id = d.faculty_id
+`
+
+func scanProgramStudy(row interface{ Scan(...interface{}) error }) (models.ProgramStudy, error) {
+	var ps models.ProgramStudy
+	err := row.Scan(&ps.ID, &ps.DepartmentID, &ps.DepartmentName, &ps.FacultyID, &ps.FacultyName,
+		&ps.Code, &ps.Name, &ps.Students, &ps.CreatedAt, &ps.UpdatedAt)
+	return ps, err
+}
+
+// GetProgramStudies mengambil program studi, semua atau milik satu department saja
+func (r *academicUnitRepository) GetProgramStudies(ctx context.Context, departmentID *uuid.UUID) ([]models.ProgramStudy, error) {
+	rows, err := r.db.QueryContext(ctx, programStudySelect+`
+		WHERE ($1::uuid IS NULL OR ps.department_id = $1)
+		ORDER BY f.name, d.name, ps.name
+	`, departmentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var programStudies []models.ProgramStudy
+	for rows.Next() {
+		ps, err := scanProgramStudy(rows)
+		if err != nil {
+			return nil, err
+		}
+		programStudies = append(programStudies, ps)
+	}
+	return programStudies, rows.Err()
+}
+
+func (r *academicUnitRepository) GetProgramStudyByID(ctx context.Context, id uuid.UUID) (models.ProgramStudy, error) {
+	return scanProgramStudy(r.db.QueryRowContext(ctx, programStudySelect+` WHERE ps.id = $1`, id))
+}
+
+func (r *academicUnitRepository) CreateProgramStudy(ctx context.Context, programStudy models.ProgramStudy) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO program_studies (id, department_id, code, name, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $5)
+	`, programStudy.ID, programStudy.DepartmentID, nullIfEmpty(programStudy.Code), programStudy.Name, programStudy.CreatedAt)
+	return err
+}
+
+func (r *academicUnitRepository) UpdateProgramStudy(ctx context.Context, programStudy models.ProgramStudy) error {
+	return execAffectingOne(ctx, r.db, `
+		UPDATE program_studies SET department_id = $1, code = $2, name = $3, updated_at = NOW() WHERE id = $4
+	`, programStudy.DepartmentID, nullIfEmpty(programStudy.Code), programStudy.Name, programStudy.ID)
+}
+
+func (r *academicUnitRepository) DeleteProgramStudy(ctx context.Context, id uuid.UUID) error {
+	return execAffectingOne(ctx, r.db, `DELETE FROM program_studies WHERE id = $1`, id)
+}
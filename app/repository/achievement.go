@@ -14,6 +14,7 @@ import (
 
 type AchievementRepository interface {
 	GetStudentIDByUserID(ctx context.Context, userID string) (string, error)
+	GetStudentAcademicStatus(ctx context.Context, studentID string) (string, error)
 	CreateAchievementMongo(ctx context.Context, data models.AchievementMongo) (string, error)
 	CreateAchievementReference(ctx context.Context, ref models.AchievementReference) error
 	GetAchievementByID(ctx context.Context, id string) (models.AchievementReference, error)
@@ -24,11 +25,13 @@ type AchievementRepository interface {
     VerifyAchievement(ctx context.Context, id string, verifierUserID string) error
     RejectAchievement(ctx context.Context, id string, verifierUserID string, note string) error
     CheckStudentAdvisorRelationship(ctx context.Context, lecturerID string, studentID string) (bool, error)
-    GetAllReferences(ctx context.Context, filterUserID string) ([]models.AchievementReference, map[string]string, map[string]string, error)
+    GetAllReferences(ctx context.Context, filter models.AchievementFilter) ([]models.AchievementReference, map[string]models.AchievementOwner, error)
     GetMongoDetailsByIDs(ctx context.Context, mongoIDs []string) (map[string]models.AchievementMongo, error)
     GetAchievementReferenceWithDetail(ctx context.Context, id string) (models.AchievementResponse, error)
     GetMongoDetailByID(ctx context.Context, mongoID string) (models.AchievementMongo, error)
     AddAttachmentToMongo(ctx context.Context, mongoID string, attachment models.Attachment) error
+    DeleteMongoAchievements(ctx context.Context, mongoIDs []string) (int64, error)
+    GetAcademicPeriodByDate(ctx context.Context, date time.Time) (models.AcademicPeriod, error)
 }
 
 type achievementRepository struct {
@@ -50,6 +53,15 @@ func (r *achievementRepository) GetStudentIDByUserID(ctx context.Context, userID
 	return studentID, nil
 }
 
+func (r *achievementRepository) GetStudentAcademicStatus(ctx context.Context, studentID string) (string, error) {
+	var status string
+	err := r.pg.QueryRowContext(ctx, `SELECT academic_status FROM students WHERE id = $1`, studentID).Scan(&status)
+	if err != nil {
+		return "", err
+	}
+	return status, nil
+}
+
 // Simpan ke MongoDB
 func (r *achievementRepository) CreateAchievementMongo(ctx context.Context, data models.AchievementMongo) (string, error) {
 	collection := r.mongo.Collection("achievements")
@@ -71,25 +83,27 @@ func (r *achievementRepository) CreateAchievementMongo(ctx context.Context, data
 func (r *achievementRepository) CreateAchievementReference(ctx context.Context, ref models.AchievementReference) error {
 	query := `
 		INSERT INTO achievement_references (
-			id, student_id, mongo_achievement_id, status, created_at, updated_at
-		) VALUES ($1, $2, $3, $4, $5, $5)
+			id, student_id, mongo_achievement_id, status, event_date, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $6)
 	`
-	_, err := r.pg.ExecContext(ctx, query, ref.ID, ref.StudentID, ref.MongoAchievementID, "draft", time.Now())
+	_, err := r.pg.ExecContext(ctx, query, ref.ID, ref.StudentID, ref.MongoAchievementID, "draft", ref.EventDate, time.Now())
 	if err != nil {
 		return fmt.Errorf("gagal insert ke postgres: %w", err)
 	}
 	return nil
 }
 
-// Ambil Data Achievement berdasarkan ID (Postgres)
+// Ambil Data Achievement berdasarkan ID (Postgres), termasuk periode akademik dari tanggal kegiatannya
 func (r *achievementRepository) GetAchievementByID(ctx context.Context, id string) (models.AchievementReference, error) {
     query := `
-        SELECT id, student_id, mongo_achievement_id, status 
-        FROM achievement_references 
-        WHERE id = $1 AND deleted_at IS NULL
+        SELECT ar.id, ar.student_id, ar.mongo_achievement_id, ar.status, ar.event_date,
+            COALESCE(ap.name, ''), ap.closed_at IS NOT NULL
+        FROM achievement_references ar` + achievementPeriodJoin + `
+        WHERE ar.id = $1 AND ar.deleted_at IS NULL
     `
     var ref models.AchievementReference    
-    err := r.pg.QueryRowContext(ctx, query, id).Scan(&ref.ID, &ref.StudentID, &ref.MongoAchievementID, &ref.Status)
+    err := r.pg.QueryRowContext(ctx, query, id).Scan(&ref.ID, &ref.StudentID, &ref.MongoAchievementID, &ref.Status,
+        &ref.EventDate, &ref.PeriodName, &ref.PeriodClosed)
     if err != nil {
         return models.AchievementReference{}, err
     }
@@ -109,6 +123,7 @@ func (r *achievementRepository) UpdateAchievement(ctx context.Context, pgID stri
             "description":     data.Description,
             "details":         data.Details,
             "tags":            data.Tags,
+            "eventDate":       data.EventDate,
             "updatedAt":       time.Now(),
         },
     }
@@ -118,9 +133,9 @@ func (r *achievementRepository) UpdateAchievement(ctx context.Context, pgID stri
         return fmt.Errorf("gagal update mongo: %w", err)
     }
 
-    // Update PostgreSQL (Hanya updated_at)
-    queryPG := `UPDATE achievement_references SET updated_at = NOW() WHERE id = $1`
-    _, err = r.pg.ExecContext(ctx, queryPG, pgID)
+    // Update PostgreSQL (tanggal kegiatan untuk atribusi periode & updated_at)
+    queryPG := `UPDATE achievement_references SET event_date = $2, updated_at = NOW() WHERE id = $1`
+    _, err = r.pg.ExecContext(ctx, queryPG, pgID, data.EventDate)
     if err != nil {
         return fmt.Errorf("gagal update postgres: %w", err)
     }
@@ -146,6 +161,25 @@ func (r *achievementRepository) SoftDeleteAchievement(ctx context.Context, pgID
     return err
 }
 
+// DeleteMongoAchievements menghapus permanen dokumen prestasi di MongoDB (dipakai saat purge user)
+func (r *achievementRepository) DeleteMongoAchievements(ctx context.Context, mongoIDs []string) (int64, error) {
+    var objectIDs []primitive.ObjectID
+    for _, id := range mongoIDs {
+        if oid, err := primitive.ObjectIDFromHex(id); err == nil {
+            objectIDs = append(objectIDs, oid)
+        }
+    }
+    if len(objectIDs) == 0 {
+        return 0, nil
+    }
+
+    result, err := r.mongo.Collection("achievements").DeleteMany(ctx, bson.M{"_id": bson.M{"$in": objectIDs}})
+    if err != nil {
+        return 0, fmt.Errorf("gagal hapus dokumen mongo: %w", err)
+    }
+    return result.DeletedCount, nil
+}
+
 func (r *achievementRepository) SubmitAchievement(ctx context.Context, id string) error {
     query := `
         UPDATE achievement_references 
@@ -232,51 +266,56 @@ func (r *achievementRepository) CheckStudentAdvisorRelationship(ctx context.Cont
     return count > 0, nil
 }
 
-func (r *achievementRepository) GetAllReferences(ctx context.Context, filterUserID string) ([]models.AchievementReference, map[string]string, map[string]string, error) {
+func (r *achievementRepository) GetAllReferences(ctx context.Context, filter models.AchievementFilter) ([]models.AchievementReference, map[string]models.AchievementOwner, error) {
     query := `
         SELECT 
-            ar.id, ar.student_id, ar.mongo_achievement_id, ar.status, ar.created_at,
-            u.full_name, s.student_id as nim
+            ar.id, ar.student_id, ar.mongo_achievement_id, ar.status, ar.event_date,
+            COALESCE(ap.name, ''), ap.closed_at IS NOT NULL, ar.created_at,
+            u.full_name, s.student_id as nim, COALESCE(u.photo_url, '')
         FROM achievement_references ar
         JOIN students s ON ar.student_id = s.id
-        JOIN users u ON s.user_id = u.id
-    `
+        JOIN users u ON s.user_id = u.id` + achievementPeriodJoin
     var args []interface{}
-    if filterUserID != "" {
+    if filter.StudentUserID != "" {
         query += ` WHERE u.id = $1`
-        args = append(args, filterUserID)
+        args = append(args, filter.StudentUserID)
+    } else if filter.AdvisorUserID != "" {
+        // Inbox dosen wali: hanya mahasiswa bimbingan, yang sedang cuti disembunyikan
+        query += `
+        JOIN lecturers l ON s.advisor_id = l.id
+        WHERE l.user_id = $1 AND s.academic_status <> 'on_leave'`
+        args = append(args, filter.AdvisorUserID)
     }
 
     query += ` ORDER BY ar.created_at DESC`
 
     rows, err := r.pg.QueryContext(ctx, query, args...)
     if err != nil {
-        return nil, nil, nil, err
+        return nil, nil, err
     }
     defer rows.Close()
 
     var refs []models.AchievementReference
-    studentNames := make(map[string]string)
-    studentNIMs := make(map[string]string)
+    owners := make(map[string]models.AchievementOwner)
 
     for rows.Next() {
         var ref models.AchievementReference
-        var fullName, nim string
+        var owner models.AchievementOwner
         
         err := rows.Scan(
-            &ref.ID, &ref.StudentID, &ref.MongoAchievementID, &ref.Status, &ref.CreatedAt,
-            &fullName, &nim,
+            &ref.ID, &ref.StudentID, &ref.MongoAchievementID, &ref.Status, &ref.EventDate,
+            &ref.PeriodName, &ref.PeriodClosed, &ref.CreatedAt,
+            &owner.Name, &owner.NIM, &owner.PhotoURL,
         )
         if err != nil {
-            return nil, nil, nil, err
+            return nil, nil, err
         }
 
         refs = append(refs, ref)
-        studentNames[ref.ID] = fullName
-        studentNIMs[ref.ID] = nim
+        owners[ref.ID] = owner
     }
 
-    return refs, studentNames, studentNIMs, nil
+    return refs, owners, nil
 }
 
 func (r *achievementRepository) GetMongoDetailsByIDs(ctx context.Context, mongoIDs []string) (map[string]models.AchievementMongo, error) {
@@ -310,10 +349,11 @@ func (r *achievementRepository) GetAchievementReferenceWithDetail(ctx context.Co
         SELECT 
             ar.id, ar.student_id, ar.mongo_achievement_id, ar.status, 
             ar.created_at, ar.submitted_at, ar.verified_at, ar.rejection_note,
-            u.full_name, s.student_id as nim
+            ar.event_date, COALESCE(ap.name, ''),
+            u.full_name, s.student_id as nim, COALESCE(u.photo_url, '')
         FROM achievement_references ar
         JOIN students s ON ar.student_id = s.id
-        JOIN users u ON s.user_id = u.id
+        JOIN users u ON s.user_id = u.id` + achievementPeriodJoin + `
         WHERE ar.id = $1 AND ar.deleted_at IS NULL
     `
     
@@ -324,7 +364,8 @@ func (r *achievementRepository) GetAchievementReferenceWithDetail(ctx context.Co
     err := r.pg.QueryRowContext(ctx, query, id).Scan(
         &res.ID, &res.StudentID, &res.MongoID, &res.Status,
         &res.CreatedAt, &submittedAt, &verifiedAt, &rejectionNote,
-        &res.StudentName, &res.StudentNIM,
+        &res.EventDate, &res.PeriodName,
+        &res.StudentName, &res.StudentNIM, &res.StudentPhotoURL,
     )
     if err != nil {
         return models.AchievementResponse{}, err
@@ -372,4 +413,9 @@ func (r *achievementRepository) AddAttachmentToMongo(ctx context.Context, mongoI
     }
 
     return nil
+}
+
+// GetAcademicPeriodByDate mencari periode akademik yang memuat tanggal kegiatan prestasi
+func (r *achievementRepository) GetAcademicPeriodByDate(ctx context.Context, date time.Time) (models.AcademicPeriod, error) {
+    return getAcademicPeriodByDate(ctx, r.pg, date)
 }
\ No newline at end of file
@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"uas/app/models"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+type APIKeyRepository interface {
+	CreateAPIKey(ctx context.Context, key models.APIKey) error
+	GetAPIKeyByHash(ctx context.Context, keyHash string) (models.APIKey, error)
+	GetAllAPIKeys(ctx context.Context) ([]models.APIKey, error)
+	RevokeAPIKey(ctx context.Context, id uuid.UUID) error
+	TouchAPIKey(ctx context.Context, id uuid.UUID, ip string) error
+}
+
+type apiKeyRepository struct {
+	db *sql.DB
+}
+
+func NewAPIKeyRepository(db *sql.DB) APIKeyRepository {
+	return &apiKeyRepository{db: db}
+}
+
+const apiKeyColumns = `
+	id, name, key_prefix, key_hash, scopes, ip_allowlist, created_by,
+	expires_at, last_used_at, COALESCE(last_used_ip, ''), revoked_at, created_at
+`
+
+func scanAPIKey(row interface{ Scan(...interface{}) error }) (models.APIKey, error) {
+	var key models.APIKey
+	err := row.Scan(
+		&key.ID, &key.Name, &key.KeyPrefix, &key.KeyHash,
+		pq.Array(&key.Scopes), pq.Array(&key.IPAllowlist), &key.CreatedBy,
+		&key.ExpiresAt, &key.LastUsedAt, &key.LastUsedIP, &key.RevokedAt, &key.CreatedAt,
+	)
+	return key, err
+}
+
+func (r *apiKeyRepository) CreateAPIKey(ctx context.Context, key models.APIKey) error {
+	query := `
+		INSERT INTO api_keys (
+			id, name, key_prefix, key_hash, scopes, ip_allowlist, created_by, expires_at, created_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`
+	_, err := r.db.ExecContext(ctx, query,
+		key.ID, key.Name, key.KeyPrefix, key.KeyHash,
+		pq.Array(key.Scopes), pq.Array(key.IPAllowlist), key.CreatedBy, key.ExpiresAt, key.CreatedAt,
+	)
+	return err
+}
+
+func (r *apiKeyRepository) GetAPIKeyByHash(ctx context.Context, keyHash string) (models.APIKey, error) {
+	query := `SELECT ` + apiKeyColumns + ` FROM api_keys WHERE key_hash = $1`
+	return scanAPIKey(r.db.QueryRowContext(ctx, query, keyHash))
+}
+
+func (r *apiKeyRepository) GetAllAPIKeys(ctx context.Context) ([]models.APIKey, error) {
+	query := `SELECT ` + apiKeyColumns + ` FROM api_keys ORDER BY created_at DESC`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("gagal query api keys: %w", err)
+	}
+	defer rows.Close()
+
+	var keys []models.APIKey
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, fmt.Errorf("gagal scanning api key: %w", err)
+		}
+		keys = append(keys, key)
+	}
+	return keys, rows.Err()
+}
+
+func (r *apiKeyRepository) RevokeAPIKey(ctx context.Context, id uuid.UUID) error {
+	result, err := r.db.ExecContext(ctx, `UPDATE api_keys SET revoked_at = NOW() WHERE id = $1 AND revoked_at IS NULL`, id)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// TouchAPIKey mencatat waktu & IP pemakaian terakhir
+func (r *apiKeyRepository) TouchAPIKey(ctx context.Context, id uuid.UUID, ip string) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE api_keys SET last_used_at = NOW(), last_used_ip = $2 WHERE id = $1`, id, ip)
+	return err
+}
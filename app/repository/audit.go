@@ -0,0 +1,43 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"uas/app/models"
+)
+
+type AuditRepository interface {
+	CreateAuditLog(ctx context.Context, log models.AuditLog) error
+}
+
+type auditRepository struct {
+	db *sql.DB
+}
+
+func NewAuditRepository(db *sql.DB) AuditRepository {
+	return &auditRepository{db: db}
+}
+
+func (r *auditRepository) CreateAuditLog(ctx context.Context, log models.AuditLog) error {
+	var metadata interface{}
+	if log.Metadata != nil {
+		raw, err := json.Marshal(log.Metadata)
+		if err != nil {
+			return fmt.Errorf("gagal encode metadata audit: %w", err)
+		}
+		metadata = string(raw)
+	}
+
+	query := `
+		INSERT INTO audit_logs (
+			id, actor_id, subject_user_id, action, method, path, status_code, ip_address, metadata, created_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	`
+	_, err := r.db.ExecContext(ctx, query,
+		log.ID, log.ActorID, log.SubjectUserID, log.Action, log.Method, log.Path,
+		log.StatusCode, log.IPAddress, metadata, log.CreatedAt,
+	)
+	return err
+}
@@ -5,13 +5,22 @@ import (
 	"database/sql"
 	"fmt"
 	"uas/app/models"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
 type LecturerRepository interface {
 	CreateLecture(ctx context.Context, tx *sql.Tx, lecture models.Lecture) error
-	GetAllLecturersByRole(ctx context.Context, roleName string) ([]models.GetLecture, error)
+	GetAllLecturersByRole(ctx context.Context, roleName string, q models.ListQuery) ([]models.GetLecture, models.ListMeta, error)
 	GetLecturerByID(ctx context.Context, id string) (models.GetLecture, error)
-	GetAdviseesByLecturerID(ctx context.Context, lecturerID string) ([]models.GetStudent, error)
+	GetLecturerByUserID(ctx context.Context, userID string) (models.GetLecture, error)
+	GetAdviseesByLecturerID(ctx context.Context, lecturerID string, q models.ListQuery) ([]models.Advisee, models.ListMeta, error)
+	RetireLecturer(ctx context.Context, tx *sql.Tx, userID uuid.UUID) error
+	ReactivateLecturer(ctx context.Context, tx *sql.Tx, userID uuid.UUID) (bool, error)
+	GetLecturerIDsByCodes(ctx context.Context, codes []string) (map[string]uuid.UUID, error)
+	GetAdvisorLoads(ctx context.Context, tx *sql.Tx, lecturerIDs []uuid.UUID) ([]models.AdvisorLoad, error)
+	SetMaxAdvisees(ctx context.Context, lecturerID uuid.UUID, maxAdvisees *int) error
 }
 
 type lecturerRepository struct {
@@ -22,20 +31,30 @@ func NewLecturerRepository(db *sql.DB) LecturerRepository {
 	return &lecturerRepository{db: db}
 }
 
+// CreateLecture menyimpan profil dosen. Department boleh dikirim lewat ID atau nama;
+// ErrUnknownDepartment jika namanya tidak terdaftar
 func (r *lecturerRepository) CreateLecture(ctx context.Context, tx *sql.Tx, lecture models.Lecture) error {
 	query := `
 		INSERT INTO lecturers (
-			id, user_id, lecturer_id, department, created_at
+			id, user_id, lecturer_id, department_id, created_at
 		) VALUES ($1, $2, $3, $4, $5)
 	`
 
-	var err error
+	var q rowQuerier = r.db
+	if tx != nil {
+		q = tx
+	}
+	departmentID, err := resolveUnitID(ctx, q, "departments", lecture.DepartmentID, lecture.Department, ErrUnknownDepartment)
+	if err != nil {
+		return err
+	}
+
 	if tx != nil {
 		_, err = tx.ExecContext(ctx, query,
 			lecture.ID,
 			lecture.UserID,
 			lecture.LectureID,
-			lecture.Department,
+			departmentID,
 			lecture.CreatedAt,
 		)
 	} else {
@@ -43,7 +62,7 @@ func (r *lecturerRepository) CreateLecture(ctx context.Context, tx *sql.Tx, lect
 			lecture.ID,
 			lecture.UserID,
 			lecture.LectureID,
-			lecture.Department,
+			departmentID,
 			lecture.CreatedAt,
 		)
 	}
@@ -51,73 +70,116 @@ func (r *lecturerRepository) CreateLecture(ctx context.Context, tx *sql.Tx, lect
 	return err
 }
 
-func (r *lecturerRepository) GetAllLecturersByRole(ctx context.Context, roleName string) ([]models.GetLecture, error) {
+var lecturerListSpec = listSpec{
+	id:     "l.id",
+	search: []string{"u.full_name", "u.username", "u.email", "l.lecturer_id"},
+	filters: map[string]listFilter{
+		"is_active":     {expr: "u.is_active", boolean: true},
+		"department":    {expr: "d.name"},
+		"department_id": {expr: "l.department_id::text"},
+		"faculty_id":    {expr: "d.faculty_id::text"},
+	},
+	sorts: map[string]listSort{
+		"full_name":   {expr: "u.full_name", cast: "text"},
+		"lecturer_id": {expr: "COALESCE(l.lecturer_id, '')", cast: "text"},
+		"department":  {expr: "COALESCE(d.name, '')", cast: "text"},
+		"created_at":  {expr: "l.created_at", cast: "timestamp"},
+	},
+	defaultSort: "full_name",
+}
+
+func (r *lecturerRepository) GetAllLecturersByRole(ctx context.Context, roleName string, q models.ListQuery) ([]models.GetLecture, models.ListMeta, error) {
+	from := `
+		FROM lecturers l
+		JOIN users u ON l.user_id = u.id
+		JOIN roles r ON u.role_id = r.id
+		LEFT JOIN departments d ON d.id = l.department_id
+	`
+
+	lq, err := lecturerListSpec.build(q, []string{"r.name = $1", "u.deleted_at IS NULL"}, []interface{}{roleName})
+	if err != nil {
+		return nil, models.ListMeta{}, err
+	}
+
+	var total int
+	if err := r.db.QueryRowContext(ctx, "SELECT count(1)"+from+lq.countWhere, lq.countArgs...).Scan(&total); err != nil {
+		return nil, models.ListMeta{}, fmt.Errorf("gagal menghitung lecturers: %w", err)
+	}
+
 	query := `
 		SELECT 
 			l.id, 
 			l.user_id, 
 			l.lecturer_id, 
-			l.department, 
+			l.department_id,
+			COALESCE(d.name, ''),
 			u.full_name, 
 			u.username, 
 			u.email, 
 			u.is_active,
+			COALESCE(u.photo_url, ''),
 			l.created_at,
-			r.name as role_name
-		FROM lecturers l
-		JOIN users u ON l.user_id = u.id
-		JOIN roles r ON u.role_id = r.id
-		WHERE r.name = $1
-		ORDER BY u.full_name ASC
-	`
+			r.name as role_name,
+	` + lq.sortKey + from + lq.where + lq.orderLimit
 
-	rows, err := r.db.QueryContext(ctx, query, roleName)
+	rows, err := r.db.QueryContext(ctx, query, lq.args...)
 	if err != nil {
-		return nil, fmt.Errorf("gagal query lecturers: %w", err)
+		return nil, models.ListMeta{}, fmt.Errorf("gagal query lecturers: %w", err)
 	}
 	defer rows.Close()
 
 	var lecturers []models.GetLecture
+	var keys []models.ListCursor
 
 	for rows.Next() {
 		var l models.GetLecture
+		var key string
 		err := rows.Scan(
 			&l.ID,
 			&l.UserID,
 			&l.LecturerID,
+			&l.DepartmentID,
 			&l.Department,
 			&l.FullName,
 			&l.Username,
 			&l.Email,
 			&l.IsActive,
+			&l.PhotoURL,
 			&l.CreatedAt,
 			&l.RoleName,
+			&key,
 		)
 		if err != nil {
-			return nil, fmt.Errorf("gagal scanning row dosen: %w", err)
+			return nil, models.ListMeta{}, fmt.Errorf("gagal scanning row dosen: %w", err)
 		}
 		lecturers = append(lecturers, l)
+		keys = append(keys, models.ListCursor{Value: key, ID: l.ID})
 	}
 
-	return lecturers, nil
+	if err := rows.Err(); err != nil {
+		return nil, models.ListMeta{}, err
+	}
+
+	lecturers, meta := page(lecturers, keys, q, lq, total)
+	return lecturers, meta, nil
 }
 
-func (r *lecturerRepository) GetLecturerByID(ctx context.Context, id string) (models.GetLecture, error) {
-	query := `
-		SELECT 
-			l.id, l.user_id, l.lecturer_id, l.department, 
-			u.full_name, u.username, u.email, u.is_active, l.created_at,
-			r.name
-		FROM lecturers l
-		JOIN users u ON l.user_id = u.id
-		JOIN roles r ON u.role_id = r.id
-		WHERE l.id = $1
-	`
+const lecturerDetailSelect = `
+	SELECT 
+		l.id, l.user_id, l.lecturer_id, l.department_id, COALESCE(d.name, ''),
+		u.full_name, u.username, u.email, u.is_active, COALESCE(u.photo_url, ''), l.created_at,
+		r.name
+	FROM lecturers l
+	JOIN users u ON l.user_id = u.id
+	JOIN roles r ON u.role_id = r.id
+	LEFT JOIN departments d ON d.id = l.department_id
+`
 
+func scanLecturerDetail(row *sql.Row) (models.GetLecture, error) {
 	var l models.GetLecture
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
-		&l.ID, &l.UserID, &l.LecturerID, &l.Department,
-		&l.FullName, &l.Username, &l.Email, &l.IsActive, &l.CreatedAt,
+	err := row.Scan(
+		&l.ID, &l.UserID, &l.LecturerID, &l.DepartmentID, &l.Department,
+		&l.FullName, &l.Username, &l.Email, &l.IsActive, &l.PhotoURL, &l.CreatedAt,
 		&l.RoleName,
 	)
 
@@ -128,53 +190,237 @@ func (r *lecturerRepository) GetLecturerByID(ctx context.Context, id string) (mo
 	return l, nil
 }
 
-func (r *lecturerRepository) GetAdviseesByLecturerID(ctx context.Context, lecturerID string) ([]models.GetStudent, error) {
+func (r *lecturerRepository) GetLecturerByID(ctx context.Context, id string) (models.GetLecture, error) {
+	return scanLecturerDetail(r.db.QueryRowContext(ctx, lecturerDetailSelect+` WHERE l.id = $1`, id))
+}
+
+// GetLecturerByUserID mengambil profil dosen aktif (belum dipensiunkan) milik user
+func (r *lecturerRepository) GetLecturerByUserID(ctx context.Context, userID string) (models.GetLecture, error) {
+	return scanLecturerDetail(r.db.QueryRowContext(ctx, lecturerDetailSelect+`
+		WHERE l.user_id = $1 AND l.retired_at IS NULL
+	`, userID))
+}
+
+var adviseeListSpec = listSpec{
+	id:     "s.id",
+	search: []string{"u.full_name", "u.username", "u.email", "s.student_id"},
+	filters: map[string]listFilter{
+		"is_active":        {expr: "u.is_active", boolean: true},
+		"program_study_id": {expr: "s.program_study_id::text"},
+		"academic_year":    {expr: "s.academy_year"},
+		"academic_status":  {expr: "s.academic_status"},
+	},
+	sorts: map[string]listSort{
+		"full_name":     {expr: "u.full_name", cast: "text"},
+		"nim":           {expr: "s.student_id", cast: "text"},
+		"academic_year": {expr: "COALESCE(s.academy_year, '')", cast: "text"},
+		"achievements":  {expr: "a.total", cast: "bigint"},
+		"verified":      {expr: "a.verified", cast: "bigint"},
+	},
+	defaultSort: "full_name",
+}
+
+// GetAdviseesByLecturerID mengambil mahasiswa bimbingan (dengan paginasi) beserta jumlah prestasinya per status
+func (r *lecturerRepository) GetAdviseesByLecturerID(ctx context.Context, lecturerID string, q models.ListQuery) ([]models.Advisee, models.ListMeta, error) {
+	from := `
+		FROM students s
+		JOIN users u ON s.user_id = u.id
+		JOIN roles r ON u.role_id = r.id
+		LEFT JOIN program_studies ps ON ps.id = s.program_study_id
+		CROSS JOIN LATERAL (
+			SELECT
+				count(*) AS total,
+				count(*) FILTER (WHERE ar.status = 'draft') AS draft,
+				count(*) FILTER (WHERE ar.status = 'submitted') AS submitted,
+				count(*) FILTER (WHERE ar.status = 'verified') AS verified,
+				count(*) FILTER (WHERE ar.status = 'rejected') AS rejected,
+				COALESCE(array_agg(ar.mongo_achievement_id) FILTER (WHERE ar.status = 'verified'), '{}') AS verified_ids
+			FROM achievement_references ar
+			WHERE ar.student_id = s.id AND ar.deleted_at IS NULL
+		) a
+	`
+
+	l, err := adviseeListSpec.build(q, []string{"s.advisor_id = $1", "u.deleted_at IS NULL"}, []interface{}{lecturerID})
+	if err != nil {
+		return nil, models.ListMeta{}, err
+	}
+
+	var total int
+	if err := r.db.QueryRowContext(ctx, "SELECT count(1)"+from+l.countWhere, l.countArgs...).Scan(&total); err != nil {
+		return nil, models.ListMeta{}, fmt.Errorf("gagal menghitung advisees: %w", err)
+	}
+
 	query := `
 		SELECT 
 			s.id, 
 			s.user_id, 
 			s.student_id, 
-			s.program_study, 
+			s.program_study_id,
+			COALESCE(ps.name, ''),
 			s.academy_year,
 			u.full_name, 
 			u.username, 
 			u.email,
 			u.is_active,
-			r.name as role_name
-		FROM students s
-		JOIN users u ON s.user_id = u.id
-		JOIN roles r ON u.role_id = r.id
-		WHERE s.advisor_id = $1
-		ORDER BY u.full_name ASC
-	`
+			COALESCE(u.photo_url, ''),
+			r.name as role_name,
+			s.academic_status,
+			s.academic_status_since,
+			a.total, a.draft, a.submitted, a.verified, a.rejected, a.verified_ids,
+	` + l.sortKey + from + l.where + l.orderLimit
 
-	rows, err := r.db.QueryContext(ctx, query, lecturerID)
+	rows, err := r.db.QueryContext(ctx, query, l.args...)
 	if err != nil {
-		return nil, fmt.Errorf("gagal query advisees: %w", err)
+		return nil, models.ListMeta{}, fmt.Errorf("gagal query advisees: %w", err)
 	}
 	defer rows.Close()
 
-	var students []models.GetStudent
+	var advisees []models.Advisee
+	var keys []models.ListCursor
 
 	for rows.Next() {
-		var s models.GetStudent
+		var a models.Advisee
+		var draft, submitted, verified, rejected int64
+		var key string
 		err := rows.Scan(
-			&s.ID,
-			&s.UserID,
-			&s.NIM,
-			&s.ProgramStudy,
-			&s.AcademyYear, 
-			&s.FullName,
-			&s.Username,
-			&s.Email,
-			&s.IsActive,
-			&s.RoleName,
+			&a.ID,
+			&a.UserID,
+			&a.NIM,
+			&a.ProgramStudyID,
+			&a.ProgramStudy,
+			&a.AcademyYear, 
+			&a.FullName,
+			&a.Username,
+			&a.Email,
+			&a.IsActive,
+			&a.PhotoURL,
+			&a.RoleName,
+			&a.AcademicStatus,
+			&a.AcademicStatusSince,
+			&a.Achievements.Total, &draft, &submitted, &verified, &rejected, pq.Array(&a.VerifiedMongoIDs),
+			&key,
 		)
 		if err != nil {
-			return nil, fmt.Errorf("gagal scanning row mahasiswa bimbingan: %w", err)
+			return nil, models.ListMeta{}, fmt.Errorf("gagal scanning row mahasiswa bimbingan: %w", err)
+		}
+		a.Achievements.ByStatus = map[string]int64{"draft": draft, "submitted": submitted, "verified": verified, "rejected": rejected}
+		advisees = append(advisees, a)
+		keys = append(keys, models.ListCursor{Value: key, ID: a.ID})
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, models.ListMeta{}, fmt.Errorf("error iterasi rows: %w", err)
+	}
+
+	advisees, meta := page(advisees, keys, q, l, total)
+	return advisees, meta, nil
+}
+
+// RetireLecturer menandai profil lecturer milik user sebagai tidak aktif (dipakai saat role user berganti)
+func (r *lecturerRepository) RetireLecturer(ctx context.Context, tx *sql.Tx, userID uuid.UUID) error {
+	_, err := tx.ExecContext(ctx, `UPDATE lecturers SET retired_at = NOW() WHERE user_id = $1 AND retired_at IS NULL`, userID)
+	return err
+}
+
+// ReactivateLecturer memakai kembali profil lecturer lama milik user. false jika user belum pernah punya profil
+func (r *lecturerRepository) ReactivateLecturer(ctx context.Context, tx *sql.Tx, userID uuid.UUID) (bool, error) {
+	result, err := tx.ExecContext(ctx, `UPDATE lecturers SET retired_at = NULL WHERE user_id = $1`, userID)
+	if err != nil {
+		return false, err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rows > 0, nil
+}
+
+// GetLecturerIDsByCodes memetakan kode dosen (lecturers.lecturer_id) ke ID lecturer yang masih aktif
+func (r *lecturerRepository) GetLecturerIDsByCodes(ctx context.Context, codes []string) (map[string]uuid.UUID, error) {
+	query := `SELECT lecturer_id, id FROM lecturers WHERE lecturer_id = ANY($1) AND retired_at IS NULL`
+
+	rows, err := r.db.QueryContext(ctx, query, pq.Array(codes))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := make(map[string]uuid.UUID)
+	for rows.Next() {
+		var code string
+		var id uuid.UUID
+		if err := rows.Scan(&code, &id); err != nil {
+			return nil, err
 		}
-		students = append(students, s)
+		ids[code] = id
 	}
+	return ids, rows.Err()
+}
 
-	return students, nil
-}
\ No newline at end of file
+// GetAdvisorLoads mengambil jumlah bimbingan aktif dan kapasitas lecturer yang masih aktif, urut department.
+// lecturerIDs nil berarti semua lecturer. Di dalam transaksi baris lecturer dikunci agar pengecekan
+// kapasitas tidak balapan dengan penugasan lain
+func (r *lecturerRepository) GetAdvisorLoads(ctx context.Context, tx *sql.Tx, lecturerIDs []uuid.UUID) ([]models.AdvisorLoad, error) {
+	var ids []string
+	if lecturerIDs != nil {
+		ids = make([]string, len(lecturerIDs))
+		for i, id := range lecturerIDs {
+			ids[i] = id.String()
+		}
+	}
+
+	query := `
+		SELECT l.id, COALESCE(l.lecturer_id, ''), COALESCE(u.full_name, ''), l.department_id, COALESCE(d.name, ''), l.max_advisees,
+			(SELECT count(1) FROM students s WHERE s.advisor_id = l.id AND s.retired_at IS NULL
+				AND s.academic_status IN ('active', 'on_leave'))
+		FROM lecturers l
+		JOIN users u ON u.id = l.user_id
+		LEFT JOIN departments d ON d.id = l.department_id
+		WHERE l.retired_at IS NULL AND ($1::uuid[] IS NULL OR l.id = ANY($1::uuid[]))
+		ORDER BY d.name, u.full_name
+	`
+
+	var rows *sql.Rows
+	var err error
+	if tx != nil {
+		rows, err = tx.QueryContext(ctx, query+" FOR UPDATE OF l", pq.Array(ids))
+	} else {
+		rows, err = r.db.QueryContext(ctx, query, pq.Array(ids))
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var loads []models.AdvisorLoad
+	for rows.Next() {
+		var l models.AdvisorLoad
+		var maxAdvisees sql.NullInt64
+		if err := rows.Scan(&l.LecturerID, &l.Code, &l.FullName, &l.DepartmentID, &l.Department, &maxAdvisees, &l.Advisees); err != nil {
+			return nil, err
+		}
+		if maxAdvisees.Valid {
+			n := int(maxAdvisees.Int64)
+			l.MaxAdvisees = &n
+		}
+		loads = append(loads, l)
+	}
+	return loads, rows.Err()
+}
+
+// SetMaxAdvisees mengubah kapasitas bimbingan lecturer; nil kembali ke default.
+// Mengembalikan sql.ErrNoRows jika lecturer tidak ditemukan
+func (r *lecturerRepository) SetMaxAdvisees(ctx context.Context, lecturerID uuid.UUID, maxAdvisees *int) error {
+	result, err := r.db.ExecContext(ctx, `UPDATE lecturers SET max_advisees = $1 WHERE id = $2 AND retired_at IS NULL`, maxAdvisees, lecturerID)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
@@ -0,0 +1,183 @@
+package repository
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"uas/app/models"
+	"uas/i18n"
+)
+
+// ListQueryError menandakan parameter list tidak didukung oleh endpoint (filter/sort tidak dikenal, cursor tidak cocok).
+// Reason adalah varian pesan di katalog: errors.invalid_list_query.<Reason>
+type ListQueryError struct {
+	Reason string
+	Params i18n.Params
+}
+
+func (e *ListQueryError) Error() string {
+	return i18n.T(i18n.Default, "errors.invalid_list_query."+e.Reason, e.Params)
+}
+
+type listFilter struct {
+	expr    string
+	boolean bool
+}
+
+type listSort struct {
+	expr string
+	cast string // tipe SQL nilai sort, dipakai saat membandingkan cursor
+}
+
+// listSpec menjelaskan kolom yang boleh dicari, difilter, dan diurutkan pada satu endpoint list
+type listSpec struct {
+	id          string // ekspresi ID unik, pemecah seri urutan dan bagian dari cursor
+	search      []string
+	filters     map[string]listFilter
+	sorts       map[string]listSort
+	defaultSort string
+}
+
+// listSQL adalah potongan query hasil listSpec.build
+type listSQL struct {
+	countWhere string // WHERE tanpa kondisi cursor, untuk menghitung total
+	countArgs  []interface{}
+	where      string
+	args       []interface{}
+	orderLimit string
+	sortKey    string // ekspresi teks nilai sort, di-SELECT paling akhir untuk membuat cursor
+	sortName   string
+}
+
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+func whereClause(conds []string) string {
+	if len(conds) == 0 {
+		return ""
+	}
+	return " WHERE " + strings.Join(conds, " AND ")
+}
+
+// build menerjemahkan ListQuery menjadi SQL. base dan args adalah kondisi tetap milik endpoint beserta argumennya
+func (spec listSpec) build(q models.ListQuery, base []string, args []interface{}) (listSQL, error) {
+	conds := append([]string{}, base...)
+	args = append([]interface{}{}, args...)
+	arg := func(v interface{}) string {
+		args = append(args, v)
+		return "$" + strconv.Itoa(len(args))
+	}
+
+	names := make([]string, 0, len(q.Filters))
+	for name := range q.Filters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		f, ok := spec.filters[name]
+		if !ok {
+			return listSQL{}, &ListQueryError{Reason: "unknown_filter", Params: i18n.Params{"filter": name}}
+		}
+		value := q.Filters[name]
+		if f.boolean {
+			b, err := strconv.ParseBool(value)
+			if err != nil {
+				return listSQL{}, &ListQueryError{Reason: "boolean_filter", Params: i18n.Params{"filter": name}}
+			}
+			conds = append(conds, f.expr+" = "+arg(b))
+		} else {
+			conds = append(conds, "lower("+f.expr+") = lower("+arg(value)+")")
+		}
+	}
+
+	// Setiap kata pencarian harus cocok dengan salah satu kolom
+	for _, term := range strings.Fields(q.Search) {
+		p := arg("%" + escapeLike(term) + "%")
+		ors := make([]string, len(spec.search))
+		for i, expr := range spec.search {
+			ors[i] = expr + " ILIKE " + p
+		}
+		conds = append(conds, "("+strings.Join(ors, " OR ")+")")
+	}
+
+	sortName := q.Sort
+	if sortName == "" {
+		sortName = spec.defaultSort
+	}
+	sortDef, ok := spec.sorts[sortName]
+	if !ok {
+		keys := make([]string, 0, len(spec.sorts))
+		for k := range spec.sorts {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		return listSQL{}, &ListQueryError{Reason: "unknown_sort", Params: i18n.Params{"sorts": strings.Join(keys, ", ")}}
+	}
+
+	out := listSQL{
+		countWhere: whereClause(conds),
+		countArgs:  append([]interface{}{}, args...),
+		sortKey:    sortDef.expr + "::text",
+		sortName:   sortName,
+	}
+
+	dir, cmp := "ASC", ">"
+	if q.Desc {
+		dir, cmp = "DESC", "<"
+	}
+
+	if q.Cursor != nil && q.Cursor.ID != "" {
+		if q.Cursor.Sort != sortName || q.Cursor.Desc != q.Desc {
+			return listSQL{}, &ListQueryError{Reason: "cursor_mismatch"}
+		}
+		conds = append(conds, fmt.Sprintf("(%s, %s) %s (%s::%s, %s::uuid)",
+			sortDef.expr, spec.id, cmp, arg(q.Cursor.Value), sortDef.cast, arg(q.Cursor.ID)))
+	}
+
+	// Ambil satu baris lebih untuk mengetahui apakah masih ada halaman berikutnya
+	out.orderLimit = fmt.Sprintf(" ORDER BY %s %s, %s %s LIMIT %d", sortDef.expr, dir, spec.id, dir, q.Limit+1)
+	if q.Cursor == nil && q.Page > 1 {
+		out.orderLimit += fmt.Sprintf(" OFFSET %d", (q.Page-1)*q.Limit)
+	}
+
+	out.where = whereClause(conds)
+	out.args = args
+	return out, nil
+}
+
+// page memotong hasil query (maksimal limit+1 baris) dan menyusun metadata.
+// keys berisi nilai sort dan ID tiap baris untuk membuat cursor
+func page[T any](items []T, keys []models.ListCursor, q models.ListQuery, l listSQL, total int) ([]T, models.ListMeta) {
+	meta := models.ListMeta{
+		Total: total,
+		Limit: q.Limit,
+		Sort:  l.sortName,
+		Order: "asc",
+	}
+	if q.Desc {
+		meta.Order = "desc"
+	}
+
+	if len(items) > q.Limit {
+		items, keys = items[:q.Limit], keys[:q.Limit]
+		meta.HasMore = true
+	}
+
+	if q.Cursor != nil {
+		if meta.HasMore {
+			last := keys[len(keys)-1]
+			last.Sort, last.Desc = l.sortName, q.Desc
+			meta.NextCursor = last.Encode()
+		}
+	} else {
+		meta.Page = q.Page
+		meta.TotalPages = (total + q.Limit - 1) / q.Limit
+	}
+
+	if items == nil {
+		items = []T{}
+	}
+	return items, meta
+}
@@ -0,0 +1,171 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"uas/app/models"
+
+	"github.com/google/uuid"
+)
+
+type MFARepository interface {
+	GetMFAByUserID(ctx context.Context, userID uuid.UUID) (models.UserMFA, error)
+	SaveMFASecret(ctx context.Context, userID uuid.UUID, secret string) error
+	EnableMFA(ctx context.Context, userID uuid.UUID, recoveryCodeHashes []string) error
+	DisableMFA(ctx context.Context, userID uuid.UUID) error
+	ReplaceRecoveryCodes(ctx context.Context, userID uuid.UUID, recoveryCodeHashes []string) error
+	UseRecoveryCode(ctx context.Context, userID uuid.UUID, codeHash string) (bool, error)
+	UseTOTPStep(ctx context.Context, userID uuid.UUID, step int64) (bool, error)
+}
+
+type mfaRepository struct {
+	db *sql.DB
+}
+
+func NewMFARepository(db *sql.DB) MFARepository {
+	return &mfaRepository{db: db}
+}
+
+func (r *mfaRepository) GetMFAByUserID(ctx context.Context, userID uuid.UUID) (models.UserMFA, error) {
+	query := `
+		SELECT user_id, totp_secret, is_enabled, enabled_at, created_at
+		FROM user_mfa
+		WHERE user_id = $1
+	`
+
+	var mfa models.UserMFA
+	err := r.db.QueryRowContext(ctx, query, userID).Scan(
+		&mfa.UserID, &mfa.TOTPSecret, &mfa.IsEnabled, &mfa.EnabledAt, &mfa.CreatedAt,
+	)
+	return mfa, err
+}
+
+// SaveMFASecret menyimpan secret baru (enrollment ulang menonaktifkan MFA sampai dikonfirmasi)
+func (r *mfaRepository) SaveMFASecret(ctx context.Context, userID uuid.UUID, secret string) error {
+	query := `
+		INSERT INTO user_mfa (user_id, totp_secret, is_enabled, created_at, updated_at)
+		VALUES ($1, $2, FALSE, NOW(), NOW())
+		ON CONFLICT (user_id) DO UPDATE
+		SET totp_secret = EXCLUDED.totp_secret, is_enabled = FALSE, enabled_at = NULL, last_totp_step = NULL, updated_at = NOW()
+	`
+	_, err := r.db.ExecContext(ctx, query, userID, secret)
+	if err != nil {
+		return fmt.Errorf("gagal menyimpan secret MFA: %w", err)
+	}
+	return nil
+}
+
+func (r *mfaRepository) EnableMFA(ctx context.Context, userID uuid.UUID, recoveryCodeHashes []string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx,
+		`UPDATE user_mfa SET is_enabled = TRUE, enabled_at = NOW(), updated_at = NOW() WHERE user_id = $1`,
+		userID,
+	)
+	if err != nil {
+		return fmt.Errorf("gagal mengaktifkan MFA: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+
+	if err := replaceRecoveryCodes(ctx, tx, userID, recoveryCodeHashes); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *mfaRepository) DisableMFA(ctx context.Context, userID uuid.UUID) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM mfa_recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return fmt.Errorf("gagal menghapus kode pemulihan: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM user_mfa WHERE user_id = $1`, userID); err != nil {
+		return fmt.Errorf("gagal menonaktifkan MFA: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+func (r *mfaRepository) ReplaceRecoveryCodes(ctx context.Context, userID uuid.UUID, recoveryCodeHashes []string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := replaceRecoveryCodes(ctx, tx, userID, recoveryCodeHashes); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// UseRecoveryCode menandai kode pemulihan sebagai terpakai. Mengembalikan false jika kode tidak cocok/sudah dipakai.
+func (r *mfaRepository) UseRecoveryCode(ctx context.Context, userID uuid.UUID, codeHash string) (bool, error) {
+	query := `
+		UPDATE mfa_recovery_codes
+		SET used_at = NOW()
+		WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL
+	`
+	result, err := r.db.ExecContext(ctx, query, userID, codeHash)
+	if err != nil {
+		return false, err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rows > 0, nil
+}
+
+// UseTOTPStep mencatat time step kode TOTP yang diterima. Mengembalikan false jika step tersebut
+// (atau yang lebih baru) sudah pernah dipakai, sehingga kode yang sama tidak bisa diputar ulang
+func (r *mfaRepository) UseTOTPStep(ctx context.Context, userID uuid.UUID, step int64) (bool, error) {
+	query := `
+		UPDATE user_mfa
+		SET last_totp_step = $2, updated_at = NOW()
+		WHERE user_id = $1 AND (last_totp_step IS NULL OR last_totp_step < $2)
+	`
+	result, err := r.db.ExecContext(ctx, query, userID, step)
+	if err != nil {
+		return false, err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rows > 0, nil
+}
+
+func replaceRecoveryCodes(ctx context.Context, tx *sql.Tx, userID uuid.UUID, hashes []string) error {
+	if _, err := tx.ExecContext(ctx, `DELETE FROM mfa_recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return fmt.Errorf("gagal menghapus kode pemulihan lama: %w", err)
+	}
+
+	for _, hash := range hashes {
+		_, err := tx.ExecContext(ctx,
+			`INSERT INTO mfa_recovery_codes (id, user_id, code_hash, created_at) VALUES ($1, $2, $3, NOW())`,
+			uuid.New(), userID, hash,
+		)
+		if err != nil {
+			return fmt.Errorf("gagal menyimpan kode pemulihan: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+type PermissionRepository interface {
+	GetPermissionNamesByRole(ctx context.Context, roleName string) ([]string, error)
+}
+
+type permissionRepository struct {
+	db *sql.DB
+}
+
+func NewPermissionRepository(db *sql.DB) PermissionRepository {
+	return &permissionRepository{db: db}
+}
+
+func (r *permissionRepository) GetPermissionNamesByRole(ctx context.Context, roleName string) ([]string, error) {
+	query := `
+		SELECT p.name
+		FROM roles r
+		JOIN role_permissions rp ON r.id = rp.role_id
+		JOIN permissions p ON rp.permission_id = p.id
+		WHERE r.name = $1
+		ORDER BY p.name ASC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, roleName)
+	if err != nil {
+		return nil, fmt.Errorf("gagal query permission role: %w", err)
+	}
+	defer rows.Close()
+
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("gagal scanning permission: %w", err)
+		}
+		names = append(names, name)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterasi rows: %w", err)
+	}
+
+	return names, nil
+}
@@ -0,0 +1,218 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"uas/app/models"
+
+	"github.com/google/uuid"
+)
+
+type ProfileRepository interface {
+	GetProfile(ctx context.Context, userID uuid.UUID) (models.Profile, error)
+	UpdateProfileFields(ctx context.Context, userID uuid.UUID, fields models.ProfileFields) error
+	UpdatePhoto(ctx context.Context, userID uuid.UUID, photoURL string, thumbnails map[string]string) error
+	CreateEmailChange(ctx context.Context, req models.EmailChangeRequest) error
+	GetPendingEmailChangeForUpdate(ctx context.Context, tx *sql.Tx, tokenHash string) (models.EmailChangeRequest, error)
+	ConfirmEmailChange(ctx context.Context, tx *sql.Tx, req models.EmailChangeRequest) error
+}
+
+type profileRepository struct {
+	db *sql.DB
+}
+
+func NewProfileRepository(db *sql.DB) ProfileRepository {
+	return &profileRepository{db: db}
+}
+
+// GetProfile mengambil user beserta profil mahasiswa (dan dosen walinya) atau profil dosen yang masih aktif
+func (r *profileRepository) GetProfile(ctx context.Context, userID uuid.UUID) (models.Profile, error) {
+	query := `
+		SELECT u.id, u.username, u.email, u.full_name, r.name, COALESCE(u.phone, ''), COALESCE(u.photo_url, ''),
+			u.photo_thumbnails, COALESCE(u.bio, ''), COALESCE(u.language, ''), u.is_active, u.created_at,
+			(SELECT e.new_email FROM email_change_requests e
+				WHERE e.user_id = u.id AND e.confirmed_at IS NULL AND e.expires_at > NOW()
+				ORDER BY e.created_at DESC LIMIT 1),
+			s.id, s.student_id, sps.name, s.academy_year,
+			al.id, al.lecturer_id, au.full_name, au.email, au.phone, ald.name,
+			l.id, l.lecturer_id, ld.name, l.max_advisees,
+			(SELECT count(1) FROM students ls WHERE ls.advisor_id = l.id AND ls.retired_at IS NULL
+				AND ls.academic_status IN ('active', 'on_leave'))
+		FROM users u
+		JOIN roles r ON r.id = u.role_id
+		LEFT JOIN students s ON s.user_id = u.id AND s.retired_at IS NULL
+		LEFT JOIN program_studies sps ON sps.id = s.program_study_id
+		LEFT JOIN lecturers al ON al.id = s.advisor_id
+		LEFT JOIN departments ald ON ald.id = al.department_id
+		LEFT JOIN users au ON au.id = al.user_id
+		LEFT JOIN lecturers l ON l.user_id = u.id AND l.retired_at IS NULL
+		LEFT JOIN departments ld ON ld.id = l.department_id
+		WHERE u.id = $1
+	`
+
+	var p models.Profile
+	var pendingEmail sql.NullString
+	var thumbnails []byte
+	var studentID, advisorID, lecturerID uuid.NullUUID
+	var nim, programStudy, academicYear sql.NullString
+	var advisorCode, advisorName, advisorEmail, advisorPhone, advisorDept sql.NullString
+	var lecturerCode, department sql.NullString
+	var maxAdvisees sql.NullInt64
+	var advisees int
+
+	err := r.db.QueryRowContext(ctx, query, userID).Scan(
+		&p.ID, &p.Username, &p.Email, &p.FullName, &p.RoleName, &p.Phone, &p.PhotoURL,
+		&thumbnails, &p.Bio, &p.Language, &p.IsActive, &p.CreatedAt,
+		&pendingEmail,
+		&studentID, &nim, &programStudy, &academicYear,
+		&advisorID, &advisorCode, &advisorName, &advisorEmail, &advisorPhone, &advisorDept,
+		&lecturerID, &lecturerCode, &department, &maxAdvisees,
+		&advisees,
+	)
+	if err != nil {
+		return models.Profile{}, err
+	}
+
+	p.PendingEmail = pendingEmail.String
+	if len(thumbnails) > 0 {
+		if err := json.Unmarshal(thumbnails, &p.PhotoThumbnails); err != nil {
+			return models.Profile{}, err
+		}
+	}
+
+	if studentID.Valid {
+		p.Student = &models.StudentProfile{
+			ID:           studentID.UUID,
+			NIM:          nim.String,
+			ProgramStudy: programStudy.String,
+			AcademicYear: academicYear.String,
+		}
+		if advisorID.Valid {
+			p.Student.Advisor = &models.AdvisorContact{
+				ID:         advisorID.UUID,
+				LecturerID: advisorCode.String,
+				FullName:   advisorName.String,
+				Email:      advisorEmail.String,
+				Phone:      advisorPhone.String,
+				Department: advisorDept.String,
+			}
+		}
+	}
+
+	if lecturerID.Valid {
+		p.Lecturer = &models.LecturerProfile{
+			ID:         lecturerID.UUID,
+			LecturerID: lecturerCode.String,
+			Department: department.String,
+			Advisees:   advisees,
+		}
+		if maxAdvisees.Valid {
+			n := int(maxAdvisees.Int64)
+			p.Lecturer.MaxAdvisees = &n
+		}
+	}
+
+	return p, nil
+}
+
+// UpdateProfileFields menyimpan field profil yang boleh diubah sendiri; string kosong disimpan sebagai NULL.
+// Thumbnail foto dikosongkan jika photo_url diganti lewat endpoint ini
+func (r *profileRepository) UpdateProfileFields(ctx context.Context, userID uuid.UUID, fields models.ProfileFields) error {
+	query := `
+		UPDATE users
+		SET phone = NULLIF($1, ''), photo_url = NULLIF($2, ''), bio = NULLIF($3, ''), language = NULLIF($4, ''),
+			photo_thumbnails = CASE WHEN photo_url IS DISTINCT FROM NULLIF($2, '') THEN NULL ELSE photo_thumbnails END,
+			updated_at = NOW()
+		WHERE id = $5
+	`
+	result, err := r.db.ExecContext(ctx, query, fields.Phone, fields.PhotoURL, fields.Bio, fields.Language, userID)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// UpdatePhoto menyimpan foto profil hasil upload beserta thumbnail-nya. photoURL kosong menghapus foto
+func (r *profileRepository) UpdatePhoto(ctx context.Context, userID uuid.UUID, photoURL string, thumbnails map[string]string) error {
+	var thumbs interface{}
+	if len(thumbnails) > 0 {
+		raw, err := json.Marshal(thumbnails)
+		if err != nil {
+			return err
+		}
+		thumbs = string(raw)
+	}
+
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE users SET photo_url = NULLIF($1, ''), photo_thumbnails = $2::jsonb, updated_at = NOW()
+		WHERE id = $3
+	`, photoURL, thumbs, userID)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// CreateEmailChange menyimpan permintaan ganti email baru dan membatalkan permintaan lama yang belum dikonfirmasi
+func (r *profileRepository) CreateEmailChange(ctx context.Context, req models.EmailChangeRequest) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM email_change_requests WHERE user_id = $1 AND confirmed_at IS NULL`, req.UserID); err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO email_change_requests (id, user_id, new_email, token_hash, expires_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`, req.ID, req.UserID, req.NewEmail, req.TokenHash, req.ExpiresAt)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// GetPendingEmailChangeForUpdate mengunci permintaan ganti email yang belum dikonfirmasi dan belum kedaluwarsa
+func (r *profileRepository) GetPendingEmailChangeForUpdate(ctx context.Context, tx *sql.Tx, tokenHash string) (models.EmailChangeRequest, error) {
+	query := `
+		SELECT e.id, e.user_id, e.new_email, u.email, e.token_hash, e.expires_at
+		FROM email_change_requests e
+		JOIN users u ON u.id = e.user_id
+		WHERE e.token_hash = $1 AND e.confirmed_at IS NULL AND e.expires_at > NOW()
+		FOR UPDATE OF e
+	`
+
+	var req models.EmailChangeRequest
+	err := tx.QueryRowContext(ctx, query, tokenHash).Scan(
+		&req.ID, &req.UserID, &req.NewEmail, &req.OldEmail, &req.TokenHash, &req.ExpiresAt,
+	)
+	return req, err
+}
+
+// ConfirmEmailChange mengganti email user dan menandai permintaan sudah dikonfirmasi
+func (r *profileRepository) ConfirmEmailChange(ctx context.Context, tx *sql.Tx, req models.EmailChangeRequest) error {
+	if _, err := tx.ExecContext(ctx, `UPDATE users SET email = $1, updated_at = NOW() WHERE id = $2`, req.NewEmail, req.UserID); err != nil {
+		return err
+	}
+	_, err := tx.ExecContext(ctx, `UPDATE email_change_requests SET confirmed_at = NOW() WHERE id = $1`, req.ID)
+	return err
+}
@@ -3,13 +3,18 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"fmt"
 	"uas/app/models"
+
+	"github.com/google/uuid"
 )
 
 type ReportRepository interface {
-	GetStatistics(ctx context.Context) (models.DashboardStatistics, error)
+	GetStatistics(ctx context.Context, periodID *uuid.UUID) (models.DashboardStatistics, error)
+	GetStatisticsByUnit(ctx context.Context, groupBy string, periodID *uuid.UUID) ([]models.UnitStatistics, error)
 	GetStudentProfile(ctx context.Context, studentID string) (models.StudentReportProfile, error)
-	GetVerifiedAchievementsByStudentID(ctx context.Context, studentID string) ([]models.AchievementReference, error)
+	GetVerifiedAchievementsByStudentID(ctx context.Context, studentID string, periodID *uuid.UUID) ([]models.AchievementReference, error)
+	GetAcademicPeriodByID(ctx context.Context, id uuid.UUID) (models.AcademicPeriod, error)
 }
 
 type reportRepository struct {
@@ -20,14 +25,26 @@ func NewReportRepository(pg *sql.DB) ReportRepository {
 	return &reportRepository{pg: pg}
 }
 
-func (r *reportRepository) GetStatistics(ctx context.Context) (models.DashboardStatistics, error) {
+// periodFilter membatasi prestasi (alias ar) ke rentang tanggal periode akademik di parameter $n.
+// Parameter NULL berarti semua periode
+func periodFilter(n int) string {
+	return fmt.Sprintf(` AND ($%[1]d::uuid IS NULL OR EXISTS (
+		SELECT 1 FROM academic_periods fp
+		WHERE fp.id = $%[1]d AND ar.event_date BETWEEN fp.start_date AND fp.end_date))`, n)
+}
+
+func (r *reportRepository) GetAcademicPeriodByID(ctx context.Context, id uuid.UUID) (models.AcademicPeriod, error) {
+	return scanAcademicPeriod(r.pg.QueryRowContext(ctx, academicPeriodSelect+` WHERE p.id = $1`, id))
+}
+
+func (r *reportRepository) GetStatistics(ctx context.Context, periodID *uuid.UUID) (models.DashboardStatistics, error) {
 	query := `
-		SELECT status, COUNT(*) 
-		FROM achievement_references 
-		WHERE deleted_at IS NULL 
-		GROUP BY status
+		SELECT ar.status, COUNT(*) 
+		FROM achievement_references ar
+		WHERE ar.deleted_at IS NULL` + periodFilter(1) + `
+		GROUP BY ar.status
 	`
-	rows, err := r.pg.QueryContext(ctx, query)
+	rows, err := r.pg.QueryContext(ctx, query, periodID)
 	if err != nil {
 		return models.DashboardStatistics{}, err
 	}
@@ -49,26 +66,94 @@ func (r *reportRepository) GetStatistics(ctx context.Context) (models.DashboardS
 	return stats, nil
 }
 
+// unitGroupColumns adalah kolom id, kode, dan nama unit untuk setiap pengelompokan statistik
+var unitGroupColumns = map[string]string{
+	models.GroupByFaculty:      "f.id, COALESCE(f.code, ''), COALESCE(f.name, '')",
+	models.GroupByDepartment:   "d.id, COALESCE(d.code, ''), COALESCE(d.name, '')",
+	models.GroupByProgramStudy: "ps.id, COALESCE(ps.code, ''), COALESCE(ps.name, '')",
+}
+
+// GetStatisticsByUnit menghitung prestasi per status untuk setiap fakultas/department/program studi.
+// Mahasiswa tanpa program studi dikumpulkan di satu grup dengan id kosong
+func (r *reportRepository) GetStatisticsByUnit(ctx context.Context, groupBy string, periodID *uuid.UUID) ([]models.UnitStatistics, error) {
+	columns, ok := unitGroupColumns[groupBy]
+	if !ok {
+		return nil, fmt.Errorf("pengelompokan %q tidak dikenal", groupBy)
+	}
+
+	query := `
+		SELECT ` + columns + `, ar.status, COUNT(*)
+		FROM achievement_references ar
+		JOIN students s ON s.id = ar.student_id
+		LEFT JOIN program_studies ps ON ps.id = s.program_study_id
+		LEFT JOIN departments d ON d.id = ps.department_id
+		LEFT JOIN faculties f ON f.id = d.faculty_id
+		WHERE ar.deleted_at IS NULL` + periodFilter(1) + `
+		GROUP BY 1, 2, 3, 4
+		ORDER BY 3, 1
+	`
+	rows, err := r.pg.QueryContext(ctx, query, periodID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var groups []models.UnitStatistics
+	index := make(map[uuid.NullUUID]int)
+	for rows.Next() {
+		var id uuid.NullUUID
+		var code, name, status string
+		var count int64
+		if err := rows.Scan(&id, &code, &name, &status, &count); err != nil {
+			return nil, err
+		}
+
+		i, ok := index[id]
+		if !ok {
+			g := models.UnitStatistics{
+				Code:     code,
+				Name:     name,
+				ByStatus: map[string]int64{"draft": 0, "submitted": 0, "verified": 0, "rejected": 0},
+			}
+			if id.Valid {
+				unitID := id.UUID
+				g.ID = &unitID
+			}
+			groups = append(groups, g)
+			i = len(groups) - 1
+			index[id] = i
+		}
+		groups[i].ByStatus[status] = count
+		groups[i].Total += count
+	}
+	return groups, rows.Err()
+}
+
 func (r *reportRepository) GetStudentProfile(ctx context.Context, studentID string) (models.StudentReportProfile, error) {
 	query := `
-		SELECT s.id, s.student_id as nim, u.full_name
+		SELECT s.id, s.student_id as nim, u.full_name,
+			COALESCE(ps.name, ''), COALESCE(d.name, ''), COALESCE(f.name, '')
 		FROM students s
 		JOIN users u ON s.user_id = u.id
+		LEFT JOIN program_studies ps ON ps.id = s.program_study_id
+		LEFT JOIN departments d ON d.id = ps.department_id
+		LEFT JOIN faculties f ON f.id = d.faculty_id
 		WHERE s.id = $1
 	`
 	var profile models.StudentReportProfile
-	err := r.pg.QueryRowContext(ctx, query, studentID).Scan(&profile.StudentID, &profile.NIM, &profile.FullName)
+	err := r.pg.QueryRowContext(ctx, query, studentID).Scan(&profile.StudentID, &profile.NIM, &profile.FullName,
+		&profile.ProgramStudy, &profile.Department, &profile.Faculty)
 	return profile, err
 }
 
-func (r *reportRepository) GetVerifiedAchievementsByStudentID(ctx context.Context, studentID string) ([]models.AchievementReference, error) {
+func (r *reportRepository) GetVerifiedAchievementsByStudentID(ctx context.Context, studentID string, periodID *uuid.UUID) ([]models.AchievementReference, error) {
 	query := `
-		SELECT id, student_id, mongo_achievement_id, status, created_at
-		FROM achievement_references
-		WHERE student_id = $1 AND deleted_at IS NULL AND status = 'verified'
-		ORDER BY created_at DESC
+		SELECT ar.id, ar.student_id, ar.mongo_achievement_id, ar.status, ar.event_date, COALESCE(ap.name, ''), ar.created_at
+		FROM achievement_references ar` + achievementPeriodJoin + `
+		WHERE ar.student_id = $1 AND ar.deleted_at IS NULL AND ar.status = 'verified'` + periodFilter(2) + `
+		ORDER BY ar.event_date DESC, ar.created_at DESC
 	`
-	rows, err := r.pg.QueryContext(ctx, query, studentID)
+	rows, err := r.pg.QueryContext(ctx, query, studentID, periodID)
 	if err != nil {
 		return nil, err
 	}
@@ -77,7 +162,7 @@ func (r *reportRepository) GetVerifiedAchievementsByStudentID(ctx context.Contex
 	var refs []models.AchievementReference
 	for rows.Next() {
 		var ref models.AchievementReference
-		rows.Scan(&ref.ID, &ref.StudentID, &ref.MongoAchievementID, &ref.Status, &ref.CreatedAt)
+		rows.Scan(&ref.ID, &ref.StudentID, &ref.MongoAchievementID, &ref.Status, &ref.EventDate, &ref.PeriodName, &ref.CreatedAt)
 		refs = append(refs, ref)
 	}
 	return refs, nil
@@ -0,0 +1,273 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"uas/app/models"
+
+	"github.com/google/uuid"
+)
+
+type RoleRepository interface {
+	GetAllRoles(ctx context.Context) ([]models.Role, error)
+	GetRoleByID(ctx context.Context, id uuid.UUID) (models.Role, error)
+	GetRoleByName(ctx context.Context, name string) (models.Role, error)
+	CreateRole(ctx context.Context, role models.Role) error
+	UpdateRole(ctx context.Context, role models.Role) error
+	DeleteRole(ctx context.Context, id uuid.UUID) error
+	CountUsersByRole(ctx context.Context, roleID uuid.UUID) (int, error)
+
+	GetAllPermissions(ctx context.Context) ([]models.Permission, error)
+	GetPermissionByID(ctx context.Context, id uuid.UUID) (models.Permission, error)
+	CreatePermission(ctx context.Context, perm models.Permission) error
+	UpdatePermission(ctx context.Context, perm models.Permission) error
+
+	GetRolePermissions(ctx context.Context, roleID uuid.UUID) ([]models.Permission, error)
+	SetRolePermissions(ctx context.Context, roleID uuid.UUID, permissionIDs []uuid.UUID) error
+	GrantPermission(ctx context.Context, roleID uuid.UUID, permissionID uuid.UUID) error
+	RevokePermission(ctx context.Context, roleID uuid.UUID, permissionID uuid.UUID) error
+	GetAllRolePermissionPairs(ctx context.Context) (map[uuid.UUID][]uuid.UUID, error)
+}
+
+type roleRepository struct {
+	db *sql.DB
+}
+
+func NewRoleRepository(db *sql.DB) RoleRepository {
+	return &roleRepository{db: db}
+}
+
+func (r *roleRepository) GetAllRoles(ctx context.Context) ([]models.Role, error) {
+	query := `
+		SELECT id, name, COALESCE(description, ''), is_system, created_at
+		FROM roles
+		ORDER BY name ASC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("gagal query roles: %w", err)
+	}
+	defer rows.Close()
+
+	var roles []models.Role
+	for rows.Next() {
+		var role models.Role
+		if err := rows.Scan(&role.ID, &role.Name, &role.Description, &role.IsSystem, &role.CreatedAt); err != nil {
+			return nil, fmt.Errorf("gagal scanning role: %w", err)
+		}
+		roles = append(roles, role)
+	}
+
+	return roles, rows.Err()
+}
+
+func (r *roleRepository) GetRoleByID(ctx context.Context, id uuid.UUID) (models.Role, error) {
+	query := `
+		SELECT id, name, COALESCE(description, ''), is_system, created_at
+		FROM roles
+		WHERE id = $1
+	`
+
+	var role models.Role
+	err := r.db.QueryRowContext(ctx, query, id).Scan(&role.ID, &role.Name, &role.Description, &role.IsSystem, &role.CreatedAt)
+	return role, err
+}
+
+func (r *roleRepository) GetRoleByName(ctx context.Context, name string) (models.Role, error) {
+	query := `
+		SELECT id, name, COALESCE(description, ''), is_system, created_at
+		FROM roles
+		WHERE name = $1
+	`
+
+	var role models.Role
+	err := r.db.QueryRowContext(ctx, query, name).Scan(&role.ID, &role.Name, &role.Description, &role.IsSystem, &role.CreatedAt)
+	return role, err
+}
+
+func (r *roleRepository) CreateRole(ctx context.Context, role models.Role) error {
+	query := `
+		INSERT INTO roles (id, name, description, is_system, created_at)
+		VALUES ($1, $2, $3, FALSE, $4)
+	`
+	_, err := r.db.ExecContext(ctx, query, role.ID, role.Name, role.Description, role.CreatedAt)
+	return err
+}
+
+func (r *roleRepository) UpdateRole(ctx context.Context, role models.Role) error {
+	query := `UPDATE roles SET name = $1, description = $2 WHERE id = $3`
+
+	result, err := r.db.ExecContext(ctx, query, role.Name, role.Description, role.ID)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+func (r *roleRepository) DeleteRole(ctx context.Context, id uuid.UUID) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM roles WHERE id = $1 AND is_system = FALSE`, id)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+func (r *roleRepository) CountUsersByRole(ctx context.Context, roleID uuid.UUID) (int, error) {
+	var count int
+	err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM users WHERE role_id = $1`, roleID).Scan(&count)
+	return count, err
+}
+
+func (r *roleRepository) GetAllPermissions(ctx context.Context) ([]models.Permission, error) {
+	query := `
+		SELECT id, name, resource, action, COALESCE(description, '')
+		FROM permissions
+		ORDER BY resource ASC, action ASC
+	`
+	return r.queryPermissions(ctx, query)
+}
+
+func (r *roleRepository) GetPermissionByID(ctx context.Context, id uuid.UUID) (models.Permission, error) {
+	query := `
+		SELECT id, name, resource, action, COALESCE(description, '')
+		FROM permissions
+		WHERE id = $1
+	`
+
+	var p models.Permission
+	err := r.db.QueryRowContext(ctx, query, id).Scan(&p.ID, &p.Name, &p.Resource, &p.Action, &p.Description)
+	return p, err
+}
+
+func (r *roleRepository) CreatePermission(ctx context.Context, perm models.Permission) error {
+	query := `
+		INSERT INTO permissions (id, name, resource, action, description)
+		VALUES ($1, $2, $3, $4, $5)
+	`
+	_, err := r.db.ExecContext(ctx, query, perm.ID, perm.Name, perm.Resource, perm.Action, perm.Description)
+	return err
+}
+
+func (r *roleRepository) UpdatePermission(ctx context.Context, perm models.Permission) error {
+	query := `UPDATE permissions SET name = $1, resource = $2, action = $3, description = $4 WHERE id = $5`
+
+	result, err := r.db.ExecContext(ctx, query, perm.Name, perm.Resource, perm.Action, perm.Description, perm.ID)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+func (r *roleRepository) GetRolePermissions(ctx context.Context, roleID uuid.UUID) ([]models.Permission, error) {
+	query := `
+		SELECT p.id, p.name, p.resource, p.action, COALESCE(p.description, '')
+		FROM permissions p
+		JOIN role_permissions rp ON rp.permission_id = p.id
+		WHERE rp.role_id = $1
+		ORDER BY p.resource ASC, p.action ASC
+	`
+	return r.queryPermissions(ctx, query, roleID)
+}
+
+// SetRolePermissions mengganti seluruh permission milik role dalam satu transaksi
+func (r *roleRepository) SetRolePermissions(ctx context.Context, roleID uuid.UUID, permissionIDs []uuid.UUID) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM role_permissions WHERE role_id = $1`, roleID); err != nil {
+		return fmt.Errorf("gagal menghapus permission lama: %w", err)
+	}
+
+	for _, permissionID := range permissionIDs {
+		_, err := tx.ExecContext(ctx,
+			`INSERT INTO role_permissions (role_id, permission_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`,
+			roleID, permissionID,
+		)
+		if err != nil {
+			return fmt.Errorf("gagal menyimpan permission role: %w", err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (r *roleRepository) GrantPermission(ctx context.Context, roleID uuid.UUID, permissionID uuid.UUID) error {
+	_, err := r.db.ExecContext(ctx,
+		`INSERT INTO role_permissions (role_id, permission_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`,
+		roleID, permissionID,
+	)
+	return err
+}
+
+func (r *roleRepository) RevokePermission(ctx context.Context, roleID uuid.UUID, permissionID uuid.UUID) error {
+	_, err := r.db.ExecContext(ctx,
+		`DELETE FROM role_permissions WHERE role_id = $1 AND permission_id = $2`,
+		roleID, permissionID,
+	)
+	return err
+}
+
+// GetAllRolePermissionPairs mengembalikan map permission_id -> daftar role_id (untuk matriks akses)
+func (r *roleRepository) GetAllRolePermissionPairs(ctx context.Context) (map[uuid.UUID][]uuid.UUID, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT role_id, permission_id FROM role_permissions`)
+	if err != nil {
+		return nil, fmt.Errorf("gagal query role_permissions: %w", err)
+	}
+	defer rows.Close()
+
+	pairs := make(map[uuid.UUID][]uuid.UUID)
+	for rows.Next() {
+		var roleID, permissionID uuid.UUID
+		if err := rows.Scan(&roleID, &permissionID); err != nil {
+			return nil, fmt.Errorf("gagal scanning role_permissions: %w", err)
+		}
+		pairs[permissionID] = append(pairs[permissionID], roleID)
+	}
+
+	return pairs, rows.Err()
+}
+
+func (r *roleRepository) queryPermissions(ctx context.Context, query string, args ...interface{}) ([]models.Permission, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("gagal query permissions: %w", err)
+	}
+	defer rows.Close()
+
+	var perms []models.Permission
+	for rows.Next() {
+		var p models.Permission
+		if err := rows.Scan(&p.ID, &p.Name, &p.Resource, &p.Action, &p.Description); err != nil {
+			return nil, fmt.Errorf("gagal scanning permission: %w", err)
+		}
+		perms = append(perms, p)
+	}
+
+	return perms, rows.Err()
+}
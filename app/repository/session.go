@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+	"uas/app/models"
+
+	"github.com/google/uuid"
+)
+
+type SessionRepository interface {
+	CreateSession(ctx context.Context, session models.UserSession) error
+	GetSessionByID(ctx context.Context, id uuid.UUID) (models.UserSession, error)
+	GetActiveSessionsByUser(ctx context.Context, userID uuid.UUID) ([]models.UserSession, error)
+	TouchSession(ctx context.Context, id uuid.UUID, ip string) error
+	RevokeSession(ctx context.Context, id uuid.UUID, userID uuid.UUID) error
+}
+
+type sessionRepository struct {
+	db *sql.DB
+}
+
+func NewSessionRepository(db *sql.DB) SessionRepository {
+	return &sessionRepository{db: db}
+}
+
+const sessionColumns = `
+	id, user_id, COALESCE(device, ''), COALESCE(ip_address, ''), COALESCE(user_agent, ''),
+	created_at, last_used_at, expires_at, revoked_at
+`
+
+func scanSession(row interface{ Scan(...interface{}) error }) (models.UserSession, error) {
+	var s models.UserSession
+	err := row.Scan(
+		&s.ID, &s.UserID, &s.Device, &s.IPAddress, &s.UserAgent,
+		&s.CreatedAt, &s.LastUsedAt, &s.ExpiresAt, &s.RevokedAt,
+	)
+	return s, err
+}
+
+func (r *sessionRepository) CreateSession(ctx context.Context, session models.UserSession) error {
+	query := `
+		INSERT INTO user_sessions (id, user_id, device, ip_address, user_agent, created_at, last_used_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+	_, err := r.db.ExecContext(ctx, query,
+		session.ID, session.UserID, session.Device, session.IPAddress, session.UserAgent,
+		session.CreatedAt, session.LastUsedAt, session.ExpiresAt,
+	)
+	return err
+}
+
+func (r *sessionRepository) GetSessionByID(ctx context.Context, id uuid.UUID) (models.UserSession, error) {
+	query := `SELECT ` + sessionColumns + ` FROM user_sessions WHERE id = $1`
+	return scanSession(r.db.QueryRowContext(ctx, query, id))
+}
+
+// GetActiveSessionsByUser mengembalikan sesi yang belum dicabut dan belum expired, terbaru dipakai lebih dulu
+func (r *sessionRepository) GetActiveSessionsByUser(ctx context.Context, userID uuid.UUID) ([]models.UserSession, error) {
+	query := `
+		SELECT ` + sessionColumns + `
+		FROM user_sessions
+		WHERE user_id = $1 AND revoked_at IS NULL AND expires_at > NOW()
+		ORDER BY last_used_at DESC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("gagal query sesi: %w", err)
+	}
+	defer rows.Close()
+
+	var sessions []models.UserSession
+	for rows.Next() {
+		session, err := scanSession(rows)
+		if err != nil {
+			return nil, fmt.Errorf("gagal scanning sesi: %w", err)
+		}
+		sessions = append(sessions, session)
+	}
+	return sessions, rows.Err()
+}
+
+func (r *sessionRepository) TouchSession(ctx context.Context, id uuid.UUID, ip string) error {
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE user_sessions SET last_used_at = $1, ip_address = $2 WHERE id = $3`,
+		time.Now(), ip, id,
+	)
+	return err
+}
+
+// RevokeSession mencabut sesi milik userID. sql.ErrNoRows jika sesi tidak ada, bukan milik user, atau sudah dicabut
+func (r *sessionRepository) RevokeSession(ctx context.Context, id uuid.UUID, userID uuid.UUID) error {
+	result, err := r.db.ExecContext(ctx,
+		`UPDATE user_sessions SET revoked_at = $1 WHERE id = $2 AND user_id = $3 AND revoked_at IS NULL`,
+		time.Now(), id, userID,
+	)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
@@ -3,16 +3,34 @@ package repository
 import (
 	"context"
 	"database/sql"
-	"errors"
 	"fmt"
+	"strings"
 	"uas/app/models"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
 type StudentRepository interface {
 	CreateStudent(ctx context.Context, tx *sql.Tx, student models.Student) error
-	GetAllStudentsByRole(ctx context.Context, roleName string) ([]models.GetStudent, error)
+	GetAllStudentsByRole(ctx context.Context, roleName string, q models.ListQuery) ([]models.GetStudent, models.ListMeta, error)
 	GetStudentByID(ctx context.Context, id string) (models.GetStudent, error)
-	UpdateStudentAdvisor(ctx context.Context, studentID string, advisorID string) error
+	ChangeAdvisor(ctx context.Context, tx *sql.Tx, change models.AdvisorChange) error
+	GetAdvisorHistory(ctx context.Context, studentID uuid.UUID) ([]models.AdvisorHistory, error)
+	GetAdvisorIDForUpdate(ctx context.Context, tx *sql.Tx, studentID uuid.UUID) (*uuid.UUID, error)
+	GetAdviseeIDsForUpdate(ctx context.Context, tx *sql.Tx, lecturerID uuid.UUID) ([]uuid.UUID, error)
+	CountSubmittedAchievements(ctx context.Context, tx *sql.Tx, studentIDs []uuid.UUID) (map[uuid.UUID]int, error)
+	GetUnadvisedStudentsForUpdate(ctx context.Context, tx *sql.Tx) ([]models.Student, error)
+	RetireStudent(ctx context.Context, tx *sql.Tx, userID uuid.UUID) error
+	ReactivateStudent(ctx context.Context, tx *sql.Tx, userID uuid.UUID) (bool, error)
+	GetTakenNIMs(ctx context.Context, nims []string) (map[string]bool, error)
+	GetProgramStudyIDsByNames(ctx context.Context, names []string) (map[string]uuid.UUID, error)
+	GetAcademicStatusForUpdate(ctx context.Context, tx *sql.Tx, studentID uuid.UUID) (models.AcademicStatus, error)
+	ChangeAcademicStatus(ctx context.Context, tx *sql.Tx, change models.AcademicStatusChange) error
+	GetAcademicStatusHistory(ctx context.Context, studentID uuid.UUID) ([]models.AcademicStatusHistory, error)
+	GetStudentByUserID(ctx context.Context, userID string) (models.StudentWithAdvisor, error)
+	GetAchievementSummary(ctx context.Context, studentID string) (models.AchievementSummary, []string, error)
+	GetRecentRejections(ctx context.Context, studentID string, limit int) ([]models.RejectionNote, error)
 }
 
 type studentRepository struct {
@@ -23,93 +41,176 @@ func NewStudentRepository(db *sql.DB) StudentRepository {
 	return &studentRepository{db: db}
 }
 
+// CreateStudent menyimpan profil mahasiswa. Program studi boleh dikirim lewat ID atau nama;
+// ErrUnknownProgramStudy jika namanya tidak terdaftar. Periode pertama riwayat status akademik (dan riwayat
+// dosen wali, jika dosen wali diisi) ikut dibuka dengan changed_by = student.CreatedBy
 func (r *studentRepository) CreateStudent(ctx context.Context, tx *sql.Tx, student models.Student) error {
 	query := `
 		INSERT INTO students (
-			id, user_id, student_id, program_study, academy_year, advisor_id, created_at
+			id, user_id, student_id, program_study_id, academy_year, advisor_id, created_at
 		)
 		VALUES ($1, $2, $3, $4, $5, $6, $7)
 	`
 
-	var err error
+	var q rowQuerier = r.db
+	var exec rowExecer = r.db
 	if tx != nil {
-		_, err = tx.ExecContext(ctx, query,
-			student.ID,
-			student.UserID,
-			student.StudentID,
-			student.ProgramStudy,
-			student.AcademicYear,
-			student.AdvisorID,
-			student.CreatedAt,
-		)
-	} else {
-		_, err = r.db.ExecContext(ctx, query,
-			student.ID,
-			student.UserID,
-			student.StudentID,
-			student.ProgramStudy,
-			student.AcademicYear,
-			student.AdvisorID,
-			student.CreatedAt,
-		)
+		q = tx
+		exec = tx
+	}
+	programStudyID, err := resolveUnitID(ctx, q, "program_studies", student.ProgramStudyID, student.ProgramStudy, ErrUnknownProgramStudy)
+	if err != nil {
+		return err
 	}
 
-	return err
+	// Mahasiswa tanpa dosen wali disimpan dengan advisor_id NULL (uuid.Nil melanggar foreign key)
+	var advisorID interface{}
+	if student.AdvisorID != uuid.Nil {
+		advisorID = student.AdvisorID
+	}
+
+	// Angkatan disimpan tanpa spasi; kosong menjadi NULL
+	var academicYear interface{}
+	if year := strings.TrimSpace(student.AcademicYear); year != "" {
+		academicYear = year
+	}
+
+	_, err = exec.ExecContext(ctx, query,
+		student.ID,
+		student.UserID,
+		student.StudentID,
+		programStudyID,
+		academicYear,
+		advisorID,
+		student.CreatedAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	// Status awal mengikuti default kolom (active sejak hari ini)
+	_, err = exec.ExecContext(ctx, `
+		INSERT INTO student_status_history (student_id, status, effective_from, changed_by)
+		SELECT id, academic_status, academic_status_since, $2 FROM students WHERE id = $1
+	`, student.ID, student.CreatedBy)
+	if err != nil {
+		return fmt.Errorf("gagal mencatat riwayat status akademik: %w", err)
+	}
+
+	if advisorID != nil {
+		_, err = exec.ExecContext(ctx, `
+			INSERT INTO student_advisor_history (student_id, advisor_id, effective_from, changed_by)
+			VALUES ($1, $2, $3, $4)
+		`, student.ID, advisorID, student.CreatedAt, student.CreatedBy)
+		if err != nil {
+			return fmt.Errorf("gagal mencatat riwayat dosen wali: %w", err)
+		}
+	}
+
+	return nil
+}
+
+var studentListSpec = listSpec{
+	id:     "s.id",
+	search: []string{"u.full_name", "u.username", "u.email", "s.student_id"},
+	filters: map[string]listFilter{
+		"is_active":        {expr: "u.is_active", boolean: true},
+		"program_study":    {expr: "ps.name"},
+		"program_study_id": {expr: "s.program_study_id::text"},
+		"department_id":    {expr: "ps.department_id::text"},
+		"faculty_id":       {expr: "d.faculty_id::text"},
+		"academic_year":    {expr: "s.academy_year"},
+		"academic_status":  {expr: "s.academic_status"},
+	},
+	sorts: map[string]listSort{
+		"full_name":     {expr: "u.full_name", cast: "text"},
+		"nim":           {expr: "s.student_id", cast: "text"},
+		"program_study": {expr: "COALESCE(ps.name, '')", cast: "text"},
+		"academic_year": {expr: "COALESCE(s.academy_year, '')", cast: "text"},
+		"created_at":    {expr: "s.created_at", cast: "timestamp"},
+	},
+	defaultSort: "full_name",
 }
 
-func (r *studentRepository) GetAllStudentsByRole(ctx context.Context, roleName string) ([]models.GetStudent, error) {
+func (r *studentRepository) GetAllStudentsByRole(ctx context.Context, roleName string, q models.ListQuery) ([]models.GetStudent, models.ListMeta, error) {
+	from := `
+		FROM students s
+		JOIN users u ON s.user_id = u.id
+		JOIN roles r ON u.role_id = r.id
+		LEFT JOIN program_studies ps ON ps.id = s.program_study_id
+		LEFT JOIN departments d ON d.id = ps.department_id
+	`
+
+	l, err := studentListSpec.build(q, []string{"r.name = $1", "u.deleted_at IS NULL"}, []interface{}{roleName})
+	if err != nil {
+		return nil, models.ListMeta{}, err
+	}
+
+	var total int
+	if err := r.db.QueryRowContext(ctx, "SELECT count(1)"+from+l.countWhere, l.countArgs...).Scan(&total); err != nil {
+		return nil, models.ListMeta{}, fmt.Errorf("gagal menghitung students: %w", err)
+	}
+
 	query := `
 		SELECT 
 			s.id, 
 			s.user_id, 
 			s.student_id, 
-			s.program_study, 
+			s.program_study_id,
+			COALESCE(ps.name, ''),
 			s.academy_year,
 			u.full_name, 
 			u.username, 
 			u.email,
 			u.is_active,
-			r.name as role_name
-		FROM students s
-		JOIN users u ON s.user_id = u.id
-		JOIN roles r ON u.role_id = r.id
-		WHERE r.name = $1
-		ORDER BY u.full_name ASC
-	`
+			COALESCE(u.photo_url, ''),
+			r.name as role_name,
+			s.academic_status,
+			s.academic_status_since,
+	` + l.sortKey + from + l.where + l.orderLimit
 
-	rows, err := r.db.QueryContext(ctx, query, roleName)
+	rows, err := r.db.QueryContext(ctx, query, l.args...)
 	if err != nil {
-		return nil, fmt.Errorf("gagal query students join roles: %w", err)
+		return nil, models.ListMeta{}, fmt.Errorf("gagal query students join roles: %w", err)
 	}
 	defer rows.Close()
 
 	var students []models.GetStudent
+	var keys []models.ListCursor
 
 	for rows.Next() {
 		var s models.GetStudent
+		var key string
 		err := rows.Scan(
 			&s.ID,
 			&s.UserID,
 			&s.NIM,
+			&s.ProgramStudyID,
 			&s.ProgramStudy,
 			&s.AcademyYear,
 			&s.FullName,
 			&s.Username,
 			&s.Email,
 			&s.IsActive,
+			&s.PhotoURL,
 			&s.RoleName,
+			&s.AcademicStatus,
+			&s.AcademicStatusSince,
+			&key,
 		)
 		if err != nil {
-			return nil, fmt.Errorf("gagal scanning row: %w", err)
+			return nil, models.ListMeta{}, fmt.Errorf("gagal scanning row: %w", err)
 		}
 		students = append(students, s)
+		keys = append(keys, models.ListCursor{Value: key, ID: s.ID})
 	}
 
 	if err = rows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterasi rows: %w", err)
+		return nil, models.ListMeta{}, fmt.Errorf("error iterasi rows: %w", err)
 	}
 
-	return students, nil
+	students, meta := page(students, keys, q, l, total)
+	return students, meta, nil
 }
 
 func (r *studentRepository) GetStudentByID(ctx context.Context, id string) (models.GetStudent, error) {
@@ -118,16 +219,21 @@ func (r *studentRepository) GetStudentByID(ctx context.Context, id string) (mode
 			s.id, 
 			s.user_id, 
 			s.student_id, 
-			s.program_study, 
+			s.program_study_id,
+			COALESCE(ps.name, ''),
 			s.academy_year,
 			u.full_name, 
 			u.username, 
 			u.email,
 			u.is_active,
-			r.name as role_name
+			COALESCE(u.photo_url, ''),
+			r.name as role_name,
+			s.academic_status,
+			s.academic_status_since
 		FROM students s
 		JOIN users u ON s.user_id = u.id
 		JOIN roles r ON u.role_id = r.id
+		LEFT JOIN program_studies ps ON ps.id = s.program_study_id
 		WHERE s.id = $1
 	`
 
@@ -137,13 +243,17 @@ func (r *studentRepository) GetStudentByID(ctx context.Context, id string) (mode
 		&s.ID,
 		&s.UserID,
 		&s.NIM,
+		&s.ProgramStudyID,
 		&s.ProgramStudy,
 		&s.AcademyYear,
 		&s.FullName,
 		&s.Username,
 		&s.Email,
 		&s.IsActive,
+		&s.PhotoURL,
 		&s.RoleName,
+		&s.AcademicStatus,
+		&s.AcademicStatusSince,
 	)
 
 	if err != nil {
@@ -153,16 +263,231 @@ func (r *studentRepository) GetStudentByID(ctx context.Context, id string) (mode
 	return s, nil
 }
 
-func (r *studentRepository) UpdateStudentAdvisor(ctx context.Context, studentID string, advisorID string) error {
+// ChangeAdvisor mengganti dosen wali mahasiswa dan mencatatnya di student_advisor_history:
+// periode yang berjalan ditutup dan periode baru dibuka pada waktu yang sama.
+// Mengembalikan sql.ErrNoRows jika mahasiswa tidak ditemukan
+func (r *studentRepository) ChangeAdvisor(ctx context.Context, tx *sql.Tx, change models.AdvisorChange) error {
+	result, err := tx.ExecContext(ctx, `UPDATE students SET advisor_id = $1, updated_at = $2 WHERE id = $3`,
+		change.AdvisorID, change.At, change.StudentID)
+	if err != nil {
+		return fmt.Errorf("gagal update dosen wali: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE student_advisor_history SET effective_to = $1
+		WHERE student_id = $2 AND effective_to IS NULL
+	`, change.At, change.StudentID); err != nil {
+		return fmt.Errorf("gagal menutup riwayat dosen wali: %w", err)
+	}
+
+	var reason interface{}
+	if change.Reason != "" {
+		reason = change.Reason
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO student_advisor_history (student_id, advisor_id, effective_from, changed_by, reason)
+		VALUES ($1, $2, $3, $4, $5)
+	`, change.StudentID, change.AdvisorID, change.At, change.ChangedBy, reason)
+	if err != nil {
+		return fmt.Errorf("gagal mencatat riwayat dosen wali: %w", err)
+	}
+
+	return nil
+}
+
+// GetAdvisorIDForUpdate mengunci baris mahasiswa dan mengembalikan dosen walinya saat ini (nil jika belum ada)
+func (r *studentRepository) GetAdvisorIDForUpdate(ctx context.Context, tx *sql.Tx, studentID uuid.UUID) (*uuid.UUID, error) {
+	var advisorID *uuid.UUID
+	err := tx.QueryRowContext(ctx, `SELECT advisor_id FROM students WHERE id = $1 FOR UPDATE`, studentID).Scan(&advisorID)
+	return advisorID, err
+}
+
+// GetAdvisorHistory mengambil riwayat dosen wali mahasiswa, terbaru lebih dulu
+func (r *studentRepository) GetAdvisorHistory(ctx context.Context, studentID uuid.UUID) ([]models.AdvisorHistory, error) {
 	query := `
-		UPDATE students 
-		SET advisor_id = $1, updated_at = NOW()
-		WHERE id = $2
+		SELECT h.id, h.student_id, h.advisor_id, COALESCE(u.full_name, ''),
+			h.effective_from, h.effective_to, h.changed_by, COALESCE(h.reason, '')
+		FROM student_advisor_history h
+		LEFT JOIN lecturers l ON l.id = h.advisor_id
+		LEFT JOIN users u ON u.id = l.user_id
+		WHERE h.student_id = $1
+		ORDER BY h.effective_from DESC
 	`
 
-	result, err := r.db.ExecContext(ctx, query, advisorID, studentID)
+	rows, err := r.db.QueryContext(ctx, query, studentID)
 	if err != nil {
-		return fmt.Errorf("gagal update dosen wali: %w", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var history []models.AdvisorHistory
+	for rows.Next() {
+		var h models.AdvisorHistory
+		if err := rows.Scan(&h.ID, &h.StudentID, &h.AdvisorID, &h.AdvisorName,
+			&h.EffectiveFrom, &h.EffectiveTo, &h.ChangedBy, &h.Reason); err != nil {
+			return nil, err
+		}
+		history = append(history, h)
+	}
+	return history, rows.Err()
+}
+
+// GetAdviseeIDsForUpdate mengunci dan mengembalikan mahasiswa aktif bimbingan lecturerID, urut NIM
+func (r *studentRepository) GetAdviseeIDsForUpdate(ctx context.Context, tx *sql.Tx, lecturerID uuid.UUID) ([]uuid.UUID, error) {
+	rows, err := tx.QueryContext(ctx, `
+		SELECT id FROM students
+		WHERE advisor_id = $1 AND retired_at IS NULL
+		ORDER BY student_id
+		FOR UPDATE
+	`, lecturerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// GetUnadvisedStudentsForUpdate mengunci dan mengembalikan mahasiswa aktif/cuti yang belum punya dosen wali, urut NIM.
+// Mahasiswa yang sudah lulus atau keluar tidak perlu dosen wali lagi
+func (r *studentRepository) GetUnadvisedStudentsForUpdate(ctx context.Context, tx *sql.Tx) ([]models.Student, error) {
+	rows, err := tx.QueryContext(ctx, `
+		SELECT s.id, s.user_id, s.student_id, COALESCE(s.program_study_id, '00000000-0000-0000-0000-000000000000'),
+			COALESCE(ps.name, ''), COALESCE(ps.department_id, '00000000-0000-0000-0000-000000000000'), COALESCE(s.academy_year, '')
+		FROM students s
+		LEFT JOIN program_studies ps ON ps.id = s.program_study_id
+		WHERE s.advisor_id IS NULL AND s.retired_at IS NULL AND s.academic_status IN ('active', 'on_leave')
+		ORDER BY s.student_id
+		FOR UPDATE OF s
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var students []models.Student
+	for rows.Next() {
+		var s models.Student
+		if err := rows.Scan(&s.ID, &s.UserID, &s.StudentID, &s.ProgramStudyID, &s.ProgramStudy, &s.DepartmentID, &s.AcademicYear); err != nil {
+			return nil, err
+		}
+		students = append(students, s)
+	}
+	return students, rows.Err()
+}
+
+// CountSubmittedAchievements menghitung prestasi berstatus 'submitted' (menunggu verifikasi) per mahasiswa
+func (r *studentRepository) CountSubmittedAchievements(ctx context.Context, tx *sql.Tx, studentIDs []uuid.UUID) (map[uuid.UUID]int, error) {
+	ids := make([]string, len(studentIDs))
+	for i, id := range studentIDs {
+		ids[i] = id.String()
+	}
+
+	query := `
+		SELECT student_id, count(1) FROM achievement_references
+		WHERE student_id = ANY($1::uuid[]) AND status = 'submitted'
+		GROUP BY student_id
+	`
+
+	var rows *sql.Rows
+	var err error
+	if tx != nil {
+		rows, err = tx.QueryContext(ctx, query, pq.Array(ids))
+	} else {
+		rows, err = r.db.QueryContext(ctx, query, pq.Array(ids))
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make(map[uuid.UUID]int)
+	for rows.Next() {
+		var id uuid.UUID
+		var n int
+		if err := rows.Scan(&id, &n); err != nil {
+			return nil, err
+		}
+		counts[id] = n
+	}
+	return counts, rows.Err()
+}
+
+// RetireStudent menandai profil student milik user sebagai tidak aktif (dipakai saat role user berganti)
+func (r *studentRepository) RetireStudent(ctx context.Context, tx *sql.Tx, userID uuid.UUID) error {
+	_, err := tx.ExecContext(ctx, `UPDATE students SET retired_at = NOW() WHERE user_id = $1 AND retired_at IS NULL`, userID)
+	return err
+}
+
+// ReactivateStudent memakai kembali profil student lama milik user. false jika user belum pernah punya profil
+func (r *studentRepository) ReactivateStudent(ctx context.Context, tx *sql.Tx, userID uuid.UUID) (bool, error) {
+	result, err := tx.ExecContext(ctx, `UPDATE students SET retired_at = NULL WHERE user_id = $1`, userID)
+	if err != nil {
+		return false, err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rows > 0, nil
+}
+
+// GetTakenNIMs mengembalikan NIM dari daftar yang sudah terdaftar
+func (r *studentRepository) GetTakenNIMs(ctx context.Context, nims []string) (map[string]bool, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT student_id FROM students WHERE student_id = ANY($1)`, pq.Array(nims))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	taken := make(map[string]bool)
+	for rows.Next() {
+		var nim string
+		if err := rows.Scan(&nim); err != nil {
+			return nil, err
+		}
+		taken[nim] = true
+	}
+	return taken, rows.Err()
+}
+
+// GetAcademicStatusForUpdate mengunci baris mahasiswa dan mengembalikan status akademiknya saat ini
+func (r *studentRepository) GetAcademicStatusForUpdate(ctx context.Context, tx *sql.Tx, studentID uuid.UUID) (models.AcademicStatus, error) {
+	var st models.AcademicStatus
+	err := tx.QueryRowContext(ctx, `
+		SELECT id, academic_status, academic_status_since FROM students
+		WHERE id = $1 AND retired_at IS NULL
+		FOR UPDATE
+	`, studentID).Scan(&st.StudentID, &st.Status, &st.Since)
+	return st, err
+}
+
+// ChangeAcademicStatus mengganti status akademik mahasiswa dan mencatatnya di student_status_history:
+// periode yang berjalan ditutup dan periode baru dibuka pada tanggal berlaku yang sama
+func (r *studentRepository) ChangeAcademicStatus(ctx context.Context, tx *sql.Tx, change models.AcademicStatusChange) error {
+	result, err := tx.ExecContext(ctx, `
+		UPDATE students SET academic_status = $1, academic_status_since = $2, updated_at = NOW()
+		WHERE id = $3
+	`, change.Status, change.EffectiveFrom, change.StudentID)
+	if err != nil {
+		return fmt.Errorf("gagal update status akademik: %w", err)
 	}
 
 	rowsAffected, err := result.RowsAffected()
@@ -170,8 +495,174 @@ func (r *studentRepository) UpdateStudentAdvisor(ctx context.Context, studentID
 		return err
 	}
 	if rowsAffected == 0 {
-		return errors.New("student_not_found")
+		return sql.ErrNoRows
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE student_status_history SET effective_to = $1
+		WHERE student_id = $2 AND effective_to IS NULL
+	`, change.EffectiveFrom, change.StudentID); err != nil {
+		return fmt.Errorf("gagal menutup riwayat status akademik: %w", err)
+	}
+
+	var reason interface{}
+	if change.Reason != "" {
+		reason = change.Reason
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO student_status_history (student_id, status, effective_from, changed_by, reason)
+		VALUES ($1, $2, $3, $4, $5)
+	`, change.StudentID, change.Status, change.EffectiveFrom, change.ChangedBy, reason)
+	if err != nil {
+		return fmt.Errorf("gagal mencatat riwayat status akademik: %w", err)
 	}
 
 	return nil
-}
\ No newline at end of file
+}
+
+// GetAcademicStatusHistory mengambil riwayat status akademik mahasiswa, terbaru lebih dulu
+func (r *studentRepository) GetAcademicStatusHistory(ctx context.Context, studentID uuid.UUID) ([]models.AcademicStatusHistory, error) {
+	query := `
+		SELECT h.id, h.student_id, h.status, h.effective_from, h.effective_to,
+			h.changed_by, COALESCE(u.full_name, ''), COALESCE(h.reason, '')
+		FROM student_status_history h
+		LEFT JOIN users u ON u.id = h.changed_by
+		WHERE h.student_id = $1
+		ORDER BY h.effective_from DESC, h.created_at DESC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, studentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var history []models.AcademicStatusHistory
+	for rows.Next() {
+		var h models.AcademicStatusHistory
+		if err := rows.Scan(&h.ID, &h.StudentID, &h.Status, &h.EffectiveFrom, &h.EffectiveTo,
+			&h.ChangedBy, &h.ChangedByName, &h.Reason); err != nil {
+			return nil, err
+		}
+		history = append(history, h)
+	}
+	return history, rows.Err()
+}
+
+// GetProgramStudyIDsByNames mencari program studi dari namanya. Key hasil adalah nama dalam huruf kecil
+func (r *studentRepository) GetProgramStudyIDsByNames(ctx context.Context, names []string) (map[string]uuid.UUID, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT LOWER(name), id FROM program_studies WHERE LOWER(name) = ANY($1)`, pq.Array(lowerAll(names)))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := make(map[string]uuid.UUID)
+	for rows.Next() {
+		var name string
+		var id uuid.UUID
+		if err := rows.Scan(&name, &id); err != nil {
+			return nil, err
+		}
+		ids[name] = id
+	}
+	return ids, rows.Err()
+}
+
+// GetStudentByUserID mengambil profil mahasiswa aktif milik user beserta kontak dosen walinya.
+// sql.ErrNoRows jika user bukan mahasiswa
+func (r *studentRepository) GetStudentByUserID(ctx context.Context, userID string) (models.StudentWithAdvisor, error) {
+	query := `
+		SELECT
+			s.id, s.user_id, s.student_id, s.program_study_id, COALESCE(ps.name, ''), s.academy_year,
+			u.full_name, u.username, u.email, u.is_active, COALESCE(u.photo_url, ''), r.name,
+			s.academic_status, s.academic_status_since,
+			l.id, COALESCE(l.lecturer_id, ''), COALESCE(lu.full_name, ''), COALESCE(lu.email, ''),
+			COALESCE(lu.phone, ''), COALESCE(d.name, '')
+		FROM students s
+		JOIN users u ON s.user_id = u.id
+		JOIN roles r ON u.role_id = r.id
+		LEFT JOIN program_studies ps ON ps.id = s.program_study_id
+		LEFT JOIN lecturers l ON l.id = s.advisor_id
+		LEFT JOIN users lu ON lu.id = l.user_id
+		LEFT JOIN departments d ON d.id = l.department_id
+		WHERE s.user_id = $1 AND s.retired_at IS NULL AND u.deleted_at IS NULL
+	`
+
+	var p models.StudentWithAdvisor
+	var advisorID uuid.NullUUID
+	var advisor models.AdvisorContact
+
+	err := r.db.QueryRowContext(ctx, query, userID).Scan(
+		&p.ID, &p.UserID, &p.NIM, &p.ProgramStudyID, &p.ProgramStudy, &p.AcademyYear,
+		&p.FullName, &p.Username, &p.Email, &p.IsActive, &p.PhotoURL, &p.RoleName,
+		&p.AcademicStatus, &p.AcademicStatusSince,
+		&advisorID, &advisor.LecturerID, &advisor.FullName, &advisor.Email,
+		&advisor.Phone, &advisor.Department,
+	)
+	if err != nil {
+		return models.StudentWithAdvisor{}, err
+	}
+
+	if advisorID.Valid {
+		advisor.ID = advisorID.UUID
+		p.Advisor = &advisor
+	}
+	return p, nil
+}
+
+// GetAchievementSummary menghitung prestasi mahasiswa per status. Mongo ID prestasi verified
+// dikembalikan agar service bisa menjumlahkan poinnya
+func (r *studentRepository) GetAchievementSummary(ctx context.Context, studentID string) (models.AchievementSummary, []string, error) {
+	query := `
+		SELECT
+			count(*),
+			count(*) FILTER (WHERE status = 'draft'),
+			count(*) FILTER (WHERE status = 'submitted'),
+			count(*) FILTER (WHERE status = 'verified'),
+			count(*) FILTER (WHERE status = 'rejected'),
+			COALESCE(array_agg(mongo_achievement_id) FILTER (WHERE status = 'verified'), '{}')
+		FROM achievement_references
+		WHERE student_id = $1 AND deleted_at IS NULL
+	`
+
+	var summary models.AchievementSummary
+	var draft, submitted, verified, rejected int64
+	var verifiedIDs []string
+
+	err := r.db.QueryRowContext(ctx, query, studentID).Scan(
+		&summary.Total, &draft, &submitted, &verified, &rejected, pq.Array(&verifiedIDs),
+	)
+	if err != nil {
+		return models.AchievementSummary{}, nil, err
+	}
+
+	summary.ByStatus = map[string]int64{"draft": draft, "submitted": submitted, "verified": verified, "rejected": rejected}
+	return summary, verifiedIDs, nil
+}
+
+// GetRecentRejections mengambil catatan penolakan prestasi yang masih berstatus rejected, terbaru lebih dulu
+func (r *studentRepository) GetRecentRejections(ctx context.Context, studentID string, limit int) ([]models.RejectionNote, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, mongo_achievement_id, COALESCE(rejection_note, ''), updated_at
+		FROM achievement_references
+		WHERE student_id = $1 AND status = 'rejected' AND deleted_at IS NULL
+		ORDER BY updated_at DESC
+		LIMIT $2
+	`, studentID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var notes []models.RejectionNote
+	for rows.Next() {
+		var n models.RejectionNote
+		if err := rows.Scan(&n.AchievementID, &n.MongoID, &n.Note, &n.RejectedAt); err != nil {
+			return nil, err
+		}
+		notes = append(notes, n)
+	}
+	return notes, rows.Err()
+}
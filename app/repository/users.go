@@ -3,20 +3,30 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	"strings"
 	"time"
 	"uas/app/models"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
 type UserRepository interface {
-	GetAllUsers(ctx context.Context) ([]models.User, error)
+	GetAllUsers(ctx context.Context, q models.ListQuery) ([]models.User, models.ListMeta, error)
 	GetUserByID(ctx context.Context, id uuid.UUID) (models.User, error)
 	GetByUsernameOrEmail(ctx context.Context, loginInput string) (models.User, error) // Tambahan buat Login
 	CreateUser(ctx context.Context, tx *sql.Tx, user models.User) error // CreateUser biasanya butuh Transaction (Tx)
-	UpdateUser(ctx context.Context, id uuid.UUID, user models.UpdateUser) error
-	DeleteUser(ctx context.Context, id uuid.UUID) error
-	UpdateUserRole(ctx context.Context, userID uuid.UUID, roleID uuid.UUID) error
+	UpdateUser(ctx context.Context, tx *sql.Tx, id uuid.UUID, user models.UpdateUser) error
+	SoftDeleteUser(ctx context.Context, tx *sql.Tx, id uuid.UUID, deletedBy uuid.UUID) error
+	RestoreUser(ctx context.Context, id uuid.UUID) error
+	GetPurgePlanForUpdate(ctx context.Context, tx *sql.Tx, id uuid.UUID) (models.UserPurgePlan, error)
+	PurgeUser(ctx context.Context, tx *sql.Tx, plan models.UserPurgePlan) error
+	UpdateUserRole(ctx context.Context, tx *sql.Tx, userID uuid.UUID, roleID uuid.UUID) error
+	CountUsersByRoleForUpdate(ctx context.Context, tx *sql.Tx, roleName string) (int, error)
+	GetUserByEmail(ctx context.Context, email string) (models.User, error)
+	GetUserByNIM(ctx context.Context, nim string) (models.User, error)
+	GetTakenIdentities(ctx context.Context, usernames []string, emails []string) (map[string]bool, map[string]bool, error)
 }
 
 type userRepository struct {
@@ -27,50 +37,108 @@ func NewUserRepository(db *sql.DB) UserRepository {
 	return &userRepository{db: db}
 }
 
-func (r *userRepository) GetAllUsers(ctx context.Context) ([]models.User, error) {
-	var users []models.User
+var userListSpec = listSpec{
+	id:     "u.id",
+	search: []string{"u.full_name", "u.username", "u.email", "s.student_id", "l.lecturer_id"},
+	filters: map[string]listFilter{
+		"role":          {expr: "r.name"},
+		"is_active":     {expr: "u.is_active", boolean: true},
+		"program_study": {expr: "ps.name"},
+		"academic_year": {expr: "s.academy_year"},
+		"department":    {expr: "COALESCE(ld.name, sd.name)"},
+		"department_id": {expr: "COALESCE(l.department_id, ps.department_id)::text"},
+		"faculty_id":    {expr: "COALESCE(ld.faculty_id, sd.faculty_id)::text"},
+		"deleted":       {expr: "(u.deleted_at IS NOT NULL)", boolean: true},
+	},
+	sorts: map[string]listSort{
+		"full_name":  {expr: "u.full_name", cast: "text"},
+		"username":   {expr: "u.username", cast: "text"},
+		"email":      {expr: "u.email", cast: "text"},
+		"role":       {expr: "r.name", cast: "text"},
+		"created_at": {expr: "u.created_at", cast: "timestamp"},
+	},
+	defaultSort: "full_name",
+}
 
-	query := `
-		SELECT u.id, u.username, u.email, u.password_hash, u.full_name, u.role_id, r.name, u.is_active, u.created_at, u.updated_at
+func (r *userRepository) GetAllUsers(ctx context.Context, q models.ListQuery) ([]models.User, models.ListMeta, error) {
+	from := `
 		FROM users u
 		JOIN roles r ON u.role_id = r.id
+		LEFT JOIN students s ON s.user_id = u.id AND s.retired_at IS NULL
+		LEFT JOIN program_studies ps ON ps.id = s.program_study_id
+		LEFT JOIN departments sd ON sd.id = ps.department_id
+		LEFT JOIN lecturers l ON l.user_id = u.id AND l.retired_at IS NULL
+		LEFT JOIN departments ld ON ld.id = l.department_id
 	`
 
-	rows, err := r.db.QueryContext(ctx, query)
+	// User yang dihapus (soft delete) hanya tampil jika diminta lewat filter deleted
+	var base []string
+	if _, ok := q.Filters["deleted"]; !ok {
+		base = append(base, "u.deleted_at IS NULL")
+	}
+
+	l, err := userListSpec.build(q, base, nil)
 	if err != nil {
-		return nil, err
+		return nil, models.ListMeta{}, err
+	}
+
+	var total int
+	if err := r.db.QueryRowContext(ctx, "SELECT count(1)"+from+l.countWhere, l.countArgs...).Scan(&total); err != nil {
+		return nil, models.ListMeta{}, err
+	}
+
+	query := `
+		SELECT u.id, u.username, u.email, u.password_hash, u.full_name, u.role_id, r.name, u.is_active, u.is_super_admin, u.created_at, u.updated_at, COALESCE(u.photo_url, ''),
+			u.deleted_at,
+	` + l.sortKey + from + l.where + l.orderLimit
+
+	rows, err := r.db.QueryContext(ctx, query, l.args...)
+	if err != nil {
+		return nil, models.ListMeta{}, err
 	}
 	defer rows.Close()
 
+	var users []models.User
+	var keys []models.ListCursor
 	for rows.Next() {
 		var user models.User
+		var key string
 		err := rows.Scan(
 			&user.ID, &user.Username, &user.Email, &user.PasswordHash,
 			&user.FullName, &user.RoleID, &user.RoleName, &user.IsActive,
-			&user.CreatedAt, &user.UpdatedAt,
+			&user.IsSuperAdmin, &user.CreatedAt, &user.UpdatedAt, &user.PhotoURL,
+			&user.DeletedAt, &key,
 		)
 		if err != nil {
-			return nil, err
+			return nil, models.ListMeta{}, err
 		}
 		users = append(users, user)
+		keys = append(keys, models.ListCursor{Value: key, ID: user.ID.String()})
 	}
-	return users, nil
+	if err := rows.Err(); err != nil {
+		return nil, models.ListMeta{}, err
+	}
+
+	users, meta := page(users, keys, q, l, total)
+	return users, meta, nil
 }
 
 func (r *userRepository) GetUserByID(ctx context.Context, id uuid.UUID) (models.User, error) {
 	var user models.User
 
 	query := `
-		SELECT u.id, u.username, u.email, u.password_hash, u.full_name, u.role_id, r.name, u.is_active, u.created_at, u.updated_at
+		SELECT u.id, u.username, u.email, u.password_hash, u.full_name, u.role_id, r.name, u.is_active, u.is_super_admin, u.created_at, u.updated_at, COALESCE(u.photo_url, ''),
+			COALESCE(u.language, '')
 		FROM users u
 		JOIN roles r ON u.role_id = r.id
-		WHERE u.id = $1
+		WHERE u.id = $1 AND u.deleted_at IS NULL
 	`
 
 	err := r.db.QueryRowContext(ctx, query, id).Scan(
 		&user.ID, &user.Username, &user.Email, &user.PasswordHash,
 		&user.FullName, &user.RoleID, &user.RoleName, &user.IsActive,
-		&user.CreatedAt, &user.UpdatedAt,
+		&user.IsSuperAdmin, &user.CreatedAt, &user.UpdatedAt, &user.PhotoURL,
+		&user.Language,
 	)
 
 	return user, err
@@ -79,15 +147,56 @@ func (r *userRepository) GetUserByID(ctx context.Context, id uuid.UUID) (models.
 func (r *userRepository) GetByUsernameOrEmail(ctx context.Context, loginInput string) (models.User, error) {
 	var user models.User
 	query := `
-		SELECT u.id, u.username, u.email, u.password_hash, u.full_name, u.role_id, r.name, u.is_active, u.created_at, u.updated_at
+		SELECT u.id, u.username, u.email, u.password_hash, u.full_name, u.role_id, r.name, u.is_active, u.is_super_admin, u.created_at, u.updated_at, COALESCE(u.photo_url, ''),
+			COALESCE(u.language, '')
 		FROM users u
 		JOIN roles r ON u.role_id = r.id
-		WHERE u.username = $1 OR u.email = $1
+		WHERE (u.username = $1 OR u.email = $1) AND u.deleted_at IS NULL
 	`
 	err := r.db.QueryRowContext(ctx, query, loginInput).Scan(
 		&user.ID, &user.Username, &user.Email, &user.PasswordHash,
 		&user.FullName, &user.RoleID, &user.RoleName, 
-		&user.IsActive, &user.CreatedAt, &user.UpdatedAt,
+		&user.IsActive, &user.IsSuperAdmin, &user.CreatedAt, &user.UpdatedAt, &user.PhotoURL,
+		&user.Language,
+	)
+	return user, err
+}
+
+// GetUserByEmail mencari user berdasarkan email saja (tanpa membedakan huruf besar/kecil), dipakai untuk SSO
+func (r *userRepository) GetUserByEmail(ctx context.Context, email string) (models.User, error) {
+	var user models.User
+	query := `
+		SELECT u.id, u.username, u.email, u.password_hash, u.full_name, u.role_id, r.name, u.is_active, u.is_super_admin, u.created_at, u.updated_at, COALESCE(u.photo_url, ''),
+			COALESCE(u.language, '')
+		FROM users u
+		JOIN roles r ON u.role_id = r.id
+		WHERE LOWER(u.email) = LOWER($1) AND u.deleted_at IS NULL
+	`
+	err := r.db.QueryRowContext(ctx, query, email).Scan(
+		&user.ID, &user.Username, &user.Email, &user.PasswordHash,
+		&user.FullName, &user.RoleID, &user.RoleName,
+		&user.IsActive, &user.IsSuperAdmin, &user.CreatedAt, &user.UpdatedAt, &user.PhotoURL,
+		&user.Language,
+	)
+	return user, err
+}
+
+// GetUserByNIM mencari user lewat NIM profil mahasiswanya yang masih aktif
+func (r *userRepository) GetUserByNIM(ctx context.Context, nim string) (models.User, error) {
+	var user models.User
+	query := `
+		SELECT u.id, u.username, u.email, u.password_hash, u.full_name, u.role_id, r.name, u.is_active, u.is_super_admin, u.created_at, u.updated_at, COALESCE(u.photo_url, ''),
+			COALESCE(u.language, '')
+		FROM users u
+		JOIN roles r ON u.role_id = r.id
+		JOIN students s ON s.user_id = u.id
+		WHERE s.student_id = $1 AND s.retired_at IS NULL AND u.deleted_at IS NULL
+	`
+	err := r.db.QueryRowContext(ctx, query, nim).Scan(
+		&user.ID, &user.Username, &user.Email, &user.PasswordHash,
+		&user.FullName, &user.RoleID, &user.RoleName,
+		&user.IsActive, &user.IsSuperAdmin, &user.CreatedAt, &user.UpdatedAt, &user.PhotoURL,
+		&user.Language,
 	)
 	return user, err
 }
@@ -116,15 +225,16 @@ func (r *userRepository) CreateUser(ctx context.Context, tx *sql.Tx, user models
 	return err
 }
 
-func (r *userRepository) UpdateUser(ctx context.Context, id uuid.UUID, user models.UpdateUser) error {
+// UpdateUser memperbarui data akun. Role tidak diubah di sini (lihat UpdateUserRole); is_active nil berarti tetap
+func (r *userRepository) UpdateUser(ctx context.Context, tx *sql.Tx, id uuid.UUID, user models.UpdateUser) error {
 	query := `
 		UPDATE users 
-		SET username = $1, email = $2, full_name = $3, role_id = $4, is_active = $5, updated_at = $6 
-		WHERE id = $7
+		SET username = $1, email = $2, full_name = $3, is_active = COALESCE($4, is_active), updated_at = $5 
+		WHERE id = $6 AND deleted_at IS NULL
 	`
 
-	result, err := r.db.ExecContext(ctx, query,
-		user.Username, user.Email, user.FullName, user.RoleID, user.IsActive, time.Now(), id,
+	result, err := tx.ExecContext(ctx, query,
+		user.Username, user.Email, user.FullName, user.IsActive, time.Now(), id,
 	)
 	if err != nil {
 		return err
@@ -140,9 +250,34 @@ func (r *userRepository) UpdateUser(ctx context.Context, id uuid.UUID, user mode
 	return nil
 }
 
-func (r *userRepository) DeleteUser(ctx context.Context, id uuid.UUID) error {
-	query := "DELETE FROM users WHERE id = $1"
-	result, err := r.db.ExecContext(ctx, query, id)
+// SoftDeleteUser menandai user terhapus dan mencabut semua sesi login-nya. Data user beserta profil
+// mahasiswa/dosen dan prestasinya tetap tersimpan sehingga bisa dipulihkan
+func (r *userRepository) SoftDeleteUser(ctx context.Context, tx *sql.Tx, id uuid.UUID, deletedBy uuid.UUID) error {
+	result, err := tx.ExecContext(ctx, `
+		UPDATE users SET deleted_at = NOW(), deleted_by = $2, updated_at = NOW()
+		WHERE id = $1 AND deleted_at IS NULL
+	`, id, deletedBy)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+
+	_, err = tx.ExecContext(ctx, `UPDATE user_sessions SET revoked_at = NOW() WHERE user_id = $1 AND revoked_at IS NULL`, id)
+	return err
+}
+
+// RestoreUser memulihkan user yang dihapus lewat soft delete
+func (r *userRepository) RestoreUser(ctx context.Context, id uuid.UUID) error {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE users SET deleted_at = NULL, deleted_by = NULL, updated_at = NOW()
+		WHERE id = $1 AND deleted_at IS NOT NULL
+	`, id)
 	if err != nil {
 		return err
 	}
@@ -156,9 +291,157 @@ func (r *userRepository) DeleteUser(ctx context.Context, id uuid.UUID) error {
 	return nil
 }
 
-func (r *userRepository) UpdateUserRole(ctx context.Context, userID uuid.UUID, roleID uuid.UUID) error {
+// GetPurgePlanForUpdate mengunci user (termasuk yang sudah di-soft delete) lalu mengumpulkan semua data
+// miliknya yang akan ikut terhapus: profil mahasiswa/dosen (termasuk yang sudah pensiun), referensi prestasi,
+// dan file foto profil
+func (r *userRepository) GetPurgePlanForUpdate(ctx context.Context, tx *sql.Tx, id uuid.UUID) (models.UserPurgePlan, error) {
+	var plan models.UserPurgePlan
+	var thumbnails []byte
+
+	err := tx.QueryRowContext(ctx, `
+		SELECT u.id, u.username, u.email, u.full_name, u.role_id, r.name, u.is_active, u.is_super_admin,
+			u.created_at, u.updated_at, COALESCE(u.photo_url, ''), u.deleted_at, u.photo_thumbnails
+		FROM users u
+		JOIN roles r ON u.role_id = r.id
+		WHERE u.id = $1
+		FOR UPDATE OF u
+	`, id).Scan(
+		&plan.User.ID, &plan.User.Username, &plan.User.Email, &plan.User.FullName, &plan.User.RoleID,
+		&plan.User.RoleName, &plan.User.IsActive, &plan.User.IsSuperAdmin,
+		&plan.User.CreatedAt, &plan.User.UpdatedAt, &plan.User.PhotoURL, &plan.User.DeletedAt, &thumbnails,
+	)
+	if err != nil {
+		return models.UserPurgePlan{}, err
+	}
+
+	if plan.User.PhotoURL != "" {
+		plan.PhotoURLs = append(plan.PhotoURLs, plan.User.PhotoURL)
+	}
+	if len(thumbnails) > 0 {
+		var sizes map[string]string
+		if err := json.Unmarshal(thumbnails, &sizes); err != nil {
+			return models.UserPurgePlan{}, err
+		}
+		for _, url := range sizes {
+			if url != plan.User.PhotoURL {
+				plan.PhotoURLs = append(plan.PhotoURLs, url)
+			}
+		}
+	}
+
+	if err := collectIDs(ctx, tx, `SELECT id FROM students WHERE user_id = $1 FOR UPDATE`, id, &plan.StudentIDs); err != nil {
+		return models.UserPurgePlan{}, err
+	}
+	if err := collectIDs(ctx, tx, `SELECT id FROM lecturers WHERE user_id = $1 FOR UPDATE`, id, &plan.LecturerIDs); err != nil {
+		return models.UserPurgePlan{}, err
+	}
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT mongo_achievement_id, status
+		FROM achievement_references
+		WHERE student_id = ANY($1::uuid[])
+	`, uuidArray(plan.StudentIDs))
+	if err != nil {
+		return models.UserPurgePlan{}, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var mongoID, status string
+		if err := rows.Scan(&mongoID, &status); err != nil {
+			return models.UserPurgePlan{}, err
+		}
+		plan.MongoAchievementIDs = append(plan.MongoAchievementIDs, mongoID)
+		if status == "verified" {
+			plan.VerifiedAchievements++
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return models.UserPurgePlan{}, err
+	}
+
+	// Mahasiswa aktif yang masih dibimbing harus dipindahkan dulu ke dosen lain
+	err = tx.QueryRowContext(ctx, `
+		SELECT count(1) FROM students WHERE advisor_id = ANY($1::uuid[]) AND retired_at IS NULL
+	`, uuidArray(plan.LecturerIDs)).Scan(&plan.ActiveAdvisees)
+	if err != nil {
+		return models.UserPurgePlan{}, err
+	}
+
+	return plan, nil
+}
+
+// collectIDs menjalankan query satu kolom UUID dengan satu argumen
+func collectIDs(ctx context.Context, tx *sql.Tx, query string, arg interface{}, dst *[]uuid.UUID) error {
+	rows, err := tx.QueryContext(ctx, query, arg)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return err
+		}
+		*dst = append(*dst, id)
+	}
+	return rows.Err()
+}
+
+// uuidArray mengubah daftar UUID menjadi parameter array untuk dibandingkan dengan $n::uuid[]
+func uuidArray(ids []uuid.UUID) interface{} {
+	values := make([]string, len(ids))
+	for i, id := range ids {
+		values[i] = id.String()
+	}
+	return pq.Array(values)
+}
+
+// PurgeUser menghapus permanen user beserta profil mahasiswa/dosen dan referensi prestasinya.
+// Sesi, MFA, riwayat dosen wali, dan permintaan ganti email ikut terhapus lewat ON DELETE CASCADE,
+// sedangkan jejak audit tetap ada dengan aktor NULL
+func (r *userRepository) PurgeUser(ctx context.Context, tx *sql.Tx, plan models.UserPurgePlan) error {
+	queries := []struct {
+		query string
+		arg   interface{}
+	}{
+		{`DELETE FROM achievement_references WHERE student_id = ANY($1::uuid[])`, uuidArray(plan.StudentIDs)},
+		{`DELETE FROM students WHERE id = ANY($1::uuid[])`, uuidArray(plan.StudentIDs)},
+		// Mahasiswa pensiun yang masih menunjuk dosen ini dilepas agar foreign key tidak menghalangi
+		{`UPDATE students SET advisor_id = NULL WHERE advisor_id = ANY($1::uuid[])`, uuidArray(plan.LecturerIDs)},
+		{`DELETE FROM lecturers WHERE id = ANY($1::uuid[])`, uuidArray(plan.LecturerIDs)},
+	}
+	for _, q := range queries {
+		if _, err := tx.ExecContext(ctx, q.query, q.arg); err != nil {
+			return err
+		}
+	}
+
+	result, err := tx.ExecContext(ctx, `DELETE FROM users WHERE id = $1`, plan.User.ID)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+func (r *userRepository) UpdateUserRole(ctx context.Context, tx *sql.Tx, userID uuid.UUID, roleID uuid.UUID) error {
 	query := `UPDATE users SET role_id = $1, updated_at = $2 WHERE id = $3`
-	result, err := r.db.ExecContext(ctx, query, roleID, time.Now(), userID)
+
+	var result sql.Result
+	var err error
+	if tx != nil {
+		result, err = tx.ExecContext(ctx, query, roleID, time.Now(), userID)
+	} else {
+		result, err = r.db.ExecContext(ctx, query, roleID, time.Now(), userID)
+	}
 	if err != nil {
 		return err
 	}
@@ -170,4 +453,63 @@ func (r *userRepository) UpdateUserRole(ctx context.Context, userID uuid.UUID, r
 		return sql.ErrNoRows
 	}
 	return nil
-}
\ No newline at end of file
+}
+
+// CountUsersByRoleForUpdate menghitung user aktif dengan role tertentu sambil mengunci baris-barisnya,
+// sehingga dua request demote bersamaan tidak bisa menghabiskan Admin terakhir
+func (r *userRepository) CountUsersByRoleForUpdate(ctx context.Context, tx *sql.Tx, roleName string) (int, error) {
+	query := `
+		SELECT u.id
+		FROM users u
+		JOIN roles r ON u.role_id = r.id
+		WHERE r.name = $1 AND u.is_active = TRUE AND u.deleted_at IS NULL
+		FOR UPDATE OF u
+	`
+
+	rows, err := tx.QueryContext(ctx, query, roleName)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	count := 0
+	for rows.Next() {
+		count++
+	}
+	return count, rows.Err()
+}
+
+// GetTakenIdentities mengembalikan username dan email (huruf kecil) dari daftar yang sudah dipakai user lain
+func (r *userRepository) GetTakenIdentities(ctx context.Context, usernames []string, emails []string) (map[string]bool, map[string]bool, error) {
+	query := `
+		SELECT LOWER(username), LOWER(email)
+		FROM users
+		WHERE LOWER(username) = ANY($1) OR LOWER(email) = ANY($2)
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, pq.Array(lowerAll(usernames)), pq.Array(lowerAll(emails)))
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	takenUsernames := make(map[string]bool)
+	takenEmails := make(map[string]bool)
+	for rows.Next() {
+		var username, email string
+		if err := rows.Scan(&username, &email); err != nil {
+			return nil, nil, err
+		}
+		takenUsernames[username] = true
+		takenEmails[email] = true
+	}
+	return takenUsernames, takenEmails, rows.Err()
+}
+
+func lowerAll(values []string) []string {
+	lowered := make([]string, len(values))
+	for i, v := range values {
+		lowered[i] = strings.ToLower(v)
+	}
+	return lowered
+}
@@ -0,0 +1,328 @@
+package services
+
+import (
+	"database/sql"
+	"strings"
+	"time"
+	"uas/app/models"
+	"uas/app/repository"
+	"uas/apperror"
+	"uas/i18n"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type AcademicPeriodService interface {
+	GetAcademicPeriods(c *fiber.Ctx) error
+	GetAcademicPeriodByID(c *fiber.Ctx) error
+	CreateAcademicPeriod(c *fiber.Ctx) error
+	UpdateAcademicPeriod(c *fiber.Ctx) error
+	DeleteAcademicPeriod(c *fiber.Ctx) error
+	ActivateAcademicPeriod(c *fiber.Ctx) error
+	CloseAcademicPeriod(c *fiber.Ctx) error
+	ReopenAcademicPeriod(c *fiber.Ctx) error
+}
+
+type academicPeriodService struct {
+	repo repository.AcademicPeriodRepository
+}
+
+func NewAcademicPeriodService(repo repository.AcademicPeriodRepository) AcademicPeriodService {
+	return &academicPeriodService{repo: repo}
+}
+
+func isExclusionViolation(err error) bool {
+	return err != nil && strings.Contains(err.Error(), "exclusion constraint")
+}
+
+// parsePeriodRequest memvalidasi nama dan rentang tanggal periode
+func parsePeriodRequest(c *fiber.Ctx) (models.AcademicPeriod, error) {
+	var req models.AcademicPeriodRequest
+	if err := bindBody(c, &req); err != nil {
+		return models.AcademicPeriod{}, err
+	}
+
+	// Format tanggal sudah diperiksa tag validate
+	start, _ := time.Parse("2006-01-02", strings.TrimSpace(req.StartDate))
+	end, _ := time.Parse("2006-01-02", strings.TrimSpace(req.EndDate))
+
+	return models.AcademicPeriod{Name: normalizeUnitName(req.Name), StartDate: start, EndDate: end}, nil
+}
+
+// periodWriteError memetakan error simpan periode: 409 untuk nama ganda atau rentang tanggal yang beririsan
+func periodWriteError(err error) error {
+	if err == sql.ErrNoRows {
+		return apperror.NotFound("period_not_found")
+	}
+	if isDuplicateKey(err) {
+		return apperror.Conflict("period_name_taken")
+	}
+	if isExclusionViolation(err) {
+		return apperror.Conflict("period_overlap")
+	}
+	return apperror.Internal(err)
+}
+
+// findPeriod mengambil periode dari path :id
+func (s *academicPeriodService) findPeriod(c *fiber.Ctx) (models.AcademicPeriod, error) {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return models.AcademicPeriod{}, invalidID("Period ID")
+	}
+
+	period, err := s.repo.GetPeriodByID(c.Context(), id)
+	if err == sql.ErrNoRows {
+		return models.AcademicPeriod{}, apperror.NotFound("period_not_found")
+	} else if err != nil {
+		return models.AcademicPeriod{}, apperror.Internal(err)
+	}
+	return period, nil
+}
+
+// respondPeriod mengirim data periode terbaru setelah perubahan
+func (s *academicPeriodService) respondPeriod(c *fiber.Ctx, id uuid.UUID, message string) error {
+	period, err := s.repo.GetPeriodByID(c.Context(), id)
+	if err != nil {
+		return apperror.Internal(err)
+	}
+
+	return c.JSON(fiber.Map{
+		"message": message,
+		"success": true,
+		"data":    period,
+	})
+}
+
+// GetAcademicPeriods godoc
+// @Summary      Ambil Semua Periode Akademik
+// @Description  Mengambil daftar periode akademik (semester) terbaru lebih dulu, beserta jumlah prestasi yang tanggal kegiatannya masuk periode tersebut.
+// @Tags         Academic Periods
+// @Produce      json
+// @Security     Bearer
+// @Success      200  {array}   models.AcademicPeriod
+// @Failure      500  {object}  apperror.Problem
+// @Router       /academic-periods [get]
+func (s *academicPeriodService) GetAcademicPeriods(c *fiber.Ctx) error {
+	periods, err := s.repo.GetPeriods(c.Context())
+	if err != nil {
+		return apperror.Internal(err)
+	}
+	if periods == nil {
+		periods = []models.AcademicPeriod{}
+	}
+
+	return c.JSON(fiber.Map{
+		"message": i18n.Message(c, "messages.periods_fetched"),
+		"success": true,
+		"data":    periods,
+	})
+}
+
+// GetAcademicPeriodByID godoc
+// @Summary      Detail Periode Akademik
+// @Tags         Academic Periods
+// @Produce      json
+// @Security     Bearer
+// @Param        id   path      string  true  "Period ID (UUID)"
+// @Success      200  {object}  models.AcademicPeriod
+// @Failure      400  {object}  apperror.Problem
+// @Failure      404  {object}  apperror.Problem
+// @Router       /academic-periods/{id} [get]
+func (s *academicPeriodService) GetAcademicPeriodByID(c *fiber.Ctx) error {
+	period, err := s.findPeriod(c)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(fiber.Map{
+		"message": i18n.Message(c, "messages.period_found"),
+		"success": true,
+		"data":    period,
+	})
+}
+
+// CreateAcademicPeriod godoc
+// @Summary      Tambah Periode Akademik
+// @Description  Membuat periode akademik baru (belum aktif). Nama harus unik dan rentang tanggal tidak boleh beririsan dengan periode lain.
+// @Tags         Academic Periods
+// @Accept       json
+// @Produce      json
+// @Security     Bearer
+// @Param        request  body      models.AcademicPeriodRequest  true  "Data Periode"
+// @Success      201      {object}  models.AcademicPeriod
+// @Failure      400      {object}  apperror.Problem
+// @Failure      409      {object}  apperror.Problem "Nama dipakai / tanggal beririsan"
+// @Failure      422  {object}  apperror.Problem "Data tidak valid (errors berisi pesan per field)"
+// @Router       /academic-periods [post]
+func (s *academicPeriodService) CreateAcademicPeriod(c *fiber.Ctx) error {
+	period, err := parsePeriodRequest(c)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	period.ID = uuid.New()
+	period.CreatedAt = now
+	period.UpdatedAt = now
+	if err := s.repo.CreatePeriod(c.Context(), period); err != nil {
+		return periodWriteError(err)
+	}
+
+	return c.Status(201).JSON(fiber.Map{
+		"message": i18n.Message(c, "messages.period_created"),
+		"success": true,
+		"data":    period,
+	})
+}
+
+// UpdateAcademicPeriod godoc
+// @Summary      Update Periode Akademik
+// @Description  Mengubah nama dan rentang tanggal periode. Periode yang sudah ditutup tidak dapat diubah; buka kembali lebih dulu.
+// @Tags         Academic Periods
+// @Accept       json
+// @Produce      json
+// @Security     Bearer
+// @Param        id       path      string                        true  "Period ID (UUID)"
+// @Param        request  body      models.AcademicPeriodRequest  true  "Data Periode"
+// @Success      200      {object}  models.AcademicPeriod
+// @Failure      400      {object}  apperror.Problem
+// @Failure      404      {object}  apperror.Problem
+// @Failure      409      {object}  apperror.Problem "Sudah ditutup / nama dipakai / tanggal beririsan"
+// @Failure      422  {object}  apperror.Problem "Data tidak valid (errors berisi pesan per field)"
+// @Router       /academic-periods/{id} [put]
+func (s *academicPeriodService) UpdateAcademicPeriod(c *fiber.Ctx) error {
+	existing, err := s.findPeriod(c)
+	if err != nil {
+		return err
+	}
+	if existing.IsClosed() {
+		return apperror.Conflict("period_closed")
+	}
+
+	period, err := parsePeriodRequest(c)
+	if err != nil {
+		return err
+	}
+
+	period.ID = existing.ID
+	if err := s.repo.UpdatePeriod(c.Context(), period); err != nil {
+		return periodWriteError(err)
+	}
+
+	return s.respondPeriod(c, existing.ID, i18n.Message(c, "messages.period_updated"))
+}
+
+// DeleteAcademicPeriod godoc
+// @Summary      Hapus Periode Akademik
+// @Description  Menghapus periode akademik yang belum memiliki prestasi.
+// @Tags         Academic Periods
+// @Produce      json
+// @Security     Bearer
+// @Param        id   path      string  true  "Period ID (UUID)"
+// @Success      200  {object}  map[string]string
+// @Failure      404  {object}  apperror.Problem
+// @Failure      409  {object}  apperror.Problem "Masih memiliki prestasi"
+// @Router       /academic-periods/{id} [delete]
+func (s *academicPeriodService) DeleteAcademicPeriod(c *fiber.Ctx) error {
+	period, err := s.findPeriod(c)
+	if err != nil {
+		return err
+	}
+
+	if period.Achievements > 0 {
+		return apperror.Conflict("period_in_use").With("achievements", period.Achievements)
+	}
+
+	if err := s.repo.DeletePeriod(c.Context(), period.ID); err == sql.ErrNoRows {
+		return apperror.NotFound("period_not_found")
+	} else if err != nil {
+		return apperror.Internal(err)
+	}
+
+	return c.JSON(fiber.Map{
+		"message": i18n.Message(c, "messages.period_deleted"),
+		"success": true,
+	})
+}
+
+// ActivateAcademicPeriod godoc
+// @Summary      Aktifkan Periode Akademik
+// @Description  Menjadikan periode sebagai periode aktif; periode aktif sebelumnya otomatis dinonaktifkan. Periode yang sudah ditutup tidak bisa diaktifkan.
+// @Tags         Academic Periods
+// @Produce      json
+// @Security     Bearer
+// @Param        id   path      string  true  "Period ID (UUID)"
+// @Success      200  {object}  models.AcademicPeriod
+// @Failure      404  {object}  apperror.Problem
+// @Failure      409  {object}  apperror.Problem "Periode sudah ditutup"
+// @Router       /academic-periods/{id}/activate [post]
+func (s *academicPeriodService) ActivateAcademicPeriod(c *fiber.Ctx) error {
+	period, err := s.findPeriod(c)
+	if err != nil {
+		return err
+	}
+	if period.IsClosed() {
+		return apperror.Conflict("period_closed")
+	}
+
+	if err := s.repo.ActivatePeriod(c.Context(), period.ID); err != nil {
+		return periodWriteError(err)
+	}
+
+	return s.respondPeriod(c, period.ID, i18n.Message(c, "messages.period_activated"))
+}
+
+// CloseAcademicPeriod godoc
+// @Summary      Tutup Periode Akademik
+// @Description  Menutup periode akademik. Prestasi yang tanggal kegiatannya masuk periode ini dibekukan: tidak bisa diubah, dihapus, disubmit, diverifikasi, atau ditolak, dan tanggal kegiatan baru di periode ini ditolak.
+// @Tags         Academic Periods
+// @Produce      json
+// @Security     Bearer
+// @Param        id   path      string  true  "Period ID (UUID)"
+// @Success      200  {object}  models.AcademicPeriod
+// @Failure      404  {object}  apperror.Problem
+// @Failure      409  {object}  apperror.Problem "Periode sudah ditutup"
+// @Router       /academic-periods/{id}/close [post]
+func (s *academicPeriodService) CloseAcademicPeriod(c *fiber.Ctx) error {
+	period, err := s.findPeriod(c)
+	if err != nil {
+		return err
+	}
+	if period.IsClosed() {
+		return apperror.InvalidTransition("period_closed")
+	}
+
+	if err := s.repo.ClosePeriod(c.Context(), period.ID, currentUserID(c)); err != nil {
+		return periodWriteError(err)
+	}
+
+	return s.respondPeriod(c, period.ID, i18n.Message(c, "messages.period_closed"))
+}
+
+// ReopenAcademicPeriod godoc
+// @Summary      Buka Kembali Periode Akademik
+// @Description  Membuka kembali periode yang sudah ditutup sehingga prestasi di dalamnya bisa diubah lagi. Periode tidak otomatis menjadi aktif.
+// @Tags         Academic Periods
+// @Produce      json
+// @Security     Bearer
+// @Param        id   path      string  true  "Period ID (UUID)"
+// @Success      200  {object}  models.AcademicPeriod
+// @Failure      404  {object}  apperror.Problem
+// @Failure      409  {object}  apperror.Problem "Periode belum ditutup"
+// @Router       /academic-periods/{id}/reopen [post]
+func (s *academicPeriodService) ReopenAcademicPeriod(c *fiber.Ctx) error {
+	period, err := s.findPeriod(c)
+	if err != nil {
+		return err
+	}
+	if !period.IsClosed() {
+		return apperror.InvalidTransition("period_not_closed")
+	}
+
+	if err := s.repo.ReopenPeriod(c.Context(), period.ID); err != nil {
+		return periodWriteError(err)
+	}
+
+	return s.respondPeriod(c, period.ID, i18n.Message(c, "messages.period_reopened"))
+}
@@ -0,0 +1,66 @@
+package services_test
+
+import (
+	"bytes"
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"uas/app/models"
+	"uas/app/services"
+	"uas/apperror"
+	"uas/mocks"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestCreateAcademicPeriod_ValidatesRangeAndOverlap(t *testing.T) {
+	mockRepo := new(mocks.MockAcademicPeriodRepo)
+	service := services.NewAcademicPeriodService(mockRepo)
+
+	mockRepo.On("CreatePeriod", mock.Anything, mock.MatchedBy(func(p models.AcademicPeriod) bool {
+		return p.Name == "Semester Genap 2025/2026"
+	})).Return(errors.New(`pq: conflicting key value violates exclusion constraint "academic_periods_no_overlap"`)).Once()
+
+	app := fiber.New(fiber.Config{ErrorHandler: apperror.Handler})
+	app.Post("/academic-periods", service.CreateAcademicPeriod)
+	send := func(body string) int {
+		req := httptest.NewRequest("POST", "/academic-periods", bytes.NewReader([]byte(body)))
+		req.Header.Set("Content-Type", "application/json")
+		resp, _ := app.Test(req)
+		return resp.StatusCode
+	}
+
+	assert.Equal(t, 422, send(`{"name":"Semester Genap 2025/2026","start_date":"2026-07-31","end_date":"2026-02-01"}`))
+	assert.Equal(t, 409, send(`{"name":"Semester  Genap 2025/2026","start_date":"2026-02-01","end_date":"2026-07-31"}`))
+	mockRepo.AssertExpectations(t)
+}
+
+func TestUpdateAcademicPeriod_ClosedIsConflict(t *testing.T) {
+	mockRepo := new(mocks.MockAcademicPeriodRepo)
+	service := services.NewAcademicPeriodService(mockRepo)
+
+	periodID := uuid.New()
+	closedAt := time.Date(2026, 2, 5, 0, 0, 0, 0, time.UTC)
+	mockRepo.On("GetPeriodByID", mock.Anything, periodID).
+		Return(models.AcademicPeriod{ID: periodID, Name: "Semester Ganjil 2025/2026", ClosedAt: &closedAt}, nil)
+
+	app := fiber.New(fiber.Config{ErrorHandler: apperror.Handler})
+	app.Put("/academic-periods/:id", service.UpdateAcademicPeriod)
+	app.Post("/academic-periods/:id/activate", service.ActivateAcademicPeriod)
+
+	req := httptest.NewRequest("PUT", "/academic-periods/"+periodID.String(),
+		bytes.NewReader([]byte(`{"name":"Semester Ganjil 2025/2026","start_date":"2025-08-01","end_date":"2026-02-28"}`)))
+	req.Header.Set("Content-Type", "application/json")
+	resp, _ := app.Test(req)
+	assert.Equal(t, 409, resp.StatusCode)
+
+	resp, _ = app.Test(httptest.NewRequest("POST", "/academic-periods/"+periodID.String()+"/activate", nil))
+	assert.Equal(t, 409, resp.StatusCode)
+
+	mockRepo.AssertNotCalled(t, "UpdatePeriod", mock.Anything, mock.Anything)
+	mockRepo.AssertNotCalled(t, "ActivatePeriod", mock.Anything, mock.Anything)
+}
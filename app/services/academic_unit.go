@@ -0,0 +1,640 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+	"uas/app/models"
+	"uas/app/repository"
+	"uas/apperror"
+	"uas/i18n"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type AcademicUnitService interface {
+	GetFaculties(c *fiber.Ctx) error
+	GetFacultyByID(c *fiber.Ctx) error
+	CreateFaculty(c *fiber.Ctx) error
+	UpdateFaculty(c *fiber.Ctx) error
+	DeleteFaculty(c *fiber.Ctx) error
+
+	GetDepartments(c *fiber.Ctx) error
+	GetDepartmentByID(c *fiber.Ctx) error
+	CreateDepartment(c *fiber.Ctx) error
+	UpdateDepartment(c *fiber.Ctx) error
+	DeleteDepartment(c *fiber.Ctx) error
+
+	GetProgramStudies(c *fiber.Ctx) error
+	GetProgramStudyByID(c *fiber.Ctx) error
+	CreateProgramStudy(c *fiber.Ctx) error
+	UpdateProgramStudy(c *fiber.Ctx) error
+	DeleteProgramStudy(c *fiber.Ctx) error
+}
+
+type academicUnitService struct {
+	repo repository.AcademicUnitRepository
+}
+
+func NewAcademicUnitService(repo repository.AcademicUnitRepository) AcademicUnitService {
+	return &academicUnitService{repo: repo}
+}
+
+// isUnknownUnit menandai profil mahasiswa/dosen yang merujuk program studi atau department yang tidak terdaftar
+func isUnknownUnit(err error) bool {
+	return errors.Is(err, repository.ErrUnknownProgramStudy) || errors.Is(err, repository.ErrUnknownDepartment)
+}
+
+// unknownUnitError adalah error untuk klien jika isUnknownUnit(err)
+func unknownUnitError(err error) error {
+	if errors.Is(err, repository.ErrUnknownProgramStudy) {
+		return apperror.BadRequest("program_study_not_registered")
+	}
+	return apperror.BadRequest("department_not_registered")
+}
+
+func isForeignKeyViolation(err error) bool {
+	return err != nil && strings.Contains(err.Error(), "foreign key")
+}
+
+// normalizeUnitName merapikan spasi nama unit agar pengecekan nama unik konsisten
+func normalizeUnitName(name string) string {
+	return strings.Join(strings.Fields(name), " ")
+}
+
+// optionalUUIDQuery membaca query parameter UUID opsional; ok false jika formatnya salah
+func optionalUUIDQuery(c *fiber.Ctx, key string) (*uuid.UUID, bool) {
+	raw := c.Query(key)
+	if raw == "" {
+		return nil, true
+	}
+	id, err := uuid.Parse(raw)
+	if err != nil {
+		return nil, false
+	}
+	return &id, true
+}
+
+// Nama unit pada pesan; diterjemahkan sesuai bahasa response
+const (
+	unitFaculty      = i18n.Key("units.faculty")
+	unitDepartment   = i18n.Key("units.department")
+	unitProgramStudy = i18n.Key("units.program_study")
+)
+
+// unitWriteError memetakan error simpan unit: 409 untuk kode/nama ganda, 400 untuk induk yang tidak ada
+func unitWriteError(err error, parent i18n.Key) error {
+	if err == sql.ErrNoRows {
+		return apperror.NotFound("not_found")
+	}
+	if isDuplicateKey(err) {
+		return apperror.Conflict("unit_duplicate")
+	}
+	if isForeignKeyViolation(err) {
+		return apperror.BadRequest("parent_not_found").WithParams(i18n.Params{"unit": parent})
+	}
+	return apperror.Internal(err)
+}
+
+// GetFaculties godoc
+// @Summary      Ambil Semua Fakultas
+// @Description  Mengambil daftar fakultas beserta jumlah department-nya.
+// @Tags         Academic Units
+// @Produce      json
+// @Security     Bearer
+// @Success      200  {array}   models.Faculty
+// @Failure      500  {object}  apperror.Problem
+// @Router       /faculties [get]
+func (s *academicUnitService) GetFaculties(c *fiber.Ctx) error {
+	faculties, err := s.repo.GetFaculties(c.Context())
+	if err != nil {
+		return apperror.Internal(err)
+	}
+	if faculties == nil {
+		faculties = []models.Faculty{}
+	}
+
+	return c.JSON(fiber.Map{
+		"message": i18n.Message(c, "messages.faculties_fetched"),
+		"success": true,
+		"data":    faculties,
+	})
+}
+
+// GetFacultyByID godoc
+// @Summary      Detail Fakultas
+// @Tags         Academic Units
+// @Produce      json
+// @Security     Bearer
+// @Param        id   path      string  true  "Faculty ID (UUID)"
+// @Success      200  {object}  models.Faculty
+// @Failure      400  {object}  apperror.Problem
+// @Failure      404  {object}  apperror.Problem
+// @Router       /faculties/{id} [get]
+func (s *academicUnitService) GetFacultyByID(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return invalidID("Faculty ID")
+	}
+
+	faculty, err := s.repo.GetFacultyByID(c.Context(), id)
+	if err == sql.ErrNoRows {
+		return apperror.NotFound("faculty_not_found")
+	} else if err != nil {
+		return apperror.Internal(err)
+	}
+
+	return c.JSON(fiber.Map{
+		"message": i18n.Message(c, "messages.faculty_found"),
+		"success": true,
+		"data":    faculty,
+	})
+}
+
+// CreateFaculty godoc
+// @Summary      Tambah Fakultas
+// @Description  Membuat fakultas baru. Kode (opsional) dan nama harus unik.
+// @Tags         Academic Units
+// @Accept       json
+// @Produce      json
+// @Security     Bearer
+// @Param        request  body      models.FacultyRequest  true  "Data Fakultas"
+// @Success      201      {object}  models.Faculty
+// @Failure      400      {object}  apperror.Problem
+// @Failure      409      {object}  apperror.Problem "Kode atau nama sudah dipakai"
+// @Failure      422  {object}  apperror.Problem "Data tidak valid (errors berisi pesan per field)"
+// @Router       /faculties [post]
+func (s *academicUnitService) CreateFaculty(c *fiber.Ctx) error {
+	var req models.FacultyRequest
+	if err := bindBody(c, &req); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	faculty := models.Faculty{
+		ID:        uuid.New(),
+		Code:      strings.TrimSpace(req.Code),
+		Name:      normalizeUnitName(req.Name),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := s.repo.CreateFaculty(c.Context(), faculty); err != nil {
+		return unitWriteError(err, unitFaculty)
+	}
+
+	return c.Status(201).JSON(fiber.Map{
+		"message": i18n.Message(c, "messages.faculty_created"),
+		"success": true,
+		"data":    faculty,
+	})
+}
+
+// UpdateFaculty godoc
+// @Summary      Update Fakultas
+// @Tags         Academic Units
+// @Accept       json
+// @Produce      json
+// @Security     Bearer
+// @Param        id       path      string                 true  "Faculty ID (UUID)"
+// @Param        request  body      models.FacultyRequest  true  "Data Fakultas"
+// @Success      200      {object}  models.Faculty
+// @Failure      400      {object}  apperror.Problem
+// @Failure      404      {object}  apperror.Problem
+// @Failure      409      {object}  apperror.Problem "Kode atau nama sudah dipakai"
+// @Failure      422  {object}  apperror.Problem "Data tidak valid (errors berisi pesan per field)"
+// @Router       /faculties/{id} [put]
+func (s *academicUnitService) UpdateFaculty(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return invalidID("Faculty ID")
+	}
+
+	var req models.FacultyRequest
+	if err := bindBody(c, &req); err != nil {
+		return err
+	}
+
+	faculty := models.Faculty{ID: id, Code: strings.TrimSpace(req.Code), Name: normalizeUnitName(req.Name)}
+	if err := s.repo.UpdateFaculty(c.Context(), faculty); err != nil {
+		return unitWriteError(err, unitFaculty)
+	}
+
+	updated, err := s.repo.GetFacultyByID(c.Context(), id)
+	if err != nil {
+		return apperror.Internal(err)
+	}
+
+	return c.JSON(fiber.Map{
+		"message": i18n.Message(c, "messages.faculty_updated"),
+		"success": true,
+		"data":    updated,
+	})
+}
+
+// DeleteFaculty godoc
+// @Summary      Hapus Fakultas
+// @Description  Menghapus fakultas yang tidak lagi memiliki department.
+// @Tags         Academic Units
+// @Produce      json
+// @Security     Bearer
+// @Param        id   path      string  true  "Faculty ID (UUID)"
+// @Success      200  {object}  map[string]string
+// @Failure      404  {object}  apperror.Problem
+// @Failure      409  {object}  apperror.Problem "Masih memiliki department"
+// @Router       /faculties/{id} [delete]
+func (s *academicUnitService) DeleteFaculty(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return invalidID("Faculty ID")
+	}
+
+	faculty, err := s.repo.GetFacultyByID(c.Context(), id)
+	if err == sql.ErrNoRows {
+		return apperror.NotFound("faculty_not_found")
+	} else if err != nil {
+		return apperror.Internal(err)
+	}
+
+	if faculty.Departments > 0 {
+		return apperror.Conflict("faculty_in_use").With("departments", faculty.Departments)
+	}
+
+	return s.deleteUnit(c, s.repo.DeleteFaculty(c.Context(), id), unitFaculty)
+}
+
+// deleteUnit membentuk response hapus unit. Foreign key yang masih terpakai (misalnya profil mahasiswa/dosen
+// yang sudah pensiun) dianggap konflik
+func (s *academicUnitService) deleteUnit(c *fiber.Ctx, err error, unit i18n.Key) error {
+	if err == sql.ErrNoRows {
+		return apperror.NotFound("not_found").Variant("unit").WithParams(i18n.Params{"unit": unit})
+	} else if isForeignKeyViolation(err) {
+		return apperror.Conflict("in_use").WithParams(i18n.Params{"unit": unit})
+	} else if err != nil {
+		return apperror.Internal(err)
+	}
+
+	return c.JSON(fiber.Map{
+		"message": i18n.Message(c, "messages.unit_deleted", i18n.Params{"unit": unit}),
+		"success": true,
+	})
+}
+
+// GetDepartments godoc
+// @Summary      Ambil Semua Department
+// @Description  Mengambil daftar department beserta jumlah program studi dan dosennya. Bisa difilter per fakultas.
+// @Tags         Academic Units
+// @Produce      json
+// @Security     Bearer
+// @Param        faculty_id  query     string  false  "Faculty ID (UUID)"
+// @Success      200         {array}   models.Department
+// @Failure      400         {object}  apperror.Problem
+// @Router       /departments [get]
+func (s *academicUnitService) GetDepartments(c *fiber.Ctx) error {
+	facultyID, ok := optionalUUIDQuery(c, "faculty_id")
+	if !ok {
+		return invalidID("faculty_id")
+	}
+
+	departments, err := s.repo.GetDepartments(c.Context(), facultyID)
+	if err != nil {
+		return apperror.Internal(err)
+	}
+	if departments == nil {
+		departments = []models.Department{}
+	}
+
+	return c.JSON(fiber.Map{
+		"message": i18n.Message(c, "messages.departments_fetched"),
+		"success": true,
+		"data":    departments,
+	})
+}
+
+// GetDepartmentByID godoc
+// @Summary      Detail Department
+// @Tags         Academic Units
+// @Produce      json
+// @Security     Bearer
+// @Param        id   path      string  true  "Department ID (UUID)"
+// @Success      200  {object}  models.Department
+// @Failure      400  {object}  apperror.Problem
+// @Failure      404  {object}  apperror.Problem
+// @Router       /departments/{id} [get]
+func (s *academicUnitService) GetDepartmentByID(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return invalidID("Department ID")
+	}
+
+	department, err := s.repo.GetDepartmentByID(c.Context(), id)
+	if err == sql.ErrNoRows {
+		return apperror.NotFound("department_not_found")
+	} else if err != nil {
+		return apperror.Internal(err)
+	}
+
+	return c.JSON(fiber.Map{
+		"message": i18n.Message(c, "messages.department_found"),
+		"success": true,
+		"data":    department,
+	})
+}
+
+// parseDepartmentRequest memvalidasi body department
+func parseDepartmentRequest(c *fiber.Ctx) (models.Department, error) {
+	var req models.DepartmentRequest
+	if err := bindBody(c, &req); err != nil {
+		return models.Department{}, err
+	}
+	facultyID, _ := uuid.Parse(strings.TrimSpace(req.FacultyID))
+	return models.Department{FacultyID: facultyID, Code: strings.TrimSpace(req.Code), Name: normalizeUnitName(req.Name)}, nil
+}
+
+// CreateDepartment godoc
+// @Summary      Tambah Department
+// @Description  Membuat department baru di bawah sebuah fakultas. Kode (opsional) dan nama harus unik.
+// @Tags         Academic Units
+// @Accept       json
+// @Produce      json
+// @Security     Bearer
+// @Param        request  body      models.DepartmentRequest  true  "Data Department"
+// @Success      201      {object}  models.Department
+// @Failure      400      {object}  apperror.Problem "Data tidak valid atau fakultas tidak ditemukan"
+// @Failure      409      {object}  apperror.Problem "Kode atau nama sudah dipakai"
+// @Failure      422  {object}  apperror.Problem "Data tidak valid (errors berisi pesan per field)"
+// @Router       /departments [post]
+func (s *academicUnitService) CreateDepartment(c *fiber.Ctx) error {
+	department, err := parseDepartmentRequest(c)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	department.ID = uuid.New()
+	department.CreatedAt = now
+	department.UpdatedAt = now
+	if err := s.repo.CreateDepartment(c.Context(), department); err != nil {
+		return unitWriteError(err, unitFaculty)
+	}
+
+	return c.Status(201).JSON(fiber.Map{
+		"message": i18n.Message(c, "messages.department_created"),
+		"success": true,
+		"data":    department,
+	})
+}
+
+// UpdateDepartment godoc
+// @Summary      Update Department
+// @Description  Mengubah kode, nama, atau fakultas department. Dosen dan program studinya ikut berpindah.
+// @Tags         Academic Units
+// @Accept       json
+// @Produce      json
+// @Security     Bearer
+// @Param        id       path      string                    true  "Department ID (UUID)"
+// @Param        request  body      models.DepartmentRequest  true  "Data Department"
+// @Success      200      {object}  models.Department
+// @Failure      400      {object}  apperror.Problem
+// @Failure      404      {object}  apperror.Problem
+// @Failure      409      {object}  apperror.Problem "Kode atau nama sudah dipakai"
+// @Failure      422  {object}  apperror.Problem "Data tidak valid (errors berisi pesan per field)"
+// @Router       /departments/{id} [put]
+func (s *academicUnitService) UpdateDepartment(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return invalidID("Department ID")
+	}
+
+	department, err := parseDepartmentRequest(c)
+	if err != nil {
+		return err
+	}
+
+	department.ID = id
+	if err := s.repo.UpdateDepartment(c.Context(), department); err != nil {
+		return unitWriteError(err, unitFaculty)
+	}
+
+	updated, err := s.repo.GetDepartmentByID(c.Context(), id)
+	if err != nil {
+		return apperror.Internal(err)
+	}
+
+	return c.JSON(fiber.Map{
+		"message": i18n.Message(c, "messages.department_updated"),
+		"success": true,
+		"data":    updated,
+	})
+}
+
+// DeleteDepartment godoc
+// @Summary      Hapus Department
+// @Description  Menghapus department yang tidak lagi memiliki program studi maupun dosen.
+// @Tags         Academic Units
+// @Produce      json
+// @Security     Bearer
+// @Param        id   path      string  true  "Department ID (UUID)"
+// @Success      200  {object}  map[string]string
+// @Failure      404  {object}  apperror.Problem
+// @Failure      409  {object}  apperror.Problem "Masih memiliki program studi atau dosen"
+// @Router       /departments/{id} [delete]
+func (s *academicUnitService) DeleteDepartment(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return invalidID("Department ID")
+	}
+
+	department, err := s.repo.GetDepartmentByID(c.Context(), id)
+	if err == sql.ErrNoRows {
+		return apperror.NotFound("department_not_found")
+	} else if err != nil {
+		return apperror.Internal(err)
+	}
+
+	if department.ProgramStudies > 0 || department.Lecturers > 0 {
+		return apperror.Conflict("department_in_use").With("program_studies", department.ProgramStudies).With("lecturers", department.Lecturers)
+	}
+
+	return s.deleteUnit(c, s.repo.DeleteDepartment(c.Context(), id), unitDepartment)
+}
+
+// GetProgramStudies godoc
+// @Summary      Ambil Semua Program Studi
+// @Description  Mengambil daftar program studi beserta department, fakultas, dan jumlah mahasiswanya. Bisa difilter per department.
+// @Tags         Academic Units
+// @Produce      json
+// @Security     Bearer
+// @Param        department_id  query     string  false  "Department ID (UUID)"
+// @Success      200            {array}   models.ProgramStudy
+// @Failure      400            {object}  apperror.Problem
+// @Router       /program-studies [get]
+func (s *academicUnitService) GetProgramStudies(c *fiber.Ctx) error {
+	departmentID, ok := optionalUUIDQuery(c, "department_id")
+	if !ok {
+		return invalidID("department_id")
+	}
+
+	programStudies, err := s.repo.GetProgramStudies(c.Context(), departmentID)
+	if err != nil {
+		return apperror.Internal(err)
+	}
+	if programStudies == nil {
+		programStudies = []models.ProgramStudy{}
+	}
+
+	return c.JSON(fiber.Map{
+		"message": i18n.Message(c, "messages.program_studies_fetched"),
+		"success": true,
+		"data":    programStudies,
+	})
+}
+
+// GetProgramStudyByID godoc
+// @Summary      Detail Program Studi
+// @Tags         Academic Units
+// @Produce      json
+// @Security     Bearer
+// @Param        id   path      string  true  "Program Study ID (UUID)"
+// @Success      200  {object}  models.ProgramStudy
+// @Failure      400  {object}  apperror.Problem
+// @Failure      404  {object}  apperror.Problem
+// @Router       /program-studies/{id} [get]
+func (s *academicUnitService) GetProgramStudyByID(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return invalidID("Program Study ID")
+	}
+
+	programStudy, err := s.repo.GetProgramStudyByID(c.Context(), id)
+	if err == sql.ErrNoRows {
+		return apperror.NotFound("program_study_not_found")
+	} else if err != nil {
+		return apperror.Internal(err)
+	}
+
+	return c.JSON(fiber.Map{
+		"message": i18n.Message(c, "messages.program_study_found"),
+		"success": true,
+		"data":    programStudy,
+	})
+}
+
+// parseProgramStudyRequest memvalidasi body program studi
+func parseProgramStudyRequest(c *fiber.Ctx) (models.ProgramStudy, error) {
+	var req models.ProgramStudyRequest
+	if err := bindBody(c, &req); err != nil {
+		return models.ProgramStudy{}, err
+	}
+	departmentID, _ := uuid.Parse(strings.TrimSpace(req.DepartmentID))
+	return models.ProgramStudy{DepartmentID: departmentID, Code: strings.TrimSpace(req.Code), Name: normalizeUnitName(req.Name)}, nil
+}
+
+// CreateProgramStudy godoc
+// @Summary      Tambah Program Studi
+// @Description  Membuat program studi baru di bawah sebuah department. Kode (opsional) dan nama harus unik.
+// @Tags         Academic Units
+// @Accept       json
+// @Produce      json
+// @Security     Bearer
+// @Param        request  body      models.ProgramStudyRequest  true  "Data Program Studi"
+// @Success      201      {object}  models.ProgramStudy
+// @Failure      400      {object}  apperror.Problem "Data tidak valid atau department tidak ditemukan"
+// @Failure      409      {object}  apperror.Problem "Kode atau nama sudah dipakai"
+// @Failure      422  {object}  apperror.Problem "Data tidak valid (errors berisi pesan per field)"
+// @Router       /program-studies [post]
+func (s *academicUnitService) CreateProgramStudy(c *fiber.Ctx) error {
+	programStudy, err := parseProgramStudyRequest(c)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	programStudy.ID = uuid.New()
+	programStudy.CreatedAt = now
+	programStudy.UpdatedAt = now
+	if err := s.repo.CreateProgramStudy(c.Context(), programStudy); err != nil {
+		return unitWriteError(err, unitDepartment)
+	}
+
+	return c.Status(201).JSON(fiber.Map{
+		"message": i18n.Message(c, "messages.program_study_created"),
+		"success": true,
+		"data":    programStudy,
+	})
+}
+
+// UpdateProgramStudy godoc
+// @Summary      Update Program Studi
+// @Description  Mengubah kode, nama, atau department program studi. Mahasiswanya ikut berpindah.
+// @Tags         Academic Units
+// @Accept       json
+// @Produce      json
+// @Security     Bearer
+// @Param        id       path      string                      true  "Program Study ID (UUID)"
+// @Param        request  body      models.ProgramStudyRequest  true  "Data Program Studi"
+// @Success      200      {object}  models.ProgramStudy
+// @Failure      400      {object}  apperror.Problem
+// @Failure      404      {object}  apperror.Problem
+// @Failure      409      {object}  apperror.Problem "Kode atau nama sudah dipakai"
+// @Failure      422  {object}  apperror.Problem "Data tidak valid (errors berisi pesan per field)"
+// @Router       /program-studies/{id} [put]
+func (s *academicUnitService) UpdateProgramStudy(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return invalidID("Program Study ID")
+	}
+
+	programStudy, err := parseProgramStudyRequest(c)
+	if err != nil {
+		return err
+	}
+
+	programStudy.ID = id
+	if err := s.repo.UpdateProgramStudy(c.Context(), programStudy); err != nil {
+		return unitWriteError(err, unitDepartment)
+	}
+
+	updated, err := s.repo.GetProgramStudyByID(c.Context(), id)
+	if err != nil {
+		return apperror.Internal(err)
+	}
+
+	return c.JSON(fiber.Map{
+		"message": i18n.Message(c, "messages.program_study_updated"),
+		"success": true,
+		"data":    updated,
+	})
+}
+
+// DeleteProgramStudy godoc
+// @Summary      Hapus Program Studi
+// @Description  Menghapus program studi yang tidak lagi memiliki mahasiswa.
+// @Tags         Academic Units
+// @Produce      json
+// @Security     Bearer
+// @Param        id   path      string  true  "Program Study ID (UUID)"
+// @Success      200  {object}  map[string]string
+// @Failure      404  {object}  apperror.Problem
+// @Failure      409  {object}  apperror.Problem "Masih memiliki mahasiswa"
+// @Router       /program-studies/{id} [delete]
+func (s *academicUnitService) DeleteProgramStudy(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return invalidID("Program Study ID")
+	}
+
+	programStudy, err := s.repo.GetProgramStudyByID(c.Context(), id)
+	if err == sql.ErrNoRows {
+		return apperror.NotFound("program_study_not_found")
+	} else if err != nil {
+		return apperror.Internal(err)
+	}
+
+	if programStudy.Students > 0 {
+		return apperror.Conflict("program_study_in_use").With("students", programStudy.Students)
+	}
+
+	return s.deleteUnit(c, s.repo.DeleteProgramStudy(c.Context(), id), unitProgramStudy)
+}
@@ -0,0 +1,66 @@
+package services_test
+
+import (
+	"bytes"
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"uas/app/models"
+	"uas/app/services"
+	"uas/apperror"
+	"uas/mocks"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestDeleteDepartment_InUseIsConflict(t *testing.T) {
+	mockRepo := new(mocks.MockAcademicUnitRepo)
+	service := services.NewAcademicUnitService(mockRepo)
+
+	usedID, emptyID := uuid.New(), uuid.New()
+	mockRepo.On("GetDepartmentByID", mock.Anything, usedID).Return(models.Department{ID: usedID, Lecturers: 2}, nil)
+	mockRepo.On("GetDepartmentByID", mock.Anything, emptyID).Return(models.Department{ID: emptyID}, nil)
+	mockRepo.On("DeleteDepartment", mock.Anything, emptyID).Return(nil).Once()
+
+	app := fiber.New(fiber.Config{ErrorHandler: apperror.Handler})
+	app.Delete("/departments/:id", service.DeleteDepartment)
+
+	resp, _ := app.Test(httptest.NewRequest("DELETE", "/departments/"+usedID.String(), nil))
+	assert.Equal(t, 409, resp.StatusCode)
+	mockRepo.AssertNotCalled(t, "DeleteDepartment", mock.Anything, usedID)
+
+	resp, _ = app.Test(httptest.NewRequest("DELETE", "/departments/"+emptyID.String(), nil))
+	assert.Equal(t, 200, resp.StatusCode)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCreateProgramStudy_DuplicateAndUnknownDepartment(t *testing.T) {
+	mockRepo := new(mocks.MockAcademicUnitRepo)
+	service := services.NewAcademicUnitService(mockRepo)
+
+	departmentID := uuid.New()
+	mockRepo.On("CreateProgramStudy", mock.Anything, mock.MatchedBy(func(ps models.ProgramStudy) bool {
+		return ps.Name == "Teknik Informatika"
+	})).Return(errors.New(`pq: duplicate key value violates unique constraint "program_studies_name_lower_idx"`)).Once()
+	mockRepo.On("CreateProgramStudy", mock.Anything, mock.MatchedBy(func(ps models.ProgramStudy) bool {
+		return ps.Name == "Sains Data"
+	})).Return(errors.New(`pq: insert or update on table "program_studies" violates foreign key constraint`)).Once()
+
+	app := fiber.New(fiber.Config{ErrorHandler: apperror.Handler})
+	app.Post("/program-studies", service.CreateProgramStudy)
+	send := func(body string) int {
+		req := httptest.NewRequest("POST", "/program-studies", bytes.NewReader([]byte(body)))
+		req.Header.Set("Content-Type", "application/json")
+		resp, _ := app.Test(req)
+		return resp.StatusCode
+	}
+
+	// Spasi berlebih dirapikan sebelum disimpan
+	assert.Equal(t, 409, send(`{"department_id":"`+departmentID.String()+`","name":"  Teknik   Informatika "}`))
+	assert.Equal(t, 400, send(`{"department_id":"`+departmentID.String()+`","name":"Sains Data"}`))
+	assert.Equal(t, 422, send(`{"department_id":"bukan-uuid","name":"Sains Data"}`))
+	mockRepo.AssertExpectations(t)
+}
@@ -1,11 +1,16 @@
 package services
 
 import (
+	"database/sql"
 	"fmt"
 	"time"
 	"uas/app/models"
 	"uas/app/repository"
+	"uas/apperror"
 	"uas/helpers"
+	"uas/i18n"
+	"uas/policy"
+	"uas/utils"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
@@ -26,64 +31,117 @@ type AchievementService interface {
 }
 
 type achievementService struct {
-	repo repository.AchievementRepository
+	repo    repository.AchievementRepository
+	access  *policy.Engine
+	storage utils.Storage
 }
 
-func NewAchievementService(repo repository.AchievementRepository) AchievementService {
-	return &achievementService{repo: repo}
+func NewAchievementService(repo repository.AchievementRepository, storage utils.Storage) AchievementService {
+	return &achievementService{repo: repo, access: policy.NewEngine(repo), storage: storage}
+}
+
+// authorize mengevaluasi policy akses untuk user login
+func (s *achievementService) authorize(c *fiber.Ctx, action policy.Action, resource policy.Resource) error {
+	return authorizeRequest(c, s.access, action, resource)
+}
+
+func authorizeRequest(c *fiber.Ctx, access *policy.Engine, action policy.Action, resource policy.Resource) error {
+	userID, err := helpers.GetUserIDFromContext(c)
+	if err != nil {
+		return err
+	}
+	roleName, _ := c.Locals("role_name").(string)
+
+	decision, err := access.Authorize(c.Context(), policy.Subject{UserID: userID, Role: roleName}, action, resource)
+	if err != nil {
+		return apperror.Internal(err)
+	}
+
+	if !decision.Allowed {
+		if decision.StateViolation {
+			return apperror.InvalidTransition(decision.Code).Variant(decision.Reason).WithParams(decision.Params)
+		}
+		return apperror.Forbidden(decision.Code).Variant(decision.Reason).WithParams(decision.Params)
+	}
+	return nil
+}
+
+// parseEventDate membaca tanggal kegiatan prestasi (YYYY-MM-DD); kosong berarti fallback
+func parseEventDate(raw string, fallback time.Time) (time.Time, error) {
+	if raw == "" {
+		return fallback, nil
+	}
+	return time.Parse("2006-01-02", raw)
+}
+
+// checkPeriodOpen menolak tanggal kegiatan yang jatuh di periode akademik yang sudah ditutup
+func (s *achievementService) checkPeriodOpen(c *fiber.Ctx, eventDate time.Time) error {
+	period, err := s.repo.GetAcademicPeriodByDate(c.Context(), eventDate)
+	if err == sql.ErrNoRows {
+		return nil
+	} else if err != nil {
+		return apperror.Internal(err)
+	}
+
+	if period.IsClosed() {
+		return apperror.Conflict("period_closed").Variant("event_date").WithParams(i18n.Params{"period": period.Name})
+	}
+	return nil
 }
 
 // CreateAchievement godoc
 // @Summary      Buat Prestasi Baru (Draft)
-// @Description  Mahasiswa membuat data prestasi baru. Status awal otomatis 'draft'.
+// @Description  Mahasiswa membuat data prestasi baru. Status awal otomatis 'draft'. Prestasi masuk ke periode akademik berdasarkan eventDate (YYYY-MM-DD, default hari ini); tanggal di periode yang sudah ditutup ditolak. Mahasiswa yang sudah lulus atau keluar tidak dapat membuat prestasi baru.
 // @Tags         Achievements
 // @Accept       json
 // @Produce      json
 // @Security     Bearer
 // @Param        request body models.CreateAchievementRequest true "Data Prestasi"
 // @Success      201  {object} map[string]interface{}
-// @Failure      400  {object} map[string]string
-// @Failure      401  {object} map[string]string
-// @Failure      404  {object} map[string]string
-// @Failure      500  {object} map[string]string
+// @Failure      400  {object} apperror.Problem
+// @Failure      401  {object} apperror.Problem
+// @Failure      403  {object} apperror.Problem "Mahasiswa sudah lulus / keluar"
+// @Failure      404  {object} apperror.Problem
+// @Failure      422  {object}  apperror.Problem "Data tidak valid (errors berisi pesan per field)"
+// @Failure      500  {object} apperror.Problem
 // @Router       /achievements [post]
 func (s *achievementService) CreateAchievement(c *fiber.Ctx) error {
 
 	var req models.CreateAchievementRequest
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(400).JSON(fiber.Map{
-			"message": "Format data tidak valid",
-			"success": false,
-		})
+	if err := bindBody(c, &req); err != nil {
+		return err
 	}
 
-	userIDLocal := c.Locals("user_id")
-	if userIDLocal == nil {
-		return c.Status(401).JSON(fiber.Map{
-			"message": "Unauthorized: User ID tidak ditemukan",
-			"success": false,
-		})
+	y, m, d := time.Now().Date()
+	eventDate, err := parseEventDate(req.EventDate, time.Date(y, m, d, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		return apperror.BadRequest("invalid_date").WithParams(i18n.Params{"field": "eventDate"})
 	}
 
-	var userID string
-	switch v := userIDLocal.(type) {
-	case string:
-		userID = v
-	case uuid.UUID:
-		userID = v.String()
-	default:
-		return c.Status(500).JSON(fiber.Map{
-			"message": "Internal Server Error: Tipe data User ID tidak dikenali",
-			"success": false,
-		})
+	userID, err := helpers.GetUserIDFromContext(c)
+	if err != nil {
+		return err
 	}
 
 	studentID, err := s.repo.GetStudentIDByUserID(c.Context(), userID)
 	if err != nil {
-		return c.Status(404).JSON(fiber.Map{
-			"message": "Data mahasiswa tidak ditemukan untuk user ini",
-			"success": false,
-		})
+		return apperror.NotFound("student_not_found").Variant("self")
+	}
+
+	// Mahasiswa lulus/keluar masih bisa melihat dan mengekspor prestasinya, tapi tidak menambah yang baru
+	academicStatus, err := s.repo.GetStudentAcademicStatus(c.Context(), studentID)
+	if err != nil {
+		return apperror.Internal(err)
+	}
+	switch academicStatus {
+	case models.AcademicStatusGraduated:
+		return apperror.Forbidden("student_not_active").Variant("graduated")
+	case models.AcademicStatusDroppedOut:
+		return apperror.Forbidden("student_not_active").Variant("dropped_out")
+	}
+
+	if err := s.checkPeriodOpen(c, eventDate); err != nil {
+		return err
 	}
 
 	mongoData := models.AchievementMongo{
@@ -94,17 +152,14 @@ func (s *achievementService) CreateAchievement(c *fiber.Ctx) error {
 		Description:     req.Description,
 		Details:         req.Details,
 		Tags:            req.Tags,
+		EventDate:       eventDate,
 		CreatedAt:       time.Now(),
 		UpdatedAt:       time.Now(),
 	}
 
 	mongoID, err := s.repo.CreateAchievementMongo(c.Context(), mongoData)
 	if err != nil {
-		return c.Status(500).JSON(fiber.Map{
-			"message": "Gagal menyimpan data detail prestasi",
-			"success": false,
-			"error":   err.Error(),
-		})
+		return apperror.Internal(err)
 	}
 
 	pgRef := models.AchievementReference{
@@ -112,24 +167,22 @@ func (s *achievementService) CreateAchievement(c *fiber.Ctx) error {
 		StudentID:          studentID,
 		MongoAchievementID: mongoID,
 		Status:             "draft",
+		EventDate:          eventDate,
 	}
 
 	err = s.repo.CreateAchievementReference(c.Context(), pgRef)
 	if err != nil {
-		return c.Status(500).JSON(fiber.Map{
-			"message": "Gagal menyimpan referensi prestasi",
-			"success": false,
-			"error":   err.Error(),
-		})
+		return apperror.Internal(err)
 	}
 
 	return c.Status(201).JSON(fiber.Map{
-		"message": "Prestasi berhasil dibuat (Draft)",
+		"message": i18n.Message(c, "messages.achievement_created"),
 		"success": true,
 		"data": fiber.Map{
 			"id":                   pgRef.ID,
 			"mongo_achievement_id": mongoID,
 			"status":               "draft",
+			"event_date":           eventDate,
 			"created_at":           time.Now(),
 		},
 	})
@@ -137,7 +190,7 @@ func (s *achievementService) CreateAchievement(c *fiber.Ctx) error {
 
 // UpdateAchievement godoc
 // @Summary      Edit Data Prestasi
-// @Description  Mengubah data prestasi. Hanya bisa dilakukan jika status masih 'draft'.
+// @Description  Mengubah data prestasi. Hanya bisa dilakukan jika status masih 'draft' dan periode akademiknya belum ditutup. eventDate kosong berarti tanggal kegiatan tidak berubah.
 // @Tags         Achievements
 // @Accept       json
 // @Produce      json
@@ -145,49 +198,38 @@ func (s *achievementService) CreateAchievement(c *fiber.Ctx) error {
 // @Param        id      path string true "Achievement ID (UUID)"
 // @Param        request body models.CreateAchievementRequest true "Data Update"
 // @Success      200  {object} map[string]string
-// @Failure      400  {object} map[string]string
-// @Failure      403  {object} map[string]string
-// @Failure      404  {object} map[string]string
-// @Failure      500  {object} map[string]string
+// @Failure      400  {object} apperror.Problem
+// @Failure      403  {object} apperror.Problem
+// @Failure      404  {object} apperror.Problem
+// @Failure      422  {object}  apperror.Problem "Data tidak valid (errors berisi pesan per field)"
+// @Failure      500  {object} apperror.Problem
 // @Router       /achievements/{id} [put]
 func (s *achievementService) UpdateAchievement(c *fiber.Ctx) error {
     id := c.Params("id")
 
     var req models.CreateAchievementRequest
-    if err := c.BodyParser(&req); err != nil {
-        return c.Status(400).JSON(fiber.Map{"message": "Format data salah", "success": false})
-    }
-
-    userIDLocal := c.Locals("user_id")
-    if userIDLocal == nil {
-        return c.Status(401).JSON(fiber.Map{"message": "Unauthorized"})
-    }
-
-    var userID string
-    switch v := userIDLocal.(type) {
-    case string: userID = v
-    case uuid.UUID: userID = v.String()
-    }
-
-    studentID, err := s.repo.GetStudentIDByUserID(c.Context(), userID)
-    if err != nil {
-        return c.Status(403).JSON(fiber.Map{"message": "User bukan mahasiswa"})
+    if err := bindBody(c, &req); err != nil {
+        return err
     }
 
     existingData, err := s.repo.GetAchievementByID(c.Context(), id)
     if err != nil {
-        return c.Status(404).JSON(fiber.Map{"message": "Prestasi tidak ditemukan"})
+        return apperror.NotFound("achievement_not_found")
     }
 
-    if existingData.StudentID != studentID {
-        return c.Status(403).JSON(fiber.Map{"message": "Anda tidak berhak mengedit data ini"})
+    if err := s.authorize(c, policy.ActionUpdate, policy.Achievement(existingData)); err != nil {
+        return err
     }
 
-    if existingData.Status != "draft" {
-        return c.Status(400).JSON(fiber.Map{
-            "message": "Gagal update: Hanya status 'draft' yang boleh diedit",
-            "current_status": existingData.Status,
-        })
+    eventDate, err := parseEventDate(req.EventDate, existingData.EventDate)
+    if err != nil {
+        return apperror.BadRequest("invalid_date").WithParams(i18n.Params{"field": "eventDate"})
+    }
+    // Pindah tanggal tidak boleh memasukkan prestasi ke periode yang sudah ditutup
+    if !eventDate.Equal(existingData.EventDate) {
+        if err := s.checkPeriodOpen(c, eventDate); err != nil {
+            return err
+        }
     }
 
     mongoData := models.AchievementMongo{
@@ -196,14 +238,15 @@ func (s *achievementService) UpdateAchievement(c *fiber.Ctx) error {
         Description:     req.Description,
         Details:         req.Details,
         Tags:            req.Tags,
+        EventDate:       eventDate,
     }
 
     err = s.repo.UpdateAchievement(c.Context(), existingData.ID, existingData.MongoAchievementID, mongoData)
     if err != nil {
-        return c.Status(500).JSON(fiber.Map{"message": "Gagal mengupdate data"})
+        return apperror.Internal(err)
     }
 
-    return c.JSON(fiber.Map{"message": "Prestasi berhasil diupdate", "success": true})
+    return c.JSON(fiber.Map{"message": i18n.Message(c, "messages.achievement_updated"), "success": true})
 }
 
 // DeleteAchievement godoc
@@ -215,47 +258,29 @@ func (s *achievementService) UpdateAchievement(c *fiber.Ctx) error {
 // @Security     Bearer
 // @Param        id   path string true "Achievement ID (UUID)"
 // @Success      200  {object} map[string]string
-// @Failure      400  {object} map[string]string
-// @Failure      403  {object} map[string]string
-// @Failure      404  {object} map[string]string
-// @Failure      500  {object} map[string]string
+// @Failure      400  {object} apperror.Problem
+// @Failure      403  {object} apperror.Problem
+// @Failure      404  {object} apperror.Problem
+// @Failure      500  {object} apperror.Problem
 // @Router       /achievements/{id} [delete]
 func (s *achievementService) DeleteAchievement(c *fiber.Ctx) error {
     id := c.Params("id")
 
-    userIDLocal := c.Locals("user_id")
-    var userID string
-    switch v := userIDLocal.(type) {
-    case string: userID = v
-    case uuid.UUID: userID = v.String()
-    }
-
-    studentID, err := s.repo.GetStudentIDByUserID(c.Context(), userID)
-    if err != nil {
-        return c.Status(403).JSON(fiber.Map{"message": "User bukan mahasiswa"})
-    }
-
     existingData, err := s.repo.GetAchievementByID(c.Context(), id)
     if err != nil {
-        return c.Status(404).JSON(fiber.Map{"message": "Prestasi tidak ditemukan"})
+        return apperror.NotFound("achievement_not_found")
     }
 
-    if existingData.StudentID != studentID {
-        return c.Status(403).JSON(fiber.Map{"message": "Anda tidak berhak menghapus data ini"})
-    }
-
-    if existingData.Status != "draft" {
-        return c.Status(400).JSON(fiber.Map{
-            "message": "Gagal hapus: Hanya status 'draft' yang boleh dihapus",
-        })
+    if err := s.authorize(c, policy.ActionDelete, policy.Achievement(existingData)); err != nil {
+        return err
     }
 
     err = s.repo.SoftDeleteAchievement(c.Context(), existingData.ID, existingData.MongoAchievementID)
     if err != nil {
-        return c.Status(500).JSON(fiber.Map{"message": "Gagal menghapus data"})
+        return apperror.Internal(err)
     }
 
-    return c.JSON(fiber.Map{"message": "Prestasi berhasil dihapus", "success": true})
+    return c.JSON(fiber.Map{"message": i18n.Message(c, "messages.achievement_deleted"), "success": true})
 }
 
 // SubmitAchievement godoc
@@ -267,54 +292,34 @@ func (s *achievementService) DeleteAchievement(c *fiber.Ctx) error {
 // @Security     Bearer
 // @Param        id   path string true "Achievement ID (UUID)"
 // @Success      200  {object} map[string]interface{}
-// @Failure      400  {object} map[string]string
-// @Failure      403  {object} map[string]string
-// @Failure      404  {object} map[string]string
-// @Failure      500  {object} map[string]string
+// @Failure      400  {object} apperror.Problem
+// @Failure      403  {object} apperror.Problem
+// @Failure      404  {object} apperror.Problem
+// @Failure      500  {object} apperror.Problem
 // @Router       /achievements/{id}/submit [post]
 func (s *achievementService) SubmitAchievement(c *fiber.Ctx) error {
     id := c.Params("id")
 
-    // 1. Ambil User ID & Student ID (Standard Auth Check)
-    userIDLocal := c.Locals("user_id")
-    var userID string
-    switch v := userIDLocal.(type) {
-    case string: userID = v
-    case uuid.UUID: userID = v.String()
-    }
-
-    studentID, err := s.repo.GetStudentIDByUserID(c.Context(), userID)
-    if err != nil {
-        return c.Status(403).JSON(fiber.Map{"message": "User bukan mahasiswa"})
-    }
-
-    // 2. Cek Data Existing
+    // 1. Cek Data Existing
     achievement, err := s.repo.GetAchievementByID(c.Context(), id)
     if err != nil {
-        return c.Status(404).JSON(fiber.Map{"message": "Prestasi tidak ditemukan"})
-    }
-
-    // 3. Validasi Kepemilikan
-    if achievement.StudentID != studentID {
-        return c.Status(403).JSON(fiber.Map{"message": "Anda tidak berhak mensubmit data ini"})
+        return apperror.NotFound("achievement_not_found")
     }
 
-    if achievement.Status != "draft" {
-        return c.Status(400).JSON(fiber.Map{
-            "message": "Gagal submit: Hanya prestasi berstatus 'draft' yang bisa disubmit",
-            "current_status": achievement.Status,
-        })
+    // 2. Validasi Kepemilikan & Status (policy)
+    if err := s.authorize(c, policy.ActionSubmit, policy.Achievement(achievement)); err != nil {
+        return err
     }
 
-    // 5. Lakukan Submit
+    // 3. Lakukan Submit
     err = s.repo.SubmitAchievement(c.Context(), id)
     if err != nil {
-        return c.Status(500).JSON(fiber.Map{"message": "Gagal melakukan submit prestasi"})
+        return apperror.Internal(err)
     }
 
     return c.JSON(fiber.Map{
         "success": true,
-        "message": "Prestasi berhasil disubmit dan menunggu verifikasi",
+        "message": i18n.Message(c, "messages.achievement_submitted"),
         "data": fiber.Map{
             "id": id,
             "status": "submitted",
@@ -332,28 +337,33 @@ func (s *achievementService) SubmitAchievement(c *fiber.Ctx) error {
 // @Security     Bearer
 // @Param        id   path string true "Achievement ID (UUID)"
 // @Success      200  {object} map[string]string
-// @Failure      401  {object} map[string]string
-// @Failure      403  {object} map[string]string "Bukan mahasiswa bimbingan anda"
-// @Failure      500  {object} map[string]string
+// @Failure      401  {object} apperror.Problem
+// @Failure      403  {object} apperror.Problem "Bukan mahasiswa bimbingan anda"
+// @Failure      500  {object} apperror.Problem
 // @Router       /achievements/{id}/verify [post]
 func (s *achievementService) VerifyAchievement(c *fiber.Ctx) error {
 	achievementID := c.Params("id")
 
 	verifierUserID, err := helpers.GetUserIDFromContext(c)
 	if err != nil {
-		return c.Status(401).JSON(fiber.Map{"message": err.Error()})
+		return err
 	}
 
-	if err := helpers.ValidateAdvisorAccess(c.Context(), s.repo, achievementID, verifierUserID); err != nil {
-		return c.Status(403).JSON(fiber.Map{"message": err.Error()})
+	achievement, err := s.repo.GetAchievementByID(c.Context(), achievementID)
+	if err != nil {
+		return apperror.NotFound("achievement_not_found")
+	}
+
+	if err := s.authorize(c, policy.ActionVerify, policy.Achievement(achievement)); err != nil {
+		return err
 	}
 
 	err = s.repo.VerifyAchievement(c.Context(), achievementID, verifierUserID)
 	if err != nil {
-		return c.Status(500).JSON(fiber.Map{"message": "Gagal memverifikasi prestasi"})
+		return apperror.Internal(err)
 	}
 
-	return c.JSON(fiber.Map{"success": true, "message": "Prestasi berhasil diverifikasi"})
+	return c.JSON(fiber.Map{"success": true, "message": i18n.Message(c, "messages.achievement_verified")})
 }
 
 // RejectAchievement godoc
@@ -366,44 +376,50 @@ func (s *achievementService) VerifyAchievement(c *fiber.Ctx) error {
 // @Param        id      path string true "Achievement ID (UUID)"
 // @Param        request body models.RejectAchievementRequest true "Alasan Penolakan"
 // @Success      200  {object} map[string]string
-// @Failure      400  {object} map[string]string "Catatan wajib diisi"
-// @Failure      403  {object} map[string]string
-// @Failure      500  {object} map[string]string
+// @Failure      400  {object} apperror.Problem "Catatan wajib diisi"
+// @Failure      403  {object} apperror.Problem
+// @Failure      422  {object}  apperror.Problem "Data tidak valid (errors berisi pesan per field)"
+// @Failure      500  {object} apperror.Problem
 // @Router       /achievements/{id}/reject [post]
 func (s *achievementService) RejectAchievement(c *fiber.Ctx) error {
 	achievementID := c.Params("id")
 
 	var req models.RejectAchievementRequest
-	if err := c.BodyParser(&req); err != nil || req.RejectionNote == "" {
-		return c.Status(400).JSON(fiber.Map{"message": "Alasan penolakan (rejection_note) wajib diisi"})
+	if err := bindBody(c, &req); err != nil {
+		return err
 	}
 
 	verifierUserID, err := helpers.GetUserIDFromContext(c)
 	if err != nil {
-		return c.Status(401).JSON(fiber.Map{"message": err.Error()})
+		return err
+	}
+
+	achievement, err := s.repo.GetAchievementByID(c.Context(), achievementID)
+	if err != nil {
+		return apperror.NotFound("achievement_not_found")
 	}
 
-	if err := helpers.ValidateAdvisorAccess(c.Context(), s.repo, achievementID, verifierUserID); err != nil {
-		return c.Status(403).JSON(fiber.Map{"message": err.Error()})
+	if err := s.authorize(c, policy.ActionReject, policy.Achievement(achievement)); err != nil {
+		return err
 	}
 
 	err = s.repo.RejectAchievement(c.Context(), achievementID, verifierUserID, req.RejectionNote)
 	if err != nil {
-		return c.Status(500).JSON(fiber.Map{"message": "Gagal menolak prestasi"})
+		return apperror.Internal(err)
 	}
 
-	return c.JSON(fiber.Map{"success": true, "message": "Prestasi berhasil ditolak"})
+	return c.JSON(fiber.Map{"success": true, "message": i18n.Message(c, "messages.achievement_rejected")})
 }
 
 // GetAllAchievements godoc
 // @Summary      List Semua Prestasi
-// @Description  Mengambil daftar prestasi. Mahasiswa melihat miliknya sendiri, Dosen melihat anak walinya, Admin lihat semua.
+// @Description  Mengambil daftar prestasi. Mahasiswa melihat miliknya sendiri, Dosen melihat anak walinya (kecuali yang sedang cuti), Admin lihat semua.
 // @Tags         Achievements
 // @Accept       json
 // @Produce      json
 // @Security     Bearer
 // @Success      200  {object} map[string][]models.AchievementResponse
-// @Failure      500  {object} map[string]string
+// @Failure      500  {object} apperror.Problem
 // @Router       /achievements [get]
 func (s *achievementService) GetAllAchievements(c *fiber.Ctx) error {
   
@@ -411,21 +427,26 @@ func (s *achievementService) GetAllAchievements(c *fiber.Ctx) error {
     roleName := c.Locals("role_name").(string)
     userIDLocal := c.Locals("user_id") // ID User Login
 
+    var userID string
+    switch v := userIDLocal.(type) {
+    case string:
+        userID = v
+    case uuid.UUID:
+        userID = v.String()
+    }
+
     // Variable untuk filter query
-    var filterUserID string = ""
-
-    if roleName == "Mahasiswa" {
-        switch v := userIDLocal.(type) {
-        case string:
-            filterUserID = v
-        case uuid.UUID:
-            filterUserID = v.String()
-        }
+    var filter models.AchievementFilter
+    switch roleName {
+    case models.RoleMahasiswa:
+        filter.StudentUserID = userID
+    case models.RoleDosen:
+        filter.AdvisorUserID = userID
     }
 
-    pgRefs, names, nims, err := s.repo.GetAllReferences(c.Context(), filterUserID)
+    pgRefs, owners, err := s.repo.GetAllReferences(c.Context(), filter)
     if err != nil {
-        return c.Status(500).JSON(fiber.Map{"message": "Gagal mengambil data referensi"})
+        return apperror.Internal(err)
     }
 
     if len(pgRefs) == 0 {
@@ -439,7 +460,7 @@ func (s *achievementService) GetAllAchievements(c *fiber.Ctx) error {
 
     mongoDocs, err := s.repo.GetMongoDetailsByIDs(c.Context(), mongoIDs)
     if err != nil {
-        return c.Status(500).JSON(fiber.Map{"message": "Gagal mengambil detail prestasi"})
+        return apperror.Internal(err)
     }
 
     var responses []models.AchievementResponse
@@ -448,13 +469,16 @@ func (s *achievementService) GetAllAchievements(c *fiber.Ctx) error {
         detail, ok := mongoDocs[ref.MongoAchievementID]
         
         res := models.AchievementResponse{
-            ID:          ref.ID,
-            MongoID:     ref.MongoAchievementID,
-            StudentID:   ref.StudentID,
-            StudentName: names[ref.ID],
-            StudentNIM:  nims[ref.ID],
-            Status:      ref.Status,
-            CreatedAt:   ref.CreatedAt,
+            ID:              ref.ID,
+            MongoID:         ref.MongoAchievementID,
+            StudentID:       ref.StudentID,
+            StudentName:     owners[ref.ID].Name,
+            StudentNIM:      owners[ref.ID].NIM,
+            StudentPhotoURL: owners[ref.ID].PhotoURL,
+            Status:          ref.Status,
+            EventDate:       ref.EventDate,
+            PeriodName:      ref.PeriodName,
+            CreatedAt:       ref.CreatedAt,
         }
 
         if ok {
@@ -482,32 +506,20 @@ func (s *achievementService) GetAllAchievements(c *fiber.Ctx) error {
 // @Security     Bearer
 // @Param        id   path string true "Achievement ID (UUID)"
 // @Success      200  {object} map[string]models.AchievementResponse
-// @Failure      403  {object} map[string]string "Akses Ditolak"
-// @Failure      404  {object} map[string]string "Tidak Ditemukan"
-// @Failure      500  {object} map[string]string
+// @Failure      403  {object} apperror.Problem "Akses Ditolak"
+// @Failure      404  {object} apperror.Problem "Tidak Ditemukan"
+// @Failure      500  {object} apperror.Problem
 // @Router       /achievements/{id} [get]
 func (s *achievementService) GetAchievementDetail(c *fiber.Ctx) error {
     id := c.Params("id")
 
     refData, err := s.repo.GetAchievementReferenceWithDetail(c.Context(), id)
     if err != nil {
-        return c.Status(404).JSON(fiber.Map{"message": "Data prestasi tidak ditemukan"})
+        return apperror.NotFound("achievement_not_found")
     }
 
-    userIDLocal := c.Locals("user_id")
-    roleName := c.Locals("role_name").(string)
-
-    if roleName == "Mahasiswa" && userIDLocal != nil {
-        currentUserID := ""
-        switch v := userIDLocal.(type) {
-        case string: currentUserID = v
-        case uuid.UUID: currentUserID = v.String()
-        }
-
-        myStudentID, _ := s.repo.GetStudentIDByUserID(c.Context(), currentUserID)
-        if myStudentID != refData.StudentID {
-            return c.Status(403).JSON(fiber.Map{"message": "Anda tidak memiliki akses ke detail prestasi ini"})
-        }
+    if err := s.authorize(c, policy.ActionRead, policy.Achievement(models.AchievementReference{StudentID: refData.StudentID, Status: refData.Status})); err != nil {
+        return err
     }
 
     mongoData, err := s.repo.GetMongoDetailByID(c.Context(), refData.MongoID)
@@ -538,32 +550,20 @@ func (s *achievementService) GetAchievementDetail(c *fiber.Ctx) error {
 // @Security     Bearer
 // @Param        id   path string true "Achievement ID (UUID)"
 // @Success      200  {object} map[string][]models.HistoryItem
-// @Failure      403  {object} map[string]string
-// @Failure      404  {object} map[string]string
+// @Failure      403  {object} apperror.Problem
+// @Failure      404  {object} apperror.Problem
 // @Router       /achievements/{id}/history [get]
 func (s *achievementService) GetAchievementHistory(c *fiber.Ctx) error {
     id := c.Params("id")
 
     data, err := s.repo.GetAchievementReferenceWithDetail(c.Context(), id)
     if err != nil {
-        return c.Status(404).JSON(fiber.Map{"message": "Prestasi tidak ditemukan"})
+        return apperror.NotFound("achievement_not_found")
     }
 
     // VALIDASI AKSES
-    userIDLocal := c.Locals("user_id")
-    roleName := c.Locals("role_name").(string)
-    
-    if roleName == "Mahasiswa" && userIDLocal != nil {
-        currentUserID := ""
-        switch v := userIDLocal.(type) {
-        case string: currentUserID = v
-        case uuid.UUID: currentUserID = v.String()
-        }
-
-        myStudentID, _ := s.repo.GetStudentIDByUserID(c.Context(), currentUserID)
-        if myStudentID != data.StudentID {
-            return c.Status(403).JSON(fiber.Map{"message": "Anda tidak memiliki akses ke detail prestasi ini"})
-        }
+    if err := s.authorize(c, policy.ActionRead, policy.Achievement(models.AchievementReference{StudentID: data.StudentID, Status: data.Status})); err != nil {
+        return err
     }
 
     var histories []models.HistoryItem
@@ -627,59 +627,55 @@ func (s *achievementService) GetAchievementHistory(c *fiber.Ctx) error {
 // @Param        id   path string true "Achievement ID (UUID)"
 // @Param        file formData file true "File Dokumen"
 // @Success      200  {object} map[string]models.Attachment
-// @Failure      400  {object} map[string]string "File missing / Status salah"
-// @Failure      403  {object} map[string]string "Forbidden"
-// @Failure      500  {object} map[string]string
+// @Failure      400  {object} apperror.Problem "File tidak ada"
+// @Failure      403  {object} apperror.Problem "Forbidden"
+// @Failure      409  {object} apperror.Problem "Status bukan draft"
+// @Failure      500  {object} apperror.Problem
 // @Router       /achievements/{id}/attachments [post]
 func (s *achievementService) UploadAttachment(c *fiber.Ctx) error {
     id := c.Params("id")
 
-    userID, err := helpers.GetUserIDFromContext(c)
-    if err != nil {
-        return c.Status(401).JSON(fiber.Map{"message": err.Error()})
-    }
-    
     data, err := s.repo.GetAchievementByID(c.Context(), id)
     if err != nil {
-        return c.Status(404).JSON(fiber.Map{"message": "Prestasi tidak ditemukan"})
+        return apperror.NotFound("achievement_not_found")
     }
 
-    studentID, _ := s.repo.GetStudentIDByUserID(c.Context(), userID)
-    if data.StudentID != studentID {
-        return c.Status(403).JSON(fiber.Map{"message": "Anda tidak berhak upload file ke prestasi ini"})
-    }
-
-    if data.Status != "draft" {
-        return c.Status(400).JSON(fiber.Map{"message": "Upload gagal: Prestasi sudah disubmit/diverifikasi"})
+    if err := s.authorize(c, policy.ActionUpdate, policy.Achievement(data)); err != nil {
+        return err
     }
 
     file, err := c.FormFile("file")
     if err != nil {
-        return c.Status(400).JSON(fiber.Map{"message": "File tidak ditemukan. Gunakan key form-data 'file'"})
+        return apperror.BadRequest("file_required")
     }
 
-    uniqueFileName := fmt.Sprintf("%d_%s", time.Now().Unix(), file.Filename)
-    filePath := fmt.Sprintf("./uploads/%s", uniqueFileName)
+    src, err := file.Open()
+    if err != nil {
+        return apperror.BadRequest("file_unreadable")
+    }
+    defer src.Close()
 
-    if err := c.SaveFile(file, filePath); err != nil {
-        return c.Status(500).JSON(fiber.Map{"message": "Gagal menyimpan file ke server"})
+    key := fmt.Sprintf("%d_%s", time.Now().Unix(), utils.SafeFileName(file.Filename))
+    fileURL, err := s.storage.Put(c.Context(), key, src)
+    if err != nil {
+        return apperror.Internal(err)
     }
 
     attachment := models.Attachment{
         FileName:   file.Filename,
-        FileURL:    "/uploads/" + uniqueFileName,
+        FileURL:    fileURL,
         FileType:   file.Header.Get("Content-Type"),
         UploadedAt: time.Now(),
     }
 
     err = s.repo.AddAttachmentToMongo(c.Context(), data.MongoAchievementID, attachment)
     if err != nil {
-        return c.Status(500).JSON(fiber.Map{"message": "Gagal mencatat file ke database"})
+        return apperror.Internal(err)
     }
 
     return c.JSON(fiber.Map{
         "success": true,
-        "message": "File berhasil diupload",
+        "message": i18n.Message(c, "messages.file_uploaded"),
         "data":    attachment,
     })
 }
\ No newline at end of file
@@ -1,10 +1,13 @@
 package services_test
 
 import (
+	"bytes"
 	"net/http/httptest"
 	"testing"
+	"time"
 	"uas/app/models"
 	"uas/app/services"
+	"uas/apperror"
 	"uas/mocks"
 
 	"github.com/gofiber/fiber/v2"
@@ -15,7 +18,7 @@ import (
 // --- TEST SUBMIT (Mahasiswa) ---
 func TestSubmitAchievement_Success(t *testing.T) {
 	mockRepo := new(mocks.MockAchievementRepo)
-	service := services.NewAchievementService(mockRepo)
+	service := services.NewAchievementService(mockRepo, mocks.NewMockStorage())
 	
 	mockRepo.On("GetStudentIDByUserID", mock.Anything, "user-mhs").Return("std-1", nil)
 	mockRepo.On("GetAchievementByID", mock.Anything, "ach-1").Return(models.AchievementReference{
@@ -23,7 +26,7 @@ func TestSubmitAchievement_Success(t *testing.T) {
 	}, nil)
 	mockRepo.On("SubmitAchievement", mock.Anything, "ach-1").Return(nil)
 
-	app := fiber.New()
+	app := fiber.New(fiber.Config{ErrorHandler: apperror.Handler})
 	app.Use(func(c *fiber.Ctx) error {
 		c.Locals("user_id", "user-mhs")
 		return c.Next()
@@ -39,14 +42,14 @@ func TestSubmitAchievement_Success(t *testing.T) {
 
 func TestSubmitAchievement_Fail_NotOwner(t *testing.T) {
 	mockRepo := new(mocks.MockAchievementRepo)
-	service := services.NewAchievementService(mockRepo)
+	service := services.NewAchievementService(mockRepo, mocks.NewMockStorage())
 
 	mockRepo.On("GetStudentIDByUserID", mock.Anything, "user-maling").Return("std-2", nil)
 	mockRepo.On("GetAchievementByID", mock.Anything, "ach-1").Return(models.AchievementReference{
 		ID: "ach-1", StudentID: "std-1", Status: "draft",
 	}, nil)
 
-	app := fiber.New()
+	app := fiber.New(fiber.Config{ErrorHandler: apperror.Handler})
 	app.Use(func(c *fiber.Ctx) error {
 		c.Locals("user_id", "user-maling")
 		return c.Next()
@@ -61,7 +64,7 @@ func TestSubmitAchievement_Fail_NotOwner(t *testing.T) {
 
 func TestVerifyAchievement_Success(t *testing.T) {
 	mockRepo := new(mocks.MockAchievementRepo)
-	service := services.NewAchievementService(mockRepo)
+	service := services.NewAchievementService(mockRepo, mocks.NewMockStorage())
 
 	mockRepo.On("GetLecturerIDByUserID", mock.Anything, "user-dosen").Return("lec-1", nil)
 	mockRepo.On("GetAchievementByID", mock.Anything, "ach-1").Return(models.AchievementReference{
@@ -70,7 +73,7 @@ func TestVerifyAchievement_Success(t *testing.T) {
 	mockRepo.On("CheckStudentAdvisorRelationship", mock.Anything, "lec-1", "std-1").Return(true, nil)
 	mockRepo.On("VerifyAchievement", mock.Anything, "ach-1", "user-dosen").Return(nil)
 
-	app := fiber.New()
+	app := fiber.New(fiber.Config{ErrorHandler: apperror.Handler})
 	app.Use(func(c *fiber.Ctx) error {
 		c.Locals("user_id", "user-dosen")
 		return c.Next()
@@ -85,7 +88,7 @@ func TestVerifyAchievement_Success(t *testing.T) {
 
 func TestVerifyAchievement_Fail_NotAdvisor(t *testing.T) {
 	mockRepo := new(mocks.MockAchievementRepo)
-	service := services.NewAchievementService(mockRepo)
+	service := services.NewAchievementService(mockRepo, mocks.NewMockStorage())
 
 	mockRepo.On("GetLecturerIDByUserID", mock.Anything, "user-dosen-asing").Return("lec-99", nil)
 	mockRepo.On("GetAchievementByID", mock.Anything, "ach-1").Return(models.AchievementReference{
@@ -94,7 +97,7 @@ func TestVerifyAchievement_Fail_NotAdvisor(t *testing.T) {
 	
 	mockRepo.On("CheckStudentAdvisorRelationship", mock.Anything, "lec-99", "std-1").Return(false, nil)
 
-	app := fiber.New()
+	app := fiber.New(fiber.Config{ErrorHandler: apperror.Handler})
 	app.Use(func(c *fiber.Ctx) error {
 		c.Locals("user_id", "user-dosen-asing")
 		return c.Next()
@@ -105,4 +108,56 @@ func TestVerifyAchievement_Fail_NotAdvisor(t *testing.T) {
 	resp, _ := app.Test(req)
 
 	assert.Equal(t, 403, resp.StatusCode)
-}
\ No newline at end of file
+}
+
+func TestCreateAchievement_ClosedPeriodRejected(t *testing.T) {
+	mockRepo := new(mocks.MockAchievementRepo)
+	service := services.NewAchievementService(mockRepo, mocks.NewMockStorage())
+
+	closedAt := time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)
+	mockRepo.On("GetStudentIDByUserID", mock.Anything, "user-mhs").Return("std-1", nil)
+	mockRepo.On("GetStudentAcademicStatus", mock.Anything, "std-1").Return(models.AcademicStatusActive, nil)
+	mockRepo.On("GetAcademicPeriodByDate", mock.Anything, time.Date(2024, 10, 5, 0, 0, 0, 0, time.UTC)).
+		Return(models.AcademicPeriod{Name: "Semester Ganjil 2024/2025", ClosedAt: &closedAt}, nil)
+
+	app := fiber.New(fiber.Config{ErrorHandler: apperror.Handler})
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("user_id", "user-mhs")
+		return c.Next()
+	})
+	app.Post("/achievements", service.CreateAchievement)
+
+	send := func(body string) int {
+		req := httptest.NewRequest("POST", "/achievements", bytes.NewReader([]byte(body)))
+		req.Header.Set("Content-Type", "application/json")
+		resp, _ := app.Test(req)
+		return resp.StatusCode
+	}
+
+	assert.Equal(t, 422, send(`{"title":"Juara 1","achievementType":"competition","eventDate":"05-10-2024"}`))
+	assert.Equal(t, 409, send(`{"title":"Juara 1","achievementType":"competition","eventDate":"2024-10-05"}`))
+	mockRepo.AssertExpectations(t)
+}
+
+func TestUpdateAchievement_ClosedPeriodIsFrozen(t *testing.T) {
+	mockRepo := new(mocks.MockAchievementRepo)
+	service := services.NewAchievementService(mockRepo, mocks.NewMockStorage())
+
+	mockRepo.On("GetStudentIDByUserID", mock.Anything, "user-mhs").Return("std-1", nil)
+	mockRepo.On("GetAchievementByID", mock.Anything, "ach-1").Return(models.AchievementReference{
+		ID: "ach-1", StudentID: "std-1", Status: "draft", PeriodName: "Semester Ganjil 2024/2025", PeriodClosed: true,
+	}, nil)
+
+	app := fiber.New(fiber.Config{ErrorHandler: apperror.Handler})
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("user_id", "user-mhs")
+		return c.Next()
+	})
+	app.Put("/achievements/:id", service.UpdateAchievement)
+
+	req := httptest.NewRequest("PUT", "/achievements/ach-1", bytes.NewReader([]byte(`{"title":"Juara 2","achievementType":"competition"}`)))
+	req.Header.Set("Content-Type", "application/json")
+	resp, _ := app.Test(req)
+
+	assert.Equal(t, 409, resp.StatusCode)
+}
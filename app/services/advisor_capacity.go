@@ -0,0 +1,135 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"uas/app/models"
+	"uas/app/repository"
+	"uas/apperror"
+
+	"github.com/google/uuid"
+)
+
+// defaultMaxAdvisees membaca kapasitas bimbingan default dari env ADVISOR_MAX_ADVISEES.
+// nil (tidak dibatasi) jika env kosong atau bukan bilangan positif
+func defaultMaxAdvisees() *int {
+	n, err := strconv.Atoi(strings.TrimSpace(os.Getenv("ADVISOR_MAX_ADVISEES")))
+	if err != nil || n <= 0 {
+		return nil
+	}
+	return &n
+}
+
+// applyDefaultCapacity mengisi kapasitas default untuk dosen yang tidak punya batas sendiri
+func applyDefaultCapacity(loads []models.AdvisorLoad) {
+	def := defaultMaxAdvisees()
+	for i := range loads {
+		if loads[i].MaxAdvisees == nil {
+			loads[i].MaxAdvisees = def
+		}
+	}
+}
+
+// checkAdvisorCapacity mengunci baris dosen di tx dan menolak jika kapasitas bimbingannya sudah penuh, kecuali force.
+// advisorID uuid.Nil berarti mahasiswa tanpa dosen wali
+func checkAdvisorCapacity(ctx context.Context, lecturerRepo repository.LecturerRepository, tx *sql.Tx, advisorID uuid.UUID, force bool) error {
+	if advisorID == uuid.Nil {
+		return nil
+	}
+
+	loads, err := lecturerRepo.GetAdvisorLoads(ctx, tx, []uuid.UUID{advisorID})
+	if err != nil {
+		return apperror.Internal(err)
+	}
+	if len(loads) == 0 {
+		return apperror.BadRequest("advisor_not_found")
+	}
+	applyDefaultCapacity(loads)
+	if loads[0].Full() && !force {
+		return apperror.Conflict("advisor_capacity_full").With("data", loads[0])
+	}
+	return nil
+}
+
+// pickLeastLoaded memilih dosen dengan bimbingan paling sedikit yang masih punya kapasitas
+// (seri: urutan candidates). nil jika semua penuh; force mengabaikan kapasitas
+func pickLeastLoaded(candidates []*models.AdvisorLoad, force bool) *models.AdvisorLoad {
+	var picked *models.AdvisorLoad
+	for _, l := range candidates {
+		if !force && l.Full() {
+			continue
+		}
+		if picked == nil || l.Advisees < picked.Advisees {
+			picked = l
+		}
+	}
+	return picked
+}
+
+// distributeAdvisees membagi mahasiswa ke dosen tujuan. round_robin bergiliran sesuai urutan targets
+// dan melewati dosen yang penuh; load_balanced selalu memilih dosen dengan bimbingan paling sedikit.
+// Advisees pada targets diperbarui sesuai hasil pembagian. false jika kapasitas semua dosen tujuan habis
+func distributeAdvisees(students []uuid.UUID, targets []*models.AdvisorLoad, strategy string, force bool) ([]uuid.UUID, bool) {
+	assigned := make([]uuid.UUID, len(students))
+	next := 0
+	for i := range students {
+		var target *models.AdvisorLoad
+		if strategy == models.DistributionLoadBalance {
+			target = pickLeastLoaded(targets, force)
+		} else {
+			for tries := 0; tries < len(targets) && target == nil; tries++ {
+				candidate := targets[(next+tries)%len(targets)]
+				if force || !candidate.Full() {
+					target = candidate
+					next = (next + tries + 1) % len(targets)
+				}
+			}
+		}
+		if target == nil {
+			return nil, false
+		}
+		assigned[i] = target.LecturerID
+		target.Advisees++
+	}
+	return assigned, true
+}
+
+// summarizeDepartments menjumlahkan beban bimbingan per department. Dosen tanpa department
+// dikelompokkan bersama dengan department_id kosong
+func summarizeDepartments(loads []models.AdvisorLoad) []models.DepartmentLoad {
+	byDept := make(map[uuid.UUID]*models.DepartmentLoad)
+	unlimited := make(map[uuid.UUID]bool)
+	for _, l := range loads {
+		var key uuid.UUID
+		if l.DepartmentID != nil {
+			key = *l.DepartmentID
+		}
+		d := byDept[key]
+		if d == nil {
+			d = &models.DepartmentLoad{DepartmentID: l.DepartmentID, Department: l.Department, Capacity: new(int)}
+			byDept[key] = d
+		}
+		d.Lecturers++
+		d.Advisees += l.Advisees
+		if l.MaxAdvisees == nil {
+			unlimited[key] = true
+		} else {
+			*d.Capacity += *l.MaxAdvisees
+		}
+	}
+
+	departments := make([]models.DepartmentLoad, 0, len(byDept))
+	for key, d := range byDept {
+		if unlimited[key] {
+			d.Capacity = nil
+		}
+		d.AvgAdvisees = float64(d.Advisees) / float64(d.Lecturers)
+		departments = append(departments, *d)
+	}
+	sort.Slice(departments, func(i, j int) bool { return departments[i].Department < departments[j].Department })
+	return departments
+}
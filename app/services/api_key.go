@@ -0,0 +1,184 @@
+package services
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+	"uas/app/models"
+	"uas/app/repository"
+	"uas/apperror"
+	"uas/helpers"
+	"uas/i18n"
+	"uas/utils"
+	"uas/validation"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// Scope yang tidak boleh diberikan ke API key: key tidak boleh membuat key lain atau bertindak atas nama user
+var forbiddenAPIKeyScopes = map[string]bool{
+	"api_keys:manage":   true,
+	"users:impersonate": true,
+}
+
+type APIKeyService interface {
+	CreateAPIKey(c *fiber.Ctx) error
+	GetAPIKeys(c *fiber.Ctx) error
+	RevokeAPIKey(c *fiber.Ctx) error
+}
+
+type apiKeyService struct {
+	repo        repository.APIKeyRepository
+	roleRepo    repository.RoleRepository
+	permissions helpers.PermissionResolver
+}
+
+func NewAPIKeyService(repo repository.APIKeyRepository, roleRepo repository.RoleRepository, permissions helpers.PermissionResolver) APIKeyService {
+	return &apiKeyService{repo: repo, roleRepo: roleRepo, permissions: permissions}
+}
+
+// CreateAPIKey godoc
+// @Summary      Buat API Key
+// @Description  Membuat API key untuk klien mesin. Key hanya ditampilkan sekali; yang disimpan hanya hash-nya. Scope harus berupa nama permission yang juga dimiliki pembuat.
+// @Tags         API Keys
+// @Accept       json
+// @Produce      json
+// @Security     Bearer
+// @Param        request body models.CreateAPIKeyRequest true "Data API Key"
+// @Success      201  {object}  models.CreateAPIKeyResponse
+// @Failure      400  {object}  apperror.Problem
+// @Failure      403  {object}  apperror.Problem
+// @Failure      422  {object}  apperror.Problem "Data tidak valid (errors berisi pesan per field)"
+// @Router       /api-keys [post]
+func (s *apiKeyService) CreateAPIKey(c *fiber.Ctx) error {
+	var req models.CreateAPIKeyRequest
+	if err := bindBody(c, &req); err != nil {
+		return err
+	}
+	req.Name = strings.TrimSpace(req.Name)
+
+	for i, entry := range req.IPAllowlist {
+		if !utils.ValidIPOrCIDR(strings.TrimSpace(entry)) {
+			return validationError(validation.Field(fmt.Sprintf("ip_allowlist[%d]", i), "ip",
+				"validation.ip_allowlist", i18n.Params{"value": entry}))
+		}
+	}
+
+	// Scope harus permission yang ada dan dimiliki oleh role pembuat
+	allPerms, err := s.roleRepo.GetAllPermissions(c.Context())
+	if err != nil {
+		return apperror.Internal(err)
+	}
+	known := make(map[string]bool, len(allPerms))
+	for _, p := range allPerms {
+		known[p.Name] = true
+	}
+
+	roleName, _ := c.Locals("role_name").(string)
+	for i, scope := range req.Scopes {
+		if !known[scope] || forbiddenAPIKeyScopes[scope] {
+			return validationError(validation.Field(fmt.Sprintf("scopes[%d]", i), "scope",
+				"validation.scope", i18n.Params{"value": scope}))
+		}
+
+		allowed, err := s.permissions.HasPermission(c.Context(), roleName, scope)
+		if err != nil {
+			return apperror.Internal(err)
+		}
+		if !allowed {
+			return apperror.Forbidden("api_key_scope_denied").WithParams(i18n.Params{"scope": scope})
+		}
+	}
+
+	rawKey, prefix, err := utils.GenerateAPIKey()
+	if err != nil {
+		return apperror.Internal(err)
+	}
+
+	key := models.APIKey{
+		ID:          uuid.New(),
+		Name:        req.Name,
+		KeyPrefix:   prefix,
+		KeyHash:     utils.HashToken(rawKey),
+		Scopes:      req.Scopes,
+		IPAllowlist: req.IPAllowlist,
+		CreatedAt:   time.Now(),
+	}
+	if key.IPAllowlist == nil {
+		key.IPAllowlist = []string{}
+	}
+	if creatorID, ok := c.Locals("user_id").(uuid.UUID); ok {
+		key.CreatedBy = &creatorID
+	}
+	if req.ExpiresInDays > 0 {
+		expiresAt := time.Now().AddDate(0, 0, req.ExpiresInDays)
+		key.ExpiresAt = &expiresAt
+	}
+
+	if err := s.repo.CreateAPIKey(c.Context(), key); err != nil {
+		return apperror.Internal(err)
+	}
+
+	return c.Status(201).JSON(fiber.Map{
+		"message": i18n.Message(c, "messages.api_key_created"),
+		"success": true,
+		"data":    models.CreateAPIKeyResponse{APIKey: key, Key: rawKey},
+	})
+}
+
+// GetAPIKeys godoc
+// @Summary      Daftar API Key
+// @Description  Menampilkan semua API key (tanpa nilai key) beserta waktu pemakaian terakhir.
+// @Tags         API Keys
+// @Produce      json
+// @Security     Bearer
+// @Success      200  {object}  map[string][]models.APIKey
+// @Failure      500  {object}  apperror.Problem
+// @Router       /api-keys [get]
+func (s *apiKeyService) GetAPIKeys(c *fiber.Ctx) error {
+	keys, err := s.repo.GetAllAPIKeys(c.Context())
+	if err != nil {
+		return apperror.Internal(err)
+	}
+
+	if keys == nil {
+		keys = []models.APIKey{}
+	}
+
+	return c.JSON(fiber.Map{
+		"message": i18n.Message(c, "messages.api_keys_fetched"),
+		"success": true,
+		"data":    keys,
+	})
+}
+
+// RevokeAPIKey godoc
+// @Summary      Cabut API Key
+// @Tags         API Keys
+// @Produce      json
+// @Security     Bearer
+// @Param        id   path      string  true  "API Key ID (UUID)"
+// @Success      200  {object}  map[string]string
+// @Failure      400  {object}  apperror.Problem
+// @Failure      404  {object}  apperror.Problem
+// @Router       /api-keys/{id} [delete]
+func (s *apiKeyService) RevokeAPIKey(c *fiber.Ctx) error {
+	keyID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return invalidID("ID")
+	}
+
+	err = s.repo.RevokeAPIKey(c.Context(), keyID)
+	if err == sql.ErrNoRows {
+		return apperror.NotFound("api_key_not_found")
+	} else if err != nil {
+		return apperror.Internal(err)
+	}
+
+	return c.JSON(fiber.Map{
+		"message": i18n.Message(c, "messages.api_key_revoked"),
+		"success": true,
+	})
+}
@@ -0,0 +1,122 @@
+package services_test
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+	"uas/app/models"
+	"uas/apperror"
+	"uas/helpers"
+	"uas/middleware"
+	"uas/mocks"
+	"uas/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newAPIKeyApp(repo *mocks.MockAPIKeyRepo) *fiber.App {
+	// Resolver tidak boleh dipanggil: permission API key selalu dari scope
+	resolver := helpers.NewPermissionResolver(new(mocks.MockPermissionRepo), time.Minute)
+
+	app := fiber.New(fiber.Config{ErrorHandler: apperror.Handler})
+	protected := app.Group("", middleware.AuthRequired(repo, nil))
+	protected.Get("/reports/statistics", middleware.RequirePermission(resolver, "reports:read"), func(c *fiber.Ctx) error {
+		return c.SendStatus(200)
+	})
+	protected.Get("/users", middleware.RequirePermission(resolver, "users:read"), func(c *fiber.Ctx) error {
+		return c.SendStatus(200)
+	})
+	return app
+}
+
+func TestAPIKey_ScopedAccess(t *testing.T) {
+	repo := new(mocks.MockAPIKeyRepo)
+	rawKey, _, _ := utils.GenerateAPIKey()
+	key := models.APIKey{ID: uuid.New(), Name: "skpi-exporter", Scopes: []string{"reports:read"}}
+
+	repo.On("GetAPIKeyByHash", mock.Anything, utils.HashToken(rawKey)).Return(key, nil)
+	repo.On("TouchAPIKey", mock.Anything, key.ID, mock.Anything).Return(nil)
+
+	app := newAPIKeyApp(repo)
+
+	req := httptest.NewRequest("GET", "/reports/statistics", nil)
+	req.Header.Set("X-API-Key", rawKey)
+	resp, _ := app.Test(req)
+	assert.Equal(t, 200, resp.StatusCode)
+
+	// Di luar scope
+	req = httptest.NewRequest("GET", "/users", nil)
+	req.Header.Set("Authorization", "ApiKey "+rawKey)
+	resp, _ = app.Test(req)
+	assert.Equal(t, 403, resp.StatusCode)
+
+	repo.AssertNumberOfCalls(t, "TouchAPIKey", 2)
+}
+
+func TestAPIKey_ExpiredRevokedAndIPAllowlist(t *testing.T) {
+	past := time.Now().Add(-time.Hour)
+
+	cases := []struct {
+		name   string
+		key    models.APIKey
+		status int
+	}{
+		{"expired", models.APIKey{ID: uuid.New(), Scopes: []string{"reports:read"}, ExpiresAt: &past}, 401},
+		{"revoked", models.APIKey{ID: uuid.New(), Scopes: []string{"reports:read"}, RevokedAt: &past}, 401},
+		{"ip not allowed", models.APIKey{ID: uuid.New(), Scopes: []string{"reports:read"}, IPAllowlist: []string{"10.0.0.0/8"}}, 403},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			repo := new(mocks.MockAPIKeyRepo)
+			repo.On("GetAPIKeyByHash", mock.Anything, mock.Anything).Return(tc.key, nil)
+
+			req := httptest.NewRequest("GET", "/reports/statistics", nil)
+			req.Header.Set("X-API-Key", "uas_dummy")
+			resp, _ := newAPIKeyApp(repo).Test(req)
+
+			assert.Equal(t, tc.status, resp.StatusCode)
+			repo.AssertNotCalled(t, "TouchAPIKey", mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestIPAllowed(t *testing.T) {
+	assert.True(t, utils.IPAllowed("1.2.3.4", nil))
+	assert.True(t, utils.IPAllowed("10.1.2.3", []string{"10.0.0.0/8"}))
+	assert.True(t, utils.IPAllowed("192.168.1.5", []string{"10.0.0.0/8", "192.168.1.5"}))
+	assert.False(t, utils.IPAllowed("192.168.1.6", []string{"10.0.0.0/8", "192.168.1.5"}))
+}
+
+func TestAPIKey_WriteHasNoUserAndIsAudited(t *testing.T) {
+	repo := new(mocks.MockAPIKeyRepo)
+	auditRepo := new(mocks.MockAuditRepo)
+	rawKey, _, _ := utils.GenerateAPIKey()
+	key := models.APIKey{ID: uuid.New(), Name: "siakad-sync", Scopes: []string{"students:update"}}
+
+	repo.On("GetAPIKeyByHash", mock.Anything, utils.HashToken(rawKey)).Return(key, nil)
+	repo.On("TouchAPIKey", mock.Anything, key.ID, mock.Anything).Return(nil)
+	auditRepo.On("CreateAuditLog", mock.Anything, mock.MatchedBy(func(l models.AuditLog) bool {
+		return l.Action == models.AuditAPIKeyRequest && l.ActorID == nil &&
+			l.Metadata["api_key_id"] == key.ID && l.StatusCode == 200
+	})).Return(nil).Once()
+
+	resolver := helpers.NewPermissionResolver(new(mocks.MockPermissionRepo), time.Minute)
+	app := fiber.New(fiber.Config{ErrorHandler: apperror.Handler})
+	app.Use(middleware.AuditAPIKey(auditRepo))
+	protected := app.Group("", middleware.AuthRequired(repo, nil))
+	protected.Put("/students/:id/advisor", middleware.RequirePermission(resolver, "students:update"), func(c *fiber.Ctx) error {
+		// key bukan user: changed_by harus NULL, bukan ID key
+		assert.Nil(t, c.Locals("user_id"))
+		return c.SendStatus(200)
+	})
+
+	req := httptest.NewRequest("PUT", "/students/"+uuid.NewString()+"/advisor", nil)
+	req.Header.Set("X-API-Key", rawKey)
+	resp, _ := app.Test(req)
+	assert.Equal(t, 200, resp.StatusCode)
+	auditRepo.AssertExpectations(t)
+}
@@ -2,8 +2,13 @@ package services
 
 import (
 	"database/sql"
+	"fmt"
+	"os"
+	"time"
 	"uas/app/models"
 	"uas/app/repository"
+	"uas/apperror"
+	"uas/helpers"
 	"uas/utils"
 
 	"github.com/gofiber/fiber/v2"
@@ -14,15 +19,48 @@ import (
 type AuthService interface {
 	Login(c *fiber.Ctx) error
 	Refresh(c *fiber.Ctx) error
-	GetProfile(c *fiber.Ctx) error
+	GetJWKS(c *fiber.Ctx) error
+	EnrollMFA(c *fiber.Ctx) error
+	VerifyMFA(c *fiber.Ctx) error
+	SetupMFA(c *fiber.Ctx) error
+	ActivateMFA(c *fiber.Ctx) error
+	DisableMFA(c *fiber.Ctx) error
+	RegenerateRecoveryCodes(c *fiber.Ctx) error
+	Impersonate(c *fiber.Ctx) error
+	GetSessions(c *fiber.Ctx) error
+	RevokeSession(c *fiber.Ctx) error
+	GetUserSessions(c *fiber.Ctx) error
+	RevokeUserSession(c *fiber.Ctx) error
 }
 
 type authService struct {
-	userRepo repository.UserRepository
+	userRepo    repository.UserRepository
+	mfaRepo     repository.MFARepository
+	permissions helpers.PermissionResolver
+	auditRepo   repository.AuditRepository
+	sessionRepo repository.SessionRepository
 }
 
-func NewAuthService(userRepo repository.UserRepository) AuthService {
-	return &authService{userRepo: userRepo}
+func NewAuthService(userRepo repository.UserRepository, mfaRepo repository.MFARepository, permissions helpers.PermissionResolver, auditRepo repository.AuditRepository, sessionRepo repository.SessionRepository) AuthService {
+	return &authService{userRepo: userRepo, mfaRepo: mfaRepo, permissions: permissions, auditRepo: auditRepo, sessionRepo: sessionRepo}
+}
+
+// tokenPermissions mengisi daftar permission di access token jika JWT_EMBED_PERMISSIONS=true.
+// Gagal lookup tidak fatal: middleware akan fallback ke resolver.
+func (s *authService) tokenPermissions(c *fiber.Ctx, user models.User) []string {
+	return embeddedPermissions(c, s.permissions, user)
+}
+
+func embeddedPermissions(c *fiber.Ctx, resolver helpers.PermissionResolver, user models.User) []string {
+	if resolver == nil || os.Getenv("JWT_EMBED_PERMISSIONS") != "true" {
+		return nil
+	}
+
+	perms, err := resolver.PermissionsForRole(c.Context(), user.RoleName)
+	if err != nil {
+		return nil
+	}
+	return perms
 }
 
 // Login godoc
@@ -32,43 +70,84 @@ func NewAuthService(userRepo repository.UserRepository) AuthService {
 // @Accept       json
 // @Produce      json
 // @Param        request body models.LoginRequest true "Login Payload"
-// @Success      200  {object} models.LoginResponse
-// @Failure      400  {object} map[string]string
-// @Failure      401  {object} map[string]string
+// @Success      200  {object} models.LoginResponse "Token langsung, atau models.MFAChallengeResponse jika MFA aktif/wajib"
+// @Failure      400  {object} apperror.Problem
+// @Failure      401  {object} apperror.Problem
+// @Failure      422  {object}  apperror.Problem "Data tidak valid (errors berisi pesan per field)"
 // @Router       /auth/login [post]
 func (s *authService) Login(c *fiber.Ctx) error {
 	var req models.LoginRequest
 
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(400).JSON(fiber.Map{"error": "Request body tidak valid"})
-	}
-
-	if req.Username == "" || req.Password == "" {
-		return c.Status(400).JSON(fiber.Map{"error": "Username dan password harus diisi"})
+	if err := bindBody(c, &req); err != nil {
+		return err
 	}
 
 	user, err := s.userRepo.GetByUsernameOrEmail(c.Context(), req.Username)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return c.Status(401).JSON(fiber.Map{"error": "Username atau password salah"})
+			return apperror.Unauthorized("invalid_credentials")
 		}
-		return c.Status(500).JSON(fiber.Map{"error": "Terjadi kesalahan pada server"})
+		return apperror.Internal(err)
 	}
 
 	if !utils.CheckPassword(req.Password, user.PasswordHash) {
-		return c.Status(401).JSON(fiber.Map{"error": "Username atau password salah"})
+		return apperror.Unauthorized("invalid_credentials")
 	}
 
 	if !user.IsActive {
-		return c.Status(403).JSON(fiber.Map{"error": "Akun anda dinonaktifkan. Silahkan hubungi admin."})
+		return apperror.Forbidden("account_inactive")
+	}
+
+	// Tahap kedua: MFA aktif atau diwajibkan untuk role ini
+	mfa, err := s.mfaRepo.GetMFAByUserID(c.Context(), user.ID)
+	if err != nil && err != sql.ErrNoRows {
+		return apperror.Internal(err)
+	}
+
+	if err == nil && mfa.IsEnabled {
+		return respondMFAChallenge(c, user, models.MFAPurposeLogin)
+	}
+
+	if isMFARequiredForRole(user.RoleName) {
+		return respondMFAChallenge(c, user, models.MFAPurposeEnroll)
+	}
+
+	return s.loginSuccess(c, user, nil)
+}
+
+// loginSuccess menerbitkan access & refresh token setelah semua tahap autentikasi lolos
+func (s *authService) loginSuccess(c *fiber.Ctx, user models.User, recoveryCodes []string) error {
+	return respondLoginTokens(c, s.sessionRepo, user, s.tokenPermissions(c, user), recoveryCodes)
+}
+
+// respondLoginTokens membuat sesi login baru beserta pasangan tokennya, lalu mengirim response login standar
+// (dipakai juga oleh SSO). sessions boleh nil: token tetap membawa sid tanpa dicatat.
+func respondLoginTokens(c *fiber.Ctx, sessions repository.SessionRepository, user models.User, permissions []string, recoveryCodes []string) error {
+	sessionID := uuid.New()
+	if sessions != nil {
+		now := time.Now()
+		userAgent := c.Get(fiber.HeaderUserAgent)
+		session := models.UserSession{
+			ID:         sessionID,
+			UserID:     user.ID,
+			Device:     utils.DeviceFromUserAgent(userAgent),
+			IPAddress:  c.IP(),
+			UserAgent:  userAgent,
+			CreatedAt:  now,
+			LastUsedAt: now,
+			ExpiresAt:  now.Add(utils.RefreshTokenTTL),
+		}
+		if err := sessions.CreateSession(c.Context(), session); err != nil {
+			return apperror.Internal(err)
+		}
 	}
 
-	accessToken, err := utils.GenerateToken(user)
+	accessToken, err := utils.GenerateSessionToken(user, permissions, sessionID)
 	if err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": "Gagal generate token"})
+		return apperror.Internal(err)
 	}
 
-	refreshToken, _ := utils.GenerateRefreshToken(user)
+	refreshToken, _ := utils.GenerateRefreshToken(user, sessionID)
 
 	userResponse := models.UserResponseDTO{
 		ID:       user.ID,
@@ -77,14 +156,21 @@ func (s *authService) Login(c *fiber.Ctx) error {
 		Role:     user.RoleName,
 	}
 
-	return c.Status(200).JSON(fiber.Map{
+	response := fiber.Map{
 		"status": "success",
 		"data": models.LoginResponse{
 			Token:        accessToken,
 			RefreshToken: refreshToken,
 			User:         userResponse,
 		},
-	})
+	}
+
+	// Kode pemulihan hanya ditampilkan sekali, saat enrollment MFA selesai
+	if len(recoveryCodes) > 0 {
+		response["recovery_codes"] = recoveryCodes
+	}
+
+	return c.Status(200).JSON(response)
 }
 
 // Refresh godoc
@@ -95,49 +181,66 @@ func (s *authService) Login(c *fiber.Ctx) error {
 // @Produce      json
 // @Param        request body models.RefreshTokenRequest true "Refresh Token Payload"
 // @Success      200  {object} map[string]string "Berisi token baru"
-// @Failure      400  {object} map[string]string
-// @Failure      401  {object} map[string]string
+// @Failure      400  {object} apperror.Problem
+// @Failure      401  {object} apperror.Problem
+// @Failure      422  {object}  apperror.Problem "Data tidak valid (errors berisi pesan per field)"
 // @Router       /auth/refresh [post]
 func (s *authService) Refresh(c *fiber.Ctx) error {
-	var req struct {
-		RefreshToken string `json:"refreshToken"`
-	}
+	var req models.RefreshTokenRequest
 
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(400).JSON(fiber.Map{"error": "Invalid request"})
+	if err := bindBody(c, &req); err != nil {
+		return err
 	}
 
 	// Parse token
-	token, err := jwt.Parse(req.RefreshToken, func(t *jwt.Token) (interface{}, error) {
-		return utils.JwtSecret, nil
-	})
+	token, err := utils.ParseToken(req.RefreshToken)
 
 	if err != nil || !token.Valid {
-		return c.Status(401).JSON(fiber.Map{"error": "Invalid refresh token"})
+		return apperror.Unauthorized("refresh_token_invalid")
 	}
 
 	claims := token.Claims.(jwt.MapClaims)
 
 	if claims["type"] != "refresh" {
-		return c.Status(401).JSON(fiber.Map{"error": "Invalid token type"})
+		return apperror.Unauthorized("token_type_invalid")
 	}
 
 	userIDStr := claims["userId"].(string)
 
 	userUUID, err := uuid.Parse(userIDStr)
 	if err != nil {
-		return c.Status(400).JSON(fiber.Map{"error": "Invalid user ID"})
+		return invalidID("User ID")
+	}
+
+	// Refresh token hanya berlaku selama sesinya masih aktif (belum dicabut lewat /auth/sessions)
+	sessionID, _ := uuid.Parse(fmt.Sprint(claims["sid"]))
+	if s.sessionRepo != nil {
+		session, err := s.sessionRepo.GetSessionByID(c.Context(), sessionID)
+		if err != nil && err != sql.ErrNoRows {
+			return apperror.Internal(err)
+		}
+		if err == sql.ErrNoRows || session.UserID != userUUID || !session.IsActive(time.Now()) {
+			return apperror.Unauthorized("session_revoked")
+		}
+
+		// Gagal mencatat pemakaian tidak membatalkan refresh
+		_ = s.sessionRepo.TouchSession(c.Context(), sessionID, c.IP())
 	}
 
 	user, err := s.userRepo.GetUserByID(c.Context(), userUUID)
 	if err != nil {
-		return c.Status(401).JSON(fiber.Map{"error": "User not found"})
+		return apperror.Unauthorized("user_not_found")
+	}
+
+	// User yang dinonaktifkan tidak mendapat access token baru (role dibaca ulang dari database)
+	if !user.IsActive {
+		return apperror.Forbidden("account_inactive")
 	}
 
 	// Generate access token baru
-	newAccessToken, err := utils.GenerateToken(user)
+	newAccessToken, err := utils.GenerateSessionToken(user, s.tokenPermissions(c, user), sessionID)
 	if err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": "Failed to generate access token"})
+		return apperror.Internal(err)
 	}
 
 	return c.JSON(fiber.Map{
@@ -146,33 +249,14 @@ func (s *authService) Refresh(c *fiber.Ctx) error {
 	})
 }
 
-// GetProfile godoc
-// @Summary      Lihat Profil Saya
-// @Description  Melihat informasi user yang sedang login (User ID, Username, Role)
+// GetJWKS godoc
+// @Summary      JSON Web Key Set
+// @Description  Kunci publik untuk memverifikasi JWT yang diterbitkan server ini (mendukung rotasi, dipilih lewat header 'kid').
 // @Tags         Auth
-// @Accept       json
 // @Produce      json
-// @Security     Bearer
 // @Success      200  {object} map[string]interface{}
-// @Failure      401  {object} map[string]string
-// @Router       /auth/profile [get]
-func (s *authService) GetProfile(c *fiber.Ctx) error {
-	userIDLocal := c.Locals("user_id")
-	if userIDLocal == nil {
-		return c.Status(401).JSON(fiber.Map{"error": "Unauthorized"})
-	}
-	userID := userIDLocal.(uuid.UUID)
-
-	username := c.Locals("username").(string)
-	role := c.Locals("role_name").(string)
-
-	return c.JSON(fiber.Map{
-		"success": true,
-		"message": "Profile berhasil diambil",
-		"data": fiber.Map{
-			"user_id":  userID,
-			"username": username,
-			"role":     role,
-		},
-	})
-}
\ No newline at end of file
+// @Router       /.well-known/jwks.json [get]
+func (s *authService) GetJWKS(c *fiber.Ctx) error {
+	c.Set(fiber.HeaderCacheControl, "public, max-age=300")
+	return c.JSON(utils.JWKS())
+}
@@ -2,13 +2,22 @@ package services_test
 
 import (
 	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
 	"database/sql"
 	"encoding/json"
+	"encoding/pem"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"testing"
 	"uas/app/models"
 	"uas/app/services"
+	"uas/apperror"
 	"uas/mocks"
+	"uas/utils"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
@@ -25,8 +34,9 @@ func hashPassword(pwd string) string {
 func TestLogin_Success(t *testing.T) {
 	// 1. SETUP
 	mockRepo := new(mocks.MockUserRepo)
-	authService := services.NewAuthService(mockRepo)
-	app := fiber.New()
+	mockMFARepo := new(mocks.MockMFARepo)
+	authService := services.NewAuthService(mockRepo, mockMFARepo, nil, nil, nil)
+	app := fiber.New(fiber.Config{ErrorHandler: apperror.Handler})
 	app.Post("/login", authService.Login)
 
 	// Data Dummy (Password asli: "123456")
@@ -41,6 +51,7 @@ func TestLogin_Success(t *testing.T) {
 	}
 
 	mockRepo.On("GetByUsernameOrEmail", mock.Anything, "george_ganteng").Return(dummyUser, nil)
+	mockMFARepo.On("GetMFAByUserID", mock.Anything, dummyUser.ID).Return(models.UserMFA{}, sql.ErrNoRows)
 
 	input := map[string]string{
 		"username": "george_ganteng",
@@ -66,8 +77,9 @@ func TestLogin_Success(t *testing.T) {
 
 func TestLogin_WrongPassword(t *testing.T) {
 	mockRepo := new(mocks.MockUserRepo)
-	authService := services.NewAuthService(mockRepo)
-	app := fiber.New()
+	mockMFARepo := new(mocks.MockMFARepo)
+	authService := services.NewAuthService(mockRepo, mockMFARepo, nil, nil, nil)
+	app := fiber.New(fiber.Config{ErrorHandler: apperror.Handler})
 	app.Post("/login", authService.Login)
 
 	// User ada di DB
@@ -95,8 +107,9 @@ func TestLogin_WrongPassword(t *testing.T) {
 
 func TestLogin_UserNotFound(t *testing.T) {
 	mockRepo := new(mocks.MockUserRepo)
-	authService := services.NewAuthService(mockRepo)
-	app := fiber.New()
+	mockMFARepo := new(mocks.MockMFARepo)
+	authService := services.NewAuthService(mockRepo, mockMFARepo, nil, nil, nil)
+	app := fiber.New(fiber.Config{ErrorHandler: apperror.Handler})
 	app.Post("/login", authService.Login)
 
 	mockRepo.On("GetByUsernameOrEmail", mock.Anything, "hantu_laut").Return(models.User{}, sql.ErrNoRows)
@@ -116,8 +129,9 @@ func TestLogin_UserNotFound(t *testing.T) {
 
 func TestLogin_AccountInactive(t *testing.T) {
 	mockRepo := new(mocks.MockUserRepo)
-	authService := services.NewAuthService(mockRepo)
-	app := fiber.New()
+	mockMFARepo := new(mocks.MockMFARepo)
+	authService := services.NewAuthService(mockRepo, mockMFARepo, nil, nil, nil)
+	app := fiber.New(fiber.Config{ErrorHandler: apperror.Handler})
 	app.Post("/login", authService.Login)
 
 	dummyUser := models.User{
@@ -139,4 +153,64 @@ func TestLogin_AccountInactive(t *testing.T) {
 	resp, _ := app.Test(req)
 
 	assert.Equal(t, 403, resp.StatusCode)
-}
\ No newline at end of file
+}
+func writePEMKey(t *testing.T, path string, key interface{}) {
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestJWT_KeyRotation_AndJWKS(t *testing.T) {
+	dir := t.TempDir()
+	user := models.User{ID: uuid.New(), Username: "george_ganteng", RoleName: "Mahasiswa"}
+
+	// Kunci pertama: Ed25519
+	_, edKey, _ := ed25519.GenerateKey(rand.Reader)
+	writePEMKey(t, filepath.Join(dir, "2026-01.pem"), edKey)
+	assert.NoError(t, utils.InitSigningKeys(dir, ""))
+
+	oldToken, err := utils.GenerateToken(user, nil)
+	assert.NoError(t, err)
+
+	// Rotasi: tambah kunci RSA yang lebih baru, kunci lama tetap bisa memverifikasi
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	writePEMKey(t, filepath.Join(dir, "2026-02.pem"), rsaKey)
+	assert.NoError(t, utils.InitSigningKeys(dir, ""))
+
+	claims, err := utils.ValidateToken(oldToken)
+	assert.NoError(t, err)
+	assert.Equal(t, user.ID, claims.UserID)
+
+	newToken, _ := utils.GenerateToken(user, nil)
+	parsed, err := utils.ParseToken(newToken)
+	assert.NoError(t, err)
+	assert.Equal(t, "2026-02", parsed.Header["kid"])
+	assert.Equal(t, "RS256", parsed.Method.Alg())
+
+	// JWKS memuat kedua kunci
+	authService := services.NewAuthService(new(mocks.MockUserRepo), new(mocks.MockMFARepo), nil, nil, nil)
+	app := fiber.New(fiber.Config{ErrorHandler: apperror.Handler})
+	app.Get("/.well-known/jwks.json", authService.GetJWKS)
+
+	resp, _ := app.Test(httptest.NewRequest("GET", "/.well-known/jwks.json", nil))
+	assert.Equal(t, 200, resp.StatusCode)
+
+	var jwks struct {
+		Keys []map[string]string `json:"keys"`
+	}
+	json.NewDecoder(resp.Body).Decode(&jwks)
+	assert.Len(t, jwks.Keys, 2)
+	assert.Equal(t, "OKP", jwks.Keys[0]["kty"])
+	assert.Equal(t, "RSA", jwks.Keys[1]["kty"])
+
+	// Kunci lama dipensiunkan: token lama ditolak
+	os.Remove(filepath.Join(dir, "2026-01.pem"))
+	assert.NoError(t, utils.InitSigningKeys(dir, ""))
+
+	_, err = utils.ValidateToken(oldToken)
+	assert.Error(t, err)
+}
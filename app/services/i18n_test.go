@@ -0,0 +1,104 @@
+package services_test
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"uas/app/models"
+	"uas/apperror"
+	"uas/i18n"
+	"uas/mocks"
+	"uas/validation"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func problemDetail(t *testing.T, app *fiber.App, path, header string) (apperror.Problem, string) {
+	t.Helper()
+	req := httptest.NewRequest("GET", path, nil)
+	if header != "" {
+		req.Header.Set("Accept-Language", header)
+	}
+	resp, _ := app.Test(req)
+
+	var problem apperror.Problem
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&problem))
+	return problem, resp.Header.Get("Content-Language")
+}
+
+func TestI18n_AcceptLanguageSelectsCatalog(t *testing.T) {
+	mockUserRepo := new(mocks.MockUserRepo)
+	userID := uuid.New()
+	mockUserRepo.On("GetUserByID", mock.Anything, userID).Return(models.User{}, sql.ErrNoRows)
+	app := newProblemApp(mockUserRepo)
+
+	problem, lang := problemDetail(t, app, "/users/"+userID.String(), "en-US,en;q=0.9,id;q=0.5")
+	assert.Equal(t, "user_not_found", problem.Code)
+	assert.Equal(t, "User not found", problem.Detail)
+	assert.Equal(t, "en", lang)
+
+	problem, lang = problemDetail(t, app, "/users/"+userID.String(), "fr, id;q=0.8")
+	assert.Equal(t, "User tidak ditemukan", problem.Detail)
+	assert.Equal(t, "id", lang)
+
+	// tanpa header dipakai bahasa Indonesia
+	problem, lang = problemDetail(t, app, "/users/"+userID.String(), "")
+	assert.Equal(t, "User tidak ditemukan", problem.Detail)
+	assert.Equal(t, "id", lang)
+}
+
+func TestI18n_UserPreferenceOverridesHeader(t *testing.T) {
+	app := fiber.New(fiber.Config{ErrorHandler: apperror.Handler})
+	app.Get("/me", func(c *fiber.Ctx) error {
+		c.Locals("language", "en") // diset AuthRequired dari claim lang
+		return apperror.BadRequest("invalid_id").WithParams(i18n.Params{"field": "Role ID"})
+	})
+
+	problem, lang := problemDetail(t, app, "/me", "id")
+	assert.Equal(t, "Invalid Role ID format", problem.Detail)
+	assert.Equal(t, "en", lang)
+}
+
+func TestI18n_ValidationErrorsTranslated(t *testing.T) {
+	app := fiber.New(fiber.Config{ErrorHandler: apperror.Handler})
+	app.Post("/check", func(c *fiber.Ctx) error {
+		return apperror.Validation(validation.Field("email", "email", "validation.email"))
+	})
+
+	req := httptest.NewRequest("POST", "/check", bytes.NewReader(nil))
+	req.Header.Set("Accept-Language", "en")
+	resp, _ := app.Test(req)
+	assert.Equal(t, 422, resp.StatusCode)
+
+	var res struct {
+		Detail string                  `json:"detail"`
+		Errors []validation.FieldError `json:"errors"`
+	}
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&res))
+	assert.Equal(t, "The submitted data is invalid", res.Detail)
+	assert.Equal(t, "email must be a valid email address", res.Errors[0].Message)
+}
+
+func TestI18n_CatalogsHaveSameKeysAndPlaceholders(t *testing.T) {
+	assert.Equal(t, i18n.Keys(i18n.Indonesian), i18n.Keys(i18n.English))
+
+	placeholder := regexp.MustCompile(`\{[a-z_]+\}`)
+	for _, key := range i18n.Keys(i18n.Indonesian) {
+		id, _ := i18n.Lookup(i18n.Indonesian, key)
+		en, _ := i18n.Lookup(i18n.English, key)
+		assert.ElementsMatch(t, placeholder.FindAllString(id, -1), placeholder.FindAllString(en, -1), key)
+	}
+}
+
+func TestI18n_Negotiate(t *testing.T) {
+	assert.Equal(t, i18n.English, i18n.Negotiate("id;q=0.3, en-GB;q=0.7"))
+	assert.Equal(t, i18n.Indonesian, i18n.Negotiate("in"))
+	assert.Equal(t, i18n.Default, i18n.Negotiate("de, fr;q=0.5"))
+	assert.Equal(t, i18n.Default, i18n.Negotiate("en;q=0"))
+}
@@ -0,0 +1,123 @@
+package services
+
+import (
+	"database/sql"
+	"os"
+	"time"
+	"uas/app/models"
+	"uas/apperror"
+	"uas/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+func impersonationTTL() time.Duration {
+	ttl, err := time.ParseDuration(os.Getenv("IMPERSONATION_TOKEN_TTL"))
+	if err != nil || ttl <= 0 {
+		return utils.ImpersonationTokenTTL
+	}
+	return ttl
+}
+
+// Impersonate godoc
+// @Summary      Impersonate User (Admin)
+// @Description  Menerbitkan token berumur pendek untuk melihat aplikasi sebagai user lain. Token ditandai impersonator_id, aksi yang mengubah data diblokir, dan setiap request dicatat di audit log. Super-admin, Admin, dan user dengan role yang memegang permission setingkat Admin tidak bisa di-impersonate.
+// @Tags         Auth
+// @Accept       json
+// @Produce      json
+// @Security     Bearer
+// @Param        id       path  string                     true  "User ID target (UUID)"
+// @Param        request  body  models.ImpersonateRequest  true  "Alasan impersonation"
+// @Success      200  {object}  models.ImpersonationResponse
+// @Failure      400  {object}  apperror.Problem
+// @Failure      403  {object}  apperror.Problem
+// @Failure      404  {object}  apperror.Problem
+// @Failure      422  {object}  apperror.Problem "Data tidak valid (errors berisi pesan per field)"
+// @Router       /users/{id}/impersonate [post]
+func (s *authService) Impersonate(c *fiber.Ctx) error {
+	actorID, ok := c.Locals("user_id").(uuid.UUID)
+	if !ok {
+		return apperror.Unauthorized("unauthorized")
+	}
+
+	// Token impersonation tidak boleh dipakai untuk impersonate user lain lagi
+	if c.Locals("impersonator_id") != nil {
+		return apperror.Forbidden("impersonation_nested")
+	}
+
+	targetID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return invalidID("User ID")
+	}
+
+	var req models.ImpersonateRequest
+	if err := bindBody(c, &req); err != nil {
+		return err
+	}
+
+	if targetID == actorID {
+		return apperror.BadRequest("impersonate_self")
+	}
+
+	target, err := s.userRepo.GetUserByID(c.Context(), targetID)
+	if err == sql.ErrNoRows {
+		return apperror.NotFound("user_not_found")
+	} else if err != nil {
+		return apperror.Internal(err)
+	}
+
+	// Akun yang setara Admin tidak boleh di-impersonate, termasuk role kustom dengan permission setingkat Admin
+	adminLevel, err := isAdminLevelRole(c.Context(), s.permissions, target.RoleName)
+	if err != nil {
+		return apperror.Internal(err)
+	}
+	if adminLevel || target.IsSuperAdmin {
+		return apperror.Forbidden("impersonate_admin")
+	}
+
+	if !target.IsActive {
+		return apperror.BadRequest("target_inactive")
+	}
+
+	ttl := impersonationTTL()
+	token, err := utils.GenerateImpersonationToken(target, actorID, s.tokenPermissions(c, target), ttl)
+	if err != nil {
+		return apperror.Internal(err)
+	}
+
+	// Impersonation tanpa jejak audit tidak boleh terjadi
+	err = s.auditRepo.CreateAuditLog(c.Context(), models.AuditLog{
+		ID:            uuid.New(),
+		ActorID:       &actorID,
+		SubjectUserID: &target.ID,
+		Action:        models.AuditImpersonationStart,
+		Method:        c.Method(),
+		Path:          c.OriginalURL(),
+		StatusCode:    200,
+		IPAddress:     c.IP(),
+		Metadata: map[string]interface{}{
+			"reason":     req.Reason,
+			"expires_in": int(ttl.Seconds()),
+		},
+		CreatedAt: time.Now(),
+	})
+	if err != nil {
+		return apperror.Internal(err)
+	}
+
+	return c.JSON(fiber.Map{
+		"status": "success",
+		"data": models.ImpersonationResponse{
+			Token:     token,
+			ExpiresIn: int(ttl.Seconds()),
+			User: models.UserResponseDTO{
+				ID:       target.ID,
+				Username: target.Username,
+				FullName: target.FullName,
+				Role:     target.RoleName,
+			},
+			ImpersonatorID: actorID,
+		},
+	})
+}
@@ -0,0 +1,126 @@
+package services_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"uas/app/models"
+	"uas/app/services"
+	"uas/apperror"
+	"uas/helpers"
+	"uas/middleware"
+	"uas/mocks"
+	"uas/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestImpersonate_IssuesFlaggedTokenAndAudits(t *testing.T) {
+	mockRepo := new(mocks.MockUserRepo)
+	mockAudit := new(mocks.MockAuditRepo)
+	authService := services.NewAuthService(mockRepo, new(mocks.MockMFARepo), nil, mockAudit, nil)
+
+	adminID := uuid.New()
+	target := models.User{ID: uuid.New(), Username: "george_mhs", RoleName: "Mahasiswa", IsActive: true}
+
+	app := fiber.New(fiber.Config{ErrorHandler: apperror.Handler})
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("user_id", adminID)
+		return c.Next()
+	})
+	app.Post("/users/:id/impersonate", authService.Impersonate)
+
+	mockRepo.On("GetUserByID", mock.Anything, target.ID).Return(target, nil)
+	mockAudit.On("CreateAuditLog", mock.Anything, mock.MatchedBy(func(l models.AuditLog) bool {
+		return l.Action == models.AuditImpersonationStart && l.ActorID != nil && *l.ActorID == adminID && *l.SubjectUserID == target.ID &&
+			l.Metadata["reason"] == "Tiket #123"
+	})).Return(nil)
+
+	body, _ := json.Marshal(models.ImpersonateRequest{Reason: "Tiket #123"})
+	req := httptest.NewRequest("POST", "/users/"+target.ID.String()+"/impersonate", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, _ := app.Test(req)
+	assert.Equal(t, 200, resp.StatusCode)
+
+	var responseBody struct {
+		Data models.ImpersonationResponse `json:"data"`
+	}
+	json.NewDecoder(resp.Body).Decode(&responseBody)
+
+	claims, err := utils.ValidateToken(responseBody.Data.Token)
+	assert.NoError(t, err)
+	assert.Equal(t, target.ID, claims.UserID)
+	assert.Equal(t, adminID, *claims.ImpersonatorID)
+	mockAudit.AssertExpectations(t)
+}
+
+func TestImpersonationToken_BlocksWritesAndLogsEveryRequest(t *testing.T) {
+	mockAudit := new(mocks.MockAuditRepo)
+	adminID := uuid.New()
+	target := models.User{ID: uuid.New(), Username: "george_mhs", RoleName: "Mahasiswa"}
+	token, _ := utils.GenerateImpersonationToken(target, adminID, nil, utils.ImpersonationTokenTTL)
+
+	app := fiber.New(fiber.Config{ErrorHandler: apperror.Handler})
+	app.Use(middleware.AuditImpersonation(mockAudit))
+	app.Get("/achievements", middleware.AuthRequired(nil, nil), func(c *fiber.Ctx) error { return c.SendStatus(200) })
+	app.Delete("/achievements/:id", middleware.AuthRequired(nil, nil), func(c *fiber.Ctx) error { return c.SendStatus(200) })
+
+	mockAudit.On("CreateAuditLog", mock.Anything, mock.MatchedBy(func(l models.AuditLog) bool {
+		return l.Action == models.AuditImpersonationRequest && l.ActorID != nil && *l.ActorID == adminID && *l.SubjectUserID == target.ID
+	})).Return(nil).Twice()
+
+	req := httptest.NewRequest("GET", "/achievements", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, _ := app.Test(req)
+	assert.Equal(t, 200, resp.StatusCode)
+	assert.Equal(t, adminID.String(), resp.Header.Get("X-Impersonated-By"))
+
+	req = httptest.NewRequest("DELETE", "/achievements/ach-1", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, _ = app.Test(req)
+	assert.Equal(t, 403, resp.StatusCode)
+
+	mockAudit.AssertExpectations(t)
+	// status di audit sama dengan yang diterima klien, termasuk error domain
+	var statuses []int
+	for _, call := range mockAudit.Calls {
+		statuses = append(statuses, call.Arguments.Get(1).(models.AuditLog).StatusCode)
+	}
+	assert.Equal(t, []int{200, 403}, statuses)
+}
+
+func TestImpersonate_RejectsAdminLevelTargets(t *testing.T) {
+	mockRepo := new(mocks.MockUserRepo)
+	mockPermRepo := new(mocks.MockPermissionRepo)
+	resolver := helpers.NewPermissionResolver(mockPermRepo, time.Minute)
+	authService := services.NewAuthService(mockRepo, new(mocks.MockMFARepo), resolver, new(mocks.MockAuditRepo), nil)
+
+	app := fiber.New(fiber.Config{ErrorHandler: apperror.Handler})
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("user_id", uuid.New())
+		return c.Next()
+	})
+	app.Post("/users/:id/impersonate", authService.Impersonate)
+
+	operator := models.User{ID: uuid.New(), Username: "operator", RoleName: "Operator", IsActive: true}
+	superAdmin := models.User{ID: uuid.New(), Username: "root", RoleName: "Dosen Wali", IsActive: true, IsSuperAdmin: true}
+	mockRepo.On("GetUserByID", mock.Anything, operator.ID).Return(operator, nil)
+	mockRepo.On("GetUserByID", mock.Anything, superAdmin.ID).Return(superAdmin, nil)
+	mockPermRepo.On("GetPermissionNamesByRole", mock.Anything, "Operator").Return([]string{"users:impersonate"}, nil)
+	mockPermRepo.On("GetPermissionNamesByRole", mock.Anything, "Dosen Wali").Return([]string{"achievements:verify"}, nil)
+
+	for _, target := range []models.User{operator, superAdmin} {
+		body, _ := json.Marshal(models.ImpersonateRequest{Reason: "Tiket #123"})
+		req := httptest.NewRequest("POST", "/users/"+target.ID.String()+"/impersonate", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, _ := app.Test(req)
+		assert.Equal(t, 403, resp.StatusCode, target.Username)
+	}
+}
@@ -2,7 +2,11 @@ package services
 
 import (
 	"database/sql"
+	"uas/app/models"
 	"uas/app/repository"
+	"uas/apperror"
+	"uas/helpers"
+	"uas/i18n"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
@@ -12,50 +16,65 @@ type LecturerService interface {
 	GetLecturers(c *fiber.Ctx) error
 	GetLecturerByID(c *fiber.Ctx) error
 	GetLecturerAdvisees(c *fiber.Ctx) error
+	GetMyLecturerProfile(c *fiber.Ctx) error
+	GetMyAdvisees(c *fiber.Ctx) error
+	GetAdvisorLoadReport(c *fiber.Ctx) error
+	UpdateLecturerCapacity(c *fiber.Ctx) error
 }
 
 type lecturerService struct {
-	repo repository.LecturerRepository
+	repo            repository.LecturerRepository
+	achievementRepo repository.AchievementRepository
 }
 
-func NewLecturerService(repo repository.LecturerRepository) LecturerService {
-	return &lecturerService{repo: repo}
+func NewLecturerService(repo repository.LecturerRepository, achievementRepo repository.AchievementRepository) LecturerService {
+	return &lecturerService{repo: repo, achievementRepo: achievementRepo}
 }
 
 // GetLecturers godoc
 // @Summary      Ambil Semua Dosen Wali
-// @Description  Mengambil daftar lengkap dosen wali yang aktif.
+// @Description  Mengambil daftar dosen wali dengan pencarian (nama, username, email, kode dosen), filter, urutan, dan paginasi offset atau cursor.
 // @Tags         Lecturers
 // @Accept       json
 // @Produce      json
 // @Security     Bearer
+// @Param        q           query     string  false  "Kata pencarian"
+// @Param        is_active   query     bool    false  "Status aktif"
+// @Param        department  query     string  false  "Nama department"
+// @Param        department_id  query  string  false  "Department ID (UUID)"
+// @Param        faculty_id  query     string  false  "Faculty ID (UUID)"
+// @Param        sort        query     string  false  "full_name | lecturer_id | department | created_at, awalan - untuk menurun"
+// @Param        order       query     string  false  "asc | desc"
+// @Param        page        query     int     false  "Halaman (mode offset)"
+// @Param        limit       query     int     false  "Jumlah per halaman (maks 100)"
+// @Param        cursor      query     string  false  "Cursor dari meta.next_cursor (mode cursor)"
 // @Success      200  {object}  map[string][]models.GetLecture
-// @Failure      500  {object}  map[string]string
+// @Failure      400  {object}  apperror.Problem
+// @Failure      500  {object}  apperror.Problem
 // @Router       /lecturers [get]
 func (s *lecturerService) GetLecturers(c *fiber.Ctx) error {
 	const targetRole = "Dosen Wali"
 
-	lecturers, err := s.repo.GetAllLecturersByRole(c.Context(), targetRole)
+	q, err := parseListQuery(c)
 	if err != nil {
-		return c.Status(500).JSON(fiber.Map{
-			"message": "Terjadi kesalahan server",
-			"success": false,
-			"error":   err.Error(),
-		})
+		return listError(err)
 	}
 
+	lecturers, meta, err := s.repo.GetAllLecturersByRole(c.Context(), targetRole, q)
+	if err != nil {
+		return listError(err)
+	}
+
+	message := i18n.Message(c, "messages.advisors_fetched")
 	if len(lecturers) == 0 {
-		return c.JSON(fiber.Map{
-			"message": "Data Dosen Wali tidak ditemukan",
-			"success": true,
-			"data":    []string{},
-		})
+		message = i18n.Message(c, "messages.advisors_empty")
 	}
 
 	return c.JSON(fiber.Map{
-		"message": "Data Dosen Wali berhasil diambil",
+		"message": message,
 		"success": true,
 		"data":    lecturers,
+		"meta":    meta,
 	})
 }
 
@@ -68,37 +87,27 @@ func (s *lecturerService) GetLecturers(c *fiber.Ctx) error {
 // @Security     Bearer
 // @Param        id   path      string  true  "Lecturer ID (UUID)"
 // @Success      200  {object}  map[string]models.GetLecture
-// @Failure      400  {object}  map[string]string "Format ID salah"
-// @Failure      404  {object}  map[string]string "Tidak ditemukan"
-// @Failure      500  {object}  map[string]string
+// @Failure      400  {object}  apperror.Problem "Format ID salah"
+// @Failure      404  {object}  apperror.Problem "Tidak ditemukan"
+// @Failure      500  {object}  apperror.Problem
 // @Router       /lecturers/{id} [get]
 func (s *lecturerService) GetLecturerByID(c *fiber.Ctx) error {
 	idParam := c.Params("id")
 
 	if _, err := uuid.Parse(idParam); err != nil {
-		return c.Status(400).JSON(fiber.Map{
-			"message": "Format ID tidak valid",
-			"success": false,
-		})
+		return invalidID("ID")
 	}
 
 	lecturer, err := s.repo.GetLecturerByID(c.Context(), idParam)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return c.Status(404).JSON(fiber.Map{
-				"message": "Dosen tidak ditemukan",
-				"success": false,
-			})
+			return apperror.NotFound("lecturer_not_found")
 		}
-		return c.Status(500).JSON(fiber.Map{
-			"message": "Terjadi kesalahan server",
-			"success": false,
-			"error":   err.Error(),
-		})
+		return apperror.Internal(err)
 	}
 
 	return c.JSON(fiber.Map{
-		"message": "Data dosen ditemukan",
+		"message": i18n.Message(c, "messages.lecturer_found"),
 		"success": true,
 		"data":    lecturer,
 	})
@@ -106,47 +115,201 @@ func (s *lecturerService) GetLecturerByID(c *fiber.Ctx) error {
 
 // GetLecturerAdvisees godoc
 // @Summary      Ambil Mahasiswa Bimbingan
-// @Description  Melihat daftar mahasiswa yang dibimbing oleh dosen tertentu.
+// @Description  Melihat daftar mahasiswa yang dibimbing oleh dosen tertentu beserta jumlah prestasi per status dan total poin (dari prestasi verified). Mendukung pencarian, filter, urutan, dan paginasi offset atau cursor.
 // @Tags         Lecturers
 // @Accept       json
 // @Produce      json
 // @Security     Bearer
-// @Param        id   path      string  true  "Lecturer ID (UUID)"
-// @Success      200  {object}  map[string][]models.GetStudent
-// @Failure      400  {object}  map[string]string
-// @Failure      500  {object}  map[string]string
+// @Param        id               path      string  true   "Lecturer ID (UUID)"
+// @Param        q                query     string  false  "Kata pencarian (nama, username, email, NIM)"
+// @Param        is_active        query     bool    false  "Status aktif"
+// @Param        program_study_id query     string  false  "Program Study ID (UUID)"
+// @Param        academic_year    query     string  false  "Angkatan"
+// @Param        academic_status  query     string  false  "active | on_leave | graduated | dropped_out"
+// @Param        sort             query     string  false  "full_name | nim | academic_year | achievements | verified, awalan - untuk menurun"
+// @Param        order            query     string  false  "asc | desc"
+// @Param        page             query     int     false  "Halaman (mode offset)"
+// @Param        limit            query     int     false  "Jumlah per halaman (maks 100)"
+// @Param        cursor           query     string  false  "Cursor dari meta.next_cursor (mode cursor)"
+// @Success      200  {object}  map[string][]models.Advisee
+// @Failure      400  {object}  apperror.Problem
+// @Failure      500  {object}  apperror.Problem
 // @Router       /lecturers/{id}/advisees [get]
 func (s *lecturerService) GetLecturerAdvisees(c *fiber.Ctx) error {
 	lecturerID := c.Params("id")
 
 	if _, err := uuid.Parse(lecturerID); err != nil {
-		return c.Status(400).JSON(fiber.Map{
-			"message": "Format ID Dosen tidak valid",
-			"success": false,
-		})
+		return invalidID("Lecturer ID")
+	}
+
+	return s.listAdvisees(c, lecturerID)
+}
+
+// listAdvisees mengirim satu halaman mahasiswa bimbingan beserta rekap prestasi dan poinnya
+func (s *lecturerService) listAdvisees(c *fiber.Ctx, lecturerID string) error {
+	q, err := parseListQuery(c)
+	if err != nil {
+		return listError(err)
+	}
+
+	advisees, meta, err := s.repo.GetAdviseesByLecturerID(c.Context(), lecturerID, q)
+	if err != nil {
+		return listError(err)
+	}
+
+	// Poin tersimpan di MongoDB: ambil sekali untuk seluruh halaman
+	var mongoIDs []string
+	for _, a := range advisees {
+		mongoIDs = append(mongoIDs, a.VerifiedMongoIDs...)
+	}
+	if len(mongoIDs) > 0 {
+		docs, err := s.achievementRepo.GetMongoDetailsByIDs(c.Context(), mongoIDs)
+		if err != nil {
+			return apperror.Internal(err)
+		}
+		for i := range advisees {
+			for _, id := range advisees[i].VerifiedMongoIDs {
+				advisees[i].Achievements.TotalPoints += docs[id].Points
+			}
+		}
+	}
+
+	message := i18n.Message(c, "messages.advisees_fetched")
+	if len(advisees) == 0 {
+		message = i18n.Message(c, "messages.advisees_empty")
 	}
 
-	students, err := s.repo.GetAdviseesByLecturerID(c.Context(), lecturerID)
-	
+	return c.JSON(fiber.Map{
+		"message": message,
+		"success": true,
+		"data":    advisees,
+		"meta":    meta,
+	})
+}
+
+// myLecturerProfile mengambil profil dosen milik user login
+func (s *lecturerService) myLecturerProfile(c *fiber.Ctx) (models.GetLecture, error) {
+	userID, err := helpers.GetUserIDFromContext(c)
 	if err != nil {
-		return c.Status(500).JSON(fiber.Map{
-			"message": "Terjadi kesalahan server saat mengambil data bimbingan",
-			"success": false,
-			"error":   err.Error(),
-		})
+		return models.GetLecture{}, err
+	}
+
+	lecturer, err := s.repo.GetLecturerByUserID(c.Context(), userID)
+	if err == sql.ErrNoRows {
+		return models.GetLecture{}, apperror.NotFound("lecturer_not_found").Variant("self")
+	} else if err != nil {
+		return models.GetLecture{}, apperror.Internal(err)
 	}
+	return lecturer, nil
+}
 
-	if len(students) == 0 {
-		return c.JSON(fiber.Map{
-			"message": "Dosen ini belum memiliki mahasiswa bimbingan",
-			"success": true,
-			"data":    []string{},
-		})
+// GetMyLecturerProfile godoc
+// @Summary      Profil Dosen Saya
+// @Description  Data dosen milik user yang sedang login (Dosen Wali).
+// @Tags         Lecturers
+// @Produce      json
+// @Security     Bearer
+// @Success      200  {object}  map[string]models.GetLecture
+// @Failure      401  {object}  apperror.Problem
+// @Failure      404  {object}  apperror.Problem "User bukan dosen"
+// @Router       /lecturers/me [get]
+func (s *lecturerService) GetMyLecturerProfile(c *fiber.Ctx) error {
+	lecturer, err := s.myLecturerProfile(c)
+	if err != nil {
+		return err
 	}
 
 	return c.JSON(fiber.Map{
-		"message": "Data mahasiswa bimbingan berhasil diambil",
+		"message": i18n.Message(c, "messages.lecturer_found"),
 		"success": true,
-		"data":    students,
+		"data":    lecturer,
 	})
-}
\ No newline at end of file
+}
+
+// GetMyAdvisees godoc
+// @Summary      Mahasiswa Bimbingan Saya
+// @Description  Sama seperti GET /lecturers/{id}/advisees untuk dosen wali yang sedang login. Parameter pencarian, filter, urutan, dan paginasi sama.
+// @Tags         Lecturers
+// @Produce      json
+// @Security     Bearer
+// @Success      200  {object}  map[string][]models.Advisee
+// @Failure      400  {object}  apperror.Problem
+// @Failure      404  {object}  apperror.Problem "User bukan dosen"
+// @Router       /lecturers/me/advisees [get]
+func (s *lecturerService) GetMyAdvisees(c *fiber.Ctx) error {
+	lecturer, err := s.myLecturerProfile(c)
+	if err != nil {
+		return err
+	}
+
+	return s.listAdvisees(c, lecturer.ID)
+}
+
+// GetAdvisorLoadReport godoc
+// @Summary      Laporan Beban Bimbingan
+// @Description  Menampilkan jumlah mahasiswa bimbingan aktif dan kapasitas setiap dosen wali, beserta rekap per department.
+// @Tags         Lecturers
+// @Produce      json
+// @Security     Bearer
+// @Success      200  {object}  models.AdvisorLoadReport
+// @Failure      500  {object}  apperror.Problem
+// @Router       /lecturers/load [get]
+func (s *lecturerService) GetAdvisorLoadReport(c *fiber.Ctx) error {
+	loads, err := s.repo.GetAdvisorLoads(c.Context(), nil, nil)
+	if err != nil {
+		return apperror.Internal(err)
+	}
+	applyDefaultCapacity(loads)
+
+	if loads == nil {
+		loads = []models.AdvisorLoad{}
+	}
+
+	return c.JSON(fiber.Map{
+		"message": i18n.Message(c, "messages.advisor_workload_fetched"),
+		"success": true,
+		"data": models.AdvisorLoadReport{
+			DefaultMaxAdvisees: defaultMaxAdvisees(),
+			Lecturers:          loads,
+			Departments:        summarizeDepartments(loads),
+		},
+	})
+}
+
+// UpdateLecturerCapacity godoc
+// @Summary      Atur Kapasitas Bimbingan Dosen
+// @Description  Mengatur jumlah maksimal mahasiswa bimbingan seorang dosen. max_advisees null mengembalikan ke default ADVISOR_MAX_ADVISEES. Bimbingan yang sudah ada tidak dipindahkan meskipun melebihi kapasitas baru.
+// @Tags         Lecturers
+// @Accept       json
+// @Produce      json
+// @Security     Bearer
+// @Param        id       path      string                                true  "Lecturer ID (UUID)"
+// @Param        request  body      models.UpdateLecturerCapacityRequest  true  "Kapasitas"
+// @Success      200      {object}  map[string]string
+// @Failure      400      {object}  apperror.Problem
+// @Failure      404      {object}  apperror.Problem
+// @Failure      422  {object}  apperror.Problem "Data tidak valid (errors berisi pesan per field)"
+// @Router       /lecturers/{id}/capacity [put]
+func (s *lecturerService) UpdateLecturerCapacity(c *fiber.Ctx) error {
+	lecturerID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return invalidID("Lecturer ID")
+	}
+
+	var req models.UpdateLecturerCapacityRequest
+	if err := bindBody(c, &req); err != nil {
+		return err
+	}
+
+	err = s.repo.SetMaxAdvisees(c.Context(), lecturerID, req.MaxAdvisees)
+	if err == sql.ErrNoRows {
+		return apperror.NotFound("lecturer_not_found")
+	} else if err != nil {
+		return apperror.Internal(err)
+	}
+
+	return c.JSON(fiber.Map{
+		"message": i18n.Message(c, "messages.advisor_capacity_updated"),
+		"success": true,
+	})
+}
@@ -0,0 +1,80 @@
+package services_test
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"uas/app/models"
+	"uas/app/services"
+	"uas/apperror"
+	"uas/mocks"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestGetMyAdvisees_SumsVerifiedPoints(t *testing.T) {
+	mockLecturerRepo := new(mocks.MockLecturerRepo)
+	mockAchRepo := new(mocks.MockAchievementRepo)
+	lecturerService := services.NewLecturerService(mockLecturerRepo, mockAchRepo)
+
+	userID, lecturerID := uuid.New(), uuid.New()
+	mockLecturerRepo.On("GetLecturerByUserID", mock.Anything, userID.String()).
+		Return(models.GetLecture{ID: lecturerID.String()}, nil)
+	mockLecturerRepo.On("GetAdviseesByLecturerID", mock.Anything, lecturerID.String(), mock.MatchedBy(func(q models.ListQuery) bool {
+		return q.Page == 2 && q.Limit == 10 && q.Filters["academic_status"] == "active"
+	})).Return([]models.Advisee{
+		{GetStudent: models.GetStudent{ID: "std-1"}, VerifiedMongoIDs: []string{"m-1", "m-2"},
+			Achievements: models.AchievementSummary{Total: 3, ByStatus: map[string]int64{"verified": 2, "draft": 1}}},
+		{GetStudent: models.GetStudent{ID: "std-2"}},
+	}, models.ListMeta{Total: 12, Page: 2, Limit: 10}, nil)
+	mockAchRepo.On("GetMongoDetailsByIDs", mock.Anything, []string{"m-1", "m-2"}).Return(map[string]models.AchievementMongo{
+		"m-1": {ID: primitive.NewObjectID(), Points: 20},
+		"m-2": {ID: primitive.NewObjectID(), Points: 15},
+	}, nil)
+
+	app := fiber.New(fiber.Config{ErrorHandler: apperror.Handler})
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("user_id", userID)
+		return c.Next()
+	})
+	app.Get("/lecturers/me/advisees", lecturerService.GetMyAdvisees)
+
+	resp, _ := app.Test(httptest.NewRequest("GET", "/lecturers/me/advisees?page=2&limit=10&academic_status=active", nil))
+	assert.Equal(t, 200, resp.StatusCode)
+
+	var body struct {
+		Data []models.Advisee `json:"data"`
+		Meta models.ListMeta  `json:"meta"`
+	}
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	assert.Len(t, body.Data, 2)
+	assert.Equal(t, 35, body.Data[0].Achievements.TotalPoints)
+	assert.Equal(t, int64(1), body.Data[0].Achievements.ByStatus["draft"])
+	assert.Equal(t, 0, body.Data[1].Achievements.TotalPoints)
+	assert.Equal(t, 12, body.Meta.Total)
+	mockLecturerRepo.AssertExpectations(t)
+	mockAchRepo.AssertExpectations(t)
+}
+
+func TestGetMyLecturerProfile_NotLecturer(t *testing.T) {
+	mockLecturerRepo := new(mocks.MockLecturerRepo)
+	lecturerService := services.NewLecturerService(mockLecturerRepo, nil)
+
+	userID := uuid.New()
+	mockLecturerRepo.On("GetLecturerByUserID", mock.Anything, userID.String()).Return(models.GetLecture{}, sql.ErrNoRows)
+
+	app := fiber.New(fiber.Config{ErrorHandler: apperror.Handler})
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("user_id", userID)
+		return c.Next()
+	})
+	app.Get("/lecturers/me", lecturerService.GetMyLecturerProfile)
+
+	resp, _ := app.Test(httptest.NewRequest("GET", "/lecturers/me", nil))
+	assert.Equal(t, 404, resp.StatusCode)
+}
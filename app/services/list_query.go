@@ -0,0 +1,89 @@
+package services
+
+import (
+	"strconv"
+	"strings"
+	"uas/app/models"
+	"uas/app/repository"
+	"uas/apperror"
+	"uas/i18n"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+)
+
+const (
+	defaultListLimit = 20
+	maxListLimit     = 100
+)
+
+// listParams adalah query param yang bukan filter
+var listParams = map[string]bool{"q": true, "sort": true, "order": true, "page": true, "limit": true, "cursor": true}
+
+// parseListQuery membaca parameter list yang sama untuk semua endpoint:
+// q (pencarian), sort (awalan "-" untuk menurun) / order, limit, page (offset) atau cursor (keyset).
+// Query param lain dianggap filter dan divalidasi oleh repository
+func parseListQuery(c *fiber.Ctx) (models.ListQuery, error) {
+	q := models.ListQuery{
+		Search:  strings.TrimSpace(utils.CopyString(c.Query("q"))),
+		Filters: map[string]string{},
+		Sort:    utils.CopyString(c.Query("sort")),
+		Limit:   defaultListLimit,
+		Page:    1,
+	}
+
+	if strings.HasPrefix(q.Sort, "-") {
+		q.Sort, q.Desc = q.Sort[1:], true
+	}
+	switch strings.ToLower(c.Query("order")) {
+	case "", "asc":
+	case "desc":
+		q.Desc = true
+	default:
+		return q, &repository.ListQueryError{Reason: "order"}
+	}
+
+	if raw := c.Query("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 || n > maxListLimit {
+			return q, &repository.ListQueryError{Reason: "limit", Params: i18n.Params{"max": maxListLimit}}
+		}
+		q.Limit = n
+	}
+
+	if raw := c.Query("page"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 {
+			return q, &repository.ListQueryError{Reason: "page"}
+		}
+		q.Page = n
+	}
+
+	// Parameter cursor (boleh kosong untuk halaman pertama) mengaktifkan mode cursor
+	if c.Context().QueryArgs().Has("cursor") {
+		q.Cursor = &models.ListCursor{}
+		if raw := c.Query("cursor"); raw != "" {
+			cursor, err := models.DecodeListCursor(raw)
+			if err != nil {
+				return q, &repository.ListQueryError{Reason: "cursor"}
+			}
+			q.Cursor = &cursor
+		}
+	}
+
+	c.Context().QueryArgs().VisitAll(func(key, value []byte) {
+		if name := string(key); !listParams[name] && len(value) > 0 {
+			q.Filters[name] = string(value)
+		}
+	})
+
+	return q, nil
+}
+
+// listError memetakan error list: parameter tidak valid jadi 400, selain itu 500
+func listError(err error) error {
+	if qerr, ok := err.(*repository.ListQueryError); ok {
+		return apperror.BadRequest("invalid_list_query").Variant(qerr.Reason).WithParams(qerr.Params)
+	}
+	return apperror.Internal(err)
+}
@@ -0,0 +1,123 @@
+package services_test
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+	"uas/app/models"
+	"uas/app/repository"
+	"uas/app/services"
+	"uas/apperror"
+	"uas/mocks"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type listBody struct {
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data"`
+	Meta    models.ListMeta `json:"meta"`
+}
+
+func TestGetAllUsers_ParsesListQuery(t *testing.T) {
+	mockUserRepo := new(mocks.MockUserRepo)
+	userService := services.NewUserService(nil, mockUserRepo, nil, nil, nil, nil)
+
+	app := fiber.New(fiber.Config{ErrorHandler: apperror.Handler})
+	app.Get("/users", userService.GetAllUsers)
+
+	mockUserRepo.On("GetAllUsers", mock.Anything, models.ListQuery{
+		Search:  "george",
+		Filters: map[string]string{"role": "Mahasiswa", "is_active": "true"},
+		Sort:    "created_at",
+		Desc:    true,
+		Limit:   5,
+		Page:    2,
+	}).Return([]models.User{{Username: "george_ganteng"}}, models.ListMeta{Total: 6, Limit: 5, Page: 2, TotalPages: 2}, nil)
+
+	resp, _ := app.Test(httptest.NewRequest("GET", "/users?q=george&role=Mahasiswa&is_active=true&sort=-created_at&limit=5&page=2", nil))
+	assert.Equal(t, 200, resp.StatusCode)
+
+	var body listBody
+	json.NewDecoder(resp.Body).Decode(&body)
+	assert.Equal(t, 6, body.Meta.Total)
+	assert.Equal(t, 2, body.Meta.TotalPages)
+
+	resp, _ = app.Test(httptest.NewRequest("GET", "/users?limit=500", nil))
+	assert.Equal(t, 400, resp.StatusCode)
+	mockUserRepo.AssertExpectations(t)
+}
+
+func TestGetStudents_CursorPagination(t *testing.T) {
+	db, mockDB, _ := sqlmock.New()
+	defer db.Close()
+
+	studentService := services.NewStudentService(db, repository.NewStudentRepository(db), nil, nil)
+	app := fiber.New(fiber.Config{ErrorHandler: apperror.Handler})
+	app.Get("/students", studentService.GetStudents)
+
+	columns := []string{"id", "user_id", "student_id", "program_study_id", "program_study", "academy_year", "full_name", "username", "email", "is_active", "photo_url", "role_name", "academic_status", "academic_status_since", "sort_key"}
+	since := time.Date(2025, 8, 1, 0, 0, 0, 0, time.UTC)
+	prodiID := uuid.NewString()
+	ids := []string{uuid.NewString(), uuid.NewString(), uuid.NewString()}
+
+	// Halaman pertama: limit 2, repository mengambil 3 baris untuk tahu masih ada halaman berikutnya
+	mockDB.ExpectQuery(`SELECT count\(1\)`).WithArgs("Mahasiswa", "Teknik Informatika").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+	mockDB.ExpectQuery(`ORDER BY s.student_id ASC, s.id ASC LIMIT 3`).WithArgs("Mahasiswa", "Teknik Informatika").
+		WillReturnRows(sqlmock.NewRows(columns).
+			AddRow(ids[0], uuid.NewString(), "2025001", prodiID, "Teknik Informatika", "2025", "Ani", "ani", "ani@kampus.ac.id", true, "", "Mahasiswa", "active", since, "2025001").
+			AddRow(ids[1], uuid.NewString(), "2025002", prodiID, "Teknik Informatika", "2025", "Budi", "budi", "budi@kampus.ac.id", true, "", "Mahasiswa", "active", since, "2025002").
+			AddRow(ids[2], uuid.NewString(), "2025003", prodiID, "Teknik Informatika", "2025", "Cici", "cici", "cici@kampus.ac.id", true, "", "Mahasiswa", "active", since, "2025003"))
+
+	resp, _ := app.Test(httptest.NewRequest("GET", "/students?program_study=Teknik+Informatika&sort=nim&limit=2&cursor=", nil))
+	assert.Equal(t, 200, resp.StatusCode)
+
+	var body listBody
+	json.NewDecoder(resp.Body).Decode(&body)
+	var students []models.GetStudent
+	json.Unmarshal(body.Data, &students)
+	assert.Len(t, students, 2)
+	assert.True(t, body.Meta.HasMore)
+	assert.Equal(t, 3, body.Meta.Total)
+	assert.Zero(t, body.Meta.Page)
+
+	cursor, err := models.DecodeListCursor(body.Meta.NextCursor)
+	assert.NoError(t, err)
+	assert.Equal(t, models.ListCursor{Sort: "nim", Value: "2025002", ID: ids[1]}, cursor)
+
+	// Halaman kedua melanjutkan setelah baris terakhir halaman pertama
+	mockDB.ExpectQuery(`SELECT count\(1\)`).WithArgs("Mahasiswa", "Teknik Informatika").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+	mockDB.ExpectQuery(`\(s.student_id, s.id\) > \(\$3::text, \$4::uuid\)`).WithArgs("Mahasiswa", "Teknik Informatika", "2025002", ids[1]).
+		WillReturnRows(sqlmock.NewRows(columns).
+			AddRow(ids[2], uuid.NewString(), "2025003", prodiID, "Teknik Informatika", "2025", "Cici", "cici", "cici@kampus.ac.id", true, "", "Mahasiswa", "active", since, "2025003"))
+
+	resp, _ = app.Test(httptest.NewRequest("GET", "/students?program_study=Teknik+Informatika&sort=nim&limit=2&cursor="+url.QueryEscape(body.Meta.NextCursor), nil))
+	assert.Equal(t, 200, resp.StatusCode)
+
+	body = listBody{}
+	json.NewDecoder(resp.Body).Decode(&body)
+	assert.False(t, body.Meta.HasMore)
+	assert.Empty(t, body.Meta.NextCursor)
+	assert.NoError(t, mockDB.ExpectationsWereMet())
+
+	// Cursor dari urutan lain ditolak
+	resp, _ = app.Test(httptest.NewRequest("GET", "/students?program_study=Teknik+Informatika&limit=2&cursor="+url.QueryEscape(body.Meta.NextCursor+cursor.Encode()), nil))
+	assert.Equal(t, 400, resp.StatusCode)
+}
+
+func TestGetStudents_UnsupportedFilter(t *testing.T) {
+	studentService := services.NewStudentService(nil, repository.NewStudentRepository(nil), nil, nil)
+	app := fiber.New(fiber.Config{ErrorHandler: apperror.Handler})
+	app.Get("/students", studentService.GetStudents)
+
+	resp, _ := app.Test(httptest.NewRequest("GET", "/students?department=Informatika", nil))
+	assert.Equal(t, 400, resp.StatusCode)
+}
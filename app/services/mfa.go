@@ -0,0 +1,385 @@
+package services
+
+import (
+	"database/sql"
+	"os"
+	"strings"
+	"time"
+	"uas/app/models"
+	"uas/apperror"
+	"uas/i18n"
+	"uas/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+const (
+	mfaIssuer         = "Sistem Prestasi Mahasiswa"
+	recoveryCodeCount = 10
+)
+
+// isMFARequiredForRole membaca daftar role wajib MFA dari env MFA_REQUIRED_ROLES (dipisah koma)
+func isMFARequiredForRole(roleName string) bool {
+	for _, role := range strings.Split(os.Getenv("MFA_REQUIRED_ROLES"), ",") {
+		if strings.EqualFold(strings.TrimSpace(role), roleName) && roleName != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// respondMFAChallenge mengirim token challenge MFA sebagai pengganti token login (dipakai juga oleh SSO)
+func respondMFAChallenge(c *fiber.Ctx, user models.User, purpose string) error {
+	mfaToken, err := utils.GenerateMFAToken(user, purpose)
+	if err != nil {
+		return apperror.Internal(err)
+	}
+
+	status := "mfa_required"
+	if purpose == models.MFAPurposeEnroll {
+		status = "mfa_enrollment_required"
+	}
+
+	return c.Status(200).JSON(fiber.Map{
+		"status": status,
+		"data": models.MFAChallengeResponse{
+			MFAToken:  mfaToken,
+			Purpose:   purpose,
+			ExpiresIn: int(utils.MFATokenTTL.Seconds()),
+		},
+	})
+}
+
+// newMFASecret membuat secret baru dan menyimpannya dalam keadaan belum aktif
+func (s *authService) newMFASecret(c *fiber.Ctx, userID uuid.UUID, account string) error {
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		return apperror.Internal(err)
+	}
+
+	if err := s.mfaRepo.SaveMFASecret(c.Context(), userID, secret); err != nil {
+		return apperror.Internal(err)
+	}
+
+	return c.JSON(fiber.Map{
+		"status": "success",
+		"data": models.MFASetupResponse{
+			Secret:          secret,
+			ProvisioningURI: utils.TOTPProvisioningURI(mfaIssuer, account, secret),
+		},
+	})
+}
+
+// enableMFA memvalidasi kode pertama dari authenticator lalu mengaktifkan MFA beserta kode pemulihan baru
+func (s *authService) enableMFA(c *fiber.Ctx, userID uuid.UUID, code string) ([]string, error) {
+	mfa, err := s.mfaRepo.GetMFAByUserID(c.Context(), userID)
+	if err == sql.ErrNoRows {
+		return nil, apperror.BadRequest("mfa_not_setup")
+	} else if err != nil {
+		return nil, apperror.Internal(err)
+	}
+
+	if mfa.IsEnabled {
+		return nil, apperror.Conflict("mfa_already_enabled")
+	}
+
+	if err := s.useTOTPCode(c, userID, mfa.TOTPSecret, code); err != nil {
+		return nil, err
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, apperror.Internal(err)
+	}
+
+	if err := s.mfaRepo.EnableMFA(c.Context(), userID, hashes); err != nil {
+		return nil, apperror.Internal(err)
+	}
+
+	return codes, nil
+}
+
+// verifyMFACode mengecek kode TOTP atau kode pemulihan milik user yang MFA-nya aktif
+func (s *authService) verifyMFACode(c *fiber.Ctx, userID uuid.UUID, code string, recoveryCode string) error {
+	mfa, err := s.mfaRepo.GetMFAByUserID(c.Context(), userID)
+	if err == sql.ErrNoRows || (err == nil && !mfa.IsEnabled) {
+		return apperror.BadRequest("mfa_not_enabled")
+	} else if err != nil {
+		return apperror.Internal(err)
+	}
+
+	if recoveryCode != "" {
+		used, err := s.mfaRepo.UseRecoveryCode(c.Context(), userID, utils.HashToken(utils.NormalizeRecoveryCode(recoveryCode)))
+		if err != nil {
+			return apperror.Internal(err)
+		}
+		if !used {
+			return apperror.Unauthorized("recovery_code_invalid")
+		}
+		return nil
+	}
+
+	return s.useTOTPCode(c, userID, mfa.TOTPSecret, code)
+}
+
+// useTOTPCode menerima kode TOTP hanya sekali: time step yang sama atau lebih lama dari kode terakhir ditolak
+func (s *authService) useTOTPCode(c *fiber.Ctx, userID uuid.UUID, secret string, code string) error {
+	step, ok := utils.MatchTOTPCode(secret, code, time.Now())
+	if !ok {
+		return apperror.Unauthorized("mfa_code_invalid")
+	}
+
+	fresh, err := s.mfaRepo.UseTOTPStep(c.Context(), userID, step)
+	if err != nil {
+		return apperror.Internal(err)
+	}
+	if !fresh {
+		return apperror.Unauthorized("mfa_code_invalid")
+	}
+	return nil
+}
+
+func newRecoveryCodes() ([]string, []string, error) {
+	codes, err := utils.GenerateRecoveryCodes(recoveryCodeCount)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	hashes := make([]string, len(codes))
+	for i, code := range codes {
+		hashes[i] = utils.HashToken(code)
+	}
+	return codes, hashes, nil
+}
+
+// EnrollMFA godoc
+// @Summary      Mulai Enrollment MFA (Saat Login)
+// @Description  Untuk role yang wajib MFA. Menukar mfaToken (purpose 'enroll') dengan secret TOTP dan URI QR code.
+// @Tags         Auth
+// @Accept       json
+// @Produce      json
+// @Param        request body models.MFAEnrollRequest true "Token challenge dari /auth/login"
+// @Success      200  {object} models.MFASetupResponse
+// @Failure      400  {object} apperror.Problem
+// @Failure      401  {object} apperror.Problem
+// @Failure      422  {object}  apperror.Problem "Data tidak valid (errors berisi pesan per field)"
+// @Router       /auth/mfa/enroll [post]
+func (s *authService) EnrollMFA(c *fiber.Ctx) error {
+	var req models.MFAEnrollRequest
+	if err := bindBody(c, &req); err != nil {
+		return err
+	}
+
+	userID, purpose, err := utils.ValidateMFAToken(req.MFAToken)
+	if err != nil || purpose != models.MFAPurposeEnroll {
+		return apperror.Unauthorized("mfa_token_invalid")
+	}
+
+	user, err := s.userRepo.GetUserByID(c.Context(), userID)
+	if err != nil {
+		return apperror.Unauthorized("user_not_found")
+	}
+
+	return s.newMFASecret(c, user.ID, user.Username)
+}
+
+// VerifyMFA godoc
+// @Summary      Verifikasi MFA (Tahap Kedua Login)
+// @Description  Menukar mfaToken + kode TOTP (atau kode pemulihan) dengan Access Token dan Refresh Token. Untuk purpose 'enroll', kode pertama sekaligus mengaktifkan MFA dan mengembalikan kode pemulihan.
+// @Tags         Auth
+// @Accept       json
+// @Produce      json
+// @Param        request body models.MFAVerifyRequest true "Token challenge dan kode"
+// @Success      200  {object} models.LoginResponse
+// @Failure      400  {object} apperror.Problem
+// @Failure      401  {object} apperror.Problem
+// @Failure      422  {object}  apperror.Problem "Data tidak valid (errors berisi pesan per field)"
+// @Router       /auth/mfa/verify [post]
+func (s *authService) VerifyMFA(c *fiber.Ctx) error {
+	var req models.MFAVerifyRequest
+	if err := bindBody(c, &req); err != nil {
+		return err
+	}
+
+	userID, purpose, err := utils.ValidateMFAToken(req.MFAToken)
+	if err != nil {
+		return apperror.Unauthorized("mfa_token_invalid")
+	}
+
+	user, err := s.userRepo.GetUserByID(c.Context(), userID)
+	if err != nil {
+		return apperror.Unauthorized("user_not_found")
+	}
+
+	if !user.IsActive {
+		return apperror.Forbidden("account_inactive")
+	}
+
+	switch purpose {
+	case models.MFAPurposeEnroll:
+		codes, err := s.enableMFA(c, user.ID, req.Code)
+		if err != nil {
+			return err
+		}
+		return s.loginSuccess(c, user, codes)
+
+	case models.MFAPurposeLogin:
+		if err := s.verifyMFACode(c, user.ID, req.Code, req.RecoveryCode); err != nil {
+			return err
+		}
+		return s.loginSuccess(c, user, nil)
+	}
+
+	return apperror.Unauthorized("mfa_token_invalid")
+}
+
+// SetupMFA godoc
+// @Summary      Setup MFA (Opsional)
+// @Description  Membuat secret TOTP baru untuk user yang sedang login. MFA baru aktif setelah dikonfirmasi lewat /auth/mfa/activate.
+// @Tags         Auth
+// @Accept       json
+// @Produce      json
+// @Security     Bearer
+// @Success      200  {object} models.MFASetupResponse
+// @Failure      401  {object} apperror.Problem
+// @Failure      409  {object} apperror.Problem "MFA sudah aktif"
+// @Router       /auth/mfa/setup [post]
+func (s *authService) SetupMFA(c *fiber.Ctx) error {
+	userID, ok := c.Locals("user_id").(uuid.UUID)
+	if !ok {
+		return apperror.Unauthorized("unauthorized")
+	}
+
+	mfa, err := s.mfaRepo.GetMFAByUserID(c.Context(), userID)
+	if err != nil && err != sql.ErrNoRows {
+		return apperror.Internal(err)
+	}
+	if err == nil && mfa.IsEnabled {
+		return apperror.Conflict("mfa_already_enabled").Variant("setup")
+	}
+
+	username, _ := c.Locals("username").(string)
+	return s.newMFASecret(c, userID, username)
+}
+
+// ActivateMFA godoc
+// @Summary      Aktifkan MFA
+// @Description  Mengonfirmasi secret dari /auth/mfa/setup dengan kode TOTP pertama. Mengembalikan kode pemulihan (hanya ditampilkan sekali).
+// @Tags         Auth
+// @Accept       json
+// @Produce      json
+// @Security     Bearer
+// @Param        request body models.MFACodeRequest true "Kode TOTP"
+// @Success      200  {object} map[string]interface{}
+// @Failure      400  {object} apperror.Problem
+// @Failure      401  {object} apperror.Problem
+// @Failure      422  {object}  apperror.Problem "Data tidak valid (errors berisi pesan per field)"
+// @Router       /auth/mfa/activate [post]
+func (s *authService) ActivateMFA(c *fiber.Ctx) error {
+	userID, ok := c.Locals("user_id").(uuid.UUID)
+	if !ok {
+		return apperror.Unauthorized("unauthorized")
+	}
+
+	var req models.MFACodeRequest
+	if err := bindBody(c, &req); err != nil {
+		return err
+	}
+
+	codes, err := s.enableMFA(c, userID, req.Code)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(fiber.Map{
+		"status": "success",
+		"data": fiber.Map{
+			"recovery_codes": codes,
+		},
+	})
+}
+
+// DisableMFA godoc
+// @Summary      Nonaktifkan MFA
+// @Description  Menonaktifkan MFA setelah verifikasi kode. Tidak diizinkan untuk role yang wajib MFA.
+// @Tags         Auth
+// @Accept       json
+// @Produce      json
+// @Security     Bearer
+// @Param        request body models.MFAVerifyRequest true "Kode TOTP atau kode pemulihan"
+// @Success      200  {object} map[string]string
+// @Failure      401  {object} apperror.Problem
+// @Failure      403  {object} apperror.Problem
+// @Failure      422  {object}  apperror.Problem "Data tidak valid (errors berisi pesan per field)"
+// @Router       /auth/mfa/disable [post]
+func (s *authService) DisableMFA(c *fiber.Ctx) error {
+	userID, ok := c.Locals("user_id").(uuid.UUID)
+	if !ok {
+		return apperror.Unauthorized("unauthorized")
+	}
+
+	role, _ := c.Locals("role_name").(string)
+	if isMFARequiredForRole(role) {
+		return apperror.Forbidden("mfa_required").WithParams(i18n.Params{"role": role})
+	}
+
+	var req models.MFAVerifyRequest
+	if err := bindBody(c, &req); err != nil {
+		return err
+	}
+
+	if err := s.verifyMFACode(c, userID, req.Code, req.RecoveryCode); err != nil {
+		return err
+	}
+
+	if err := s.mfaRepo.DisableMFA(c.Context(), userID); err != nil {
+		return apperror.Internal(err)
+	}
+
+	return c.JSON(fiber.Map{"status": "success", "message": i18n.Message(c, "messages.mfa_disabled")})
+}
+
+// RegenerateRecoveryCodes godoc
+// @Summary      Buat Ulang Kode Pemulihan
+// @Description  Mengganti seluruh kode pemulihan lama dengan yang baru setelah verifikasi kode TOTP.
+// @Tags         Auth
+// @Accept       json
+// @Produce      json
+// @Security     Bearer
+// @Param        request body models.MFACodeRequest true "Kode TOTP"
+// @Success      200  {object} map[string]interface{}
+// @Failure      401  {object} apperror.Problem
+// @Failure      422  {object}  apperror.Problem "Data tidak valid (errors berisi pesan per field)"
+// @Router       /auth/mfa/recovery-codes [post]
+func (s *authService) RegenerateRecoveryCodes(c *fiber.Ctx) error {
+	userID, ok := c.Locals("user_id").(uuid.UUID)
+	if !ok {
+		return apperror.Unauthorized("unauthorized")
+	}
+
+	var req models.MFACodeRequest
+	if err := bindBody(c, &req); err != nil {
+		return err
+	}
+
+	if err := s.verifyMFACode(c, userID, req.Code, ""); err != nil {
+		return err
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return apperror.Internal(err)
+	}
+
+	if err := s.mfaRepo.ReplaceRecoveryCodes(c.Context(), userID, hashes); err != nil {
+		return apperror.Internal(err)
+	}
+
+	return c.JSON(fiber.Map{
+		"status": "success",
+		"data": fiber.Map{
+			"recovery_codes": codes,
+		},
+	})
+}
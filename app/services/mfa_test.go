@@ -0,0 +1,199 @@
+package services_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"uas/app/models"
+	"uas/app/services"
+	"uas/apperror"
+	"uas/mocks"
+	"uas/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestTOTP_RFC6238Vector(t *testing.T) {
+	// Secret "12345678901234567890" (ASCII) dari RFC 6238 Appendix B, dipotong 6 digit
+	secret := "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+	code, err := utils.GenerateTOTPCode(secret, time.Unix(59, 0))
+	assert.NoError(t, err)
+	assert.Equal(t, "287082", code)
+
+	code, _ = utils.GenerateTOTPCode(secret, time.Unix(1111111109, 0))
+	assert.Equal(t, "081804", code)
+
+	assert.True(t, utils.ValidateTOTPCode(secret, "081804", time.Unix(1111111109+30, 0)))
+	assert.False(t, utils.ValidateTOTPCode(secret, "081804", time.Unix(1111111109+120, 0)))
+}
+
+func TestLogin_MFAEnabled_ReturnsChallenge(t *testing.T) {
+	mockRepo := new(mocks.MockUserRepo)
+	mockMFARepo := new(mocks.MockMFARepo)
+	authService := services.NewAuthService(mockRepo, mockMFARepo, nil, nil, nil)
+	app := fiber.New(fiber.Config{ErrorHandler: apperror.Handler})
+	app.Post("/login", authService.Login)
+
+	dummyUser := models.User{
+		ID:           uuid.New(),
+		Username:     "george_admin",
+		PasswordHash: hashPassword("123456"),
+		RoleName:     "Admin",
+		IsActive:     true,
+	}
+
+	mockRepo.On("GetByUsernameOrEmail", mock.Anything, "george_admin").Return(dummyUser, nil)
+	mockMFARepo.On("GetMFAByUserID", mock.Anything, dummyUser.ID).Return(models.UserMFA{
+		UserID: dummyUser.ID, TOTPSecret: "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ", IsEnabled: true,
+	}, nil)
+
+	body, _ := json.Marshal(map[string]string{"username": "george_admin", "password": "123456"})
+	req := httptest.NewRequest("POST", "/login", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, _ := app.Test(req)
+	assert.Equal(t, 200, resp.StatusCode)
+
+	var responseBody map[string]interface{}
+	json.NewDecoder(resp.Body).Decode(&responseBody)
+
+	assert.Equal(t, "mfa_required", responseBody["status"])
+	data := responseBody["data"].(map[string]interface{})
+	assert.NotEmpty(t, data["mfaToken"])
+	assert.Nil(t, data["token"])
+}
+
+func TestVerifyMFA_ValidCode_IssuesTokens(t *testing.T) {
+	mockRepo := new(mocks.MockUserRepo)
+	mockMFARepo := new(mocks.MockMFARepo)
+	authService := services.NewAuthService(mockRepo, mockMFARepo, nil, nil, nil)
+	app := fiber.New(fiber.Config{ErrorHandler: apperror.Handler})
+	app.Post("/mfa/verify", authService.VerifyMFA)
+
+	secret := "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+	dummyUser := models.User{ID: uuid.New(), Username: "george_admin", RoleName: "Admin", IsActive: true}
+
+	mockRepo.On("GetUserByID", mock.Anything, dummyUser.ID).Return(dummyUser, nil)
+	mockMFARepo.On("GetMFAByUserID", mock.Anything, dummyUser.ID).Return(models.UserMFA{
+		UserID: dummyUser.ID, TOTPSecret: secret, IsEnabled: true,
+	}, nil)
+
+	mockMFARepo.On("UseTOTPStep", mock.Anything, dummyUser.ID, mock.AnythingOfType("int64")).Return(true, nil)
+
+	mfaToken, _ := utils.GenerateMFAToken(dummyUser, models.MFAPurposeLogin)
+	code, _ := utils.GenerateTOTPCode(secret, time.Now())
+
+	body, _ := json.Marshal(models.MFAVerifyRequest{MFAToken: mfaToken, Code: code})
+	req := httptest.NewRequest("POST", "/mfa/verify", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, _ := app.Test(req)
+	assert.Equal(t, 200, resp.StatusCode)
+
+	var responseBody map[string]interface{}
+	json.NewDecoder(resp.Body).Decode(&responseBody)
+	data := responseBody["data"].(map[string]interface{})
+	assert.NotEmpty(t, data["token"])
+	assert.NotEmpty(t, data["refreshToken"])
+}
+
+func TestVerifyMFA_ReplayedCode(t *testing.T) {
+	mockRepo := new(mocks.MockUserRepo)
+	mockMFARepo := new(mocks.MockMFARepo)
+	authService := services.NewAuthService(mockRepo, mockMFARepo, nil, nil, nil)
+	app := fiber.New(fiber.Config{ErrorHandler: apperror.Handler})
+	app.Post("/mfa/verify", authService.VerifyMFA)
+
+	secret := "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+	dummyUser := models.User{ID: uuid.New(), Username: "george_admin", RoleName: "Admin", IsActive: true}
+
+	mockRepo.On("GetUserByID", mock.Anything, dummyUser.ID).Return(dummyUser, nil)
+	mockMFARepo.On("GetMFAByUserID", mock.Anything, dummyUser.ID).Return(models.UserMFA{
+		UserID: dummyUser.ID, TOTPSecret: secret, IsEnabled: true,
+	}, nil)
+	// step kode ini sudah pernah diterima
+	mockMFARepo.On("UseTOTPStep", mock.Anything, dummyUser.ID, mock.AnythingOfType("int64")).Return(false, nil)
+
+	mfaToken, _ := utils.GenerateMFAToken(dummyUser, models.MFAPurposeLogin)
+	code, _ := utils.GenerateTOTPCode(secret, time.Now())
+
+	body, _ := json.Marshal(models.MFAVerifyRequest{MFAToken: mfaToken, Code: code})
+	req := httptest.NewRequest("POST", "/mfa/verify", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, _ := app.Test(req)
+	assert.Equal(t, 401, resp.StatusCode)
+}
+
+func TestValidateToken_RejectsNonAccessTokens(t *testing.T) {
+	user := models.User{ID: uuid.New(), Username: "george_admin", RoleName: "Admin"}
+
+	mfaToken, _ := utils.GenerateMFAToken(user, models.MFAPurposeLogin)
+	_, err := utils.ValidateToken(mfaToken)
+	assert.Error(t, err)
+
+	refreshToken, _ := utils.GenerateRefreshToken(user, uuid.New())
+	_, err = utils.ValidateToken(refreshToken)
+	assert.Error(t, err)
+
+	accessToken, _ := utils.GenerateToken(user, nil)
+	claims, err := utils.ValidateToken(accessToken)
+	assert.NoError(t, err)
+	assert.Equal(t, user.ID, claims.UserID)
+}
+
+func TestVerifyMFA_WrongCode(t *testing.T) {
+	mockRepo := new(mocks.MockUserRepo)
+	mockMFARepo := new(mocks.MockMFARepo)
+	authService := services.NewAuthService(mockRepo, mockMFARepo, nil, nil, nil)
+	app := fiber.New(fiber.Config{ErrorHandler: apperror.Handler})
+	app.Post("/mfa/verify", authService.VerifyMFA)
+
+	dummyUser := models.User{ID: uuid.New(), Username: "george_admin", RoleName: "Admin", IsActive: true}
+
+	mockRepo.On("GetUserByID", mock.Anything, dummyUser.ID).Return(dummyUser, nil)
+	mockMFARepo.On("GetMFAByUserID", mock.Anything, dummyUser.ID).Return(models.UserMFA{
+		UserID: dummyUser.ID, TOTPSecret: "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ", IsEnabled: true,
+	}, nil)
+
+	mfaToken, _ := utils.GenerateMFAToken(dummyUser, models.MFAPurposeLogin)
+
+	body, _ := json.Marshal(models.MFAVerifyRequest{MFAToken: mfaToken, Code: "000000"})
+	req := httptest.NewRequest("POST", "/mfa/verify", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, _ := app.Test(req)
+	assert.Equal(t, 401, resp.StatusCode)
+}
+
+func TestVerifyMFA_RecoveryCode(t *testing.T) {
+	mockRepo := new(mocks.MockUserRepo)
+	mockMFARepo := new(mocks.MockMFARepo)
+	authService := services.NewAuthService(mockRepo, mockMFARepo, nil, nil, nil)
+	app := fiber.New(fiber.Config{ErrorHandler: apperror.Handler})
+	app.Post("/mfa/verify", authService.VerifyMFA)
+
+	dummyUser := models.User{ID: uuid.New(), Username: "george_dosen", RoleName: "Dosen Wali", IsActive: true}
+
+	mockRepo.On("GetUserByID", mock.Anything, dummyUser.ID).Return(dummyUser, nil)
+	mockMFARepo.On("GetMFAByUserID", mock.Anything, dummyUser.ID).Return(models.UserMFA{
+		UserID: dummyUser.ID, TOTPSecret: "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ", IsEnabled: true,
+	}, nil)
+	mockMFARepo.On("UseRecoveryCode", mock.Anything, dummyUser.ID, utils.HashToken("abcde-12345")).Return(true, nil)
+
+	mfaToken, _ := utils.GenerateMFAToken(dummyUser, models.MFAPurposeLogin)
+
+	body, _ := json.Marshal(models.MFAVerifyRequest{MFAToken: mfaToken, RecoveryCode: " ABCDE-12345 "})
+	req := httptest.NewRequest("POST", "/mfa/verify", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, _ := app.Test(req)
+	assert.Equal(t, 200, resp.StatusCode)
+	mockMFARepo.AssertExpectations(t)
+}
@@ -0,0 +1,438 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+	"uas/app/models"
+	"uas/app/repository"
+	"uas/apperror"
+	"uas/helpers"
+	"uas/i18n"
+	"uas/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+const oidcFlowCookie = "oidc_flow"
+
+type OIDCService interface {
+	Login(c *fiber.Ctx) error
+	Callback(c *fiber.Ctx) error
+}
+
+// OIDCOptions mengatur pencocokan akun dan provisioning just-in-time untuk login SSO
+type OIDCOptions struct {
+	JITProvisioning bool
+	NIMClaim        string
+	GroupsClaim     string
+	GroupRoles      map[string]string // grup di IdP -> nama role
+	SecureCookie    bool
+
+	// TrustIdPMFA melewati MFA lokal hanya jika id_token menunjukkan IdP sudah melakukan MFA
+	// (claim amr berisi salah satu MFAAmr, atau acr termasuk MFAAcr)
+	TrustIdPMFA bool
+	MFAAmr      []string
+	MFAAcr      []string
+}
+
+// OIDCOptionsFromEnv membaca OIDC_JIT_PROVISIONING, OIDC_NIM_CLAIM, OIDC_GROUPS_CLAIM,
+// OIDC_GROUP_ROLES (format "grup=Role,grup2=Role2"; pemetaan ke Admin diabaikan), serta
+// OIDC_TRUST_IDP_MFA, OIDC_MFA_AMR (default "mfa,otp"), dan OIDC_MFA_ACR.
+func OIDCOptionsFromEnv() OIDCOptions {
+	amr := os.Getenv("OIDC_MFA_AMR")
+	if amr == "" {
+		amr = "mfa,otp"
+	}
+
+	opts := OIDCOptions{
+		JITProvisioning: os.Getenv("OIDC_JIT_PROVISIONING") == "true",
+		NIMClaim:        os.Getenv("OIDC_NIM_CLAIM"),
+		GroupsClaim:     os.Getenv("OIDC_GROUPS_CLAIM"),
+		GroupRoles:      make(map[string]string),
+		SecureCookie:    strings.HasPrefix(os.Getenv("OIDC_REDIRECT_URL"), "https://"),
+		TrustIdPMFA:     os.Getenv("OIDC_TRUST_IDP_MFA") == "true",
+		MFAAmr:          splitList(amr),
+		MFAAcr:          splitList(os.Getenv("OIDC_MFA_ACR")),
+	}
+
+	for _, pair := range strings.Split(os.Getenv("OIDC_GROUP_ROLES"), ",") {
+		group, role, ok := strings.Cut(pair, "=")
+		group, role = strings.TrimSpace(group), strings.TrimSpace(role)
+		if !ok || group == "" || role == "" {
+			continue
+		}
+		if role == models.RoleAdmin {
+			log.Printf("OIDC_GROUP_ROLES: pemetaan grup %q ke Admin diabaikan", group)
+			continue
+		}
+		opts.GroupRoles[group] = role
+	}
+
+	return opts
+}
+
+type oidcService struct {
+	db           *sql.DB
+	provider     *utils.OIDCProvider
+	userRepo     repository.UserRepository
+	studentRepo  repository.StudentRepository
+	lecturerRepo repository.LecturerRepository
+	roleRepo     repository.RoleRepository
+	sessionRepo  repository.SessionRepository
+	mfaRepo      repository.MFARepository
+	permissions  helpers.PermissionResolver
+	opts         OIDCOptions
+}
+
+func NewOIDCService(
+	db *sql.DB,
+	provider *utils.OIDCProvider,
+	userRepo repository.UserRepository,
+	studentRepo repository.StudentRepository,
+	lecturerRepo repository.LecturerRepository,
+	roleRepo repository.RoleRepository,
+	sessionRepo repository.SessionRepository,
+	mfaRepo repository.MFARepository,
+	permissions helpers.PermissionResolver,
+	opts OIDCOptions,
+) OIDCService {
+	if opts.NIMClaim == "" {
+		opts.NIMClaim = "nim"
+	}
+	if opts.GroupsClaim == "" {
+		opts.GroupsClaim = "groups"
+	}
+
+	return &oidcService{
+		db:           db,
+		provider:     provider,
+		userRepo:     userRepo,
+		studentRepo:  studentRepo,
+		lecturerRepo: lecturerRepo,
+		roleRepo:     roleRepo,
+		sessionRepo:  sessionRepo,
+		mfaRepo:      mfaRepo,
+		permissions:  permissions,
+		opts:         opts,
+	}
+}
+
+// Login godoc
+// @Summary      Login SSO (OpenID Connect)
+// @Description  Mengarahkan browser ke identity provider kampus (authorization code + PKCE). State, nonce, dan code verifier disimpan di cookie bertanda tangan.
+// @Tags         Auth
+// @Success      302
+// @Failure      502  {object}  apperror.Problem
+// @Router       /auth/oidc/login [get]
+func (s *oidcService) Login(c *fiber.Ctx) error {
+	state, err1 := utils.RandomURLToken(32)
+	nonce, err2 := utils.RandomURLToken(32)
+	verifier, err3 := utils.RandomURLToken(48)
+	if err1 != nil || err2 != nil || err3 != nil {
+		return apperror.Internal(err3)
+	}
+
+	authURL, err := s.provider.AuthCodeURL(c.Context(), state, nonce, verifier)
+	if err != nil {
+		log.Println("OIDC:", err)
+		return apperror.Upstream("idp_unreachable", err)
+	}
+
+	flowToken, err := utils.GenerateOIDCFlowToken(state, nonce, verifier)
+	if err != nil {
+		return apperror.Internal(err)
+	}
+
+	c.Cookie(&fiber.Cookie{
+		Name:     oidcFlowCookie,
+		Value:    flowToken,
+		Expires:  time.Now().Add(utils.OIDCFlowTTL),
+		HTTPOnly: true,
+		Secure:   s.opts.SecureCookie,
+		SameSite: fiber.CookieSameSiteLaxMode,
+	})
+
+	return c.Redirect(authURL, fiber.StatusFound)
+}
+
+// Callback godoc
+// @Summary      Callback SSO (OpenID Connect)
+// @Description  Menukar authorization code, memverifikasi id_token, mencocokkan akun lewat email atau NIM (atau membuat akun baru jika provisioning aktif), lalu menerbitkan Access Token dan Refresh Token. Seperti login password, user dengan MFA aktif atau role wajib MFA mendapat challenge MFA (selesaikan lewat /auth/mfa/verify), kecuali OIDC_TRUST_IDP_MFA aktif dan id_token menunjukkan MFA di IdP
+// @Tags         Auth
+// @Produce      json
+// @Param        code   query  string  true  "Authorization code dari IdP"
+// @Param        state  query  string  true  "State dari langkah login"
+// @Success      200  {object}  models.LoginResponse "Token langsung, atau models.MFAChallengeResponse jika MFA aktif/wajib"
+// @Failure      400  {object}  apperror.Problem
+// @Failure      401  {object}  apperror.Problem
+// @Failure      403  {object}  apperror.Problem
+// @Router       /auth/oidc/callback [get]
+func (s *oidcService) Callback(c *fiber.Ctx) error {
+	// Cookie hanya berlaku untuk satu kali callback
+	flowToken := c.Cookies(oidcFlowCookie)
+	c.ClearCookie(oidcFlowCookie)
+
+	if idpError := c.Query("error"); idpError != "" {
+		return apperror.Unauthorized("sso_cancelled").WithParams(i18n.Params{"error": idpError})
+	}
+
+	code, state := c.Query("code"), c.Query("state")
+	if code == "" || state == "" {
+		return apperror.BadRequest("sso_missing_params")
+	}
+
+	nonce, verifier, err := utils.ValidateOIDCFlowToken(flowToken, state)
+	if err != nil {
+		return apperror.BadRequest("sso_state_invalid")
+	}
+
+	rawIDToken, err := s.provider.Exchange(c.Context(), code, verifier)
+	if err != nil {
+		log.Println("OIDC:", err)
+		return apperror.Unauthorized("sso_exchange_failed")
+	}
+
+	claims, err := s.provider.VerifyIDToken(c.Context(), rawIDToken, nonce)
+	if err != nil {
+		log.Println("OIDC:", err)
+		return apperror.Unauthorized("sso_id_token_invalid")
+	}
+
+	user, err := s.resolveUser(c.Context(), claims)
+	if err != nil {
+		return err
+	}
+
+	if !user.IsActive {
+		return apperror.Forbidden("account_inactive")
+	}
+
+	// Tahap kedua sama dengan login password, kecuali IdP terpercaya sudah melakukan MFA
+	if !s.idpPerformedMFA(claims) {
+		mfa, err := s.mfaRepo.GetMFAByUserID(c.Context(), user.ID)
+		if err != nil && err != sql.ErrNoRows {
+			return apperror.Internal(err)
+		}
+
+		if err == nil && mfa.IsEnabled {
+			return respondMFAChallenge(c, user, models.MFAPurposeLogin)
+		}
+
+		if isMFARequiredForRole(user.RoleName) {
+			return respondMFAChallenge(c, user, models.MFAPurposeEnroll)
+		}
+	}
+
+	return respondLoginTokens(c, s.sessionRepo, user, embeddedPermissions(c, s.permissions, user), nil)
+}
+
+// idpPerformedMFA bernilai true hanya jika OIDC_TRUST_IDP_MFA aktif dan claim amr/acr id_token menunjukkan MFA
+func (s *oidcService) idpPerformedMFA(claims jwt.MapClaims) bool {
+	if !s.opts.TrustIdPMFA {
+		return false
+	}
+
+	if amr, ok := claims["amr"].([]interface{}); ok {
+		for _, method := range amr {
+			if m, ok := method.(string); ok && containsFold(s.opts.MFAAmr, m) {
+				return true
+			}
+		}
+	}
+
+	acr := stringClaim(claims, "acr")
+	return acr != "" && containsFold(s.opts.MFAAcr, acr)
+}
+
+func containsFold(list []string, value string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// splitList memecah daftar dipisah koma dan membuang entri kosong
+func splitList(raw string) []string {
+	var items []string
+	for _, item := range strings.Split(raw, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// resolveUser mencocokkan claims IdP ke akun lokal: email (jika terverifikasi) lalu NIM
+func (s *oidcService) resolveUser(ctx context.Context, claims jwt.MapClaims) (models.User, error) {
+	email := s.verifiedEmail(claims)
+	nim := stringClaim(claims, s.opts.NIMClaim)
+
+	if email != "" {
+		user, err := s.userRepo.GetUserByEmail(ctx, email)
+		if err == nil {
+			return user, nil
+		} else if err != sql.ErrNoRows {
+			return models.User{}, apperror.Internal(err)
+		}
+	}
+
+	if nim != "" {
+		user, err := s.userRepo.GetUserByNIM(ctx, nim)
+		if err == nil {
+			return user, nil
+		} else if err != sql.ErrNoRows {
+			return models.User{}, apperror.Internal(err)
+		}
+	}
+
+	if !s.opts.JITProvisioning {
+		return models.User{}, apperror.Forbidden("sso_account_not_registered")
+	}
+
+	return s.provisionUser(ctx, claims, email, nim)
+}
+
+// provisionUser membuat akun baru dari claims IdP. Role ditentukan dari grup pertama yang ada di OIDC_GROUP_ROLES.
+func (s *oidcService) provisionUser(ctx context.Context, claims jwt.MapClaims, email string, nim string) (models.User, error) {
+	if email == "" {
+		return models.User{}, apperror.Forbidden("sso_email_required")
+	}
+
+	roleName := ""
+	for _, group := range stringsClaim(claims, s.opts.GroupsClaim) {
+		if mapped, ok := s.opts.GroupRoles[group]; ok {
+			roleName = mapped
+			break
+		}
+	}
+	if roleName == "" {
+		return models.User{}, apperror.Forbidden("sso_group_not_mapped")
+	}
+
+	if roleName == models.RoleMahasiswa && nim == "" {
+		return models.User{}, apperror.Forbidden("sso_nim_required")
+	}
+
+	role, err := s.roleRepo.GetRoleByName(ctx, roleName)
+	if err == sql.ErrNoRows {
+		return models.User{}, apperror.Internal(fmt.Errorf("role %s hasil pemetaan grup tidak ditemukan", roleName))
+	} else if err != nil {
+		return models.User{}, apperror.Internal(err)
+	}
+
+	// Password acak yang tidak pernah ditampilkan: akun SSO login lewat IdP
+	secret, err := utils.RandomURLToken(32)
+	if err != nil {
+		return models.User{}, apperror.Internal(err)
+	}
+	passwordHash, err := utils.HashPassword(secret)
+	if err != nil {
+		return models.User{}, apperror.Internal(err)
+	}
+
+	username := stringClaim(claims, "preferred_username")
+	if username == "" {
+		username = nim
+	}
+	if username == "" {
+		username, _, _ = strings.Cut(email, "@")
+	}
+	if len(username) > 50 {
+		username = username[:50]
+	}
+
+	fullName := stringClaim(claims, "name")
+	if fullName == "" {
+		fullName = username
+	}
+
+	now := time.Now()
+	user := models.User{
+		ID:           uuid.New(),
+		Username:     username,
+		Email:        email,
+		PasswordHash: passwordHash,
+		FullName:     fullName,
+		RoleID:       role.ID,
+		RoleName:     role.Name,
+		IsActive:     true,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return models.User{}, apperror.Internal(err)
+	}
+	defer tx.Rollback()
+
+	if err := s.userRepo.CreateUser(ctx, tx, user); err != nil {
+		if isDuplicateKey(err) {
+			return models.User{}, apperror.Conflict("sso_account_conflict")
+		}
+		return models.User{}, apperror.Internal(err)
+	}
+
+	switch role.Name {
+	case models.RoleMahasiswa:
+		student := models.Student{ID: uuid.New(), UserID: user.ID, StudentID: nim, CreatedAt: now}
+		if err := s.studentRepo.CreateStudent(ctx, tx, student); err != nil {
+			if isDuplicateKey(err) {
+				return models.User{}, apperror.Conflict("nim_taken")
+			}
+			return models.User{}, apperror.Internal(err)
+		}
+	case models.RoleDosen:
+		lecture := models.Lecture{ID: uuid.New(), UserID: user.ID, CreatedAt: now}
+		if err := s.lecturerRepo.CreateLecture(ctx, tx, lecture); err != nil {
+			return models.User{}, apperror.Internal(err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return models.User{}, apperror.Internal(err)
+	}
+
+	log.Printf("OIDC: akun %s dibuat otomatis dengan role %s", user.Username, role.Name)
+	return user, nil
+}
+
+// verifiedEmail mengembalikan email dari claims hanya jika IdP menyatakan email_verified=true.
+// Claim yang tidak ada dianggap belum terverifikasi: email tanpa verifikasi bisa dipakai mengambil alih akun lokal
+func (s *oidcService) verifiedEmail(claims jwt.MapClaims) string {
+	if verified, _ := claims["email_verified"].(bool); !verified {
+		return ""
+	}
+	return strings.TrimSpace(stringClaim(claims, "email"))
+}
+
+func stringClaim(claims jwt.MapClaims, name string) string {
+	value, _ := claims[name].(string)
+	return value
+}
+
+// stringsClaim membaca claim berupa array string atau satu string (sebagian IdP mengirim grup tunggal sebagai string)
+func stringsClaim(claims jwt.MapClaims, name string) []string {
+	switch value := claims[name].(type) {
+	case string:
+		return []string{value}
+	case []interface{}:
+		values := make([]string, 0, len(value))
+		for _, v := range value {
+			if str, ok := v.(string); ok {
+				values = append(values, str)
+			}
+		}
+		return values
+	}
+	return nil
+}
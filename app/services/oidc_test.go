@@ -0,0 +1,342 @@
+package services_test
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+	"uas/app/models"
+	"uas/app/services"
+	"uas/apperror"
+	"uas/mocks"
+	"uas/utils"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+const oidcTestClientID = "uas-backend"
+
+// mockIdP adalah identity provider minimal: discovery, authorize, token (dengan cek PKCE), dan JWKS
+type mockIdP struct {
+	server *httptest.Server
+	key    *rsa.PrivateKey
+	claims jwt.MapClaims
+
+	mu    sync.Mutex
+	codes map[string]url.Values
+}
+
+func newMockIdP(t *testing.T, claims jwt.MapClaims) *mockIdP {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+
+	idp := &mockIdP{key: key, claims: claims, codes: make(map[string]url.Values)}
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 idp.server.URL,
+			"authorization_endpoint": idp.server.URL + "/authorize",
+			"token_endpoint":         idp.server.URL + "/token",
+			"jwks_uri":               idp.server.URL + "/jwks",
+		})
+	})
+
+	mux.HandleFunc("/authorize", func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		code := uuid.NewString()
+		idp.mu.Lock()
+		idp.codes[code] = query
+		idp.mu.Unlock()
+
+		redirect := query.Get("redirect_uri") + "?code=" + code + "&state=" + url.QueryEscape(query.Get("state"))
+		http.Redirect(w, r, redirect, http.StatusFound)
+	})
+
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		idp.mu.Lock()
+		authReq, ok := idp.codes[r.PostForm.Get("code")]
+		delete(idp.codes, r.PostForm.Get("code"))
+		idp.mu.Unlock()
+
+		if !ok || authReq.Get("code_challenge") != utils.PKCEChallenge(r.PostForm.Get("code_verifier")) {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+			return
+		}
+
+		idClaims := jwt.MapClaims{
+			"iss":   idp.server.URL,
+			"aud":   oidcTestClientID,
+			"sub":   "idp-user-1",
+			"nonce": authReq.Get("nonce"),
+			"iat":   time.Now().Unix(),
+			"exp":   time.Now().Add(5 * time.Minute).Unix(),
+		}
+		for k, v := range idp.claims {
+			idClaims[k] = v
+		}
+
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, idClaims)
+		token.Header["kid"] = "idp-1"
+		signed, _ := token.SignedString(idp.key)
+		json.NewEncoder(w).Encode(map[string]string{"access_token": "at", "token_type": "Bearer", "id_token": signed})
+	})
+
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"keys": []map[string]string{{
+				"kid": "idp-1",
+				"kty": "RSA",
+				"use": "sig",
+				"alg": "RS256",
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			}},
+		})
+	})
+
+	idp.server = httptest.NewServer(mux)
+	t.Cleanup(idp.server.Close)
+	return idp
+}
+
+func (idp *mockIdP) provider() *utils.OIDCProvider {
+	return utils.NewOIDCProvider(utils.OIDCConfig{
+		Issuer:      idp.server.URL,
+		ClientID:    oidcTestClientID,
+		RedirectURL: "http://localhost:3000/oidc/callback",
+	})
+}
+
+// runOIDCFlow menjalankan login -> authorize di IdP -> callback, dan mengembalikan response callback
+func runOIDCFlow(t *testing.T, app *fiber.App, tamperState bool) *http.Response {
+	resp, err := app.Test(httptest.NewRequest("GET", "/oidc/login", nil))
+	assert.NoError(t, err)
+	assert.Equal(t, 302, resp.StatusCode)
+
+	var flowCookie *http.Cookie
+	for _, cookie := range resp.Cookies() {
+		if cookie.Name == "oidc_flow" {
+			flowCookie = cookie
+		}
+	}
+	assert.NotNil(t, flowCookie)
+
+	authURL, _ := url.Parse(resp.Header.Get("Location"))
+	assert.Equal(t, "S256", authURL.Query().Get("code_challenge_method"))
+
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+	idpResp, err := client.Get(authURL.String())
+	assert.NoError(t, err)
+
+	callbackURL, _ := url.Parse(idpResp.Header.Get("Location"))
+	query := callbackURL.Query()
+	if tamperState {
+		query.Set("state", "state-palsu")
+	}
+
+	req := httptest.NewRequest("GET", "/oidc/callback?"+query.Encode(), nil)
+	req.AddCookie(&http.Cookie{Name: flowCookie.Name, Value: flowCookie.Value})
+	resp, err = app.Test(req)
+	assert.NoError(t, err)
+	return resp
+}
+
+// mfaRepoWithout adalah repo MFA untuk user yang belum mendaftarkan MFA
+func mfaRepoWithout() *mocks.MockMFARepo {
+	repo := new(mocks.MockMFARepo)
+	repo.On("GetMFAByUserID", mock.Anything, mock.Anything).Return(models.UserMFA{}, sql.ErrNoRows)
+	return repo
+}
+
+func TestOIDC_MatchesExistingUserByEmail(t *testing.T) {
+	idp := newMockIdP(t, jwt.MapClaims{"email": "George@Kampus.ac.id", "email_verified": true})
+	mockUserRepo := new(mocks.MockUserRepo)
+	svc := services.NewOIDCService(nil, idp.provider(), mockUserRepo, nil, nil, nil, nil, mfaRepoWithout(), nil, services.OIDCOptions{})
+
+	app := fiber.New(fiber.Config{ErrorHandler: apperror.Handler})
+	app.Get("/oidc/login", svc.Login)
+	app.Get("/oidc/callback", svc.Callback)
+
+	existing := models.User{ID: uuid.New(), Username: "george_mhs", RoleName: "Mahasiswa", IsActive: true}
+	mockUserRepo.On("GetUserByEmail", mock.Anything, "George@Kampus.ac.id").Return(existing, nil)
+
+	resp := runOIDCFlow(t, app, false)
+	assert.Equal(t, 200, resp.StatusCode)
+
+	var body map[string]interface{}
+	json.NewDecoder(resp.Body).Decode(&body)
+	data := body["data"].(map[string]interface{})
+	assert.NotEmpty(t, data["token"])
+	assert.NotEmpty(t, data["refreshToken"])
+	assert.Equal(t, "george_mhs", data["user"].(map[string]interface{})["username"])
+}
+
+func TestOIDC_JITProvisionsStudentFromGroup(t *testing.T) {
+	idp := newMockIdP(t, jwt.MapClaims{
+		"email":              "budi@kampus.ac.id",
+		"email_verified":     true,
+		"preferred_username": "budi",
+		"name":               "Budi Santoso",
+		"nim":                "434221001",
+		"groups":             []string{"staff-wifi", "mahasiswa"},
+	})
+
+	db, mockDB, _ := sqlmock.New()
+	defer db.Close()
+	mockDB.ExpectBegin()
+	mockDB.ExpectCommit()
+
+	mockUserRepo := new(mocks.MockUserRepo)
+	mockStudentRepo := new(mocks.MockStudentRepo)
+	mockRoleRepo := new(mocks.MockRoleRepo)
+	opts := services.OIDCOptions{
+		JITProvisioning: true,
+		GroupRoles:      map[string]string{"mahasiswa": models.RoleMahasiswa},
+	}
+	svc := services.NewOIDCService(db, idp.provider(), mockUserRepo, mockStudentRepo, nil, mockRoleRepo, nil, mfaRepoWithout(), nil, opts)
+
+	app := fiber.New(fiber.Config{ErrorHandler: apperror.Handler})
+	app.Get("/oidc/login", svc.Login)
+	app.Get("/oidc/callback", svc.Callback)
+
+	roleID := uuid.New()
+	mockUserRepo.On("GetUserByEmail", mock.Anything, "budi@kampus.ac.id").Return(models.User{}, sql.ErrNoRows)
+	mockUserRepo.On("GetUserByNIM", mock.Anything, "434221001").Return(models.User{}, sql.ErrNoRows)
+	mockRoleRepo.On("GetRoleByName", mock.Anything, models.RoleMahasiswa).Return(models.Role{ID: roleID, Name: models.RoleMahasiswa}, nil)
+	mockUserRepo.On("CreateUser", mock.Anything, mock.Anything, mock.MatchedBy(func(u models.User) bool {
+		return u.Username == "budi" && u.FullName == "Budi Santoso" && u.RoleID == roleID && u.PasswordHash != ""
+	})).Return(nil)
+	mockStudentRepo.On("CreateStudent", mock.Anything, mock.Anything, mock.MatchedBy(func(s models.Student) bool {
+		return s.StudentID == "434221001" && s.AdvisorID == uuid.Nil
+	})).Return(nil)
+
+	resp := runOIDCFlow(t, app, false)
+	assert.Equal(t, 200, resp.StatusCode)
+	mockUserRepo.AssertExpectations(t)
+	mockStudentRepo.AssertExpectations(t)
+	assert.NoError(t, mockDB.ExpectationsWereMet())
+}
+
+func TestOIDC_UnverifiedEmailDoesNotMatchExistingUser(t *testing.T) {
+	// IdP tidak mengirim email_verified sama sekali
+	idp := newMockIdP(t, jwt.MapClaims{"email": "admin@kampus.ac.id"})
+	mockUserRepo := new(mocks.MockUserRepo)
+	svc := services.NewOIDCService(nil, idp.provider(), mockUserRepo, nil, nil, nil, nil, mfaRepoWithout(), nil, services.OIDCOptions{})
+
+	app := fiber.New(fiber.Config{ErrorHandler: apperror.Handler})
+	app.Get("/oidc/login", svc.Login)
+	app.Get("/oidc/callback", svc.Callback)
+
+	resp := runOIDCFlow(t, app, false)
+	assert.Equal(t, 403, resp.StatusCode)
+	mockUserRepo.AssertNotCalled(t, "GetUserByEmail", mock.Anything, mock.Anything)
+}
+
+func TestOIDC_UnknownUserWithoutProvisioning(t *testing.T) {
+	idp := newMockIdP(t, jwt.MapClaims{"email": "tamu@kampus.ac.id", "email_verified": true})
+	mockUserRepo := new(mocks.MockUserRepo)
+	svc := services.NewOIDCService(nil, idp.provider(), mockUserRepo, nil, nil, nil, nil, nil, nil, services.OIDCOptions{})
+
+	app := fiber.New(fiber.Config{ErrorHandler: apperror.Handler})
+	app.Get("/oidc/login", svc.Login)
+	app.Get("/oidc/callback", svc.Callback)
+
+	mockUserRepo.On("GetUserByEmail", mock.Anything, "tamu@kampus.ac.id").Return(models.User{}, sql.ErrNoRows)
+
+	resp := runOIDCFlow(t, app, false)
+	assert.Equal(t, 403, resp.StatusCode)
+	mockUserRepo.AssertNotCalled(t, "CreateUser", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestOIDC_RejectsStateMismatch(t *testing.T) {
+	idp := newMockIdP(t, jwt.MapClaims{"email": "george@kampus.ac.id"})
+	mockUserRepo := new(mocks.MockUserRepo)
+	svc := services.NewOIDCService(nil, idp.provider(), mockUserRepo, nil, nil, nil, nil, nil, nil, services.OIDCOptions{})
+
+	app := fiber.New(fiber.Config{ErrorHandler: apperror.Handler})
+	app.Get("/oidc/login", svc.Login)
+	app.Get("/oidc/callback", svc.Callback)
+
+	resp := runOIDCFlow(t, app, true)
+	assert.Equal(t, 400, resp.StatusCode)
+	mockUserRepo.AssertNotCalled(t, "GetUserByEmail", mock.Anything, mock.Anything)
+}
+
+func newOIDCMFAApp(t *testing.T, idpClaims jwt.MapClaims, opts services.OIDCOptions) (*fiber.App, models.User) {
+	idp := newMockIdP(t, idpClaims)
+	mockUserRepo := new(mocks.MockUserRepo)
+	mockMFARepo := new(mocks.MockMFARepo)
+	svc := services.NewOIDCService(nil, idp.provider(), mockUserRepo, nil, nil, nil, nil, mockMFARepo, nil, opts)
+
+	app := fiber.New(fiber.Config{ErrorHandler: apperror.Handler})
+	app.Get("/oidc/login", svc.Login)
+	app.Get("/oidc/callback", svc.Callback)
+
+	admin := models.User{ID: uuid.New(), Username: "george_admin", RoleName: models.RoleAdmin, IsActive: true}
+	mockUserRepo.On("GetUserByEmail", mock.Anything, "admin@kampus.ac.id").Return(admin, nil)
+	mockMFARepo.On("GetMFAByUserID", mock.Anything, admin.ID).Return(models.UserMFA{
+		UserID: admin.ID, TOTPSecret: "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ", IsEnabled: true,
+	}, nil)
+	return app, admin
+}
+
+func TestOIDC_MFAEnabledUserGetsChallenge(t *testing.T) {
+	// amr dari IdP tidak dipercaya tanpa OIDC_TRUST_IDP_MFA
+	app, _ := newOIDCMFAApp(t, jwt.MapClaims{"email": "admin@kampus.ac.id", "email_verified": true, "amr": []string{"pwd", "mfa"}}, services.OIDCOptions{})
+
+	resp := runOIDCFlow(t, app, false)
+	assert.Equal(t, 200, resp.StatusCode)
+
+	var body map[string]interface{}
+	json.NewDecoder(resp.Body).Decode(&body)
+	assert.Equal(t, "mfa_required", body["status"])
+	data := body["data"].(map[string]interface{})
+	assert.NotEmpty(t, data["mfaToken"])
+	assert.Nil(t, data["token"])
+}
+
+func TestOIDC_TrustedIdPMFASkipsLocalMFA(t *testing.T) {
+	opts := services.OIDCOptions{TrustIdPMFA: true, MFAAmr: []string{"mfa", "otp"}}
+
+	app, _ := newOIDCMFAApp(t, jwt.MapClaims{"email": "admin@kampus.ac.id", "email_verified": true, "amr": []string{"pwd", "otp"}}, opts)
+	resp := runOIDCFlow(t, app, false)
+	var body map[string]interface{}
+	json.NewDecoder(resp.Body).Decode(&body)
+	assert.NotEmpty(t, body["data"].(map[string]interface{})["token"])
+
+	// amr tanpa metode MFA tetap mendapat challenge
+	app, _ = newOIDCMFAApp(t, jwt.MapClaims{"email": "admin@kampus.ac.id", "email_verified": true, "amr": []string{"pwd"}}, opts)
+	resp = runOIDCFlow(t, app, false)
+	body = nil
+	json.NewDecoder(resp.Body).Decode(&body)
+	assert.Equal(t, "mfa_required", body["status"])
+}
+
+func TestOIDC_FlowTokenIsNotAccessToken(t *testing.T) {
+	flowToken, err := utils.GenerateOIDCFlowToken("state", "nonce", "verifier")
+	assert.NoError(t, err)
+
+	_, err = utils.ValidateToken(flowToken)
+	assert.Error(t, err)
+
+	// Sebaliknya, access token tidak diterima sebagai cookie alur SSO
+	accessToken, _ := utils.GenerateToken(models.User{ID: uuid.New()}, nil)
+	_, _, err = utils.ValidateOIDCFlowToken(accessToken, "")
+	assert.Error(t, err)
+}
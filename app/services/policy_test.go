@@ -0,0 +1,22 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"uas/mocks"
+	"uas/policy"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestPolicy_AttributeErrorIsNotSilentlyDenied(t *testing.T) {
+	repo := new(mocks.MockAchievementRepo)
+	repo.On("GetStudentIDByUserID", mock.Anything, "mhs-1").Return("", errors.New("koneksi database putus"))
+
+	engine := policy.NewEngine(repo)
+	_, err := engine.Authorize(context.Background(), policy.Subject{UserID: "mhs-1", Role: "Mahasiswa"}, policy.ActionSubmit,
+		policy.Resource{Kind: policy.KindAchievement, OwnerStudentID: "std-1", Status: "draft"})
+	assert.Error(t, err)
+}
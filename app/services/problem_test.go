@@ -0,0 +1,84 @@
+package services_test
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http/httptest"
+	"testing"
+	"uas/app/models"
+	"uas/app/services"
+	"uas/apperror"
+	"uas/mocks"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/requestid"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newProblemApp(userRepo *mocks.MockUserRepo) *fiber.App {
+	app := fiber.New(fiber.Config{ErrorHandler: apperror.Handler})
+	app.Use(requestid.New())
+	app.Get("/users/:id", services.NewUserService(nil, userRepo, nil, nil, nil, nil).GetUserByID)
+	return app
+}
+
+func TestProblem_NotFoundHasCodeAndRequestID(t *testing.T) {
+	mockUserRepo := new(mocks.MockUserRepo)
+	userID := uuid.New()
+	mockUserRepo.On("GetUserByID", mock.Anything, userID).Return(models.User{}, sql.ErrNoRows)
+
+	req := httptest.NewRequest("GET", "/users/"+userID.String(), nil)
+	req.Header.Set("X-Request-ID", "req-123")
+	resp, _ := newProblemApp(mockUserRepo).Test(req)
+
+	assert.Equal(t, 404, resp.StatusCode)
+	assert.Equal(t, apperror.ProblemContentType, resp.Header.Get("Content-Type"))
+
+	var problem apperror.Problem
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&problem))
+	assert.Equal(t, "user_not_found", problem.Code)
+	assert.Equal(t, 404, problem.Status)
+	assert.Equal(t, "Not Found", problem.Title)
+	assert.Equal(t, "req-123", problem.RequestID)
+	assert.Equal(t, "/users/"+userID.String(), problem.Instance)
+	assert.False(t, problem.Success)
+}
+
+func TestProblem_InternalErrorHidesCause(t *testing.T) {
+	mockUserRepo := new(mocks.MockUserRepo)
+	userID := uuid.New()
+	mockUserRepo.On("GetUserByID", mock.Anything, userID).
+		Return(models.User{}, errors.New(`pq: relation "users" does not exist`))
+
+	resp, _ := newProblemApp(mockUserRepo).Test(httptest.NewRequest("GET", "/users/"+userID.String(), nil))
+	assert.Equal(t, 500, resp.StatusCode)
+
+	body, _ := io.ReadAll(resp.Body)
+	assert.NotContains(t, string(body), "relation")
+
+	var problem apperror.Problem
+	assert.NoError(t, json.Unmarshal(body, &problem))
+	assert.Equal(t, "internal_error", problem.Code)
+	assert.NotEmpty(t, problem.RequestID)
+	assert.Equal(t, problem.RequestID, resp.Header.Get("X-Request-ID"))
+}
+
+func TestProblem_UnknownRoute(t *testing.T) {
+	resp, _ := newProblemApp(new(mocks.MockUserRepo)).Test(httptest.NewRequest("GET", "/tidak-ada", nil))
+	assert.Equal(t, 404, resp.StatusCode)
+
+	var problem apperror.Problem
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&problem))
+	assert.Equal(t, "route_not_found", problem.Code)
+}
+
+func TestProblem_KindIsMatchable(t *testing.T) {
+	err := error(apperror.NotFound("user_not_found"))
+	assert.True(t, errors.Is(err, apperror.NotFoundKind))
+	assert.False(t, errors.Is(err, apperror.ConflictKind))
+	assert.Equal(t, 409, apperror.InvalidTransition("x").Status())
+}
@@ -0,0 +1,492 @@
+package services
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"uas/app/models"
+	"uas/app/repository"
+	"uas/apperror"
+	"uas/i18n"
+	"uas/utils"
+	"uas/validation"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+const emailChangeTTL = 24 * time.Hour
+
+var phonePattern = regexp.MustCompile(`^\+?[0-9]{8,15}$`)
+
+// profileEditableFields adalah field yang boleh diubah user sendiri. NIM, program studi,
+// dosen wali, dan data akun lain hanya bisa diubah Admin lewat endpoint masing-masing
+var profileEditableFields = map[string]bool{"email": true, "phone": true, "photo_url": true, "bio": true, "language": true}
+
+type ProfileService interface {
+	GetProfile(c *fiber.Ctx) error
+	UpdateProfile(c *fiber.Ctx) error
+	ConfirmEmailChange(c *fiber.Ctx) error
+	UploadPhoto(c *fiber.Ctx) error
+	DeletePhoto(c *fiber.Ctx) error
+}
+
+type profileService struct {
+	db        *sql.DB
+	repo      repository.ProfileRepository
+	userRepo  repository.UserRepository
+	auditRepo repository.AuditRepository
+	mailer    utils.Mailer
+	storage   utils.Storage
+}
+
+func NewProfileService(db *sql.DB, repo repository.ProfileRepository, userRepo repository.UserRepository, auditRepo repository.AuditRepository, mailer utils.Mailer, storage utils.Storage) ProfileService {
+	return &profileService{db: db, repo: repo, userRepo: userRepo, auditRepo: auditRepo, mailer: mailer, storage: storage}
+}
+
+// audit mencatat perubahan profil. Perubahan sudah tersimpan, jadi kegagalan audit hanya dicatat ke log
+func (s *profileService) audit(c *fiber.Ctx, userID uuid.UUID, action string, metadata map[string]interface{}) {
+	err := s.auditRepo.CreateAuditLog(c.Context(), models.AuditLog{
+		ID:            uuid.New(),
+		ActorID:       &userID,
+		SubjectUserID: &userID,
+		Action:        action,
+		Method:        c.Method(),
+		Path:          c.OriginalURL(),
+		StatusCode:    200,
+		IPAddress:     c.IP(),
+		Metadata:      metadata,
+		CreatedAt:     time.Now(),
+	})
+	if err != nil {
+		log.Printf("gagal mencatat audit %s untuk user %s: %v", action, userID, err)
+	}
+}
+
+// GetProfile godoc
+// @Summary      Lihat Profil Saya
+// @Description  Melihat profil lengkap user yang sedang login, termasuk data mahasiswa beserta dosen walinya atau data dosen
+// @Tags         Auth
+// @Produce      json
+// @Security     Bearer
+// @Success      200  {object} models.Profile
+// @Failure      401  {object} apperror.Problem
+// @Router       /auth/profile [get]
+func (s *profileService) GetProfile(c *fiber.Ctx) error {
+	userID, ok := c.Locals("user_id").(uuid.UUID)
+	if !ok {
+		return apperror.Unauthorized("unauthorized")
+	}
+
+	profile, err := s.repo.GetProfile(c.Context(), userID)
+	if err == sql.ErrNoRows {
+		return apperror.NotFound("user_not_found")
+	} else if err != nil {
+		return apperror.Internal(err)
+	}
+
+	if impersonatorID, ok := c.Locals("impersonator_id").(uuid.UUID); ok {
+		profile.ImpersonatorID = &impersonatorID
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": i18n.Message(c, "messages.profile_fetched"),
+		"data":    profile,
+	})
+}
+
+// UpdateProfile godoc
+// @Summary      Ubah Profil Saya
+// @Description  Mengubah email, nomor telepon, foto, bio, dan bahasa (id/en, kosong = ikuti Accept-Language) milik sendiri. Bahasa baru langsung dipakai response ini dan tersimpan di access token berikutnya. Email baru baru berlaku setelah dikonfirmasi lewat tautan yang dikirim ke alamat tersebut. NIM, program studi, dan dosen wali hanya bisa diubah Admin.
+// @Tags         Auth
+// @Accept       json
+// @Produce      json
+// @Security     Bearer
+// @Param        request  body      models.UpdateProfileRequest  true  "Field yang diubah"
+// @Success      200      {object}  models.Profile
+// @Failure      400      {object}  apperror.Problem
+// @Failure      403      {object}  apperror.Problem "Field hanya bisa diubah Admin"
+// @Failure      409      {object}  apperror.Problem "Email sudah dipakai"
+// @Failure      422  {object}  apperror.Problem "Data tidak valid (errors berisi pesan per field)"
+// @Router       /auth/profile [put]
+func (s *profileService) UpdateProfile(c *fiber.Ctx) error {
+	userID, ok := c.Locals("user_id").(uuid.UUID)
+	if !ok {
+		return apperror.Unauthorized("unauthorized")
+	}
+
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(c.Body(), &raw); err != nil {
+		return apperror.BadRequest("invalid_json")
+	}
+
+	var forbidden []string
+	for field := range raw {
+		if !profileEditableFields[field] {
+			forbidden = append(forbidden, field)
+		}
+	}
+	if len(forbidden) > 0 {
+		sort.Strings(forbidden)
+		return apperror.Forbidden("admin_only_fields").WithParams(i18n.Params{"fields": strings.Join(forbidden, ", ")})
+	}
+
+	var req models.UpdateProfileRequest
+	if err := json.Unmarshal(c.Body(), &req); err != nil {
+		return apperror.BadRequest("invalid_json")
+	}
+	if errs := validation.Struct(req); len(errs) > 0 {
+		return validationError(errs...)
+	}
+
+	current, err := s.repo.GetProfile(c.Context(), userID)
+	if err == sql.ErrNoRows {
+		return apperror.NotFound("user_not_found")
+	} else if err != nil {
+		return apperror.Internal(err)
+	}
+
+	unchanged := models.ProfileFields{Phone: current.Phone, PhotoURL: current.PhotoURL, Bio: current.Bio, Language: current.Language}
+	fields := unchanged
+	if req.Phone != nil {
+		fields.Phone = strings.NewReplacer(" ", "", "-", "").Replace(strings.TrimSpace(*req.Phone))
+		if fields.Phone != "" && !phonePattern.MatchString(fields.Phone) {
+			return validationError(validation.Field("phone", "phone", "validation.phone"))
+		}
+	}
+	if req.PhotoURL != nil {
+		fields.PhotoURL = strings.TrimSpace(*req.PhotoURL)
+		if fields.PhotoURL != "" && !(strings.HasPrefix(fields.PhotoURL, "https://") ||
+			strings.HasPrefix(fields.PhotoURL, "http://") || strings.HasPrefix(fields.PhotoURL, "/uploads/")) {
+			return validationError(validation.Field("photo_url", "url", "validation.photo_url"))
+		}
+	}
+	if req.Bio != nil {
+		fields.Bio = strings.TrimSpace(*req.Bio)
+	}
+	if req.Language != nil {
+		fields.Language = *req.Language
+	}
+
+	var newEmail string
+	if req.Email != nil {
+		newEmail = strings.ToLower(strings.TrimSpace(*req.Email))
+		if strings.EqualFold(newEmail, current.Email) {
+			newEmail = ""
+		} else if _, err := s.userRepo.GetUserByEmail(c.Context(), newEmail); err == nil {
+			return apperror.Conflict("email_taken")
+		} else if err != sql.ErrNoRows {
+			return apperror.Internal(err)
+		}
+	}
+
+	if fields != unchanged {
+		if err := s.repo.UpdateProfileFields(c.Context(), userID, fields); err != nil {
+			return apperror.Internal(err)
+		}
+
+		if fields.PhotoURL != current.PhotoURL {
+			s.removePhotoFiles(c, userID, current, fields.PhotoURL)
+		}
+
+		// Bahasa baru langsung berlaku untuk response ini; access token berikutnya membawa claim lang yang baru
+		if fields.Language != current.Language {
+			c.Locals("language", fields.Language)
+			c.Locals("lang", nil)
+		}
+
+		if fields.Phone != current.Phone {
+			s.audit(c, userID, models.AuditProfileUpdate, map[string]interface{}{
+				"field": "phone",
+				"old":   current.Phone,
+				"new":   fields.Phone,
+			})
+		}
+	}
+
+	message := i18n.Message(c, "messages.profile_updated")
+	if newEmail != "" {
+		if err := s.requestEmailChange(c, current, newEmail); err != nil {
+			return apperror.Internal(err)
+		}
+		message = i18n.Message(c, "messages.profile_updated_email_pending", i18n.Params{"email": newEmail})
+	}
+
+	profile, err := s.repo.GetProfile(c.Context(), userID)
+	if err != nil {
+		return apperror.Internal(err)
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": message,
+		"data":    profile,
+	})
+}
+
+// requestEmailChange menyimpan permintaan ganti email lalu mengirim tautan konfirmasi ke alamat baru
+// dan pemberitahuan ke alamat lama
+func (s *profileService) requestEmailChange(c *fiber.Ctx, current models.Profile, newEmail string) error {
+	token, err := utils.RandomURLToken(32)
+	if err != nil {
+		return err
+	}
+
+	err = s.repo.CreateEmailChange(c.Context(), models.EmailChangeRequest{
+		ID:        uuid.New(),
+		UserID:    current.ID,
+		NewEmail:  newEmail,
+		TokenHash: utils.HashToken(token),
+		ExpiresAt: time.Now().Add(emailChangeTTL),
+	})
+	if err != nil {
+		return err
+	}
+
+	// Penerima adalah user yang sedang login, jadi email memakai bahasa response (preferensi user atau Accept-Language)
+	lang := i18n.FromContext(c)
+	params := i18n.Params{
+		"name":     current.FullName,
+		"username": current.Username,
+		"email":    newEmail,
+		"link":     emailConfirmLink(lang, token),
+	}
+	if err := s.mailer.Send(newEmail, i18n.T(lang, "email.confirm_change.subject"), i18n.T(lang, "email.confirm_change.body", params)); err != nil {
+		return err
+	}
+
+	if err := s.mailer.Send(current.Email, i18n.T(lang, "email.change_requested.subject"), i18n.T(lang, "email.change_requested.body", params)); err != nil {
+		log.Printf("gagal mengirim pemberitahuan ganti email ke %s: %v", current.Email, err)
+	}
+
+	s.audit(c, current.ID, models.AuditEmailChangeRequested, map[string]interface{}{
+		"old_email": current.Email,
+		"new_email": newEmail,
+	})
+	return nil
+}
+
+// emailConfirmLink memakai EMAIL_CONFIRM_URL (misalnya halaman frontend) sebagai awalan token
+func emailConfirmLink(lang i18n.Lang, token string) string {
+	base := os.Getenv("EMAIL_CONFIRM_URL")
+	if base == "" {
+		return i18n.T(lang, "email.confirm_change.token", i18n.Params{"token": token})
+	}
+	return base + token
+}
+
+// ConfirmEmailChange godoc
+// @Summary      Konfirmasi Perubahan Email
+// @Description  Menerapkan email baru memakai token dari email konfirmasi. Token berlaku 24 jam dan hanya bisa dipakai sekali.
+// @Tags         Auth
+// @Accept       json
+// @Produce      json
+// @Param        request  body      models.ConfirmEmailRequest  true  "Token konfirmasi"
+// @Success      200      {object}  map[string]string
+// @Failure      400      {object}  apperror.Problem "Token tidak valid"
+// @Failure      409      {object}  apperror.Problem "Email sudah dipakai"
+// @Failure      422  {object}  apperror.Problem "Data tidak valid (errors berisi pesan per field)"
+// @Router       /auth/profile/email/confirm [post]
+func (s *profileService) ConfirmEmailChange(c *fiber.Ctx) error {
+	var req models.ConfirmEmailRequest
+	if err := bindBody(c, &req); err != nil {
+		return err
+	}
+
+	tx, err := s.db.BeginTx(c.Context(), nil)
+	if err != nil {
+		return apperror.Internal(err)
+	}
+	defer tx.Rollback()
+
+	change, err := s.repo.GetPendingEmailChangeForUpdate(c.Context(), tx, utils.HashToken(req.Token))
+	if err == sql.ErrNoRows {
+		return apperror.BadRequest("email_token_invalid")
+	} else if err != nil {
+		return apperror.Internal(err)
+	}
+
+	if err := s.repo.ConfirmEmailChange(c.Context(), tx, change); err != nil {
+		if isDuplicateKey(err) {
+			return apperror.Conflict("email_taken")
+		}
+		return apperror.Internal(err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return apperror.Internal(err)
+	}
+
+	s.audit(c, change.UserID, models.AuditEmailChanged, map[string]interface{}{
+		"old_email": change.OldEmail,
+		"new_email": change.NewEmail,
+	})
+
+	// Endpoint ini tanpa login, jadi bahasa email mengikuti bahasa request
+	lang := i18n.FromContext(c)
+	notice := i18n.T(lang, "email.changed.body", i18n.Params{"email": change.NewEmail})
+	if err := s.mailer.Send(change.OldEmail, i18n.T(lang, "email.changed.subject"), notice); err != nil {
+		log.Printf("gagal mengirim pemberitahuan email terganti ke %s: %v", change.OldEmail, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": i18n.Message(c, "messages.email_changed"),
+	})
+}
+
+// UploadPhoto godoc
+// @Summary      Upload Foto Profil
+// @Description  Mengunggah foto profil (JPEG, PNG, atau GIF; maksimal 5 MB; minimal 128x128). Foto diputar sesuai orientasi EXIF, dipotong persegi di tengah, lalu disimpan ulang sebagai JPEG 512, 256, 128, dan 64 px tanpa metadata EXIF. photo_url menunjuk ke ukuran 512.
+// @Tags         Auth
+// @Accept       multipart/form-data
+// @Produce      json
+// @Security     Bearer
+// @Param        file  formData  file  true  "File foto"
+// @Success      200   {object}  models.Profile
+// @Failure      400   {object}  apperror.Problem "Foto tidak valid"
+// @Failure      413   {object}  apperror.Problem "Foto terlalu besar"
+// @Router       /auth/profile/photo [post]
+func (s *profileService) UploadPhoto(c *fiber.Ctx) error {
+	userID, ok := c.Locals("user_id").(uuid.UUID)
+	if !ok {
+		return apperror.Unauthorized("unauthorized")
+	}
+
+	file, err := c.FormFile("file")
+	if err != nil {
+		return apperror.BadRequest("file_required")
+	}
+	if file.Size > utils.MaxPhotoBytes {
+		return apperror.TooLarge("file_too_large").Variant("photo")
+	}
+
+	src, err := file.Open()
+	if err != nil {
+		return apperror.BadRequest("file_unreadable")
+	}
+	data, err := io.ReadAll(io.LimitReader(src, utils.MaxPhotoBytes+1))
+	src.Close()
+	if err != nil {
+		return apperror.BadRequest("file_unreadable")
+	}
+	if len(data) > utils.MaxPhotoBytes {
+		return apperror.TooLarge("file_too_large").Variant("photo")
+	}
+
+	images, err := utils.ProcessPhoto(data, utils.PhotoSizes)
+	if errors.Is(err, utils.ErrUnsupportedImage) {
+		return apperror.BadRequest("unsupported_image")
+	} else if errors.Is(err, utils.ErrImageTooSmall) {
+		return apperror.BadRequest("invalid_image_size").Variant("too_small")
+	} else if errors.Is(err, utils.ErrImageTooLarge) {
+		return apperror.BadRequest("invalid_image_size").Variant("too_large")
+	} else if err != nil {
+		return apperror.Internal(err)
+	}
+
+	current, err := s.repo.GetProfile(c.Context(), userID)
+	if err == sql.ErrNoRows {
+		return apperror.NotFound("user_not_found")
+	} else if err != nil {
+		return apperror.Internal(err)
+	}
+
+	// Nama file acak agar cache browser/CDN untuk foto lama tidak terpakai
+	version, err := utils.RandomURLToken(8)
+	if err != nil {
+		return apperror.Internal(err)
+	}
+
+	thumbnails := make(map[string]string, len(images))
+	for _, size := range utils.PhotoSizes {
+		key := fmt.Sprintf("photos/%s/%s_%d.jpg", userID, version, size)
+		url, err := s.storage.Put(c.Context(), key, bytes.NewReader(images[size]))
+		if err != nil {
+			s.removePhotoFiles(c, userID, models.Profile{PhotoThumbnails: thumbnails}, "")
+			return apperror.Internal(err)
+		}
+		thumbnails[strconv.Itoa(size)] = url
+	}
+
+	photoURL := thumbnails[strconv.Itoa(utils.PhotoSizes[0])]
+	if err := s.repo.UpdatePhoto(c.Context(), userID, photoURL, thumbnails); err != nil {
+		s.removePhotoFiles(c, userID, models.Profile{PhotoThumbnails: thumbnails}, "")
+		return apperror.Internal(err)
+	}
+	s.removePhotoFiles(c, userID, current, "")
+
+	current.PhotoURL = photoURL
+	current.PhotoThumbnails = thumbnails
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": i18n.Message(c, "messages.photo_updated"),
+		"data":    current,
+	})
+}
+
+// DeletePhoto godoc
+// @Summary      Hapus Foto Profil
+// @Description  Menghapus foto profil beserta seluruh thumbnail-nya
+// @Tags         Auth
+// @Produce      json
+// @Security     Bearer
+// @Success      200  {object}  map[string]string
+// @Router       /auth/profile/photo [delete]
+func (s *profileService) DeletePhoto(c *fiber.Ctx) error {
+	userID, ok := c.Locals("user_id").(uuid.UUID)
+	if !ok {
+		return apperror.Unauthorized("unauthorized")
+	}
+
+	current, err := s.repo.GetProfile(c.Context(), userID)
+	if err == sql.ErrNoRows {
+		return apperror.NotFound("user_not_found")
+	} else if err != nil {
+		return apperror.Internal(err)
+	}
+
+	if err := s.repo.UpdatePhoto(c.Context(), userID, "", nil); err != nil {
+		return apperror.Internal(err)
+	}
+	s.removePhotoFiles(c, userID, current, "")
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": i18n.Message(c, "messages.photo_deleted"),
+	})
+}
+
+// removePhotoFiles menghapus file foto lama milik user di storage. URL eksternal atau file milik user lain
+// (photo_url bisa diisi manual lewat PUT /auth/profile) dilewati. Kegagalan hanya dicatat ke log
+// karena data user sudah tidak menunjuk ke file tersebut. keep adalah URL yang masih dipakai
+func (s *profileService) removePhotoFiles(c *fiber.Ctx, userID uuid.UUID, p models.Profile, keep string) {
+	prefix := "photos/" + userID.String() + "/"
+	urls := []string{p.PhotoURL}
+	for _, url := range p.PhotoThumbnails {
+		if url != p.PhotoURL {
+			urls = append(urls, url)
+		}
+	}
+
+	for _, url := range urls {
+		key, ok := s.storage.KeyFromURL(url)
+		if !ok || url == keep || !strings.HasPrefix(key, prefix) {
+			continue
+		}
+		if err := s.storage.Delete(c.Context(), key); err != nil {
+			log.Printf("gagal menghapus file foto %s: %v", key, err)
+		}
+	}
+}
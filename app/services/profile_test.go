@@ -0,0 +1,221 @@
+package services_test
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/binary"
+	"encoding/json"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"uas/app/models"
+	"uas/app/services"
+	"uas/apperror"
+	"uas/mocks"
+	"uas/utils"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newProfileApp(profileService services.ProfileService, userID uuid.UUID) *fiber.App {
+	app := fiber.New(fiber.Config{ErrorHandler: apperror.Handler})
+	app.Post("/profile/email/confirm", profileService.ConfirmEmailChange)
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("user_id", userID)
+		return c.Next()
+	})
+	app.Put("/profile", profileService.UpdateProfile)
+	app.Post("/profile/photo", profileService.UploadPhoto)
+	return app
+}
+
+func TestUpdateProfile_AdminOnlyFields_Forbidden(t *testing.T) {
+	mockProfileRepo := new(mocks.MockProfileRepo)
+	profileService := services.NewProfileService(nil, mockProfileRepo, new(mocks.MockUserRepo), new(mocks.MockAuditRepo), &mocks.MockMailer{}, mocks.NewMockStorage())
+
+	body, _ := json.Marshal(map[string]string{"bio": "Suka ngoding", "nim": "999", "program_study": "Kedokteran"})
+	req := httptest.NewRequest("PUT", "/profile", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, _ := newProfileApp(profileService, uuid.New()).Test(req)
+	assert.Equal(t, 403, resp.StatusCode)
+
+	var res map[string]interface{}
+	json.NewDecoder(resp.Body).Decode(&res)
+	assert.Equal(t, "Field berikut hanya bisa diubah Admin: nim, program_study", res["message"])
+	mockProfileRepo.AssertNotCalled(t, "UpdateProfileFields", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestUpdateProfile_EmailChangeNeedsConfirmation(t *testing.T) {
+	db, mockDB, _ := sqlmock.New()
+	defer db.Close()
+
+	mockProfileRepo := new(mocks.MockProfileRepo)
+	mockUserRepo := new(mocks.MockUserRepo)
+	mockAuditRepo := new(mocks.MockAuditRepo)
+	mailer := &mocks.MockMailer{}
+	profileService := services.NewProfileService(db, mockProfileRepo, mockUserRepo, mockAuditRepo, mailer, mocks.NewMockStorage())
+
+	userID := uuid.New()
+	current := models.Profile{ID: userID, Username: "george_ganteng", Email: "george@kampus.ac.id", FullName: "George"}
+	app := newProfileApp(profileService, userID)
+
+	mockProfileRepo.On("GetProfile", mock.Anything, userID).Return(current, nil)
+	mockUserRepo.On("GetUserByEmail", mock.Anything, "george.baru@kampus.ac.id").Return(models.User{}, sql.ErrNoRows)
+	mockProfileRepo.On("UpdateProfileFields", mock.Anything, userID, models.ProfileFields{Phone: "+6281234567890"}).Return(nil)
+	mockProfileRepo.On("CreateEmailChange", mock.Anything, mock.MatchedBy(func(r models.EmailChangeRequest) bool {
+		return r.UserID == userID && r.NewEmail == "george.baru@kampus.ac.id" && r.TokenHash != ""
+	})).Return(nil)
+	mockAuditRepo.On("CreateAuditLog", mock.Anything, mock.Anything).Return(nil)
+
+	body, _ := json.Marshal(map[string]string{"email": " George.Baru@kampus.ac.id ", "phone": "+62 812-3456-7890"})
+	req := httptest.NewRequest("PUT", "/profile", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, _ := app.Test(req)
+	assert.Equal(t, 200, resp.StatusCode)
+
+	// Tautan konfirmasi ke alamat baru, pemberitahuan ke alamat lama
+	assert.Len(t, mailer.Sent, 2)
+	assert.Equal(t, "george.baru@kampus.ac.id", mailer.Sent[0].To)
+	assert.Equal(t, "george@kampus.ac.id", mailer.Sent[1].To)
+
+	token := strings.Fields(strings.SplitN(mailer.Sent[0].Body, "Token: ", 2)[1])[0]
+	change := models.EmailChangeRequest{ID: uuid.New(), UserID: userID, NewEmail: "george.baru@kampus.ac.id", OldEmail: current.Email}
+	mockProfileRepo.On("GetPendingEmailChangeForUpdate", mock.Anything, mock.Anything, utils.HashToken(token)).Return(change, nil)
+	mockProfileRepo.On("ConfirmEmailChange", mock.Anything, mock.Anything, change).Return(nil)
+	mockDB.ExpectBegin()
+	mockDB.ExpectCommit()
+
+	body, _ = json.Marshal(map[string]string{"token": token})
+	req = httptest.NewRequest("POST", "/profile/email/confirm", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, _ = app.Test(req)
+	assert.Equal(t, 200, resp.StatusCode)
+
+	// Audit: perubahan telepon, permintaan ganti email, dan email terganti
+	var actions []string
+	for _, call := range mockAuditRepo.Calls {
+		actions = append(actions, call.Arguments.Get(1).(models.AuditLog).Action)
+	}
+	assert.Equal(t, []string{models.AuditProfileUpdate, models.AuditEmailChangeRequested, models.AuditEmailChanged}, actions)
+	assert.NoError(t, mockDB.ExpectationsWereMet())
+	mockProfileRepo.AssertExpectations(t)
+}
+
+func photoRequest(filename string, content []byte) *http.Request {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	part, _ := writer.CreateFormFile("file", filename)
+	part.Write(content)
+	writer.Close()
+
+	req := httptest.NewRequest("POST", "/profile/photo", body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	return req
+}
+
+// exifJPEG membuat JPEG 600x400 (setengah kiri merah, kanan biru) dengan segmen EXIF
+// berisi Orientation dan teks GPS palsu
+func exifJPEG(orientation uint16) []byte {
+	img := image.NewRGBA(image.Rect(0, 0, 600, 400))
+	for y := 0; y < 400; y++ {
+		for x := 0; x < 600; x++ {
+			c := color.RGBA{R: 255, A: 255}
+			if x >= 300 {
+				c = color.RGBA{B: 255, A: 255}
+			}
+			img.Set(x, y, c)
+		}
+	}
+	var encoded bytes.Buffer
+	jpeg.Encode(&encoded, img, &jpeg.Options{Quality: 95})
+
+	tiff := []byte("II*\x00\x08\x00\x00\x00\x01\x00")
+	entry := make([]byte, 12)
+	binary.LittleEndian.PutUint16(entry[0:], 0x0112)
+	binary.LittleEndian.PutUint16(entry[2:], 3)
+	binary.LittleEndian.PutUint32(entry[4:], 1)
+	binary.LittleEndian.PutUint16(entry[8:], orientation)
+	tiff = append(tiff, entry...)
+	tiff = append(tiff, []byte("\x00\x00\x00\x00GPS -6.2,106.8")...)
+
+	segment := append([]byte("Exif\x00\x00"), tiff...)
+	app1 := []byte{0xFF, 0xE1, 0, 0}
+	binary.BigEndian.PutUint16(app1[2:], uint16(len(segment)+2))
+
+	out := append([]byte{}, encoded.Bytes()[:2]...)
+	out = append(out, app1...)
+	out = append(out, segment...)
+	return append(out, encoded.Bytes()[2:]...)
+}
+
+func TestUploadPhoto_ProcessesAndReplacesOldPhoto(t *testing.T) {
+	mockProfileRepo := new(mocks.MockProfileRepo)
+	storage := mocks.NewMockStorage()
+	profileService := services.NewProfileService(nil, mockProfileRepo, new(mocks.MockUserRepo), new(mocks.MockAuditRepo), &mocks.MockMailer{}, storage)
+
+	userID := uuid.New()
+	oldKey := "photos/" + userID.String() + "/lama_512.jpg"
+	storage.Files[oldKey] = []byte("foto lama")
+	current := models.Profile{ID: userID, PhotoURL: "/uploads/" + oldKey, PhotoThumbnails: map[string]string{"512": "/uploads/" + oldKey}}
+
+	mockProfileRepo.On("GetProfile", mock.Anything, userID).Return(current, nil)
+	mockProfileRepo.On("UpdatePhoto", mock.Anything, userID, mock.AnythingOfType("string"), mock.AnythingOfType("map[string]string")).Return(nil)
+
+	resp, _ := newProfileApp(profileService, userID).Test(photoRequest("selfie.jpg", exifJPEG(6)))
+	assert.Equal(t, 200, resp.StatusCode)
+
+	call := mockProfileRepo.Calls[len(mockProfileRepo.Calls)-1]
+	photoURL := call.Arguments.Get(2).(string)
+	thumbnails := call.Arguments.Get(3).(map[string]string)
+	assert.Len(t, thumbnails, 4)
+	assert.Equal(t, thumbnails["512"], photoURL)
+
+	// Foto lama dihapus, tersisa empat ukuran baru
+	assert.Equal(t, []string{oldKey}, storage.Deleted)
+	assert.Len(t, storage.Files, 4)
+
+	for size, side := range map[string]int{"512": 400, "64": 64} {
+		data := storage.Files[strings.TrimPrefix(thumbnails[size], "/uploads/")]
+		assert.NotContains(t, string(data), "Exif")
+		assert.NotContains(t, string(data), "GPS")
+
+		img, format, err := image.Decode(bytes.NewReader(data))
+		assert.NoError(t, err)
+		assert.Equal(t, "jpeg", format)
+		// Tidak diperbesar: sisi terpendek foto asli 400 px
+		assert.Equal(t, image.Rect(0, 0, side, side), img.Bounds())
+
+		// Orientation 6 memutar 90 derajat searah jarum jam: kiri (merah) menjadi atas
+		r, _, b, _ := img.At(side/2, 2).RGBA()
+		assert.Greater(t, r, b)
+		r, _, b, _ = img.At(side/2, side-3).RGBA()
+		assert.Greater(t, b, r)
+	}
+}
+
+func TestUploadPhoto_RejectsNonImage(t *testing.T) {
+	mockProfileRepo := new(mocks.MockProfileRepo)
+	storage := mocks.NewMockStorage()
+	profileService := services.NewProfileService(nil, mockProfileRepo, new(mocks.MockUserRepo), new(mocks.MockAuditRepo), &mocks.MockMailer{}, storage)
+
+	resp, _ := newProfileApp(profileService, uuid.New()).Test(photoRequest("foto.jpg", []byte("<?php echo 'bukan gambar'; ?>")))
+	assert.Equal(t, 400, resp.StatusCode)
+
+	var res map[string]interface{}
+	json.NewDecoder(resp.Body).Decode(&res)
+	assert.Equal(t, "format foto harus JPEG, PNG, atau GIF", res["message"])
+	assert.Empty(t, storage.Files)
+	mockProfileRepo.AssertNotCalled(t, "UpdatePhoto", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
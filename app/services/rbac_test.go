@@ -0,0 +1,130 @@
+package services_test
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+	"uas/apperror"
+	"uas/helpers"
+	"uas/middleware"
+	"uas/mocks"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newRBACApp(resolver helpers.PermissionResolver, role string, tokenPerms []string) *fiber.App {
+	app := fiber.New(fiber.Config{ErrorHandler: apperror.Handler})
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("user_id", uuid.New())
+		c.Locals("role_name", role)
+		if tokenPerms != nil {
+			c.Locals("permissions", tokenPerms)
+		}
+		return c.Next()
+	})
+	app.Get("/users", middleware.RequirePermission(resolver, "users:read"), func(c *fiber.Ctx) error {
+		return c.SendStatus(200)
+	})
+	return app
+}
+
+func TestRequirePermission_CachesPerRole(t *testing.T) {
+	mockRepo := new(mocks.MockPermissionRepo)
+	mockRepo.On("GetPermissionNamesByRole", mock.Anything, "Admin").Return([]string{"users:read"}, nil).Once()
+
+	resolver := helpers.NewPermissionResolver(mockRepo, time.Minute)
+	app := newRBACApp(resolver, "Admin", nil)
+
+	for i := 0; i < 3; i++ {
+		resp, _ := app.Test(httptest.NewRequest("GET", "/users", nil))
+		assert.Equal(t, 200, resp.StatusCode)
+	}
+
+	// Hanya satu query ke database untuk tiga request
+	mockRepo.AssertNumberOfCalls(t, "GetPermissionNamesByRole", 1)
+}
+
+func TestRequirePermission_InvalidateReloads(t *testing.T) {
+	mockRepo := new(mocks.MockPermissionRepo)
+	mockRepo.On("GetPermissionNamesByRole", mock.Anything, "Mahasiswa").Return([]string{"users:read"}, nil).Once()
+	mockRepo.On("GetPermissionNamesByRole", mock.Anything, "Mahasiswa").Return([]string{}, nil).Once()
+
+	resolver := helpers.NewPermissionResolver(mockRepo, time.Hour)
+	app := newRBACApp(resolver, "Mahasiswa", nil)
+
+	resp, _ := app.Test(httptest.NewRequest("GET", "/users", nil))
+	assert.Equal(t, 200, resp.StatusCode)
+
+	resolver.Invalidate("Mahasiswa")
+
+	resp, _ = app.Test(httptest.NewRequest("GET", "/users", nil))
+	assert.Equal(t, 403, resp.StatusCode)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestRequirePermission_TokenFastPath(t *testing.T) {
+	mockRepo := new(mocks.MockPermissionRepo)
+	resolver := helpers.NewPermissionResolver(mockRepo, time.Minute)
+
+	resp, _ := newRBACApp(resolver, "Admin", []string{"users:read"}).Test(httptest.NewRequest("GET", "/users", nil))
+	assert.Equal(t, 200, resp.StatusCode)
+
+	resp, _ = newRBACApp(resolver, "Admin", []string{"reports:read"}).Test(httptest.NewRequest("GET", "/users", nil))
+	assert.Equal(t, 403, resp.StatusCode)
+
+	mockRepo.AssertNotCalled(t, "GetPermissionNamesByRole", mock.Anything, mock.Anything)
+}
+
+func TestRequirePermission_StaleTokenPermissionsUseResolver(t *testing.T) {
+	mockRepo := new(mocks.MockPermissionRepo)
+	mockRepo.On("GetPermissionNamesByRole", mock.Anything, "Dosen Wali").Return([]string{}, nil).Once()
+	resolver := helpers.NewPermissionResolver(mockRepo, time.Minute)
+
+	app := fiber.New(fiber.Config{ErrorHandler: apperror.Handler})
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("user_id", uuid.New())
+		c.Locals("role_name", "Dosen Wali")
+		c.Locals("permissions", []string{"users:read"})
+		c.Locals("token_issued_at", time.Now().Add(-time.Minute))
+		return c.Next()
+	})
+	app.Get("/users", middleware.RequirePermission(resolver, "users:read"), func(c *fiber.Ctx) error {
+		return c.SendStatus(200)
+	})
+
+	resp, _ := app.Test(httptest.NewRequest("GET", "/users", nil))
+	assert.Equal(t, 200, resp.StatusCode)
+
+	// permission role dicabut setelah token terbit: permission di token tidak dipercaya lagi
+	resolver.Invalidate("Dosen Wali")
+
+	resp, _ = app.Test(httptest.NewRequest("GET", "/users", nil))
+	assert.Equal(t, 403, resp.StatusCode)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestRequirePermission_UserChangedAfterTokenIssued(t *testing.T) {
+	resolver := helpers.NewPermissionResolver(new(mocks.MockPermissionRepo), time.Minute)
+	userID := uuid.New()
+
+	app := fiber.New(fiber.Config{ErrorHandler: apperror.Handler})
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("user_id", userID)
+		c.Locals("role_name", "Admin")
+		c.Locals("permissions", []string{"users:read"})
+		c.Locals("token_issued_at", time.Now().Add(-time.Minute))
+		return c.Next()
+	})
+	app.Get("/users", middleware.RequirePermission(resolver, "users:read"), func(c *fiber.Ctx) error {
+		return c.SendStatus(200)
+	})
+
+	// role user diganti/dinonaktifkan: token lama harus di-refresh
+	resolver.InvalidateUser(userID)
+
+	resp, _ := app.Test(httptest.NewRequest("GET", "/users", nil))
+	assert.Equal(t, 401, resp.StatusCode)
+}
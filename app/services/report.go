@@ -1,11 +1,15 @@
 package services
 
 import (
+	"database/sql"
 	"uas/app/models"
 	"uas/app/repository"
-	"uas/helpers"
+	"uas/apperror"
+	"uas/i18n"
+	"uas/policy"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
 )
 
 type ReportService interface {
@@ -16,80 +20,131 @@ type ReportService interface {
 type reportService struct {
 	reportRepo      repository.ReportRepository
 	achievementRepo repository.AchievementRepository
+	access          *policy.Engine
 }
 
 func NewReportService(reportRepo repository.ReportRepository, achievementRepo repository.AchievementRepository) ReportService {
 	return &reportService{
 		reportRepo:      reportRepo,
 		achievementRepo: achievementRepo,
+		access:          policy.NewEngine(achievementRepo),
 	}
 }
 
+// resolvePeriod membaca filter opsional ?period_id=
+func (s *reportService) resolvePeriod(c *fiber.Ctx) (*models.AcademicPeriod, error) {
+	periodID, valid := optionalUUIDQuery(c, "period_id")
+	if !valid {
+		return nil, invalidID("period_id")
+	}
+	if periodID == nil {
+		return nil, nil
+	}
+
+	period, err := s.reportRepo.GetAcademicPeriodByID(c.Context(), *periodID)
+	if err == sql.ErrNoRows {
+		return nil, apperror.NotFound("period_not_found")
+	} else if err != nil {
+		return nil, apperror.Internal(err)
+	}
+	return &period, nil
+}
+
+// periodID mengembalikan id periode untuk filter repository (nil berarti semua periode)
+func periodID(period *models.AcademicPeriod) *uuid.UUID {
+	if period == nil {
+		return nil
+	}
+	return &period.ID
+}
+
 // GetSystemStatistics godoc
 // @Summary      Dashboard Statistik
-// @Description  Menampilkan ringkasan jumlah prestasi berdasarkan status (Draft, Verified, Rejected). Admin & Dosen Wali Only.
+// @Description  Menampilkan ringkasan jumlah prestasi berdasarkan status (Draft, Verified, Rejected). Dengan group_by, ringkasan juga dipecah per fakultas, department, atau program studi mahasiswa. Dengan period_id, hanya prestasi yang tanggal kegiatannya masuk periode akademik tersebut yang dihitung. Admin & Dosen Wali Only.
 // @Tags         Reports
 // @Accept       json
 // @Produce      json
 // @Security     Bearer
+// @Param        group_by   query     string  false  "faculty | department | program_study"
+// @Param        period_id  query     string  false  "Academic Period ID (UUID)"
 // @Success      200  {object}  models.DashboardStatistics
-// @Failure      403  {object}  map[string]string "Akses Ditolak"
-// @Failure      500  {object}  map[string]string
+// @Failure      400  {object}  apperror.Problem "group_by / period_id tidak valid"
+// @Failure      403  {object}  apperror.Problem "Akses Ditolak"
+// @Failure      404  {object}  apperror.Problem "Periode akademik tidak ditemukan"
+// @Failure      500  {object}  apperror.Problem
 // @Router       /reports/statistics [get]
 func (s *reportService) GetSystemStatistics(c *fiber.Ctx) error {
 	roleName := c.Locals("role_name").(string)
 
 	if roleName != "Admin" && roleName != "Dosen Wali" {
-		return c.Status(403).JSON(fiber.Map{"message": "Akses ditolak"})
+		return apperror.Forbidden("access_denied")
+	}
+
+	groupBy := c.Query("group_by")
+	switch groupBy {
+	case "", models.GroupByFaculty, models.GroupByDepartment, models.GroupByProgramStudy:
+	default:
+		return apperror.BadRequest("invalid_group_by")
 	}
 
-	stats, err := s.reportRepo.GetStatistics(c.Context())
+	period, err := s.resolvePeriod(c)
 	if err != nil {
-		return c.Status(500).JSON(fiber.Map{"message": "Gagal mengambil statistik"})
+		return err
 	}
 
+	stats, err := s.reportRepo.GetStatistics(c.Context(), periodID(period))
+	if err != nil {
+		return apperror.Internal(err)
+	}
+
+	if groupBy != "" {
+		groups, err := s.reportRepo.GetStatisticsByUnit(c.Context(), groupBy, periodID(period))
+		if err != nil {
+			return apperror.Internal(err)
+		}
+		stats.GroupBy = groupBy
+		stats.Groups = groups
+	}
+	stats.Period = period
+
 	return c.JSON(fiber.Map{"success": true, "data": stats})
 }
 
 // GetStudentReport godoc
 // @Summary      Rapor Prestasi Mahasiswa (Transkrip)
-// @Description  Menampilkan profil, total poin (SKP), dan daftar prestasi verified mahasiswa. Mahasiswa hanya bisa lihat punya sendiri. Dosen Wali hanya anak bimbingan.
+// @Description  Menampilkan profil, total poin (SKP), dan daftar prestasi verified mahasiswa. Dengan period_id, hanya prestasi pada periode akademik tersebut. Mahasiswa hanya bisa lihat punya sendiri. Dosen Wali hanya anak bimbingan.
 // @Tags         Reports
 // @Accept       json
 // @Produce      json
 // @Security     Bearer
-// @Param        id   path      string  true  "Student ID (UUID)"
+// @Param        id         path      string  true   "Student ID (UUID)"
+// @Param        period_id  query     string  false  "Academic Period ID (UUID)"
 // @Success      200  {object}  models.StudentReportResponse
-// @Failure      403  {object}  map[string]string "Bukan hak akses anda"
-// @Failure      404  {object}  map[string]string "Mahasiswa tidak ditemukan"
-// @Failure      500  {object}  map[string]string
+// @Failure      400  {object}  apperror.Problem "period_id tidak valid"
+// @Failure      403  {object}  apperror.Problem "Bukan hak akses anda"
+// @Failure      404  {object}  apperror.Problem "Mahasiswa tidak ditemukan"
+// @Failure      500  {object}  apperror.Problem
 // @Router       /reports/student/{id} [get]
 func (s *reportService) GetStudentReport(c *fiber.Ctx) error {
 	targetStudentID := c.Params("id")
-	requesterID, _ := helpers.GetUserIDFromContext(c)
-	requesterRole := c.Locals("role_name").(string)
 
-	if requesterRole == "Mahasiswa" {
-		myStudentID, _ := s.achievementRepo.GetStudentIDByUserID(c.Context(), requesterID)
-		if myStudentID != targetStudentID {
-			return c.Status(403).JSON(fiber.Map{"message": "Akses ditolak"})
-		}
-	} else if requesterRole == "Dosen Wali" {
-		lecturerID, _ := s.achievementRepo.GetLecturerIDByUserID(c.Context(), requesterID)
-		isAdvisor, _ := s.achievementRepo.CheckStudentAdvisorRelationship(c.Context(), lecturerID, targetStudentID)
-		if !isAdvisor {
-			return c.Status(403).JSON(fiber.Map{"message": "Bukan mahasiswa bimbingan Anda"})
-		}
+	if err := authorizeRequest(c, s.access, policy.ActionRead, policy.StudentReport(targetStudentID)); err != nil {
+		return err
+	}
+
+	period, err := s.resolvePeriod(c)
+	if err != nil {
+		return err
 	}
 
 	profile, err := s.reportRepo.GetStudentProfile(c.Context(), targetStudentID)
 	if err != nil {
-		return c.Status(404).JSON(fiber.Map{"message": "Mahasiswa tidak ditemukan"})
+		return apperror.NotFound("student_not_found")
     }
 
-	refs, err := s.reportRepo.GetVerifiedAchievementsByStudentID(c.Context(), targetStudentID)
+	refs, err := s.reportRepo.GetVerifiedAchievementsByStudentID(c.Context(), targetStudentID, periodID(period))
 	if err != nil {
-		return c.Status(500).JSON(fiber.Map{"message": "Gagal mengambil data prestasi"})
+		return apperror.Internal(err)
 	}
 
 	var mongoIDs []string
@@ -104,10 +159,12 @@ func (s *reportService) GetStudentReport(c *fiber.Ctx) error {
 	for _, ref := range refs {
 		detail, ok := mongoDocs[ref.MongoAchievementID]
 		item := models.AchievementResponse{
-			ID:        ref.ID,
-			Status:    ref.Status,
-			CreatedAt: ref.CreatedAt,
-            Title:     "[Data Corrupt]",
+			ID:         ref.ID,
+			Status:     ref.Status,
+			EventDate:  ref.EventDate,
+			PeriodName: ref.PeriodName,
+			CreatedAt:  ref.CreatedAt,
+            Title:      i18n.Message(c, "report.corrupt_title"),
 		}
 		if ok {
 			item.Title = detail.Title
@@ -125,6 +182,7 @@ func (s *reportService) GetStudentReport(c *fiber.Ctx) error {
 		"success": true,
 		"data": models.StudentReportResponse{
 			Profile:      profile,
+			Period:       period,
 			Achievements: achievementList,
 		},
 	})
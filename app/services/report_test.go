@@ -1,14 +1,17 @@
 package services_test
 
 import (
+	"database/sql"
 	"errors"
 	"net/http/httptest"
 	"testing"
 	"uas/app/models"
 	"uas/app/services"
+	"uas/apperror"
 	"uas/mocks"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -31,10 +34,10 @@ func TestGetSystemStatistics_Success(t *testing.T) {
 	// 3. LATIH STUNTMAN
 	// "Eh Repo, kalau kamu dipanggil fungsi GetStatistics, 
 	// tolong balikin data dummyStats dan errornya nil ya!"
-	mockRepo.On("GetStatistics", mock.Anything).Return(dummyStats, nil)
+	mockRepo.On("GetStatistics", mock.Anything, (*uuid.UUID)(nil)).Return(dummyStats, nil)
 
 	// 4. SETUP FIBER (Pura-pura jadi Server)
-	app := fiber.New()
+	app := fiber.New(fiber.Config{ErrorHandler: apperror.Handler})
 	
 	// Kita pasang middleware buat nipu c.Locals ("role_name")
 	// Seolah-olah yang login adalah Admin
@@ -65,7 +68,7 @@ func TestGetSystemStatistics_Forbidden(t *testing.T) {
 	mockRepo := new(mocks.MockReportRepo)
 	reportService := services.NewReportService(mockRepo, nil)
 
-	app := fiber.New()
+	app := fiber.New(fiber.Config{ErrorHandler: apperror.Handler})
 	// Nipu Locals jadi "Mahasiswa"
 	app.Use(func(c *fiber.Ctx) error {
 		c.Locals("role_name", "Mahasiswa") 
@@ -90,9 +93,9 @@ func TestGetSystemStatistics_DBError(t *testing.T) {
 	reportService := services.NewReportService(mockRepo, nil)
 
 	// Latih Stuntman buat balikin Error
-	mockRepo.On("GetStatistics", mock.Anything).Return(models.DashboardStatistics{}, errors.New("database mati"))
+	mockRepo.On("GetStatistics", mock.Anything, (*uuid.UUID)(nil)).Return(models.DashboardStatistics{}, errors.New("database mati"))
 
-	app := fiber.New()
+	app := fiber.New(fiber.Config{ErrorHandler: apperror.Handler})
 	app.Use(func(c *fiber.Ctx) error {
 		c.Locals("role_name", "Admin")
 		return c.Next()
@@ -104,4 +107,54 @@ func TestGetSystemStatistics_DBError(t *testing.T) {
 
 	// Harapannya: Error Server (500)
 	assert.Equal(t, 500, resp.StatusCode)
-}
\ No newline at end of file
+}
+func TestGetSystemStatistics_GroupByProgramStudy(t *testing.T) {
+	mockRepo := new(mocks.MockReportRepo)
+	reportService := services.NewReportService(mockRepo, nil)
+
+	mockRepo.On("GetStatistics", mock.Anything, (*uuid.UUID)(nil)).Return(models.DashboardStatistics{TotalPrestasi: 3}, nil)
+	mockRepo.On("GetStatisticsByUnit", mock.Anything, models.GroupByProgramStudy, (*uuid.UUID)(nil)).Return([]models.UnitStatistics{
+		{Name: "Teknik Informatika", Total: 3, ByStatus: map[string]int64{"verified": 3}},
+	}, nil)
+
+	app := fiber.New(fiber.Config{ErrorHandler: apperror.Handler})
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("role_name", "Admin")
+		return c.Next()
+	})
+	app.Get("/stats", reportService.GetSystemStatistics)
+
+	resp, _ := app.Test(httptest.NewRequest("GET", "/stats?group_by=program_study", nil))
+	assert.Equal(t, 200, resp.StatusCode)
+
+	resp, _ = app.Test(httptest.NewRequest("GET", "/stats?group_by=angkatan", nil))
+	assert.Equal(t, 400, resp.StatusCode)
+	mockRepo.AssertNumberOfCalls(t, "GetStatisticsByUnit", 1)
+}
+
+func TestGetSystemStatistics_PeriodFilter(t *testing.T) {
+	mockRepo := new(mocks.MockReportRepo)
+	reportService := services.NewReportService(mockRepo, nil)
+
+	periodID, unknownID := uuid.New(), uuid.New()
+	mockRepo.On("GetAcademicPeriodByID", mock.Anything, periodID).Return(models.AcademicPeriod{ID: periodID, Name: "Semester Ganjil 2025/2026"}, nil)
+	mockRepo.On("GetAcademicPeriodByID", mock.Anything, unknownID).Return(models.AcademicPeriod{}, sql.ErrNoRows)
+	mockRepo.On("GetStatistics", mock.Anything, &periodID).Return(models.DashboardStatistics{TotalPrestasi: 2}, nil).Once()
+
+	app := fiber.New(fiber.Config{ErrorHandler: apperror.Handler})
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("role_name", "Admin")
+		return c.Next()
+	})
+	app.Get("/stats", reportService.GetSystemStatistics)
+
+	resp, _ := app.Test(httptest.NewRequest("GET", "/stats?period_id=semester-ganjil", nil))
+	assert.Equal(t, 400, resp.StatusCode)
+
+	resp, _ = app.Test(httptest.NewRequest("GET", "/stats?period_id="+unknownID.String(), nil))
+	assert.Equal(t, 404, resp.StatusCode)
+
+	resp, _ = app.Test(httptest.NewRequest("GET", "/stats?period_id="+periodID.String(), nil))
+	assert.Equal(t, 200, resp.StatusCode)
+	mockRepo.AssertExpectations(t)
+}
@@ -0,0 +1,558 @@
+package services
+
+import (
+	"database/sql"
+	"strings"
+	"time"
+	"uas/app/models"
+	"uas/app/repository"
+	"uas/apperror"
+	"uas/helpers"
+	"uas/i18n"
+	"uas/validation"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type RoleService interface {
+	GetRoles(c *fiber.Ctx) error
+	GetRoleByID(c *fiber.Ctx) error
+	CreateRole(c *fiber.Ctx) error
+	UpdateRole(c *fiber.Ctx) error
+	DeleteRole(c *fiber.Ctx) error
+	GetPermissions(c *fiber.Ctx) error
+	CreatePermission(c *fiber.Ctx) error
+	UpdatePermission(c *fiber.Ctx) error
+	SetRolePermissions(c *fiber.Ctx) error
+	GrantPermission(c *fiber.Ctx) error
+	RevokePermission(c *fiber.Ctx) error
+	GetPermissionMatrix(c *fiber.Ctx) error
+}
+
+type roleService struct {
+	repo        repository.RoleRepository
+	permissions helpers.PermissionResolver
+}
+
+func NewRoleService(repo repository.RoleRepository, permissions helpers.PermissionResolver) RoleService {
+	return &roleService{repo: repo, permissions: permissions}
+}
+
+func isDuplicateKey(err error) bool {
+	return err != nil && strings.Contains(err.Error(), "duplicate key")
+}
+
+// GetRoles godoc
+// @Summary      Ambil Semua Role
+// @Description  Mengambil daftar role beserta penanda role sistem (Admin Only).
+// @Tags         Roles
+// @Accept       json
+// @Produce      json
+// @Security     Bearer
+// @Success      200  {object}  map[string][]models.Role
+// @Failure      500  {object}  apperror.Problem
+// @Router       /roles [get]
+func (s *roleService) GetRoles(c *fiber.Ctx) error {
+	roles, err := s.repo.GetAllRoles(c.Context())
+	if err != nil {
+		return apperror.Internal(err)
+	}
+
+	return c.JSON(fiber.Map{
+		"message": i18n.Message(c, "messages.roles_fetched"),
+		"success": true,
+		"data":    roles,
+	})
+}
+
+// GetRoleByID godoc
+// @Summary      Detail Role
+// @Description  Mengambil detail role beserta daftar permission yang dimiliki.
+// @Tags         Roles
+// @Accept       json
+// @Produce      json
+// @Security     Bearer
+// @Param        id   path      string  true  "Role ID (UUID)"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      400  {object}  apperror.Problem
+// @Failure      404  {object}  apperror.Problem
+// @Router       /roles/{id} [get]
+func (s *roleService) GetRoleByID(c *fiber.Ctx) error {
+	roleID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return invalidID("Role ID")
+	}
+
+	role, err := s.repo.GetRoleByID(c.Context(), roleID)
+	if err == sql.ErrNoRows {
+		return apperror.NotFound("role_not_found")
+	} else if err != nil {
+		return apperror.Internal(err)
+	}
+
+	perms, err := s.repo.GetRolePermissions(c.Context(), roleID)
+	if err != nil {
+		return apperror.Internal(err)
+	}
+
+	return c.JSON(fiber.Map{
+		"message": i18n.Message(c, "messages.role_found"),
+		"success": true,
+		"data": fiber.Map{
+			"role":        role,
+			"permissions": perms,
+		},
+	})
+}
+
+// CreateRole godoc
+// @Summary      Tambah Role
+// @Description  Membuat role baru (bukan role sistem).
+// @Tags         Roles
+// @Accept       json
+// @Produce      json
+// @Security     Bearer
+// @Param        request body models.RoleRequest true "Data Role"
+// @Success      201  {object}  models.Role
+// @Failure      400  {object}  apperror.Problem
+// @Failure      409  {object}  apperror.Problem "Nama role sudah dipakai"
+// @Failure      422  {object}  apperror.Problem "Data tidak valid (errors berisi pesan per field)"
+// @Router       /roles [post]
+func (s *roleService) CreateRole(c *fiber.Ctx) error {
+	var req models.RoleRequest
+	if err := bindBody(c, &req); err != nil {
+		return err
+	}
+
+	role := models.Role{
+		ID:          uuid.New(),
+		Name:        strings.TrimSpace(req.Name),
+		Description: req.Description,
+		CreatedAt:   time.Now(),
+	}
+
+	if err := s.repo.CreateRole(c.Context(), role); err != nil {
+		if isDuplicateKey(err) {
+			return apperror.Conflict("role_name_taken")
+		}
+		return apperror.Internal(err)
+	}
+
+	s.permissions.Invalidate(role.Name)
+
+	return c.Status(201).JSON(fiber.Map{
+		"message": i18n.Message(c, "messages.role_created"),
+		"success": true,
+		"data":    role,
+	})
+}
+
+// UpdateRole godoc
+// @Summary      Update Role
+// @Description  Mengubah nama/deskripsi role. Nama role sistem tidak bisa diubah.
+// @Tags         Roles
+// @Accept       json
+// @Produce      json
+// @Security     Bearer
+// @Param        id       path  string             true  "Role ID (UUID)"
+// @Param        request  body  models.RoleRequest true  "Data Role"
+// @Success      200  {object}  models.Role
+// @Failure      400  {object}  apperror.Problem
+// @Failure      403  {object}  apperror.Problem "Role sistem"
+// @Failure      404  {object}  apperror.Problem
+// @Failure      422  {object}  apperror.Problem "Data tidak valid (errors berisi pesan per field)"
+// @Router       /roles/{id} [put]
+func (s *roleService) UpdateRole(c *fiber.Ctx) error {
+	roleID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return invalidID("Role ID")
+	}
+
+	var req models.RoleRequest
+	if err := bindBody(c, &req); err != nil {
+		return err
+	}
+
+	role, err := s.repo.GetRoleByID(c.Context(), roleID)
+	if err == sql.ErrNoRows {
+		return apperror.NotFound("role_not_found")
+	} else if err != nil {
+		return apperror.Internal(err)
+	}
+
+	oldName := role.Name
+	newName := strings.TrimSpace(req.Name)
+	if role.IsSystem && newName != oldName {
+		return apperror.Forbidden("system_role_immutable").Variant("rename")
+	}
+
+	role.Name = newName
+	role.Description = req.Description
+
+	if err := s.repo.UpdateRole(c.Context(), role); err != nil {
+		if isDuplicateKey(err) {
+			return apperror.Conflict("role_name_taken")
+		}
+		return apperror.Internal(err)
+	}
+
+	s.permissions.Invalidate(oldName)
+	s.permissions.Invalidate(newName)
+
+	return c.JSON(fiber.Map{
+		"message": i18n.Message(c, "messages.role_updated"),
+		"success": true,
+		"data":    role,
+	})
+}
+
+// DeleteRole godoc
+// @Summary      Hapus Role
+// @Description  Menghapus role non-sistem yang tidak sedang dipakai user mana pun.
+// @Tags         Roles
+// @Accept       json
+// @Produce      json
+// @Security     Bearer
+// @Param        id   path      string  true  "Role ID (UUID)"
+// @Success      200  {object}  map[string]string
+// @Failure      403  {object}  apperror.Problem "Role sistem"
+// @Failure      404  {object}  apperror.Problem
+// @Failure      409  {object}  apperror.Problem "Masih dipakai user"
+// @Router       /roles/{id} [delete]
+func (s *roleService) DeleteRole(c *fiber.Ctx) error {
+	roleID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return invalidID("Role ID")
+	}
+
+	role, err := s.repo.GetRoleByID(c.Context(), roleID)
+	if err == sql.ErrNoRows {
+		return apperror.NotFound("role_not_found")
+	} else if err != nil {
+		return apperror.Internal(err)
+	}
+
+	if role.IsSystem {
+		return apperror.Forbidden("system_role_immutable").Variant("delete")
+	}
+
+	userCount, err := s.repo.CountUsersByRole(c.Context(), roleID)
+	if err != nil {
+		return apperror.Internal(err)
+	}
+	if userCount > 0 {
+		return apperror.Conflict("role_in_use").With("user_count", userCount)
+	}
+
+	if err := s.repo.DeleteRole(c.Context(), roleID); err != nil {
+		return apperror.Internal(err)
+	}
+
+	s.permissions.Invalidate(role.Name)
+
+	return c.JSON(fiber.Map{
+		"message": i18n.Message(c, "messages.role_deleted"),
+		"success": true,
+	})
+}
+
+// GetPermissions godoc
+// @Summary      Ambil Semua Permission
+// @Description  Mengambil daftar seluruh permission (resource:action).
+// @Tags         Roles
+// @Accept       json
+// @Produce      json
+// @Security     Bearer
+// @Success      200  {object}  map[string][]models.Permission
+// @Failure      500  {object}  apperror.Problem
+// @Router       /permissions [get]
+func (s *roleService) GetPermissions(c *fiber.Ctx) error {
+	perms, err := s.repo.GetAllPermissions(c.Context())
+	if err != nil {
+		return apperror.Internal(err)
+	}
+
+	return c.JSON(fiber.Map{
+		"message": i18n.Message(c, "messages.permissions_fetched"),
+		"success": true,
+		"data":    perms,
+	})
+}
+
+// CreatePermission godoc
+// @Summary      Tambah Permission
+// @Description  Membuat permission baru. Nama otomatis 'resource:action'.
+// @Tags         Roles
+// @Accept       json
+// @Produce      json
+// @Security     Bearer
+// @Param        request body models.PermissionRequest true "Data Permission"
+// @Success      201  {object}  models.Permission
+// @Failure      400  {object}  apperror.Problem
+// @Failure      409  {object}  apperror.Problem
+// @Failure      422  {object}  apperror.Problem "Data tidak valid (errors berisi pesan per field)"
+// @Router       /permissions [post]
+func (s *roleService) CreatePermission(c *fiber.Ctx) error {
+	var req models.PermissionRequest
+	if err := bindBody(c, &req); err != nil {
+		return err
+	}
+
+	resource := strings.ToLower(strings.TrimSpace(req.Resource))
+	action := strings.ToLower(strings.TrimSpace(req.Action))
+	var missing []validation.FieldError
+	if resource == "" {
+		missing = append(missing, validation.Field("resource", "required", "validation.required"))
+	}
+	if action == "" {
+		missing = append(missing, validation.Field("action", "required", "validation.required"))
+	}
+	if len(missing) > 0 {
+		return validationError(missing...)
+	}
+
+	perm := models.Permission{
+		ID:          uuid.New(),
+		Name:        resource + ":" + action,
+		Resource:    resource,
+		Action:      action,
+		Description: req.Description,
+	}
+
+	if err := s.repo.CreatePermission(c.Context(), perm); err != nil {
+		if isDuplicateKey(err) {
+			return apperror.Conflict("permission_exists")
+		}
+		return apperror.Internal(err)
+	}
+
+	return c.Status(201).JSON(fiber.Map{
+		"message": i18n.Message(c, "messages.permission_created"),
+		"success": true,
+		"data":    perm,
+	})
+}
+
+// UpdatePermission godoc
+// @Summary      Update Permission
+// @Description  Mengubah deskripsi permission. Nama (resource:action) tidak bisa diubah karena dipakai langsung oleh route.
+// @Tags         Roles
+// @Accept       json
+// @Produce      json
+// @Security     Bearer
+// @Param        id       path  string                   true  "Permission ID (UUID)"
+// @Param        request  body  models.PermissionRequest true  "Data Permission"
+// @Success      200  {object}  models.Permission
+// @Failure      400  {object}  apperror.Problem
+// @Failure      404  {object}  apperror.Problem
+// @Failure      422  {object}  apperror.Problem "Data tidak valid (errors berisi pesan per field)"
+// @Router       /permissions/{id} [put]
+func (s *roleService) UpdatePermission(c *fiber.Ctx) error {
+	permissionID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return invalidID("Permission ID")
+	}
+
+	var req models.PermissionRequest
+	if err := bindBody(c, &req); err != nil {
+		return err
+	}
+
+	perm, err := s.repo.GetPermissionByID(c.Context(), permissionID)
+	if err == sql.ErrNoRows {
+		return apperror.NotFound("permission_not_found")
+	} else if err != nil {
+		return apperror.Internal(err)
+	}
+
+	if (req.Resource != "" && req.Resource != perm.Resource) || (req.Action != "" && req.Action != perm.Action) {
+		return apperror.BadRequest("permission_immutable")
+	}
+
+	perm.Description = req.Description
+	if err := s.repo.UpdatePermission(c.Context(), perm); err != nil {
+		return apperror.Internal(err)
+	}
+
+	s.permissions.InvalidateAll()
+
+	return c.JSON(fiber.Map{
+		"message": i18n.Message(c, "messages.permission_updated"),
+		"success": true,
+		"data":    perm,
+	})
+}
+
+// SetRolePermissions godoc
+// @Summary      Atur Permission Role
+// @Description  Mengganti seluruh permission milik role dengan daftar baru.
+// @Tags         Roles
+// @Accept       json
+// @Produce      json
+// @Security     Bearer
+// @Param        id       path  string                          true  "Role ID (UUID)"
+// @Param        request  body  models.AssignPermissionsRequest true  "Daftar Permission ID"
+// @Success      200  {object}  map[string][]models.Permission
+// @Failure      400  {object}  apperror.Problem
+// @Failure      404  {object}  apperror.Problem
+// @Failure      422  {object}  apperror.Problem "Data tidak valid (errors berisi pesan per field)"
+// @Router       /roles/{id}/permissions [put]
+func (s *roleService) SetRolePermissions(c *fiber.Ctx) error {
+	roleID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return invalidID("Role ID")
+	}
+
+	var req models.AssignPermissionsRequest
+	if err := bindBody(c, &req); err != nil {
+		return err
+	}
+
+	permissionIDs := make([]uuid.UUID, 0, len(req.PermissionIDs))
+	for _, raw := range req.PermissionIDs {
+		id, _ := uuid.Parse(strings.TrimSpace(raw))
+		permissionIDs = append(permissionIDs, id)
+	}
+
+	role, err := s.repo.GetRoleByID(c.Context(), roleID)
+	if err == sql.ErrNoRows {
+		return apperror.NotFound("role_not_found")
+	} else if err != nil {
+		return apperror.Internal(err)
+	}
+
+	if err := s.repo.SetRolePermissions(c.Context(), roleID, permissionIDs); err != nil {
+		if strings.Contains(err.Error(), "foreign key") {
+			return apperror.BadRequest("permission_not_found").Variant("some")
+		}
+		return apperror.Internal(err)
+	}
+
+	s.permissions.Invalidate(role.Name)
+
+	perms, _ := s.repo.GetRolePermissions(c.Context(), roleID)
+	return c.JSON(fiber.Map{
+		"message": i18n.Message(c, "messages.role_permissions_updated"),
+		"success": true,
+		"data":    perms,
+	})
+}
+
+// GrantPermission godoc
+// @Summary      Tambah Satu Permission ke Role
+// @Tags         Roles
+// @Produce      json
+// @Security     Bearer
+// @Param        id            path  string  true  "Role ID (UUID)"
+// @Param        permissionId  path  string  true  "Permission ID (UUID)"
+// @Success      200  {object}  map[string]string
+// @Failure      400  {object}  apperror.Problem
+// @Failure      404  {object}  apperror.Problem
+// @Router       /roles/{id}/permissions/{permissionId} [post]
+func (s *roleService) GrantPermission(c *fiber.Ctx) error {
+	return s.changeRolePermission(c, true)
+}
+
+// RevokePermission godoc
+// @Summary      Cabut Satu Permission dari Role
+// @Tags         Roles
+// @Produce      json
+// @Security     Bearer
+// @Param        id            path  string  true  "Role ID (UUID)"
+// @Param        permissionId  path  string  true  "Permission ID (UUID)"
+// @Success      200  {object}  map[string]string
+// @Failure      400  {object}  apperror.Problem
+// @Failure      404  {object}  apperror.Problem
+// @Router       /roles/{id}/permissions/{permissionId} [delete]
+func (s *roleService) RevokePermission(c *fiber.Ctx) error {
+	return s.changeRolePermission(c, false)
+}
+
+func (s *roleService) changeRolePermission(c *fiber.Ctx, grant bool) error {
+	roleID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return invalidID("Role ID")
+	}
+
+	permissionID, err := uuid.Parse(c.Params("permissionId"))
+	if err != nil {
+		return invalidID("Permission ID")
+	}
+
+	role, err := s.repo.GetRoleByID(c.Context(), roleID)
+	if err == sql.ErrNoRows {
+		return apperror.NotFound("role_not_found")
+	} else if err != nil {
+		return apperror.Internal(err)
+	}
+
+	if _, err := s.repo.GetPermissionByID(c.Context(), permissionID); err == sql.ErrNoRows {
+		return apperror.NotFound("permission_not_found")
+	} else if err != nil {
+		return apperror.Internal(err)
+	}
+
+	message := i18n.Message(c, "messages.role_permission_added")
+	if grant {
+		err = s.repo.GrantPermission(c.Context(), roleID, permissionID)
+	} else {
+		err = s.repo.RevokePermission(c.Context(), roleID, permissionID)
+		message = i18n.Message(c, "messages.role_permission_removed")
+	}
+	if err != nil {
+		return apperror.Internal(err)
+	}
+
+	s.permissions.Invalidate(role.Name)
+
+	return c.JSON(fiber.Map{
+		"message": message,
+		"success": true,
+	})
+}
+
+// GetPermissionMatrix godoc
+// @Summary      Matriks Role x Permission
+// @Description  Tabel seluruh permission dengan penanda role mana saja yang memilikinya (untuk tampilan admin).
+// @Tags         Roles
+// @Accept       json
+// @Produce      json
+// @Security     Bearer
+// @Success      200  {object}  models.PermissionMatrix
+// @Failure      500  {object}  apperror.Problem
+// @Router       /roles/matrix [get]
+func (s *roleService) GetPermissionMatrix(c *fiber.Ctx) error {
+	roles, err := s.repo.GetAllRoles(c.Context())
+	if err != nil {
+		return apperror.Internal(err)
+	}
+
+	perms, err := s.repo.GetAllPermissions(c.Context())
+	if err != nil {
+		return apperror.Internal(err)
+	}
+
+	pairs, err := s.repo.GetAllRolePermissionPairs(c.Context())
+	if err != nil {
+		return apperror.Internal(err)
+	}
+
+	matrix := models.PermissionMatrix{Roles: roles, Rows: []models.PermissionMatrixRow{}}
+	for _, perm := range perms {
+		row := models.PermissionMatrixRow{Permission: perm, Roles: make(map[string]bool, len(roles))}
+		for _, role := range roles {
+			row.Roles[role.ID.String()] = false
+		}
+		for _, roleID := range pairs[perm.ID] {
+			row.Roles[roleID.String()] = true
+		}
+		matrix.Rows = append(matrix.Rows, row)
+	}
+
+	return c.JSON(fiber.Map{
+		"message": i18n.Message(c, "messages.permission_matrix_fetched"),
+		"success": true,
+		"data":    matrix,
+	})
+}
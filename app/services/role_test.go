@@ -0,0 +1,74 @@
+package services_test
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+	"uas/app/models"
+	"uas/app/services"
+	"uas/apperror"
+	"uas/helpers"
+	"uas/mocks"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestDeleteRole_SystemRoleForbidden(t *testing.T) {
+	mockRepo := new(mocks.MockRoleRepo)
+	resolver := helpers.NewPermissionResolver(new(mocks.MockPermissionRepo), time.Minute)
+	roleService := services.NewRoleService(mockRepo, resolver)
+	app := fiber.New(fiber.Config{ErrorHandler: apperror.Handler})
+	app.Delete("/roles/:id", roleService.DeleteRole)
+
+	roleID := uuid.New()
+	mockRepo.On("GetRoleByID", mock.Anything, roleID).Return(models.Role{ID: roleID, Name: "Admin", IsSystem: true}, nil)
+
+	resp, _ := app.Test(httptest.NewRequest("DELETE", "/roles/"+roleID.String(), nil))
+	assert.Equal(t, 403, resp.StatusCode)
+	mockRepo.AssertNotCalled(t, "DeleteRole", mock.Anything, roleID)
+}
+
+func TestDeleteRole_StillAssignedToUsers(t *testing.T) {
+	mockRepo := new(mocks.MockRoleRepo)
+	resolver := helpers.NewPermissionResolver(new(mocks.MockPermissionRepo), time.Minute)
+	roleService := services.NewRoleService(mockRepo, resolver)
+	app := fiber.New(fiber.Config{ErrorHandler: apperror.Handler})
+	app.Delete("/roles/:id", roleService.DeleteRole)
+
+	roleID := uuid.New()
+	mockRepo.On("GetRoleByID", mock.Anything, roleID).Return(models.Role{ID: roleID, Name: "Asisten"}, nil)
+	mockRepo.On("CountUsersByRole", mock.Anything, roleID).Return(2, nil)
+
+	resp, _ := app.Test(httptest.NewRequest("DELETE", "/roles/"+roleID.String(), nil))
+	assert.Equal(t, 409, resp.StatusCode)
+	mockRepo.AssertNotCalled(t, "DeleteRole", mock.Anything, roleID)
+}
+
+func TestGrantPermission_InvalidatesCache(t *testing.T) {
+	mockRepo := new(mocks.MockRoleRepo)
+	mockPermRepo := new(mocks.MockPermissionRepo)
+	resolver := helpers.NewPermissionResolver(mockPermRepo, time.Minute)
+	roleService := services.NewRoleService(mockRepo, resolver)
+	app := fiber.New(fiber.Config{ErrorHandler: apperror.Handler})
+	app.Post("/roles/:id/permissions/:permissionId", roleService.GrantPermission)
+
+	roleID, permID := uuid.New(), uuid.New()
+	mockPermRepo.On("GetPermissionNamesByRole", mock.Anything, "Asisten").Return([]string{}, nil).Once()
+	mockPermRepo.On("GetPermissionNamesByRole", mock.Anything, "Asisten").Return([]string{"reports:read"}, nil).Once()
+	mockRepo.On("GetRoleByID", mock.Anything, roleID).Return(models.Role{ID: roleID, Name: "Asisten"}, nil)
+	mockRepo.On("GetPermissionByID", mock.Anything, permID).Return(models.Permission{ID: permID, Name: "reports:read"}, nil)
+	mockRepo.On("GrantPermission", mock.Anything, roleID, permID).Return(nil)
+
+	ok, _ := resolver.HasPermission(t.Context(), "Asisten", "reports:read")
+	assert.False(t, ok)
+
+	resp, _ := app.Test(httptest.NewRequest("POST", "/roles/"+roleID.String()+"/permissions/"+permID.String(), nil))
+	assert.Equal(t, 200, resp.StatusCode)
+
+	ok, _ = resolver.HasPermission(t.Context(), "Asisten", "reports:read")
+	assert.True(t, ok)
+	mockPermRepo.AssertExpectations(t)
+}
@@ -0,0 +1,134 @@
+package services
+
+import (
+	"database/sql"
+	"uas/app/models"
+	"uas/apperror"
+	"uas/i18n"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// listSessions mengambil sesi aktif milik userID dan menandai sesi yang sedang dipakai request ini
+func (s *authService) listSessions(c *fiber.Ctx, userID uuid.UUID) error {
+	sessions, err := s.sessionRepo.GetActiveSessionsByUser(c.Context(), userID)
+	if err != nil {
+		return apperror.Internal(err)
+	}
+
+	currentID, _ := c.Locals("session_id").(uuid.UUID)
+	for i := range sessions {
+		sessions[i].Current = sessions[i].ID == currentID
+	}
+
+	if sessions == nil {
+		sessions = []models.UserSession{}
+	}
+
+	return c.JSON(fiber.Map{
+		"status": "success",
+		"data":   sessions,
+	})
+}
+
+func (s *authService) revokeSession(c *fiber.Ctx, userID uuid.UUID, sessionParam string) error {
+	sessionID, err := uuid.Parse(sessionParam)
+	if err != nil {
+		return invalidID("Session ID")
+	}
+
+	err = s.sessionRepo.RevokeSession(c.Context(), sessionID, userID)
+	if err == sql.ErrNoRows {
+		return apperror.NotFound("session_not_found")
+	} else if err != nil {
+		return apperror.Internal(err)
+	}
+
+	return c.JSON(fiber.Map{
+		"status":  "success",
+		"message": i18n.Message(c, "messages.session_revoked"),
+	})
+}
+
+// GetSessions godoc
+// @Summary      Daftar Sesi Login
+// @Description  Menampilkan perangkat, IP, user agent, waktu login, dan waktu terakhir dipakai dari setiap sesi aktif milik user yang sedang login
+// @Tags         Auth
+// @Produce      json
+// @Security     Bearer
+// @Success      200  {array}   models.UserSession
+// @Failure      401  {object}  apperror.Problem
+// @Router       /auth/sessions [get]
+func (s *authService) GetSessions(c *fiber.Ctx) error {
+	userID, ok := c.Locals("user_id").(uuid.UUID)
+	if !ok {
+		return apperror.Unauthorized("unauthorized")
+	}
+
+	return s.listSessions(c, userID)
+}
+
+// RevokeSession godoc
+// @Summary      Cabut Sesi Login
+// @Description  Mencabut satu sesi milik sendiri. Refresh token sesi tersebut langsung tidak berlaku; access token-nya ditolak paling lambat setelah SESSION_CHECK_TTL (default 30 detik).
+// @Tags         Auth
+// @Produce      json
+// @Security     Bearer
+// @Param        id   path      string  true  "Session ID (UUID)"
+// @Success      200  {object}  map[string]string
+// @Failure      404  {object}  apperror.Problem
+// @Router       /auth/sessions/{id} [delete]
+func (s *authService) RevokeSession(c *fiber.Ctx) error {
+	userID, ok := c.Locals("user_id").(uuid.UUID)
+	if !ok {
+		return apperror.Unauthorized("unauthorized")
+	}
+
+	return s.revokeSession(c, userID, c.Params("id"))
+}
+
+// GetUserSessions godoc
+// @Summary      Daftar Sesi Login User (Admin)
+// @Description  Menampilkan sesi aktif milik user lain
+// @Tags         Users
+// @Produce      json
+// @Security     Bearer
+// @Param        id   path      string  true  "User ID (UUID)"
+// @Success      200  {array}   models.UserSession
+// @Failure      404  {object}  apperror.Problem
+// @Router       /users/{id}/sessions [get]
+func (s *authService) GetUserSessions(c *fiber.Ctx) error {
+	userID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return invalidID("User ID")
+	}
+
+	if _, err := s.userRepo.GetUserByID(c.Context(), userID); err == sql.ErrNoRows {
+		return apperror.NotFound("user_not_found")
+	} else if err != nil {
+		return apperror.Internal(err)
+	}
+
+	return s.listSessions(c, userID)
+}
+
+// RevokeUserSession godoc
+// @Summary      Cabut Sesi Login User (Admin)
+// @Description  Mencabut satu sesi milik user lain. Refresh token sesi tersebut langsung tidak berlaku; access token-nya ditolak paling lambat setelah SESSION_CHECK_TTL (default 30 detik).
+// @Tags         Users
+// @Produce      json
+// @Security     Bearer
+// @Param        id         path      string  true  "User ID (UUID)"
+// @Param        sessionId  path      string  true  "Session ID (UUID)"
+// @Success      200  {object}  map[string]string
+// @Failure      404  {object}  apperror.Problem
+// @Router       /users/{id}/sessions/{sessionId} [delete]
+func (s *authService) RevokeUserSession(c *fiber.Ctx) error {
+	userID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return invalidID("User ID")
+	}
+
+	return s.revokeSession(c, userID, c.Params("sessionId"))
+}
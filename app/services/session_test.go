@@ -0,0 +1,156 @@
+package services_test
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"uas/app/models"
+	"uas/app/services"
+	"uas/apperror"
+	"uas/helpers"
+	"uas/middleware"
+	"uas/mocks"
+	"uas/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestLogin_CreatesSession_RefreshStopsAfterRevoke(t *testing.T) {
+	mockRepo := new(mocks.MockUserRepo)
+	mockMFARepo := new(mocks.MockMFARepo)
+	mockSessionRepo := new(mocks.MockSessionRepo)
+	authService := services.NewAuthService(mockRepo, mockMFARepo, nil, nil, mockSessionRepo)
+	app := fiber.New(fiber.Config{ErrorHandler: apperror.Handler})
+	app.Post("/login", authService.Login)
+	app.Post("/refresh", authService.Refresh)
+
+	dummyUser := models.User{
+		ID:           uuid.New(),
+		Username:     "george_ganteng",
+		PasswordHash: hashPassword("123456"),
+		RoleName:     "Mahasiswa",
+		IsActive:     true,
+	}
+
+	var created models.UserSession
+	mockRepo.On("GetByUsernameOrEmail", mock.Anything, "george_ganteng").Return(dummyUser, nil)
+	mockRepo.On("GetUserByID", mock.Anything, dummyUser.ID).Return(dummyUser, nil)
+	mockMFARepo.On("GetMFAByUserID", mock.Anything, dummyUser.ID).Return(models.UserMFA{}, sql.ErrNoRows)
+	mockSessionRepo.On("CreateSession", mock.Anything, mock.MatchedBy(func(s models.UserSession) bool {
+		created = s
+		return s.UserID == dummyUser.ID && s.Device == "Firefox di Linux"
+	})).Return(nil)
+
+	body, _ := json.Marshal(map[string]string{"username": "george_ganteng", "password": "123456"})
+	req := httptest.NewRequest("POST", "/login", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Mozilla/5.0 (X11; Linux x86_64; rv:131.0) Gecko/20100101 Firefox/131.0")
+
+	resp, _ := app.Test(req)
+	assert.Equal(t, 200, resp.StatusCode)
+
+	var loginBody map[string]interface{}
+	json.NewDecoder(resp.Body).Decode(&loginBody)
+	data := loginBody["data"].(map[string]interface{})
+
+	claims, err := utils.ValidateToken(data["token"].(string))
+	assert.NoError(t, err)
+	assert.Equal(t, created.ID, *claims.SessionID)
+
+	// Sesi sudah dicabut: refresh token ditolak
+	revokedAt := time.Now()
+	created.RevokedAt = &revokedAt
+	mockSessionRepo.On("GetSessionByID", mock.Anything, created.ID).Return(created, nil)
+
+	body, _ = json.Marshal(map[string]string{"refreshToken": data["refreshToken"].(string)})
+	req = httptest.NewRequest("POST", "/refresh", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, _ = app.Test(req)
+	assert.Equal(t, 401, resp.StatusCode)
+}
+
+func TestGetSessions_MarksCurrentSession(t *testing.T) {
+	mockSessionRepo := new(mocks.MockSessionRepo)
+	authService := services.NewAuthService(new(mocks.MockUserRepo), new(mocks.MockMFARepo), nil, nil, mockSessionRepo)
+
+	userID, currentID := uuid.New(), uuid.New()
+	app := fiber.New(fiber.Config{ErrorHandler: apperror.Handler})
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("user_id", userID)
+		c.Locals("session_id", currentID)
+		return c.Next()
+	})
+	app.Get("/sessions", authService.GetSessions)
+
+	mockSessionRepo.On("GetActiveSessionsByUser", mock.Anything, userID).Return([]models.UserSession{
+		{ID: uuid.New(), UserID: userID, Device: "Chrome di Android"},
+		{ID: currentID, UserID: userID, Device: "Firefox di Linux"},
+	}, nil)
+
+	resp, _ := app.Test(httptest.NewRequest("GET", "/sessions", nil))
+	assert.Equal(t, 200, resp.StatusCode)
+
+	var body struct {
+		Data []models.UserSession `json:"data"`
+	}
+	json.NewDecoder(resp.Body).Decode(&body)
+	assert.Len(t, body.Data, 2)
+	assert.False(t, body.Data[0].Current)
+	assert.True(t, body.Data[1].Current)
+}
+
+func TestRevokeSession_OtherUsersSession_NotFound(t *testing.T) {
+	mockSessionRepo := new(mocks.MockSessionRepo)
+	authService := services.NewAuthService(new(mocks.MockUserRepo), new(mocks.MockMFARepo), nil, nil, mockSessionRepo)
+
+	userID, foreignSession := uuid.New(), uuid.New()
+	app := fiber.New(fiber.Config{ErrorHandler: apperror.Handler})
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("user_id", userID)
+		return c.Next()
+	})
+	app.Delete("/sessions/:id", authService.RevokeSession)
+
+	// Repository hanya mencabut sesi dengan user_id yang cocok
+	mockSessionRepo.On("RevokeSession", mock.Anything, foreignSession, userID).Return(sql.ErrNoRows)
+
+	resp, _ := app.Test(httptest.NewRequest("DELETE", "/sessions/"+foreignSession.String(), nil))
+	assert.Equal(t, 404, resp.StatusCode)
+	mockSessionRepo.AssertExpectations(t)
+}
+
+func TestAuthRequired_RejectsAccessTokenOfRevokedSession(t *testing.T) {
+	mockSessionRepo := new(mocks.MockSessionRepo)
+	app := fiber.New(fiber.Config{ErrorHandler: apperror.Handler})
+	app.Get("/me", middleware.AuthRequired(nil, helpers.NewSessionChecker(mockSessionRepo, time.Minute)),
+		func(c *fiber.Ctx) error { return c.SendStatus(200) })
+
+	user := models.User{ID: uuid.New(), Username: "george_ganteng", RoleName: "Mahasiswa"}
+	active, revoked := uuid.New(), uuid.New()
+	revokedAt := time.Now()
+	mockSessionRepo.On("GetSessionByID", mock.Anything, active).
+		Return(models.UserSession{ID: active, UserID: user.ID, ExpiresAt: time.Now().Add(time.Hour)}, nil).Once()
+	mockSessionRepo.On("GetSessionByID", mock.Anything, revoked).
+		Return(models.UserSession{ID: revoked, UserID: user.ID, ExpiresAt: time.Now().Add(time.Hour), RevokedAt: &revokedAt}, nil)
+
+	request := func(sessionID uuid.UUID) int {
+		token, _ := utils.GenerateSessionToken(user, nil, sessionID)
+		req := httptest.NewRequest("GET", "/me", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp, _ := app.Test(req)
+		return resp.StatusCode
+	}
+
+	assert.Equal(t, 200, request(active))
+	// sesi aktif di-cache: request kedua tidak ke database
+	assert.Equal(t, 200, request(active))
+	assert.Equal(t, 401, request(revoked))
+	mockSessionRepo.AssertExpectations(t)
+}
@@ -0,0 +1,287 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"uas/app/models"
+	"uas/apperror"
+	"uas/i18n"
+	"uas/validation"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// GetAdvisorHistory godoc
+// @Summary      Riwayat Dosen Wali
+// @Description  Menampilkan seluruh periode perwalian mahasiswa beserta tanggal berlakunya. effective_to kosong berarti periode yang sedang berjalan.
+// @Tags         Students
+// @Produce      json
+// @Security     Bearer
+// @Param        id   path      string  true  "Student ID (UUID)"
+// @Success      200  {array}   models.AdvisorHistory
+// @Failure      400  {object}  apperror.Problem
+// @Router       /students/{id}/advisor-history [get]
+func (s *studentService) GetAdvisorHistory(c *fiber.Ctx) error {
+	studentID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return invalidID("Student ID")
+	}
+
+	history, err := s.repo.GetAdvisorHistory(c.Context(), studentID)
+	if err != nil {
+		return apperror.Internal(err)
+	}
+
+	if history == nil {
+		history = []models.AdvisorHistory{}
+	}
+
+	return c.JSON(fiber.Map{
+		"message": i18n.Message(c, "messages.advisor_history_fetched"),
+		"success": true,
+		"data":    history,
+	})
+}
+
+// ReassignAdvisees godoc
+// @Summary      Pindahkan Seluruh Mahasiswa Bimbingan
+// @Description  Memindahkan semua mahasiswa bimbingan seorang dosen ke satu atau beberapa dosen lain dalam satu transaksi. Strategi round_robin membagi bergiliran, load_balanced mengutamakan dosen dengan bimbingan paling sedikit. Kapasitas dosen tujuan dihormati kecuali force bernilai true. Prestasi berstatus submitted ikut berpindah ke dosen wali baru untuk diverifikasi.
+// @Tags         Lecturers
+// @Accept       json
+// @Produce      json
+// @Security     Bearer
+// @Param        id       path      string                          true  "Lecturer ID asal (UUID)"
+// @Param        request  body      models.ReassignAdviseesRequest  true  "Dosen tujuan dan strategi"
+// @Success      200      {object}  models.ReassignAdviseesResult
+// @Failure      400      {object}  apperror.Problem
+// @Failure      409      {object}  apperror.Problem "Kapasitas tidak cukup"
+// @Failure      422  {object}  apperror.Problem "Data tidak valid (errors berisi pesan per field)"
+// @Router       /lecturers/{id}/advisees/reassign [post]
+func (s *studentService) ReassignAdvisees(c *fiber.Ctx) error {
+	fromID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return invalidID("Lecturer ID")
+	}
+
+	var req models.ReassignAdviseesRequest
+	if err := bindBody(c, &req); err != nil {
+		return err
+	}
+
+	if req.Strategy == "" {
+		req.Strategy = models.DistributionRoundRobin
+	}
+
+	targets := make([]uuid.UUID, 0, len(req.ToLecturerIDs))
+	seen := make(map[uuid.UUID]bool)
+	for i, raw := range req.ToLecturerIDs {
+		id, _ := uuid.Parse(strings.TrimSpace(raw))
+		if id == fromID {
+			return validationError(validation.Field(fmt.Sprintf("to_lecturer_ids[%d]", i), "nefield",
+				"validation.same_lecturer"))
+		}
+		if !seen[id] {
+			seen[id] = true
+			targets = append(targets, id)
+		}
+	}
+
+	tx, err := s.db.BeginTx(c.Context(), nil)
+	if err != nil {
+		return apperror.Internal(err)
+	}
+	defer tx.Rollback()
+
+	loads, err := s.lecturerRepo.GetAdvisorLoads(c.Context(), tx, targets)
+	if err != nil {
+		return apperror.Internal(err)
+	}
+	applyDefaultCapacity(loads)
+
+	// Urutan dosen tujuan mengikuti request, bukan urutan hasil query
+	byID := make(map[uuid.UUID]*models.AdvisorLoad, len(loads))
+	for i := range loads {
+		byID[loads[i].LecturerID] = &loads[i]
+	}
+	targetLoads := make([]*models.AdvisorLoad, 0, len(targets))
+	for _, id := range targets {
+		load, ok := byID[id]
+		if !ok {
+			return apperror.BadRequest("lecturer_unavailable").WithParams(i18n.Params{"id": id.String()})
+		}
+		targetLoads = append(targetLoads, load)
+	}
+
+	students, err := s.repo.GetAdviseeIDsForUpdate(c.Context(), tx, fromID)
+	if err != nil {
+		return apperror.Internal(err)
+	}
+
+	result := models.ReassignAdviseesResult{
+		FromLecturerID: fromID,
+		Strategy:       req.Strategy,
+		Assignments:    []models.AdviseeAssignment{},
+	}
+	if len(students) == 0 {
+		return c.JSON(fiber.Map{
+			"message": i18n.Message(c, "messages.no_advisees_to_move"),
+			"success": true,
+			"data":    result,
+		})
+	}
+
+	// Verifikator prestasi ditentukan dari students.advisor_id, jadi prestasi 'submitted'
+	// otomatis berpindah ke dosen baru begitu advisor_id diganti di transaksi ini
+	pending, err := s.repo.CountSubmittedAchievements(c.Context(), tx, students)
+	if err != nil {
+		return apperror.Internal(err)
+	}
+
+	assigned, ok := distributeAdvisees(students, targetLoads, req.Strategy, req.Force)
+	if !ok {
+		return apperror.Conflict("advisor_capacity_full").Variant("reassign")
+	}
+
+	now := time.Now()
+	changedBy := currentUserID(c)
+	for i, advisorID := range assigned {
+		err := s.repo.ChangeAdvisor(c.Context(), tx, models.AdvisorChange{
+			StudentID: students[i],
+			AdvisorID: advisorID,
+			ChangedBy: changedBy,
+			Reason:    req.Reason,
+			At:        now,
+		})
+		if err != nil {
+			return apperror.Internal(err)
+		}
+
+		result.Assignments = append(result.Assignments, models.AdviseeAssignment{
+			StudentID:           students[i],
+			AdvisorID:           advisorID,
+			PendingAchievements: pending[students[i]],
+		})
+		result.PendingAchievements += pending[students[i]]
+	}
+	result.Moved = len(result.Assignments)
+
+	if err := tx.Commit(); err != nil {
+		return apperror.Internal(err)
+	}
+
+	return c.JSON(fiber.Map{
+		"message": i18n.Message(c, "messages.advisees_reassigned"),
+		"success": true,
+		"data":    result,
+	})
+}
+
+// AutoAssignAdvisors godoc
+// @Summary      Tetapkan Dosen Wali Otomatis
+// @Description  Menetapkan dosen wali untuk semua mahasiswa aktif yang belum punya dosen wali. Dosen dipilih dari department yang sama dengan program studi mahasiswa, yang bimbingannya paling sedikit dan masih punya kapasitas. Mahasiswa yang tidak mendapat dosen dilaporkan di unassigned. dry_run hanya menampilkan rencana.
+// @Tags         Students
+// @Accept       json
+// @Produce      json
+// @Security     Bearer
+// @Param        request  body      models.AutoAssignAdvisorsRequest  false  "Opsi"
+// @Success      200      {object}  models.AutoAssignAdvisorsResult
+// @Failure      422  {object}  apperror.Problem "Data tidak valid (errors berisi pesan per field)"
+// @Router       /students/auto-assign-advisors [post]
+func (s *studentService) AutoAssignAdvisors(c *fiber.Ctx) error {
+	var req models.AutoAssignAdvisorsRequest
+	if len(c.Body()) > 0 {
+		if err := bindBody(c, &req); err != nil {
+			return err
+		}
+	}
+
+	tx, err := s.db.BeginTx(c.Context(), nil)
+	if err != nil {
+		return apperror.Internal(err)
+	}
+	defer tx.Rollback()
+
+	students, err := s.repo.GetUnadvisedStudentsForUpdate(c.Context(), tx)
+	if err != nil {
+		return apperror.Internal(err)
+	}
+
+	loads, err := s.lecturerRepo.GetAdvisorLoads(c.Context(), tx, nil)
+	if err != nil {
+		return apperror.Internal(err)
+	}
+	applyDefaultCapacity(loads)
+
+	// Mahasiswa dicocokkan dengan dosen dari department yang menaungi program studinya
+	byDept := make(map[uuid.UUID][]*models.AdvisorLoad)
+	for i := range loads {
+		if loads[i].DepartmentID != nil {
+			byDept[*loads[i].DepartmentID] = append(byDept[*loads[i].DepartmentID], &loads[i])
+		}
+	}
+
+	result := models.AutoAssignAdvisorsResult{
+		DryRun:     req.DryRun,
+		Assigned:   []models.AutoAssignment{},
+		Unassigned: []models.AutoAssignment{},
+	}
+
+	now := time.Now()
+	changedBy := currentUserID(c)
+	for _, student := range students {
+		assignment := models.AutoAssignment{
+			StudentID:    student.ID,
+			NIM:          student.StudentID,
+			ProgramStudy: student.ProgramStudy,
+		}
+
+		if student.DepartmentID == uuid.Nil {
+			assignment.Reason = i18n.Message(c, "report.auto_assign.unknown_program_study")
+			result.Unassigned = append(result.Unassigned, assignment)
+			continue
+		}
+
+		candidates := byDept[student.DepartmentID]
+		advisor := pickLeastLoaded(candidates, false)
+		if advisor == nil {
+			assignment.Reason = i18n.Message(c, "report.auto_assign.no_lecturer", i18n.Params{"program_study": student.ProgramStudy})
+			if len(candidates) > 0 {
+				assignment.Reason = i18n.Message(c, "report.auto_assign.capacity_full", i18n.Params{"program_study": student.ProgramStudy})
+			}
+			result.Unassigned = append(result.Unassigned, assignment)
+			continue
+		}
+
+		if !req.DryRun {
+			err := s.repo.ChangeAdvisor(c.Context(), tx, models.AdvisorChange{
+				StudentID: student.ID,
+				AdvisorID: advisor.LecturerID,
+				ChangedBy: changedBy,
+				Reason:    req.Reason,
+				At:        now,
+			})
+			if err != nil {
+				return apperror.Internal(err)
+			}
+		}
+
+		advisor.Advisees++
+		advisorID := advisor.LecturerID
+		assignment.AdvisorID = &advisorID
+		result.Assigned = append(result.Assigned, assignment)
+	}
+
+	if !req.DryRun {
+		if err := tx.Commit(); err != nil {
+			return apperror.Internal(err)
+		}
+	}
+
+	return c.JSON(fiber.Map{
+		"message": i18n.Message(c, "messages.advisors_auto_assigned"),
+		"success": true,
+		"data":    result,
+	})
+}
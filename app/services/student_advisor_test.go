@@ -0,0 +1,280 @@
+package services_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"uas/app/models"
+	"uas/app/repository"
+	"uas/app/services"
+	"uas/apperror"
+	"uas/mocks"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newReassignApp(studentService services.StudentService, adminID uuid.UUID) *fiber.App {
+	app := fiber.New(fiber.Config{ErrorHandler: apperror.Handler})
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("user_id", adminID)
+		return c.Next()
+	})
+	app.Post("/lecturers/:id/advisees/reassign", studentService.ReassignAdvisees)
+	return app
+}
+
+func TestReassignAdvisees_LoadBalanced_FillsLightestLecturerFirst(t *testing.T) {
+	db, mockDB, _ := sqlmock.New()
+	defer db.Close()
+	mockDB.ExpectBegin()
+	mockDB.ExpectCommit()
+
+	mockStudentRepo := new(mocks.MockStudentRepo)
+	mockLecturerRepo := new(mocks.MockLecturerRepo)
+	studentService := services.NewStudentService(db, mockStudentRepo, mockLecturerRepo, nil)
+
+	fromID, busyID, freeID, adminID := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	students := []uuid.UUID{uuid.New(), uuid.New(), uuid.New()}
+
+	// Hasil query urut department/nama, bukan urutan request
+	mockLecturerRepo.On("GetAdvisorLoads", mock.Anything, mock.Anything, []uuid.UUID{busyID, freeID}).
+		Return([]models.AdvisorLoad{{LecturerID: freeID}, {LecturerID: busyID, Advisees: 2}}, nil)
+	mockStudentRepo.On("GetAdviseeIDsForUpdate", mock.Anything, mock.Anything, fromID).Return(students, nil)
+	mockStudentRepo.On("CountSubmittedAchievements", mock.Anything, mock.Anything, students).
+		Return(map[uuid.UUID]int{students[1]: 2}, nil)
+
+	var moved []models.AdvisorChange
+	mockStudentRepo.On("ChangeAdvisor", mock.Anything, mock.Anything, mock.MatchedBy(func(ch models.AdvisorChange) bool {
+		moved = append(moved, ch)
+		return ch.ChangedBy != nil && *ch.ChangedBy == adminID && ch.Reason == "Pensiun"
+	})).Return(nil)
+
+	body, _ := json.Marshal(map[string]interface{}{
+		"to_lecturer_ids": []string{busyID.String(), freeID.String()},
+		"strategy":        "load_balanced",
+		"reason":          "Pensiun",
+	})
+	req := httptest.NewRequest("POST", "/lecturers/"+fromID.String()+"/advisees/reassign", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, _ := newReassignApp(studentService, adminID).Test(req)
+	assert.Equal(t, 200, resp.StatusCode)
+
+	var res struct {
+		Data models.ReassignAdviseesResult `json:"data"`
+	}
+	json.NewDecoder(resp.Body).Decode(&res)
+
+	// Beban awal 2 dan 0: dua mahasiswa pertama ke dosen yang kosong, lalu seri 2-2 jatuh ke urutan pertama
+	assert.Equal(t, 3, res.Data.Moved)
+	assert.Equal(t, freeID, res.Data.Assignments[0].AdvisorID)
+	assert.Equal(t, freeID, res.Data.Assignments[1].AdvisorID)
+	assert.Equal(t, busyID, res.Data.Assignments[2].AdvisorID)
+	assert.Equal(t, 2, res.Data.PendingAchievements)
+	assert.Len(t, moved, 3)
+	assert.NoError(t, mockDB.ExpectationsWereMet())
+}
+
+func TestReassignAdvisees_InactiveTarget_RollsBack(t *testing.T) {
+	db, mockDB, _ := sqlmock.New()
+	defer db.Close()
+	mockDB.ExpectBegin()
+	mockDB.ExpectRollback()
+
+	mockStudentRepo := new(mocks.MockStudentRepo)
+	mockLecturerRepo := new(mocks.MockLecturerRepo)
+	studentService := services.NewStudentService(db, mockStudentRepo, mockLecturerRepo, nil)
+
+	fromID, retiredID := uuid.New(), uuid.New()
+	mockLecturerRepo.On("GetAdvisorLoads", mock.Anything, mock.Anything, mock.Anything).Return([]models.AdvisorLoad{}, nil)
+
+	body, _ := json.Marshal(map[string]interface{}{"to_lecturer_ids": []string{retiredID.String()}})
+	req := httptest.NewRequest("POST", "/lecturers/"+fromID.String()+"/advisees/reassign", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, _ := newReassignApp(studentService, uuid.New()).Test(req)
+	assert.Equal(t, 400, resp.StatusCode)
+
+	mockStudentRepo.AssertNotCalled(t, "ChangeAdvisor", mock.Anything, mock.Anything, mock.Anything)
+	assert.NoError(t, mockDB.ExpectationsWereMet())
+}
+
+func TestUpdateStudentAdvisor_FullLecturer_RequiresForce(t *testing.T) {
+	t.Setenv("ADVISOR_MAX_ADVISEES", "2")
+
+	db, mockDB, _ := sqlmock.New()
+	defer db.Close()
+
+	mockStudentRepo := new(mocks.MockStudentRepo)
+	mockLecturerRepo := new(mocks.MockLecturerRepo)
+	studentService := services.NewStudentService(db, mockStudentRepo, mockLecturerRepo, nil)
+
+	app := fiber.New(fiber.Config{ErrorHandler: apperror.Handler})
+	app.Put("/students/:id/advisor", studentService.UpdateStudentAdvisor)
+
+	studentID, advisorID, previousID := uuid.New(), uuid.New(), uuid.New()
+	mockStudentRepo.On("GetAdvisorIDForUpdate", mock.Anything, mock.Anything, studentID).Return(&previousID, nil)
+	mockLecturerRepo.On("GetAdvisorLoads", mock.Anything, mock.Anything, []uuid.UUID{advisorID}).
+		Return([]models.AdvisorLoad{{LecturerID: advisorID, Advisees: 2}}, nil)
+	mockStudentRepo.On("ChangeAdvisor", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	send := func(force bool) int {
+		body, _ := json.Marshal(map[string]interface{}{"advisor_id": advisorID.String(), "force": force})
+		req := httptest.NewRequest("PUT", "/students/"+studentID.String()+"/advisor", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp, _ := app.Test(req)
+		return resp.StatusCode
+	}
+
+	mockDB.ExpectBegin()
+	mockDB.ExpectRollback()
+	assert.Equal(t, 409, send(false))
+	mockStudentRepo.AssertNotCalled(t, "ChangeAdvisor", mock.Anything, mock.Anything, mock.Anything)
+
+	mockDB.ExpectBegin()
+	mockDB.ExpectCommit()
+	assert.Equal(t, 200, send(true))
+	mockStudentRepo.AssertNumberOfCalls(t, "ChangeAdvisor", 1)
+	assert.NoError(t, mockDB.ExpectationsWereMet())
+}
+
+func TestUpdateStudentAdvisor_SameAdvisor_IsNoOp(t *testing.T) {
+	t.Setenv("ADVISOR_MAX_ADVISEES", "2")
+
+	db, mockDB, _ := sqlmock.New()
+	defer db.Close()
+	mockDB.ExpectBegin()
+	mockDB.ExpectRollback()
+
+	mockStudentRepo := new(mocks.MockStudentRepo)
+	mockLecturerRepo := new(mocks.MockLecturerRepo)
+	studentService := services.NewStudentService(db, mockStudentRepo, mockLecturerRepo, nil)
+
+	app := fiber.New(fiber.Config{ErrorHandler: apperror.Handler})
+	app.Put("/students/:id/advisor", studentService.UpdateStudentAdvisor)
+
+	// dosen sudah penuh, tapi mahasiswa ini memang bimbingannya
+	studentID, advisorID := uuid.New(), uuid.New()
+	mockStudentRepo.On("GetAdvisorIDForUpdate", mock.Anything, mock.Anything, studentID).Return(&advisorID, nil)
+
+	body, _ := json.Marshal(map[string]interface{}{"advisor_id": advisorID.String()})
+	req := httptest.NewRequest("PUT", "/students/"+studentID.String()+"/advisor", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp, _ := app.Test(req)
+	assert.Equal(t, 200, resp.StatusCode)
+
+	mockLecturerRepo.AssertNotCalled(t, "GetAdvisorLoads", mock.Anything, mock.Anything, mock.Anything)
+	mockStudentRepo.AssertNotCalled(t, "ChangeAdvisor", mock.Anything, mock.Anything, mock.Anything)
+	assert.NoError(t, mockDB.ExpectationsWereMet())
+}
+
+func TestAutoAssignAdvisors_PicksLeastLoadedInDepartment(t *testing.T) {
+	db, mockDB, _ := sqlmock.New()
+	defer db.Close()
+	mockDB.ExpectBegin()
+	mockDB.ExpectCommit()
+
+	mockStudentRepo := new(mocks.MockStudentRepo)
+	mockLecturerRepo := new(mocks.MockLecturerRepo)
+	studentService := services.NewStudentService(db, mockStudentRepo, mockLecturerRepo, nil)
+
+	app := fiber.New(fiber.Config{ErrorHandler: apperror.Handler})
+	app.Post("/students/auto-assign-advisors", studentService.AutoAssignAdvisors)
+
+	one := 1
+	busyID, lightID, fullID := uuid.New(), uuid.New(), uuid.New()
+	tiDept, siDept, kedokteranDept := uuid.New(), uuid.New(), uuid.New()
+	mockLecturerRepo.On("GetAdvisorLoads", mock.Anything, mock.Anything, []uuid.UUID(nil)).Return([]models.AdvisorLoad{
+		{LecturerID: busyID, DepartmentID: &tiDept, Department: "Teknik Informatika", Advisees: 5},
+		{LecturerID: lightID, DepartmentID: &tiDept, Department: "Teknik Informatika", Advisees: 1},
+		{LecturerID: fullID, DepartmentID: &siDept, Department: "Sistem Informasi", Advisees: 1, MaxAdvisees: &one},
+	}, nil)
+	// Program studi Rekayasa Perangkat Lunak dinaungi department Teknik Informatika
+	mockStudentRepo.On("GetUnadvisedStudentsForUpdate", mock.Anything, mock.Anything).Return([]models.Student{
+		{ID: uuid.New(), StudentID: "2025001", ProgramStudy: "Rekayasa Perangkat Lunak", DepartmentID: tiDept},
+		{ID: uuid.New(), StudentID: "2025002", ProgramStudy: "Sistem Informasi", DepartmentID: siDept},
+		{ID: uuid.New(), StudentID: "2025003", ProgramStudy: "Kedokteran", DepartmentID: kedokteranDept},
+		{ID: uuid.New(), StudentID: "2025004"},
+	}, nil)
+	mockStudentRepo.On("ChangeAdvisor", mock.Anything, mock.Anything, mock.MatchedBy(func(ch models.AdvisorChange) bool {
+		return ch.AdvisorID == lightID
+	})).Return(nil).Once()
+
+	resp, _ := app.Test(httptest.NewRequest("POST", "/students/auto-assign-advisors", nil))
+	assert.Equal(t, 200, resp.StatusCode)
+
+	var res struct {
+		Data models.AutoAssignAdvisorsResult `json:"data"`
+	}
+	json.NewDecoder(resp.Body).Decode(&res)
+
+	assert.Len(t, res.Data.Assigned, 1)
+	assert.Equal(t, lightID, *res.Data.Assigned[0].AdvisorID)
+	assert.Len(t, res.Data.Unassigned, 3)
+	assert.Contains(t, res.Data.Unassigned[0].Reason, "sudah penuh")
+	assert.Contains(t, res.Data.Unassigned[1].Reason, "tidak ada dosen")
+	assert.Contains(t, res.Data.Unassigned[2].Reason, "belum terdaftar")
+
+	mockStudentRepo.AssertExpectations(t)
+	assert.NoError(t, mockDB.ExpectationsWereMet())
+}
+
+func TestCreateStudent_OpensStatusAndAdvisorHistory(t *testing.T) {
+	db, mockDB, _ := sqlmock.New()
+	defer db.Close()
+
+	studentID, advisorID, actorID := uuid.New(), uuid.New(), uuid.New()
+	createdAt := time.Date(2025, 8, 1, 9, 0, 0, 0, time.UTC)
+
+	mockDB.ExpectBegin()
+	mockDB.ExpectExec(`INSERT INTO students`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mockDB.ExpectExec(`INSERT INTO student_status_history`).WithArgs(studentID, &actorID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mockDB.ExpectExec(`INSERT INTO student_advisor_history`).
+		WithArgs(studentID, advisorID, createdAt, &actorID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mockDB.ExpectCommit()
+
+	tx, _ := db.Begin()
+	err := repository.NewStudentRepository(db).CreateStudent(context.Background(), tx, models.Student{
+		ID:        studentID,
+		UserID:    uuid.New(),
+		StudentID: "2025001",
+		AdvisorID: advisorID,
+		CreatedBy: &actorID,
+		CreatedAt: createdAt,
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, tx.Commit())
+	assert.NoError(t, mockDB.ExpectationsWereMet())
+}
+
+func TestCreateStudent_TrimsAcademicYear(t *testing.T) {
+	db, mockDB, _ := sqlmock.New()
+	defer db.Close()
+
+	studentID, userID := uuid.New(), uuid.New()
+	createdAt := time.Date(2025, 8, 1, 9, 0, 0, 0, time.UTC)
+
+	mockDB.ExpectExec(`INSERT INTO students`).
+		WithArgs(studentID, userID, "2025001", nil, "2025", nil, createdAt).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mockDB.ExpectExec(`INSERT INTO student_status_history`).WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err := repository.NewStudentRepository(db).CreateStudent(context.Background(), nil, models.Student{
+		ID:           studentID,
+		UserID:       userID,
+		StudentID:    "2025001",
+		AcademicYear: " 2025 ",
+		CreatedAt:    createdAt,
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, mockDB.ExpectationsWereMet())
+}
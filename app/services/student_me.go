@@ -0,0 +1,140 @@
+package services
+
+import (
+	"database/sql"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"uas/app/models"
+	"uas/apperror"
+	"uas/helpers"
+	"uas/i18n"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// defaultGraduationSKP dipakai jika env GRADUATION_SKP_POINTS kosong atau tidak valid
+const defaultGraduationSKP = 100
+
+// recentRejectionLimit adalah jumlah catatan penolakan terbaru pada ringkasan mahasiswa
+const recentRejectionLimit = 5
+
+// graduationSKPPoints membaca syarat poin SKP kelulusan dari env GRADUATION_SKP_POINTS
+func graduationSKPPoints() int {
+	n, err := strconv.Atoi(strings.TrimSpace(os.Getenv("GRADUATION_SKP_POINTS")))
+	if err != nil || n <= 0 {
+		return defaultGraduationSKP
+	}
+	return n
+}
+
+// skpProgress menghitung capaian poin terhadap syarat SKP; persentase dibatasi 100
+func skpProgress(earned, required int) models.SKPProgress {
+	p := models.SKPProgress{Required: required, Earned: earned}
+	if earned < required {
+		p.Remaining = required - earned
+	}
+	p.Fulfilled = p.Remaining == 0
+	p.Percent = math.Min(100, math.Round(float64(earned)*1000/float64(required))/10)
+	return p
+}
+
+// myStudentProfile mengambil profil mahasiswa milik user login
+func (s *studentService) myStudentProfile(c *fiber.Ctx) (models.StudentWithAdvisor, error) {
+	userID, err := helpers.GetUserIDFromContext(c)
+	if err != nil {
+		return models.StudentWithAdvisor{}, err
+	}
+
+	profile, err := s.repo.GetStudentByUserID(c.Context(), userID)
+	if err == sql.ErrNoRows {
+		return models.StudentWithAdvisor{}, apperror.NotFound("student_not_found").Variant("self")
+	} else if err != nil {
+		return models.StudentWithAdvisor{}, apperror.Internal(err)
+	}
+	return profile, nil
+}
+
+// GetMyStudentProfile godoc
+// @Summary      Profil Mahasiswa Saya
+// @Description  Data mahasiswa milik user yang sedang login beserta kontak dosen walinya (advisor null jika belum punya dosen wali).
+// @Tags         Students
+// @Produce      json
+// @Security     Bearer
+// @Success      200  {object}  map[string]models.StudentWithAdvisor
+// @Failure      401  {object}  apperror.Problem
+// @Failure      404  {object}  apperror.Problem "User bukan mahasiswa"
+// @Router       /students/me [get]
+func (s *studentService) GetMyStudentProfile(c *fiber.Ctx) error {
+	profile, err := s.myStudentProfile(c)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(fiber.Map{
+		"message": i18n.Message(c, "messages.student_found"),
+		"success": true,
+		"data":    profile,
+	})
+}
+
+// GetMyStudentSummary godoc
+// @Summary      Ringkasan Prestasi Saya
+// @Description  Jumlah prestasi per status, total poin prestasi verified, capaian terhadap syarat SKP kelulusan (env GRADUATION_SKP_POINTS), dan catatan penolakan terbaru milik mahasiswa yang sedang login.
+// @Tags         Students
+// @Produce      json
+// @Security     Bearer
+// @Success      200  {object}  map[string]models.StudentSummary
+// @Failure      401  {object}  apperror.Problem
+// @Failure      404  {object}  apperror.Problem "User bukan mahasiswa"
+// @Failure      500  {object}  apperror.Problem
+// @Router       /students/me/summary [get]
+func (s *studentService) GetMyStudentSummary(c *fiber.Ctx) error {
+	profile, err := s.myStudentProfile(c)
+	if err != nil {
+		return err
+	}
+
+	summary, verifiedIDs, err := s.repo.GetAchievementSummary(c.Context(), profile.ID)
+	if err != nil {
+		return apperror.Internal(err)
+	}
+
+	rejections, err := s.repo.GetRecentRejections(c.Context(), profile.ID, recentRejectionLimit)
+	if err != nil {
+		return apperror.Internal(err)
+	}
+
+	// Poin dan judul tersimpan di MongoDB: ambil sekali untuk keduanya
+	mongoIDs := append([]string{}, verifiedIDs...)
+	for _, r := range rejections {
+		mongoIDs = append(mongoIDs, r.MongoID)
+	}
+	if len(mongoIDs) > 0 {
+		docs, err := s.achievementRepo.GetMongoDetailsByIDs(c.Context(), mongoIDs)
+		if err != nil {
+			return apperror.Internal(err)
+		}
+		for _, id := range verifiedIDs {
+			summary.TotalPoints += docs[id].Points
+		}
+		for i := range rejections {
+			rejections[i].Title = docs[rejections[i].MongoID].Title
+		}
+	}
+
+	if rejections == nil {
+		rejections = []models.RejectionNote{}
+	}
+
+	return c.JSON(fiber.Map{
+		"message": i18n.Message(c, "messages.achievement_summary_fetched"),
+		"success": true,
+		"data": models.StudentSummary{
+			Achievements:     summary,
+			SKP:              skpProgress(summary.TotalPoints, graduationSKPPoints()),
+			RecentRejections: rejections,
+		},
+	})
+}
@@ -0,0 +1,77 @@
+package services_test
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"uas/app/models"
+	"uas/app/services"
+	"uas/apperror"
+	"uas/mocks"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newStudentMeApp(studentService services.StudentService, userID uuid.UUID) *fiber.App {
+	app := fiber.New(fiber.Config{ErrorHandler: apperror.Handler})
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("user_id", userID)
+		return c.Next()
+	})
+	app.Get("/students/me", studentService.GetMyStudentProfile)
+	app.Get("/students/me/summary", studentService.GetMyStudentSummary)
+	return app
+}
+
+func TestGetMyStudentSummary_PointsAndRejections(t *testing.T) {
+	t.Setenv("GRADUATION_SKP_POINTS", "50")
+
+	mockStudentRepo := new(mocks.MockStudentRepo)
+	mockAchRepo := new(mocks.MockAchievementRepo)
+	studentService := services.NewStudentService(nil, mockStudentRepo, nil, mockAchRepo)
+
+	userID := uuid.New()
+	mockStudentRepo.On("GetStudentByUserID", mock.Anything, userID.String()).
+		Return(models.StudentWithAdvisor{GetStudent: models.GetStudent{ID: "std-1"}}, nil)
+	mockStudentRepo.On("GetAchievementSummary", mock.Anything, "std-1").Return(models.AchievementSummary{
+		Total: 4, ByStatus: map[string]int64{"verified": 2, "rejected": 1, "draft": 1},
+	}, []string{"m-1", "m-2"}, nil)
+	mockStudentRepo.On("GetRecentRejections", mock.Anything, "std-1", 5).Return([]models.RejectionNote{
+		{AchievementID: "ach-3", MongoID: "m-3", Note: "Sertifikat tidak terbaca", RejectedAt: time.Now()},
+	}, nil)
+	mockAchRepo.On("GetMongoDetailsByIDs", mock.Anything, []string{"m-1", "m-2", "m-3"}).Return(map[string]models.AchievementMongo{
+		"m-1": {Points: 20},
+		"m-2": {Points: 15},
+		"m-3": {Title: "Juara Lomba Esai", Points: 10},
+	}, nil)
+
+	resp, _ := newStudentMeApp(studentService, userID).Test(httptest.NewRequest("GET", "/students/me/summary", nil))
+	assert.Equal(t, 200, resp.StatusCode)
+
+	var body struct {
+		Data models.StudentSummary `json:"data"`
+	}
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	assert.Equal(t, 35, body.Data.Achievements.TotalPoints)
+	assert.Equal(t, models.SKPProgress{Required: 50, Earned: 35, Remaining: 15, Percent: 70}, body.Data.SKP)
+	assert.Len(t, body.Data.RecentRejections, 1)
+	assert.Equal(t, "Juara Lomba Esai", body.Data.RecentRejections[0].Title)
+	mockStudentRepo.AssertExpectations(t)
+	mockAchRepo.AssertExpectations(t)
+}
+
+func TestGetMyStudentProfile_NotStudent(t *testing.T) {
+	mockStudentRepo := new(mocks.MockStudentRepo)
+	studentService := services.NewStudentService(nil, mockStudentRepo, nil, nil)
+
+	userID := uuid.New()
+	mockStudentRepo.On("GetStudentByUserID", mock.Anything, userID.String()).Return(models.StudentWithAdvisor{}, sql.ErrNoRows)
+
+	resp, _ := newStudentMeApp(studentService, userID).Test(httptest.NewRequest("GET", "/students/me", nil))
+	assert.Equal(t, 404, resp.StatusCode)
+}
@@ -0,0 +1,149 @@
+package services
+
+import (
+	"database/sql"
+	"time"
+	"uas/app/models"
+	"uas/apperror"
+	"uas/i18n"
+	"uas/validation"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// academicTransitions adalah perpindahan status akademik yang diizinkan. Lulus bersifat final;
+// mahasiswa yang keluar bisa diaktifkan kembali (misalnya setelah banding atau daftar ulang)
+var academicTransitions = map[string][]string{
+	models.AcademicStatusActive:     {models.AcademicStatusOnLeave, models.AcademicStatusGraduated, models.AcademicStatusDroppedOut},
+	models.AcademicStatusOnLeave:    {models.AcademicStatusActive, models.AcademicStatusDroppedOut},
+	models.AcademicStatusDroppedOut: {models.AcademicStatusActive},
+	models.AcademicStatusGraduated:  {},
+}
+
+func canTransitAcademicStatus(from, to string) bool {
+	for _, next := range academicTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// ChangeAcademicStatus godoc
+// @Summary      Ubah Status Akademik Mahasiswa
+// @Description  Mengubah status akademik mahasiswa (active, on_leave, graduated, dropped_out) dengan tanggal berlaku. Perpindahan yang diizinkan: active → on_leave/graduated/dropped_out, on_leave → active/dropped_out, dropped_out → active. Status graduated bersifat final. Tanggal berlaku default hari ini, tidak boleh di masa depan atau sebelum status saat ini mulai berlaku. Perubahan dicatat di riwayat status.
+// @Tags         Students
+// @Accept       json
+// @Produce      json
+// @Security     Bearer
+// @Param        id       path      string                              true  "Student ID (UUID)"
+// @Param        request  body      models.ChangeAcademicStatusRequest  true  "Status baru"
+// @Success      200      {object}  models.AcademicStatus
+// @Failure      400      {object}  apperror.Problem
+// @Failure      404      {object}  apperror.Problem
+// @Failure      409      {object}  apperror.Problem "Perpindahan status tidak diizinkan"
+// @Failure      422  {object}  apperror.Problem "Data tidak valid (errors berisi pesan per field)"
+// @Router       /students/{id}/status [post]
+func (s *studentService) ChangeAcademicStatus(c *fiber.Ctx) error {
+	studentID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return invalidID("Student ID")
+	}
+
+	var req models.ChangeAcademicStatusRequest
+	if err := bindBody(c, &req); err != nil {
+		return err
+	}
+
+	// Tanggal dibandingkan sebagai tanggal kalender (zona server), disimpan ke kolom DATE
+	y, m, d := time.Now().Date()
+	today := time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	effective := today
+	if req.EffectiveDate != "" {
+		effective, err = time.Parse("2006-01-02", req.EffectiveDate)
+		if err != nil {
+			return apperror.BadRequest("invalid_date").WithParams(i18n.Params{"field": "effective_date"})
+		}
+	}
+	if effective.After(today) {
+		return validationError(validation.Field("effective_date", "not_future", "validation.not_future"))
+	}
+
+	tx, err := s.db.BeginTx(c.Context(), nil)
+	if err != nil {
+		return apperror.Internal(err)
+	}
+	defer tx.Rollback()
+
+	current, err := s.repo.GetAcademicStatusForUpdate(c.Context(), tx, studentID)
+	if err == sql.ErrNoRows {
+		return apperror.NotFound("student_not_found")
+	} else if err != nil {
+		return apperror.Internal(err)
+	}
+
+	if !canTransitAcademicStatus(current.Status, req.Status) {
+		return apperror.InvalidTransition("invalid_status_transition").Variant("student_status").WithParams(i18n.Params{"from": current.Status, "to": req.Status})
+	}
+	if effective.Before(current.Since) {
+		return apperror.BadRequest("effective_date_too_early").WithParams(i18n.Params{"date": current.Since.Format("2006-01-02")})
+	}
+
+	err = s.repo.ChangeAcademicStatus(c.Context(), tx, models.AcademicStatusChange{
+		StudentID:     studentID,
+		Status:        req.Status,
+		EffectiveFrom: effective,
+		ChangedBy:     currentUserID(c),
+		Reason:        req.Reason,
+	})
+	if err != nil {
+		return apperror.Internal(err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return apperror.Internal(err)
+	}
+
+	return c.JSON(fiber.Map{
+		"message": i18n.Message(c, "messages.academic_status_updated"),
+		"success": true,
+		"data": models.AcademicStatus{
+			StudentID: studentID,
+			Status:    req.Status,
+			Since:     effective,
+		},
+	})
+}
+
+// GetAcademicStatusHistory godoc
+// @Summary      Riwayat Status Akademik
+// @Description  Menampilkan seluruh periode status akademik mahasiswa beserta tanggal berlakunya. effective_to kosong berarti status yang sedang berjalan.
+// @Tags         Students
+// @Produce      json
+// @Security     Bearer
+// @Param        id   path      string  true  "Student ID (UUID)"
+// @Success      200  {array}   models.AcademicStatusHistory
+// @Failure      400  {object}  apperror.Problem
+// @Router       /students/{id}/status-history [get]
+func (s *studentService) GetAcademicStatusHistory(c *fiber.Ctx) error {
+	studentID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return invalidID("Student ID")
+	}
+
+	history, err := s.repo.GetAcademicStatusHistory(c.Context(), studentID)
+	if err != nil {
+		return apperror.Internal(err)
+	}
+
+	if history == nil {
+		history = []models.AcademicStatusHistory{}
+	}
+
+	return c.JSON(fiber.Map{
+		"message": i18n.Message(c, "messages.academic_status_history_fetched"),
+		"success": true,
+		"data":    history,
+	})
+}
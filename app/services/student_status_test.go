@@ -0,0 +1,130 @@
+package services_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"uas/app/models"
+	"uas/app/services"
+	"uas/apperror"
+	"uas/mocks"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func changeStatus(app *fiber.App, studentID uuid.UUID, body map[string]string) int {
+	payload, _ := json.Marshal(body)
+	req := httptest.NewRequest("POST", "/students/"+studentID.String()+"/status", bytes.NewReader(payload))
+	req.Header.Set("Content-Type", "application/json")
+	resp, _ := app.Test(req)
+	return resp.StatusCode
+}
+
+func TestChangeAcademicStatus_OnLeaveWithEffectiveDate(t *testing.T) {
+	db, mockDB, _ := sqlmock.New()
+	defer db.Close()
+
+	mockStudentRepo := new(mocks.MockStudentRepo)
+	studentService := services.NewStudentService(db, mockStudentRepo, nil, nil)
+
+	adminID, studentID := uuid.New(), uuid.New()
+	since := time.Date(2024, 8, 1, 0, 0, 0, 0, time.UTC)
+	mockStudentRepo.On("GetAcademicStatusForUpdate", mock.Anything, mock.Anything, studentID).
+		Return(models.AcademicStatus{StudentID: studentID, Status: models.AcademicStatusActive, Since: since}, nil)
+	mockStudentRepo.On("ChangeAcademicStatus", mock.Anything, mock.Anything, mock.MatchedBy(func(ch models.AcademicStatusChange) bool {
+		return ch.Status == models.AcademicStatusOnLeave && ch.ChangedBy != nil && *ch.ChangedBy == adminID &&
+			ch.EffectiveFrom.Equal(time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)) && ch.Reason == "Cuti sakit"
+	})).Return(nil).Once()
+
+	app := fiber.New(fiber.Config{ErrorHandler: apperror.Handler})
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("user_id", adminID)
+		return c.Next()
+	})
+	app.Post("/students/:id/status", studentService.ChangeAcademicStatus)
+
+	// Tanggal berlaku sebelum status saat ini dimulai ditolak
+	mockDB.ExpectBegin()
+	mockDB.ExpectRollback()
+	assert.Equal(t, 400, changeStatus(app, studentID, map[string]string{"status": "on_leave", "effective_date": "2024-07-31"}))
+
+	mockDB.ExpectBegin()
+	mockDB.ExpectCommit()
+	assert.Equal(t, 200, changeStatus(app, studentID, map[string]string{"status": "on_leave", "effective_date": "2025-02-01", "reason": "Cuti sakit"}))
+
+	// Tanggal di masa depan ditolak sebelum menyentuh database
+	tomorrow := time.Now().AddDate(0, 0, 1).Format("2006-01-02")
+	assert.Equal(t, 422, changeStatus(app, studentID, map[string]string{"status": "on_leave", "effective_date": tomorrow}))
+
+	assert.NoError(t, mockDB.ExpectationsWereMet())
+	mockStudentRepo.AssertExpectations(t)
+}
+
+func TestChangeAcademicStatus_GraduatedIsFinal(t *testing.T) {
+	db, mockDB, _ := sqlmock.New()
+	defer db.Close()
+
+	mockStudentRepo := new(mocks.MockStudentRepo)
+	studentService := services.NewStudentService(db, mockStudentRepo, nil, nil)
+
+	studentID := uuid.New()
+	mockStudentRepo.On("GetAcademicStatusForUpdate", mock.Anything, mock.Anything, studentID).
+		Return(models.AcademicStatus{StudentID: studentID, Status: models.AcademicStatusGraduated, Since: time.Date(2024, 9, 1, 0, 0, 0, 0, time.UTC)}, nil)
+	mockDB.ExpectBegin()
+	mockDB.ExpectRollback()
+
+	app := fiber.New(fiber.Config{ErrorHandler: apperror.Handler})
+	app.Post("/students/:id/status", studentService.ChangeAcademicStatus)
+
+	assert.Equal(t, 409, changeStatus(app, studentID, map[string]string{"status": "active"}))
+	mockStudentRepo.AssertNotCalled(t, "ChangeAcademicStatus", mock.Anything, mock.Anything, mock.Anything)
+	assert.NoError(t, mockDB.ExpectationsWereMet())
+}
+
+func TestCreateAchievement_GraduatedStudentRejected(t *testing.T) {
+	mockRepo := new(mocks.MockAchievementRepo)
+	service := services.NewAchievementService(mockRepo, mocks.NewMockStorage())
+
+	mockRepo.On("GetStudentIDByUserID", mock.Anything, "user-alumni").Return("std-1", nil)
+	mockRepo.On("GetStudentAcademicStatus", mock.Anything, "std-1").Return(models.AcademicStatusGraduated, nil)
+
+	app := fiber.New(fiber.Config{ErrorHandler: apperror.Handler})
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("user_id", "user-alumni")
+		return c.Next()
+	})
+	app.Post("/achievements", service.CreateAchievement)
+
+	req := httptest.NewRequest("POST", "/achievements", bytes.NewReader([]byte(`{"title":"Juara 1","achievementType":"competition"}`)))
+	req.Header.Set("Content-Type", "application/json")
+	resp, _ := app.Test(req)
+
+	assert.Equal(t, 403, resp.StatusCode)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestGetAllAchievements_AdvisorInboxFiltersAdvisees(t *testing.T) {
+	mockRepo := new(mocks.MockAchievementRepo)
+	service := services.NewAchievementService(mockRepo, mocks.NewMockStorage())
+
+	mockRepo.On("GetAllReferences", mock.Anything, models.AchievementFilter{AdvisorUserID: "user-dosen"}).
+		Return([]models.AchievementReference{}, map[string]models.AchievementOwner{}, nil)
+
+	app := fiber.New(fiber.Config{ErrorHandler: apperror.Handler})
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("user_id", "user-dosen")
+		c.Locals("role_name", models.RoleDosen)
+		return c.Next()
+	})
+	app.Get("/achievements", service.GetAllAchievements)
+
+	resp, _ := app.Test(httptest.NewRequest("GET", "/achievements", nil))
+	assert.Equal(t, 200, resp.StatusCode)
+	mockRepo.AssertExpectations(t)
+}
@@ -3,8 +3,11 @@ package services
 import (
 	"database/sql"
 	"strings"
+	"time"
 	"uas/app/models"
 	"uas/app/repository"
+	"uas/apperror"
+	"uas/i18n"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
@@ -14,51 +17,74 @@ type StudentService interface {
 	GetStudents(c *fiber.Ctx) error
 	GetStudentByID(c *fiber.Ctx) error
 	UpdateStudentAdvisor(c *fiber.Ctx) error
+	GetAdvisorHistory(c *fiber.Ctx) error
+	ReassignAdvisees(c *fiber.Ctx) error
+	AutoAssignAdvisors(c *fiber.Ctx) error
+	ChangeAcademicStatus(c *fiber.Ctx) error
+	GetAcademicStatusHistory(c *fiber.Ctx) error
+	GetMyStudentProfile(c *fiber.Ctx) error
+	GetMyStudentSummary(c *fiber.Ctx) error
 }
 
 type studentService struct {
-	repo repository.StudentRepository
+	db              *sql.DB
+	repo            repository.StudentRepository
+	lecturerRepo    repository.LecturerRepository
+	achievementRepo repository.AchievementRepository
 }
 
-func NewStudentService(repo repository.StudentRepository) StudentService {
-	return &studentService{repo: repo}
+func NewStudentService(db *sql.DB, repo repository.StudentRepository, lecturerRepo repository.LecturerRepository, achievementRepo repository.AchievementRepository) StudentService {
+	return &studentService{db: db, repo: repo, lecturerRepo: lecturerRepo, achievementRepo: achievementRepo}
 }
 
 // GetStudents godoc
 // @Summary      Ambil Semua Data Mahasiswa
-// @Description  Mengambil daftar lengkap mahasiswa (terfilter role 'Mahasiswa'). Admin Only.
+// @Description  Mengambil daftar mahasiswa (terfilter role 'Mahasiswa') dengan pencarian (nama, username, email, NIM), filter, urutan, dan paginasi offset atau cursor. Admin Only.
 // @Tags         Students
 // @Accept       json
 // @Produce      json
 // @Security     Bearer
+// @Param        q              query     string  false  "Kata pencarian"
+// @Param        is_active      query     bool    false  "Status aktif"
+// @Param        program_study  query     string  false  "Nama program studi"
+// @Param        program_study_id  query  string  false  "Program Study ID (UUID)"
+// @Param        department_id  query     string  false  "Department ID (UUID) program studi"
+// @Param        faculty_id     query     string  false  "Faculty ID (UUID) program studi"
+// @Param        academic_year  query     string  false  "Angkatan"
+// @Param        academic_status  query   string  false  "Status akademik: active | on_leave | graduated | dropped_out"
+// @Param        sort           query     string  false  "full_name | nim | program_study | academic_year | created_at, awalan - untuk menurun"
+// @Param        order          query     string  false  "asc | desc"
+// @Param        page           query     int     false  "Halaman (mode offset)"
+// @Param        limit          query     int     false  "Jumlah per halaman (maks 100)"
+// @Param        cursor         query     string  false  "Cursor dari meta.next_cursor (mode cursor)"
 // @Success      200  {object}  map[string][]models.GetStudent
-// @Failure      500  {object}  map[string]string
+// @Failure      400  {object}  apperror.Problem
+// @Failure      500  {object}  apperror.Problem
 // @Router       /students [get]
 func (s *studentService) GetStudents(c *fiber.Ctx) error {
 	const targetRole = "Mahasiswa"
+
+	q, err := parseListQuery(c)
+	if err != nil {
+		return listError(err)
+	}
 	
-	students, err := s.repo.GetAllStudentsByRole(c.Context(), targetRole)
+	students, meta, err := s.repo.GetAllStudentsByRole(c.Context(), targetRole, q)
 	
 	if err != nil {
-		return c.Status(500).JSON(fiber.Map{
-			"message": "Terjadi kesalahan pada server saat mengambil data mahasiswa",
-			"success": false,
-            "error":   err.Error(),
-		})
+		return listError(err)
 	}
 
+	message := i18n.Message(c, "messages.students_fetched")
 	if len(students) == 0 {
-		return c.JSON(fiber.Map{
-			"message": "Data Mahasiswa tidak ditemukan",
-			"success": true,
-			"data":    []string{},
-		})
+		message = i18n.Message(c, "messages.students_empty")
 	}
 
 	return c.JSON(fiber.Map{
-		"message": "Data Mahasiswa berhasil diambil",
+		"message": message,
 		"success": true,
 		"data":    students,
+		"meta":    meta,
 	})
 }
 
@@ -71,38 +97,28 @@ func (s *studentService) GetStudents(c *fiber.Ctx) error {
 // @Security     Bearer
 // @Param        id   path      string  true  "Student ID (UUID)"
 // @Success      200  {object}  map[string]models.GetStudent
-// @Failure      400  {object}  map[string]string "Format ID salah"
-// @Failure      404  {object}  map[string]string "Tidak ditemukan"
-// @Failure      500  {object}  map[string]string
+// @Failure      400  {object}  apperror.Problem "Format ID salah"
+// @Failure      404  {object}  apperror.Problem "Tidak ditemukan"
+// @Failure      500  {object}  apperror.Problem
 // @Router       /students/{id} [get]
 func (s *studentService) GetStudentByID(c *fiber.Ctx) error {
 	idParam := c.Params("id")
 
 	if _, err := uuid.Parse(idParam); err != nil {
-		return c.Status(400).JSON(fiber.Map{
-			"message": "Format ID tidak valid",
-			"success": false,
-		})
+		return invalidID("ID")
 	}
 
 	student, err := s.repo.GetStudentByID(c.Context(), idParam)
 	
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return c.Status(404).JSON(fiber.Map{
-				"message": "Data mahasiswa tidak ditemukan",
-				"success": false,
-			})
+			return apperror.NotFound("student_not_found")
 		}
-		return c.Status(500).JSON(fiber.Map{
-			"message": "Terjadi kesalahan pada server",
-			"success": false,
-            "error":   err.Error(),
-		})
+		return apperror.Internal(err)
 	}
 
 	return c.JSON(fiber.Map{
-		"message": "Data mahasiswa ditemukan",
+		"message": i18n.Message(c, "messages.student_found"),
 		"success": true,
 		"data":    student,
 	})
@@ -110,7 +126,7 @@ func (s *studentService) GetStudentByID(c *fiber.Ctx) error {
 
 // UpdateStudentAdvisor godoc
 // @Summary      Update Dosen Wali
-// @Description  Mengganti dosen wali (Advisor) untuk mahasiswa tertentu.
+// @Description  Mengganti dosen wali (Advisor) untuk mahasiswa tertentu. Perubahan dicatat di riwayat dosen wali. Ditolak jika kapasitas bimbingan dosen penuh, kecuali force bernilai true.
 // @Tags         Students
 // @Accept       json
 // @Produce      json
@@ -118,60 +134,83 @@ func (s *studentService) GetStudentByID(c *fiber.Ctx) error {
 // @Param        id       path      string                       true  "Student ID (UUID)"
 // @Param        request  body      models.UpdateAdvisorRequest  true  "Advisor ID Baru"
 // @Success      200      {object}  map[string]string
-// @Failure      400      {object}  map[string]string "ID salah / Dosen tidak ada"
-// @Failure      404      {object}  map[string]string "Mahasiswa tidak ditemukan"
-// @Failure      500      {object}  map[string]string
+// @Failure      400      {object}  apperror.Problem "ID salah / Dosen tidak ada"
+// @Failure      404      {object}  apperror.Problem "Mahasiswa tidak ditemukan"
+// @Failure      409      {object}  apperror.Problem "Kapasitas dosen penuh"
+// @Failure      422  {object}  apperror.Problem "Data tidak valid (errors berisi pesan per field)"
+// @Failure      500      {object}  apperror.Problem
 // @Router       /students/{id}/advisor [put]
 func (s *studentService) UpdateStudentAdvisor(c *fiber.Ctx) error {
 	
 	studentID := c.Params("id")
 	if _, err := uuid.Parse(studentID); err != nil {
-		return c.Status(400).JSON(fiber.Map{
-			"message": "Format Student ID tidak valid",
-			"success": false,
-		})
+		return invalidID("Student ID")
 	}
 
 	var req models.UpdateAdvisorRequest
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(400).JSON(fiber.Map{
-			"message": "Format data JSON tidak valid",
-			"success": false,
-		})
+	if err := bindBody(c, &req); err != nil {
+		return err
+	}
+	advisorID, _ := uuid.Parse(strings.TrimSpace(req.AdvisorID))
+
+	tx, err := s.db.BeginTx(c.Context(), nil)
+	if err != nil {
+		return apperror.Internal(err)
 	}
+	defer tx.Rollback()
 
-	if _, err := uuid.Parse(req.AdvisorID); err != nil {
-		return c.Status(400).JSON(fiber.Map{
-			"message": "Format Advisor ID tidak valid",
-			"success": false,
+	current, err := s.repo.GetAdvisorIDForUpdate(c.Context(), tx, uuid.MustParse(studentID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return apperror.NotFound("student_not_found")
+		}
+		return apperror.Internal(err)
+	}
+	// Dosen wali tidak berubah: tidak perlu cek kapasitas maupun baris riwayat baru
+	if current != nil && *current == advisorID {
+		return c.JSON(fiber.Map{
+			"message": i18n.Message(c, "messages.advisor_updated"),
+			"success": true,
 		})
 	}
 
-	err := s.repo.UpdateStudentAdvisor(c.Context(), studentID, req.AdvisorID)
+	if err := checkAdvisorCapacity(c.Context(), s.lecturerRepo, tx, advisorID, req.Force); err != nil {
+		return err
+	}
+
+	err = s.repo.ChangeAdvisor(c.Context(), tx, models.AdvisorChange{
+		StudentID: uuid.MustParse(studentID),
+		AdvisorID: advisorID,
+		ChangedBy: currentUserID(c),
+		Reason:    req.Reason,
+		At:        time.Now(),
+	})
 	if err != nil {
-		if err.Error() == "student_not_found" {
-			return c.Status(404).JSON(fiber.Map{
-				"message": "Data mahasiswa tidak ditemukan",
-				"success": false,
-			})
+		if err == sql.ErrNoRows {
+			return apperror.NotFound("student_not_found")
 		}
 		
 		if strings.Contains(err.Error(), "foreign key") {
-			return c.Status(400).JSON(fiber.Map{
-				"message": "ID Dosen Wali tidak ditemukan di database",
-				"success": false,
-			})
+			return apperror.BadRequest("advisor_not_found")
 		}
 
-		return c.Status(500).JSON(fiber.Map{
-			"message": "Gagal mengganti dosen wali",
-			"success": false,
-			"error":   err.Error(),
-		})
+		return apperror.Internal(err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return apperror.Internal(err)
 	}
 
 	return c.JSON(fiber.Map{
-		"message": "Dosen Wali berhasil diperbarui",
+		"message": i18n.Message(c, "messages.advisor_updated"),
 		"success": true,
 	})
-}
\ No newline at end of file
+}
+
+// currentUserID mengembalikan ID user yang sedang login, nil jika tidak ada
+func currentUserID(c *fiber.Ctx) *uuid.UUID {
+	if id, ok := c.Locals("user_id").(uuid.UUID); ok {
+		return &id
+	}
+	return nil
+}
@@ -0,0 +1,575 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"io"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+	"uas/app/models"
+	"uas/apperror"
+	"uas/i18n"
+	"uas/utils"
+	"uas/validation"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+const (
+	importMaxFileSize = 2 << 20
+	importMaxRows     = 5000
+)
+
+// importColumns memetakan judul kolom (huruf kecil, spasi jadi _) ke field ImportUserRow
+var importColumns = map[string]string{
+	"username":              "username",
+	"email":                 "email",
+	"full_name":             "full_name",
+	"nama":                  "full_name",
+	"nama_lengkap":          "full_name",
+	"password":              "password",
+	"nim":                   "nim",
+	"student_id":            "nim",
+	"program_study":         "program_study",
+	"program_studi":         "program_study",
+	"prodi":                 "program_study",
+	"academic_year":         "academic_year",
+	"academy_year":          "academic_year",
+	"angkatan":              "academic_year",
+	"advisor_code":          "advisor_code",
+	"advisor_lecturer_code": "advisor_code",
+	"kode_dosen_wali":       "advisor_code",
+}
+
+var importRequiredColumns = []string{"username", "email", "nim", "program_study", "academic_year", "advisor_code"}
+
+type importCandidate struct {
+	result         models.ImportUserRowResult
+	row            models.ImportUserRow
+	advisorID      uuid.UUID
+	programStudyID uuid.UUID
+	passwordHash   string
+}
+
+// ImportUsers godoc
+// @Summary      Import Mahasiswa Massal (Admin)
+// @Description  Membuat banyak akun mahasiswa sekaligus dari file CSV/XLSX dengan kolom username, email, nim, program_study, academic_year, advisor_code (opsional: full_name, password). Password yang kosong dibuatkan acak dan ditampilkan sekali di hasil import.
+// @Tags         Users
+// @Accept       multipart/form-data
+// @Produce      json
+// @Security     Bearer
+// @Param        file     formData  file    true   "File .csv atau .xlsx"
+// @Param        mode     formData  string  false  "atomic (default) atau best_effort"
+// @Param        dry_run  formData  bool    false  "true = hanya validasi, tidak menyimpan"
+// @Success      200  {object}  models.ImportUsersResult
+// @Success      201  {object}  models.ImportUsersResult
+// @Failure      400  {object}  apperror.Problem
+// @Failure      422  {object}  apperror.Problem "Ada baris tidak valid (mode atomic), data berisi models.ImportUsersResult"
+// @Router       /users/import [post]
+func (s *userService) ImportUsers(c *fiber.Ctx) error {
+	lang := i18n.FromContext(c)
+	mode := c.FormValue("mode", models.ImportModeAtomic)
+	if mode != models.ImportModeAtomic && mode != models.ImportModeBestEffort {
+		return apperror.BadRequest("invalid_import_mode")
+	}
+	dryRun := c.FormValue("dry_run") == "true"
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		return apperror.BadRequest("file_required").Variant("import")
+	}
+	if fileHeader.Size > importMaxFileSize {
+		return apperror.TooLarge("file_too_large").Variant("import")
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return apperror.BadRequest("file_unreadable").Variant("import")
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, importMaxFileSize))
+	if err != nil {
+		return apperror.BadRequest("file_unreadable").Variant("import")
+	}
+
+	// Error ReadSpreadsheet menjelaskan isi file kiriman klien sendiri, aman untuk ditampilkan
+	sheet, err := utils.ReadSpreadsheet(fileHeader.Filename, data)
+	if errors.Is(err, utils.ErrUnsupportedSpreadsheet) {
+		return apperror.BadRequest("invalid_import_file").Variant("format")
+	} else if err != nil {
+		return apperror.BadRequest("invalid_import_file").Variant("corrupt").WithParams(i18n.Params{"detail": err.Error()})
+	}
+
+	candidates, err := parseImportRows(sheet)
+	if err != nil {
+		return err
+	}
+
+	if err := s.validateImportRows(c.Context(), lang, candidates); err != nil {
+		return apperror.Internal(err)
+	}
+
+	result := summarizeImport(mode, dryRun, candidates)
+
+	if dryRun {
+		return c.JSON(fiber.Map{
+			"message": i18n.Message(c, "messages.import_validated"),
+			"success": result.Invalid == 0,
+			"data":    result,
+		})
+	}
+
+	if mode == models.ImportModeAtomic && result.Invalid > 0 {
+		return apperror.New(apperror.ValidationKind, "import_rows_invalid").With("data", result)
+	}
+
+	role, err := s.roleRepo.GetRoleByName(c.Context(), models.RoleMahasiswa)
+	if err != nil {
+		return apperror.Internal(err)
+	}
+
+	if err := hashImportPasswords(candidates); err != nil {
+		return apperror.Internal(err)
+	}
+
+	if mode == models.ImportModeAtomic {
+		s.commitImportAtomic(c.Context(), lang, currentUserID(c), role, candidates)
+	} else {
+		s.commitImportBestEffort(c.Context(), lang, currentUserID(c), role, candidates)
+	}
+
+	result = summarizeImport(mode, dryRun, candidates)
+
+	switch {
+	case result.Created == 0 && result.Failed > 0:
+		return apperror.Conflict("import_failed").With("data", result)
+	case result.Failed > 0 || result.Invalid > 0:
+		return c.JSON(fiber.Map{
+			"message": i18n.Message(c, "messages.import_partial", i18n.Params{"created": result.Created, "total": result.Total}),
+			"success": true,
+			"data":    result,
+		})
+	}
+
+	return c.Status(201).JSON(fiber.Map{
+		"message": i18n.Message(c, "messages.import_created", i18n.Params{"created": result.Created}),
+		"success": true,
+		"data":    result,
+	})
+}
+
+// parseImportRows membaca header dan mengubah setiap baris data menjadi kandidat. Baris kosong dilewati.
+func parseImportRows(sheet [][]string) ([]*importCandidate, error) {
+	if len(sheet) == 0 {
+		return nil, apperror.BadRequest("invalid_import_file").Variant("empty")
+	}
+
+	columns := make(map[string]int)
+	for i, title := range sheet[0] {
+		key := strings.ReplaceAll(strings.ToLower(strings.TrimSpace(title)), " ", "_")
+		if field, ok := importColumns[key]; ok {
+			columns[field] = i
+		}
+	}
+
+	var missing []string
+	for _, col := range importRequiredColumns {
+		if _, ok := columns[col]; !ok {
+			missing = append(missing, col)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, apperror.BadRequest("invalid_import_file").Variant("missing_columns").
+			WithParams(i18n.Params{"columns": strings.Join(missing, ", ")})
+	}
+
+	cell := func(row []string, field string) string {
+		i, ok := columns[field]
+		if !ok || i >= len(row) {
+			return ""
+		}
+		return strings.TrimSpace(row[i])
+	}
+
+	var candidates []*importCandidate
+	for i, row := range sheet[1:] {
+		if strings.TrimSpace(strings.Join(row, "")) == "" {
+			continue
+		}
+
+		record := models.ImportUserRow{
+			Username:     cell(row, "username"),
+			Email:        cell(row, "email"),
+			FullName:     cell(row, "full_name"),
+			Password:     cell(row, "password"),
+			NIM:          cell(row, "nim"),
+			ProgramStudy: cell(row, "program_study"),
+			AcademicYear: cell(row, "academic_year"),
+			AdvisorCode:  cell(row, "advisor_code"),
+		}
+		if record.FullName == "" {
+			record.FullName = record.Username
+		}
+
+		candidates = append(candidates, &importCandidate{
+			row: record,
+			result: models.ImportUserRowResult{
+				Row:      i + 2,
+				Username: record.Username,
+				NIM:      record.NIM,
+			},
+		})
+	}
+
+	if len(candidates) == 0 {
+		return nil, apperror.BadRequest("invalid_import_file").Variant("no_rows")
+	}
+	if len(candidates) > importMaxRows {
+		return nil, apperror.BadRequest("invalid_import_file").Variant("too_many_rows").
+			WithParams(i18n.Params{"max": importMaxRows})
+	}
+	return candidates, nil
+}
+
+// advisorLoadsByID mengambil beban bimbingan dosen wali yang dirujuk file import, dengan kapasitas
+// default yang sama seperti penugasan dosen wali biasa
+func (s *userService) advisorLoadsByID(ctx context.Context, advisors map[string]uuid.UUID) (map[uuid.UUID]*models.AdvisorLoad, error) {
+	ids := make([]uuid.UUID, 0, len(advisors))
+	for _, id := range advisors {
+		ids = append(ids, id)
+	}
+	byID := make(map[uuid.UUID]*models.AdvisorLoad, len(ids))
+	if len(ids) == 0 {
+		return byID, nil
+	}
+
+	loads, err := s.lecturerRepo.GetAdvisorLoads(ctx, nil, ids)
+	if err != nil {
+		return nil, err
+	}
+	applyDefaultCapacity(loads)
+	for i := range loads {
+		byID[loads[i].LecturerID] = &loads[i]
+	}
+	return byID, nil
+}
+
+// validateImportRows memeriksa format setiap baris, duplikat di dalam file, data yang sudah terdaftar, serta kode dan kapasitas dosen wali.
+// Pesan error per baris ditulis dalam bahasa lang
+func (s *userService) validateImportRows(ctx context.Context, lang i18n.Lang, candidates []*importCandidate) error {
+	var usernames, emails, nims, codes, programStudies []string
+	for _, cand := range candidates {
+		usernames = append(usernames, cand.row.Username)
+		emails = append(emails, cand.row.Email)
+		nims = append(nims, cand.row.NIM)
+		programStudies = append(programStudies, strings.Join(strings.Fields(cand.row.ProgramStudy), " "))
+		if cand.row.AdvisorCode != "" {
+			codes = append(codes, cand.row.AdvisorCode)
+		}
+	}
+
+	takenUsernames, takenEmails, err := s.userRepo.GetTakenIdentities(ctx, usernames, emails)
+	if err != nil {
+		return err
+	}
+	takenNIMs, err := s.studentRepo.GetTakenNIMs(ctx, nims)
+	if err != nil {
+		return err
+	}
+	programStudyIDs, err := s.studentRepo.GetProgramStudyIDsByNames(ctx, programStudies)
+	if err != nil {
+		return err
+	}
+	advisors := map[string]uuid.UUID{}
+	loads := map[uuid.UUID]*models.AdvisorLoad{}
+	if len(codes) > 0 {
+		if advisors, err = s.lecturerRepo.GetLecturerIDsByCodes(ctx, codes); err != nil {
+			return err
+		}
+		if loads, err = s.advisorLoadsByID(ctx, advisors); err != nil {
+			return err
+		}
+	}
+
+	seenUsernames := make(map[string]int)
+	seenEmails := make(map[string]int)
+	seenNIMs := make(map[string]int)
+
+	for _, cand := range candidates {
+		row := cand.row
+		var errs []string
+
+		rowError := func(key string, params ...i18n.Params) {
+			errs = append(errs, i18n.T(lang, "import."+key, params...))
+		}
+
+		username := strings.ToLower(row.Username)
+		email := strings.ToLower(row.Email)
+
+		switch {
+		case row.Username == "":
+			rowError("required", i18n.Params{"field": "username"})
+		case len(row.Username) > 50 || strings.ContainsAny(row.Username, " \t"):
+			rowError("username_invalid")
+		case seenUsernames[username] != 0:
+			rowError("duplicate", i18n.Params{"field": "username", "row": seenUsernames[username]})
+		case takenUsernames[username]:
+			rowError("taken", i18n.Params{"field": "username"})
+		}
+
+		switch {
+		case row.Email == "":
+			rowError("required", i18n.Params{"field": "email"})
+		case len(row.Email) > 100 || !validation.IsEmail(row.Email):
+			rowError("email_invalid")
+		case seenEmails[email] != 0:
+			rowError("duplicate", i18n.Params{"field": "email", "row": seenEmails[email]})
+		case takenEmails[email]:
+			rowError("taken", i18n.Params{"field": "email"})
+		}
+
+		switch {
+		case row.NIM == "":
+			rowError("required", i18n.Params{"field": "nim"})
+		case !validation.IsNIM(row.NIM):
+			rowError("nim_invalid")
+		case seenNIMs[row.NIM] != 0:
+			rowError("duplicate", i18n.Params{"field": "nim", "row": seenNIMs[row.NIM]})
+		case takenNIMs[row.NIM]:
+			rowError("taken", i18n.Params{"field": "nim"})
+		}
+
+		if row.ProgramStudy == "" {
+			rowError("required", i18n.Params{"field": "program_study"})
+		} else if id, ok := programStudyIDs[strings.ToLower(strings.Join(strings.Fields(row.ProgramStudy), " "))]; ok {
+			cand.programStudyID = id
+		} else {
+			rowError("program_study_unknown", i18n.Params{"name": row.ProgramStudy})
+		}
+		if !validation.IsAcademicYear(row.AcademicYear) {
+			rowError("academic_year_invalid")
+		}
+		if len(row.FullName) > 100 {
+			rowError("full_name_too_long")
+		}
+		if row.Password != "" && len(row.Password) < 8 {
+			rowError("password_too_short")
+		}
+
+		if row.AdvisorCode != "" {
+			advisorID, ok := advisors[row.AdvisorCode]
+			load := loads[advisorID]
+			switch {
+			case !ok:
+				rowError("advisor_unknown", i18n.Params{"code": row.AdvisorCode})
+			case load != nil && load.Full():
+				rowError("advisor_full", i18n.Params{"code": row.AdvisorCode})
+			case load != nil && len(errs) == 0:
+				// Hanya baris yang akan disimpan yang memakai kuota dosen
+				load.Advisees++
+			}
+			cand.advisorID = advisorID
+		}
+
+		if username != "" && seenUsernames[username] == 0 {
+			seenUsernames[username] = cand.result.Row
+		}
+		if email != "" && seenEmails[email] == 0 {
+			seenEmails[email] = cand.result.Row
+		}
+		if row.NIM != "" && seenNIMs[row.NIM] == 0 {
+			seenNIMs[row.NIM] = cand.result.Row
+		}
+
+		cand.result.Errors = errs
+		cand.result.Status = models.ImportRowValid
+		if len(errs) > 0 {
+			cand.result.Status = models.ImportRowInvalid
+		}
+	}
+
+	return nil
+}
+
+// hashImportPasswords meng-hash password baris valid secara paralel (bcrypt lambat untuk ratusan baris).
+// Baris tanpa password dibuatkan password acak yang dikembalikan di hasil import.
+func hashImportPasswords(candidates []*importCandidate) error {
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var firstErr error
+	sem := make(chan struct{}, runtime.NumCPU())
+
+	for _, cand := range candidates {
+		if cand.result.Status != models.ImportRowValid {
+			continue
+		}
+
+		if cand.row.Password == "" {
+			generated, err := utils.RandomURLToken(9)
+			if err != nil {
+				return err
+			}
+			cand.row.Password = generated
+			cand.result.InitialPassword = generated
+		}
+
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(cand *importCandidate) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			hash, err := utils.HashPassword(cand.row.Password)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil && firstErr == nil {
+				firstErr = err
+			}
+			cand.passwordHash = hash
+		}(cand)
+	}
+
+	wg.Wait()
+	return firstErr
+}
+
+func (cand *importCandidate) toUser(role models.Role) (models.User, *models.Student) {
+	now := time.Now()
+	user := models.User{
+		ID:           uuid.New(),
+		Username:     cand.row.Username,
+		Email:        cand.row.Email,
+		PasswordHash: cand.passwordHash,
+		FullName:     cand.row.FullName,
+		RoleID:       role.ID,
+		RoleName:     role.Name,
+		IsActive:     true,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+	student := &models.Student{
+		StudentID:      cand.row.NIM,
+		ProgramStudyID: cand.programStudyID,
+		ProgramStudy:   cand.row.ProgramStudy,
+		AcademicYear:   cand.row.AcademicYear,
+		AdvisorID:      cand.advisorID,
+	}
+	return user, student
+}
+
+// commitImportAtomic menyimpan semua baris dalam satu transaksi. Satu baris gagal membatalkan semuanya.
+func (s *userService) commitImportAtomic(ctx context.Context, lang i18n.Lang, actor *uuid.UUID, role models.Role, candidates []*importCandidate) {
+	// failed == nil berarti transaksinya sendiri yang gagal: semua baris ditandai gagal
+	failAll := func(failed *importCandidate, message string) {
+		for _, cand := range candidates {
+			cand.result.InitialPassword = ""
+			if failed == nil || cand == failed {
+				cand.result.Status = models.ImportRowFailed
+				cand.result.Errors = []string{message}
+			} else {
+				cand.result.Status = models.ImportRowSkipped
+			}
+		}
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		failAll(nil, i18n.T(lang, "import.tx_begin_failed"))
+		return
+	}
+	defer tx.Rollback()
+
+	for _, cand := range candidates {
+		user, student := cand.toUser(role)
+		if key, err := s.insertUserWithProfile(ctx, tx, actor, user, student, nil, false); err != nil {
+			failAll(cand, importFailureMessage(lang, key, err))
+			return
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		failAll(nil, i18n.T(lang, "import.tx_commit_failed"))
+		return
+	}
+
+	for _, cand := range candidates {
+		cand.result.Status = models.ImportRowCreated
+	}
+}
+
+// commitImportBestEffort menyimpan setiap baris valid dalam transaksinya sendiri
+func (s *userService) commitImportBestEffort(ctx context.Context, lang i18n.Lang, actor *uuid.UUID, role models.Role, candidates []*importCandidate) {
+	for _, cand := range candidates {
+		if cand.result.Status != models.ImportRowValid {
+			continue
+		}
+
+		err := s.importOne(ctx, lang, actor, role, cand)
+		if err != nil {
+			cand.result.Status = models.ImportRowFailed
+			cand.result.Errors = []string{err.Error()}
+			cand.result.InitialPassword = ""
+			continue
+		}
+		cand.result.Status = models.ImportRowCreated
+	}
+}
+
+func (s *userService) importOne(ctx context.Context, lang i18n.Lang, actor *uuid.UUID, role models.Role, cand *importCandidate) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.New(i18n.T(lang, "import.tx_begin_failed"))
+	}
+	defer tx.Rollback()
+
+	user, student := cand.toUser(role)
+	if key, err := s.insertUserWithProfile(ctx, tx, actor, user, student, nil, false); err != nil {
+		return errors.New(importFailureMessage(lang, key, err))
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.New(i18n.T(lang, "import.tx_commit_failed"))
+	}
+	return nil
+}
+
+// importFailureMessage menerjemahkan key dari insertUserWithProfile untuk laporan import
+func importFailureMessage(lang i18n.Lang, key string, err error) string {
+	message := i18n.T(lang, key)
+	if isDuplicateKey(err) {
+		return i18n.T(lang, "import.identity_taken", i18n.Params{"message": message})
+	}
+	return message
+}
+
+func summarizeImport(mode string, dryRun bool, candidates []*importCandidate) models.ImportUsersResult {
+	result := models.ImportUsersResult{
+		Mode:   mode,
+		DryRun: dryRun,
+		Total:  len(candidates),
+		Rows:   make([]models.ImportUserRowResult, 0, len(candidates)),
+	}
+
+	for _, cand := range candidates {
+		switch cand.result.Status {
+		case models.ImportRowValid:
+			result.Valid++
+		case models.ImportRowInvalid:
+			result.Invalid++
+		case models.ImportRowCreated:
+			result.Valid++
+			result.Created++
+		case models.ImportRowFailed:
+			result.Valid++
+			result.Failed++
+		case models.ImportRowSkipped:
+			result.Valid++
+		}
+		result.Rows = append(result.Rows, cand.result)
+	}
+	return result
+}
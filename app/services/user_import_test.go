@@ -0,0 +1,238 @@
+package services_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"uas/app/models"
+	"uas/app/services"
+	"uas/apperror"
+	"uas/mocks"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+const importCSV = "username,email,nim,program_study,academic_year,advisor_code\n" +
+	"maba_1,maba1@kampus.ac.id,2025001,Teknik Informatika,2025,DSN01\n" +
+	"maba_2,MABA1@kampus.ac.id,2025002,Teknik Informatika,2025,DSN01\n" +
+	"maba_3,maba3@kampus.ac.id,2024999,Sistem Informasi,2025,DSN99\n"
+
+func importRequest(filename string, content []byte, fields map[string]string) *http.Request {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	part, _ := writer.CreateFormFile("file", filename)
+	part.Write(content)
+	for k, v := range fields {
+		writer.WriteField(k, v)
+	}
+	writer.Close()
+
+	req := httptest.NewRequest("POST", "/users/import", body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	return req
+}
+
+func decodeImportResult(t *testing.T, resp *http.Response) models.ImportUsersResult {
+	var body struct {
+		Data models.ImportUsersResult `json:"data"`
+	}
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	return body.Data
+}
+
+// importProgramStudyID adalah program studi Teknik Informatika di data master; Sistem Informasi belum terdaftar
+var importProgramStudyID = uuid.New()
+
+func newImportMocks(advisorID uuid.UUID) (*mocks.MockUserRepo, *mocks.MockStudentRepo, *mocks.MockLecturerRepo) {
+	mockUserRepo := new(mocks.MockUserRepo)
+	mockStudentRepo := new(mocks.MockStudentRepo)
+	mockLecturerRepo := new(mocks.MockLecturerRepo)
+
+	mockUserRepo.On("GetTakenIdentities", mock.Anything, mock.Anything, mock.Anything).Return(map[string]bool{}, map[string]bool{}, nil)
+	mockStudentRepo.On("GetTakenNIMs", mock.Anything, mock.Anything).Return(map[string]bool{"2024999": true}, nil)
+	mockStudentRepo.On("GetProgramStudyIDsByNames", mock.Anything, mock.Anything).Return(map[string]uuid.UUID{"teknik informatika": importProgramStudyID}, nil)
+	mockLecturerRepo.On("GetLecturerIDsByCodes", mock.Anything, mock.Anything).Return(map[string]uuid.UUID{"DSN01": advisorID}, nil)
+	mockLecturerRepo.On("GetAdvisorLoads", mock.Anything, mock.Anything, []uuid.UUID{advisorID}).Return([]models.AdvisorLoad{{LecturerID: advisorID}}, nil)
+	return mockUserRepo, mockStudentRepo, mockLecturerRepo
+}
+
+func TestImportUsers_DryRun_ReportsRowErrors(t *testing.T) {
+	mockUserRepo, mockStudentRepo, mockLecturerRepo := newImportMocks(uuid.New())
+	userService := services.NewUserService(nil, mockUserRepo, mockStudentRepo, mockLecturerRepo, nil, nil)
+
+	app := fiber.New(fiber.Config{ErrorHandler: apperror.Handler})
+	app.Post("/users/import", userService.ImportUsers)
+
+	resp, _ := app.Test(importRequest("maba.csv", []byte(importCSV), map[string]string{"dry_run": "true"}))
+	assert.Equal(t, 200, resp.StatusCode)
+
+	result := decodeImportResult(t, resp)
+	assert.True(t, result.DryRun)
+	assert.Equal(t, 3, result.Total)
+	assert.Equal(t, 1, result.Valid)
+	assert.Equal(t, 2, result.Invalid)
+
+	assert.Equal(t, models.ImportRowValid, result.Rows[0].Status)
+	assert.Equal(t, 3, result.Rows[1].Row)
+	assert.Contains(t, result.Rows[1].Errors, "email sama dengan baris 2")
+	assert.Contains(t, result.Rows[2].Errors, "nim sudah terdaftar")
+	assert.Contains(t, result.Rows[2].Errors, "dosen wali dengan kode DSN99 tidak ditemukan")
+	assert.Contains(t, result.Rows[2].Errors, "program studi Sistem Informasi tidak terdaftar")
+
+	mockUserRepo.AssertNotCalled(t, "CreateUser", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestImportUsers_Atomic_RejectsWhenAnyRowInvalid(t *testing.T) {
+	db, mockDB, _ := sqlmock.New()
+	defer db.Close()
+
+	mockUserRepo, mockStudentRepo, mockLecturerRepo := newImportMocks(uuid.New())
+	userService := services.NewUserService(db, mockUserRepo, mockStudentRepo, mockLecturerRepo, new(mocks.MockRoleRepo), nil)
+
+	app := fiber.New(fiber.Config{ErrorHandler: apperror.Handler})
+	app.Post("/users/import", userService.ImportUsers)
+
+	resp, _ := app.Test(importRequest("maba.csv", []byte(importCSV), nil))
+	assert.Equal(t, 422, resp.StatusCode)
+	assert.Equal(t, 2, decodeImportResult(t, resp).Invalid)
+
+	mockUserRepo.AssertNotCalled(t, "CreateUser", mock.Anything, mock.Anything, mock.Anything)
+	assert.NoError(t, mockDB.ExpectationsWereMet())
+}
+
+func TestImportUsers_AdvisorCapacityCheckedPerRow(t *testing.T) {
+	advisorID := uuid.New()
+	mockUserRepo, mockStudentRepo, _ := newImportMocks(advisorID)
+	mockLecturerRepo := new(mocks.MockLecturerRepo)
+	mockLecturerRepo.On("GetLecturerIDsByCodes", mock.Anything, mock.Anything).Return(map[string]uuid.UUID{"DSN01": advisorID}, nil)
+	// sisa kuota satu mahasiswa: baris kedua harus ditolak
+	max := 3
+	mockLecturerRepo.On("GetAdvisorLoads", mock.Anything, mock.Anything, []uuid.UUID{advisorID}).
+		Return([]models.AdvisorLoad{{LecturerID: advisorID, Advisees: 2, MaxAdvisees: &max}}, nil)
+	userService := services.NewUserService(nil, mockUserRepo, mockStudentRepo, mockLecturerRepo, nil, nil)
+
+	app := fiber.New(fiber.Config{ErrorHandler: apperror.Handler})
+	app.Post("/users/import", userService.ImportUsers)
+
+	csv := "username,email,nim,program_study,academic_year,advisor_code\n" +
+		"maba_1,maba1@kampus.ac.id,2025001,Teknik Informatika,2025,DSN01\n" +
+		"maba_2,maba2@kampus.ac.id,2025002,Teknik Informatika,2025,DSN01\n"
+	resp, _ := app.Test(importRequest("maba.csv", []byte(csv), map[string]string{"dry_run": "true"}))
+	assert.Equal(t, 200, resp.StatusCode)
+
+	result := decodeImportResult(t, resp)
+	assert.Equal(t, 1, result.Valid)
+	assert.Equal(t, models.ImportRowValid, result.Rows[0].Status)
+	assert.Equal(t, models.ImportRowInvalid, result.Rows[1].Status)
+	assert.Contains(t, result.Rows[1].Errors, "dosen wali dengan kode DSN01 sudah mencapai kapasitas bimbingan")
+}
+
+// buildXLSX membuat workbook minimal: baris header memakai shared strings, baris data memakai inline string dan angka
+func buildXLSX(t *testing.T) []byte {
+	buf := &bytes.Buffer{}
+	zw := zip.NewWriter(buf)
+
+	files := map[string]string{
+		"xl/sharedStrings.xml": `<?xml version="1.0" encoding="UTF-8"?>
+<sst xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">
+<si><t>Username</t></si><si><t>Email</t></si><si><t>NIM</t></si>
+<si><r><t>Program </t></r><r><t>Study</t></r></si><si><t>Academic Year</t></si><si><t>Advisor Code</t></si>
+</sst>`,
+		"xl/worksheets/sheet1.xml": `<?xml version="1.0" encoding="UTF-8"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>
+<row r="1"><c r="A1" t="s"><v>0</v></c><c r="B1" t="s"><v>1</v></c><c r="C1" t="s"><v>2</v></c><c r="D1" t="s"><v>3</v></c><c r="E1" t="s"><v>4</v></c><c r="F1" t="s"><v>5</v></c></row>
+<row r="2"><c r="A2" t="inlineStr"><is><t>maba_1</t></is></c><c r="B2" t="inlineStr"><is><t>maba1@kampus.ac.id</t></is></c><c r="C2"><v>2025001</v></c><c r="D2" t="inlineStr"><is><t>Teknik Informatika</t></is></c><c r="E2"><v>2025</v></c><c r="F2" t="inlineStr"><is><t>DSN01</t></is></c></row>
+<row r="4"><c r="A4" t="inlineStr"><is><t>maba_3</t></is></c><c r="B4" t="inlineStr"><is><t>bukan-email</t></is></c><c r="C4"><v>2025003</v></c><c r="D4" t="inlineStr"><is><t>Sistem Informasi</t></is></c><c r="E4"><v>2025</v></c></row>
+</sheetData></worksheet>`,
+	}
+	for name, content := range files {
+		w, err := zw.Create(name)
+		assert.NoError(t, err)
+		w.Write([]byte(content))
+	}
+	assert.NoError(t, zw.Close())
+	return buf.Bytes()
+}
+
+func TestImportUsers_BestEffortXLSX_CreatesValidRows(t *testing.T) {
+	db, mockDB, _ := sqlmock.New()
+	defer db.Close()
+	mockDB.ExpectBegin()
+	mockDB.ExpectCommit()
+
+	advisorID := uuid.New()
+	roleID := uuid.New()
+	mockUserRepo, mockStudentRepo, mockLecturerRepo := newImportMocks(advisorID)
+	mockRoleRepo := new(mocks.MockRoleRepo)
+	userService := services.NewUserService(db, mockUserRepo, mockStudentRepo, mockLecturerRepo, mockRoleRepo, nil)
+
+	app := fiber.New(fiber.Config{ErrorHandler: apperror.Handler})
+	app.Post("/users/import", userService.ImportUsers)
+
+	mockRoleRepo.On("GetRoleByName", mock.Anything, models.RoleMahasiswa).Return(models.Role{ID: roleID, Name: models.RoleMahasiswa}, nil)
+	mockUserRepo.On("CreateUser", mock.Anything, mock.Anything, mock.MatchedBy(func(u models.User) bool {
+		return u.Username == "maba_1" && u.RoleID == roleID && u.PasswordHash != ""
+	})).Return(nil).Once()
+	mockStudentRepo.On("CreateStudent", mock.Anything, mock.Anything, mock.MatchedBy(func(s models.Student) bool {
+		return s.StudentID == "2025001" && s.ProgramStudyID == importProgramStudyID && s.AdvisorID == advisorID
+	})).Return(nil).Once()
+
+	resp, _ := app.Test(importRequest("maba.xlsx", buildXLSX(t), map[string]string{"mode": "best_effort"}))
+	assert.Equal(t, 200, resp.StatusCode)
+
+	result := decodeImportResult(t, resp)
+	assert.Equal(t, 2, result.Total)
+	assert.Equal(t, 1, result.Created)
+	assert.Equal(t, models.ImportRowCreated, result.Rows[0].Status)
+	assert.NotEmpty(t, result.Rows[0].InitialPassword)
+	assert.Equal(t, 4, result.Rows[1].Row)
+	assert.Equal(t, models.ImportRowInvalid, result.Rows[1].Status)
+	assert.Contains(t, result.Rows[1].Errors, "format email tidak valid")
+
+	mockUserRepo.AssertExpectations(t)
+	mockStudentRepo.AssertExpectations(t)
+	assert.NoError(t, mockDB.ExpectationsWereMet())
+}
+
+func TestImportUsers_DuplicateAtCommit_ReportedAsIdentityTaken(t *testing.T) {
+	db, mockDB, _ := sqlmock.New()
+	defer db.Close()
+	mockDB.ExpectBegin()
+	mockDB.ExpectRollback()
+
+	advisorID := uuid.New()
+	roleID := uuid.New()
+	mockUserRepo, mockStudentRepo, mockLecturerRepo := newImportMocks(advisorID)
+	mockRoleRepo := new(mocks.MockRoleRepo)
+	userService := services.NewUserService(db, mockUserRepo, mockStudentRepo, mockLecturerRepo, mockRoleRepo, nil)
+
+	app := fiber.New(fiber.Config{ErrorHandler: apperror.Handler})
+	app.Post("/users/import", userService.ImportUsers)
+
+	// username lolos validasi tapi keduluan request lain sebelum commit
+	mockRoleRepo.On("GetRoleByName", mock.Anything, models.RoleMahasiswa).Return(models.Role{ID: roleID, Name: models.RoleMahasiswa}, nil)
+	mockUserRepo.On("CreateUser", mock.Anything, mock.Anything, mock.Anything).
+		Return(errors.New(`pq: duplicate key value violates unique constraint "users_username_key"`))
+
+	csv := "username,email,nim,program_study,academic_year,advisor_code\n" +
+		"maba_1,maba1@kampus.ac.id,2025001,Teknik Informatika,2025,DSN01\n"
+	resp, _ := app.Test(importRequest("maba.csv", []byte(csv), map[string]string{"mode": "best_effort"}))
+	assert.Equal(t, 409, resp.StatusCode)
+
+	result := decodeImportResult(t, resp)
+	assert.Equal(t, 1, result.Failed)
+	assert.Equal(t, models.ImportRowFailed, result.Rows[0].Status)
+	assert.Equal(t, []string{"Gagal menyimpan data user: username, email, atau nim sudah terdaftar"}, result.Rows[0].Errors)
+	assert.Empty(t, result.Rows[0].InitialPassword)
+	mockStudentRepo.AssertNotCalled(t, "CreateStudent", mock.Anything, mock.Anything, mock.Anything)
+	assert.NoError(t, mockDB.ExpectationsWereMet())
+}
@@ -0,0 +1,189 @@
+package services
+
+import (
+	"database/sql"
+	"log"
+	"strings"
+	"time"
+	"uas/app/models"
+	"uas/app/repository"
+	"uas/apperror"
+	"uas/i18n"
+	"uas/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type UserPurgeService interface {
+	PurgeUser(c *fiber.Ctx) error
+}
+
+type userPurgeService struct {
+	db        *sql.DB
+	userRepo  repository.UserRepository
+	achRepo   repository.AchievementRepository
+	auditRepo repository.AuditRepository
+	storage   utils.Storage
+}
+
+func NewUserPurgeService(db *sql.DB, userRepo repository.UserRepository, achRepo repository.AchievementRepository, auditRepo repository.AuditRepository, storage utils.Storage) UserPurgeService {
+	return &userPurgeService{db: db, userRepo: userRepo, achRepo: achRepo, auditRepo: auditRepo, storage: storage}
+}
+
+// PurgeUser godoc
+// @Summary      Hapus User Permanen (Super-admin)
+// @Description  Menghapus permanen user beserta profil mahasiswa/dosen, referensi prestasi di Postgres, dokumen prestasi di MongoDB, lampiran, dan foto profil. Ditolak jika user punya prestasi terverifikasi kecuali force=true, dan jika dosen masih punya mahasiswa bimbingan aktif. Hanya super-admin.
+// @Tags         Users
+// @Produce      json
+// @Security     Bearer
+// @Param        id     path      string  true   "User ID (UUID)"
+// @Param        force  query     bool    false  "Tetap hapus meskipun ada prestasi terverifikasi"
+// @Success      200    {object}  models.UserPurgeResult
+// @Failure      403    {object}  apperror.Problem "Bukan super-admin"
+// @Failure      404    {object}  apperror.Problem
+// @Failure      409    {object}  apperror.Problem "Ada prestasi terverifikasi atau mahasiswa bimbingan"
+// @Router       /users/{id}/purge [delete]
+func (s *userPurgeService) PurgeUser(c *fiber.Ctx) error {
+	userID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return invalidID("ID")
+	}
+
+	actorID, ok := c.Locals("user_id").(uuid.UUID)
+	if !ok {
+		return apperror.Unauthorized("unauthorized")
+	}
+	if actorID == userID {
+		return apperror.Forbidden("self_delete")
+	}
+
+	actor, err := s.userRepo.GetUserByID(c.Context(), actorID)
+	if err != nil && err != sql.ErrNoRows {
+		return apperror.Internal(err)
+	}
+	if !actor.IsSuperAdmin {
+		return apperror.Forbidden("super_admin_required").Variant("purge")
+	}
+
+	force := c.QueryBool("force")
+
+	tx, err := s.db.BeginTx(c.Context(), nil)
+	if err != nil {
+		return apperror.Internal(err)
+	}
+	defer tx.Rollback()
+
+	plan, err := s.userRepo.GetPurgePlanForUpdate(c.Context(), tx, userID)
+	if err == sql.ErrNoRows {
+		return apperror.NotFound("user_not_found")
+	} else if err != nil {
+		return apperror.Internal(err)
+	}
+
+	// Admin aktif terakhir tidak boleh hilang (Admin yang sudah di-soft delete tidak dihitung lagi)
+	if plan.User.RoleName == models.RoleAdmin && plan.User.DeletedAt == nil {
+		adminCount, err := s.userRepo.CountUsersByRoleForUpdate(c.Context(), tx, models.RoleAdmin)
+		if err != nil {
+			return apperror.Internal(err)
+		}
+		if adminCount <= 1 {
+			return apperror.Conflict("last_admin")
+		}
+	}
+
+	if plan.ActiveAdvisees > 0 {
+		return apperror.Conflict("lecturer_has_advisees").WithParams(i18n.Params{"count": plan.ActiveAdvisees})
+	}
+
+	if plan.VerifiedAchievements > 0 && !force {
+		return apperror.Conflict("verified_achievements_exist").WithParams(i18n.Params{"count": plan.VerifiedAchievements})
+	}
+
+	// Ambil lampiran sebelum dokumen MongoDB dihapus
+	var attachmentURLs []string
+	if len(plan.MongoAchievementIDs) > 0 {
+		docs, err := s.achRepo.GetMongoDetailsByIDs(c.Context(), plan.MongoAchievementIDs)
+		if err != nil {
+			return apperror.Internal(err)
+		}
+		for _, doc := range docs {
+			for _, attachment := range doc.Attachments {
+				attachmentURLs = append(attachmentURLs, attachment.FileURL)
+			}
+		}
+	}
+
+	if err := s.userRepo.PurgeUser(c.Context(), tx, plan); err != nil {
+		return apperror.Internal(err)
+	}
+	if err := tx.Commit(); err != nil {
+		return apperror.Internal(err)
+	}
+
+	result := models.UserPurgeResult{
+		UserID:               userID,
+		Username:             plan.User.Username,
+		Students:             len(plan.StudentIDs),
+		Lecturers:            len(plan.LecturerIDs),
+		Achievements:         len(plan.MongoAchievementIDs),
+		VerifiedAchievements: plan.VerifiedAchievements,
+	}
+
+	// Postgres sudah bersih. MongoDB dan file tidak ikut transaksi, jadi kegagalan di sini hanya
+	// dilaporkan sebagai peringatan; sisa dokumen/file tidak lagi dirujuk data mana pun
+	if len(plan.MongoAchievementIDs) > 0 {
+		if _, err := s.achRepo.DeleteMongoAchievements(c.Context(), plan.MongoAchievementIDs); err != nil {
+			log.Printf("purge user %s: %v", userID, err)
+			result.Warnings = append(result.Warnings, i18n.Message(c, "report.purge.mongo_failed"))
+		}
+	}
+
+	photoPrefix := "photos/" + userID.String() + "/"
+	for _, url := range plan.PhotoURLs {
+		if key, ok := s.storage.KeyFromURL(url); ok && strings.HasPrefix(key, photoPrefix) {
+			attachmentURLs = append(attachmentURLs, url)
+		}
+	}
+	for _, url := range attachmentURLs {
+		key, ok := s.storage.KeyFromURL(url)
+		if !ok {
+			continue
+		}
+		if err := s.storage.Delete(c.Context(), key); err != nil {
+			log.Printf("purge user %s: gagal menghapus file %s: %v", userID, key, err)
+			result.Warnings = append(result.Warnings, i18n.Message(c, "report.purge.file_failed", i18n.Params{"file": key}))
+			continue
+		}
+		result.FilesDeleted++
+	}
+
+	// Jejak purge disimpan tanpa subject karena user-nya sudah tidak ada
+	err = s.auditRepo.CreateAuditLog(c.Context(), models.AuditLog{
+		ID:         uuid.New(),
+		ActorID:    &actorID,
+		Action:     models.AuditUserPurge,
+		Method:     c.Method(),
+		Path:       c.OriginalURL(),
+		StatusCode: 200,
+		IPAddress:  c.IP(),
+		Metadata: map[string]interface{}{
+			"user_id":               userID.String(),
+			"username":              plan.User.Username,
+			"email":                 plan.User.Email,
+			"achievements":          result.Achievements,
+			"verified_achievements": result.VerifiedAchievements,
+			"force":                 force,
+		},
+		CreatedAt: time.Now(),
+	})
+	if err != nil {
+		log.Printf("gagal mencatat audit %s untuk user %s: %v", models.AuditUserPurge, userID, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"message": i18n.Message(c, "messages.user_purged"),
+		"success": true,
+		"data":    result,
+	})
+}
@@ -0,0 +1,129 @@
+package services_test
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"uas/app/models"
+	"uas/app/services"
+	"uas/apperror"
+	"uas/mocks"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func newPurgeApp(purgeService services.UserPurgeService, actorID uuid.UUID) *fiber.App {
+	app := fiber.New(fiber.Config{ErrorHandler: apperror.Handler})
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("user_id", actorID)
+		return c.Next()
+	})
+	app.Delete("/users/:id/purge", purgeService.PurgeUser)
+	return app
+}
+
+func TestPurgeUser_VerifiedAchievementsNeedForce(t *testing.T) {
+	db, mockDB, _ := sqlmock.New()
+	defer db.Close()
+
+	mockUserRepo := new(mocks.MockUserRepo)
+	mockAchRepo := new(mocks.MockAchievementRepo)
+	mockAuditRepo := new(mocks.MockAuditRepo)
+	storage := mocks.NewMockStorage()
+	purgeService := services.NewUserPurgeService(db, mockUserRepo, mockAchRepo, mockAuditRepo, storage)
+
+	actorID, userID := uuid.New(), uuid.New()
+	mongoID := primitive.NewObjectID()
+	plan := models.UserPurgePlan{
+		User:                 models.User{ID: userID, Username: "mhs_lulus", RoleName: "Mahasiswa"},
+		StudentIDs:           []uuid.UUID{uuid.New()},
+		MongoAchievementIDs:  []string{mongoID.Hex()},
+		VerifiedAchievements: 1,
+		PhotoURLs:            []string{"/uploads/photos/" + userID.String() + "/a_512.jpg"},
+	}
+	storage.Files["1700000000_sertifikat.pdf"] = []byte("pdf")
+	storage.Files["photos/"+userID.String()+"/a_512.jpg"] = []byte("jpg")
+
+	mockUserRepo.On("GetUserByID", mock.Anything, actorID).Return(models.User{ID: actorID, IsSuperAdmin: true}, nil)
+	mockUserRepo.On("GetPurgePlanForUpdate", mock.Anything, mock.Anything, userID).Return(plan, nil)
+	app := newPurgeApp(purgeService, actorID)
+
+	// Tanpa force ditolak dan tidak ada yang terhapus
+	mockDB.ExpectBegin()
+	mockDB.ExpectRollback()
+	resp, _ := app.Test(httptest.NewRequest("DELETE", "/users/"+userID.String()+"/purge", nil))
+	assert.Equal(t, 409, resp.StatusCode)
+	assert.Len(t, storage.Files, 2)
+
+	// Dengan force: Postgres, MongoDB, lampiran, dan foto ikut terhapus
+	mockAchRepo.On("GetMongoDetailsByIDs", mock.Anything, plan.MongoAchievementIDs).Return(map[string]models.AchievementMongo{
+		mongoID.Hex(): {ID: mongoID, Attachments: []models.Attachment{{FileURL: "/uploads/1700000000_sertifikat.pdf"}}},
+	}, nil)
+	mockUserRepo.On("PurgeUser", mock.Anything, mock.Anything, plan).Return(nil)
+	mockAchRepo.On("DeleteMongoAchievements", mock.Anything, plan.MongoAchievementIDs).Return(1, nil)
+	mockAuditRepo.On("CreateAuditLog", mock.Anything, mock.MatchedBy(func(l models.AuditLog) bool {
+		return l.Action == models.AuditUserPurge && l.ActorID != nil && *l.ActorID == actorID && l.SubjectUserID == nil
+	})).Return(nil)
+	mockDB.ExpectBegin()
+	mockDB.ExpectCommit()
+
+	resp, _ = app.Test(httptest.NewRequest("DELETE", "/users/"+userID.String()+"/purge?force=true", nil))
+	assert.Equal(t, 200, resp.StatusCode)
+
+	var body struct {
+		Data models.UserPurgeResult `json:"data"`
+	}
+	json.NewDecoder(resp.Body).Decode(&body)
+	assert.Equal(t, 1, body.Data.Achievements)
+	assert.Equal(t, 2, body.Data.FilesDeleted)
+	assert.Empty(t, body.Data.Warnings)
+	assert.Empty(t, storage.Files)
+
+	assert.NoError(t, mockDB.ExpectationsWereMet())
+	mockUserRepo.AssertExpectations(t)
+	mockAchRepo.AssertExpectations(t)
+	mockAuditRepo.AssertExpectations(t)
+}
+
+func TestPurgeUser_OnlySuperAdmin(t *testing.T) {
+	mockUserRepo := new(mocks.MockUserRepo)
+	purgeService := services.NewUserPurgeService(nil, mockUserRepo, new(mocks.MockAchievementRepo), new(mocks.MockAuditRepo), mocks.NewMockStorage())
+
+	actorID := uuid.New()
+	mockUserRepo.On("GetUserByID", mock.Anything, actorID).Return(models.User{ID: actorID, RoleName: "Admin"}, nil)
+
+	resp, _ := newPurgeApp(purgeService, actorID).Test(httptest.NewRequest("DELETE", "/users/"+uuid.NewString()+"/purge?force=true", nil))
+	assert.Equal(t, 403, resp.StatusCode)
+	mockUserRepo.AssertNotCalled(t, "GetPurgePlanForUpdate", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestDeleteUser_LastAdminIsKept(t *testing.T) {
+	db, mockDB, _ := sqlmock.New()
+	defer db.Close()
+
+	mockUserRepo := new(mocks.MockUserRepo)
+	userService := services.NewUserService(db, mockUserRepo, nil, nil, nil, nil)
+
+	actorID, adminID := uuid.New(), uuid.New()
+	mockUserRepo.On("GetUserByID", mock.Anything, adminID).Return(models.User{ID: adminID, RoleName: models.RoleAdmin}, nil)
+	mockUserRepo.On("CountUsersByRoleForUpdate", mock.Anything, mock.Anything, models.RoleAdmin).Return(1, nil)
+	mockDB.ExpectBegin()
+	mockDB.ExpectRollback()
+
+	app := fiber.New(fiber.Config{ErrorHandler: apperror.Handler})
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("user_id", actorID)
+		return c.Next()
+	})
+	app.Delete("/users/:id", userService.DeleteUser)
+
+	resp, _ := app.Test(httptest.NewRequest("DELETE", "/users/"+adminID.String(), nil))
+	assert.Equal(t, 409, resp.StatusCode)
+	mockUserRepo.AssertNotCalled(t, "SoftDeleteUser", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	assert.NoError(t, mockDB.ExpectationsWereMet())
+}
@@ -3,10 +3,15 @@ package services_test
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
+	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 	"uas/app/models"
 	"uas/app/services"
+	"uas/apperror"
+	"uas/helpers"
 	"uas/mocks"
 
 	"github.com/DATA-DOG/go-sqlmock"
@@ -26,12 +31,16 @@ func TestCreateUser_Student_Success(t *testing.T) {
 	mockUserRepo := new(mocks.MockUserRepo)
 	mockStudentRepo := new(mocks.MockStudentRepo)
 	mockLecturerRepo := new(mocks.MockLecturerRepo)
+	mockRoleRepo := new(mocks.MockRoleRepo)
 
-	userService := services.NewUserService(db, mockUserRepo, mockStudentRepo, mockLecturerRepo)
+	userService := services.NewUserService(db, mockUserRepo, mockStudentRepo, mockLecturerRepo, mockRoleRepo, nil)
 
-	app := fiber.New()
+	app := fiber.New(fiber.Config{ErrorHandler: apperror.Handler})
 	app.Post("/users", userService.CreateUser)
 
+	mockRoleRepo.On("GetRoleByID", mock.Anything, uuid.MustParse("00000000-0000-0000-0000-000000000001")).
+		Return(models.Role{ID: uuid.MustParse("00000000-0000-0000-0000-000000000001"), Name: models.RoleMahasiswa}, nil)
+
 	mockDB.ExpectBegin()
 
 	mockUserRepo.On("CreateUser", mock.Anything, mock.Anything, mock.Anything).Return(nil)
@@ -42,6 +51,8 @@ func TestCreateUser_Student_Success(t *testing.T) {
 
 	// UUID Dummy
   dummyAdvisorID := uuid.MustParse("00000000-0000-0000-0000-000000000002")
+	mockLecturerRepo.On("GetAdvisorLoads", mock.Anything, mock.Anything, []uuid.UUID{dummyAdvisorID}).
+		Return([]models.AdvisorLoad{{LecturerID: dummyAdvisorID, Advisees: 3}}, nil)
 
 	input := models.CreateUserRequest{
 		Username: "maba_2025",
@@ -78,11 +89,23 @@ func TestCreateUser_Rollback_OnError(t *testing.T) {
 	defer db.Close()
 
 	mockUserRepo := new(mocks.MockUserRepo)
-	userService := services.NewUserService(db, mockUserRepo, nil, nil)
+	mockRoleRepo := new(mocks.MockRoleRepo)
+	userService := services.NewUserService(db, mockUserRepo, nil, nil, mockRoleRepo, nil)
+
+	// hanya super-admin yang boleh membuat Admin
+	actorID := uuid.New()
+	mockUserRepo.On("GetUserByID", mock.Anything, actorID).Return(models.User{ID: actorID, IsSuperAdmin: true}, nil)
 
-	app := fiber.New()
+	app := fiber.New(fiber.Config{ErrorHandler: apperror.Handler})
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("user_id", actorID)
+		return c.Next()
+	})
 	app.Post("/users", userService.CreateUser)
 
+	roleID := uuid.MustParse("00000000-0000-0000-0000-000000000001")
+	mockRoleRepo.On("GetRoleByID", mock.Anything, roleID).Return(models.Role{ID: roleID, Name: models.RoleAdmin}, nil)
+
 	mockDB.ExpectBegin()
 
 	mockUserRepo.On("CreateUser", mock.Anything, mock.Anything, mock.Anything).Return(assert.AnError)
@@ -91,8 +114,11 @@ func TestCreateUser_Rollback_OnError(t *testing.T) {
 
 	input := models.CreateUserRequest{
 		Username: "error_user",
-		Password: "123",
-		RoleID:   "00000000-0000-0000-0000-000000000001",
+		Email:    "error@kampus.ac.id",
+		Password: "password123",
+		FullName: "Error User",
+		RoleID:   roleID.String(),
+		RoleName: models.RoleAdmin,
 	}
 	body, _ := json.Marshal(input)
 	req := httptest.NewRequest("POST", "/users", bytes.NewReader(body))
@@ -104,4 +130,410 @@ func TestCreateUser_Rollback_OnError(t *testing.T) {
 	if err := mockDB.ExpectationsWereMet(); err != nil {
 		t.Errorf("there were unfulfilled expectations: %s", err)
 	}
-}
\ No newline at end of file
+}
+func TestCreateUser_DuplicateIdentity_Conflict(t *testing.T) {
+	duplicate := errors.New(`pq: duplicate key value violates unique constraint "users_email_key"`)
+	for _, tc := range []struct {
+		name       string
+		userErr    error
+		studentErr error
+		code       string
+	}{
+		{"username or email", duplicate, nil, "user_exists"},
+		{"nim", nil, duplicate, "nim_taken"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			db, mockDB, _ := sqlmock.New()
+			defer db.Close()
+
+			mockUserRepo := new(mocks.MockUserRepo)
+			mockStudentRepo := new(mocks.MockStudentRepo)
+			mockRoleRepo := new(mocks.MockRoleRepo)
+			userService := services.NewUserService(db, mockUserRepo, mockStudentRepo, nil, mockRoleRepo, nil)
+
+			app := fiber.New(fiber.Config{ErrorHandler: apperror.Handler})
+			app.Post("/users", userService.CreateUser)
+
+			roleID := uuid.New()
+			mockRoleRepo.On("GetRoleByID", mock.Anything, roleID).Return(models.Role{ID: roleID, Name: models.RoleMahasiswa}, nil)
+			mockUserRepo.On("CreateUser", mock.Anything, mock.Anything, mock.Anything).Return(tc.userErr)
+			mockStudentRepo.On("CreateStudent", mock.Anything, mock.Anything, mock.Anything).Return(tc.studentErr)
+
+			mockDB.ExpectBegin()
+			mockDB.ExpectRollback()
+
+			body, _ := json.Marshal(models.CreateUserRequest{
+				Username: "maba_2025",
+				Email:    "maba@kampus.ac.id",
+				Password: "password123",
+				FullName: "Maba",
+				RoleName: models.RoleMahasiswa,
+				RoleID:   roleID.String(),
+				Student:  &models.Student{StudentID: "2025001", ProgramStudy: "Teknik Informatika", AcademicYear: "2025"},
+			})
+			req := httptest.NewRequest("POST", "/users", bytes.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+
+			resp, _ := app.Test(req)
+			assert.Equal(t, 409, resp.StatusCode)
+
+			var problem apperror.Problem
+			assert.NoError(t, json.NewDecoder(resp.Body).Decode(&problem))
+			assert.Equal(t, tc.code, problem.Code)
+			assert.NoError(t, mockDB.ExpectationsWereMet())
+		})
+	}
+}
+
+func newUpdateRoleApp(userService services.UserService, actorID uuid.UUID) *fiber.App {
+	app := fiber.New(fiber.Config{ErrorHandler: apperror.Handler})
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("user_id", actorID)
+		return c.Next()
+	})
+	app.Put("/users/:id/role", userService.UpdateUserRole)
+	return app
+}
+
+func updateRoleRequest(userID uuid.UUID, body models.UpdateRole) *http.Request {
+	payload, _ := json.Marshal(body)
+	req := httptest.NewRequest("PUT", "/users/"+userID.String()+"/role", bytes.NewReader(payload))
+	req.Header.Set("Content-Type", "application/json")
+	return req
+}
+
+func TestUpdateUserRole_CannotChangeOwnRole(t *testing.T) {
+	mockUserRepo := new(mocks.MockUserRepo)
+	userService := services.NewUserService(nil, mockUserRepo, nil, nil, nil, nil)
+
+	actorID := uuid.New()
+	app := newUpdateRoleApp(userService, actorID)
+
+	resp, _ := app.Test(updateRoleRequest(actorID, models.UpdateRole{RoleID: uuid.NewString()}))
+	assert.Equal(t, 403, resp.StatusCode)
+	mockUserRepo.AssertNotCalled(t, "GetUserByID", mock.Anything, mock.Anything)
+}
+
+func TestUpdateUserRole_OnlySuperAdminGrantsAdmin(t *testing.T) {
+	mockUserRepo := new(mocks.MockUserRepo)
+	mockRoleRepo := new(mocks.MockRoleRepo)
+	userService := services.NewUserService(nil, mockUserRepo, nil, nil, mockRoleRepo, nil)
+
+	actorID, targetID, adminRoleID := uuid.New(), uuid.New(), uuid.New()
+	app := newUpdateRoleApp(userService, actorID)
+
+	mockUserRepo.On("GetUserByID", mock.Anything, targetID).Return(models.User{ID: targetID, RoleID: uuid.New(), RoleName: "Dosen Wali"}, nil)
+	mockUserRepo.On("GetUserByID", mock.Anything, actorID).Return(models.User{ID: actorID, RoleName: "Admin", IsSuperAdmin: false}, nil)
+	mockRoleRepo.On("GetRoleByID", mock.Anything, adminRoleID).Return(models.Role{ID: adminRoleID, Name: "Admin", IsSystem: true}, nil)
+
+	resp, _ := app.Test(updateRoleRequest(targetID, models.UpdateRole{RoleID: adminRoleID.String()}))
+	assert.Equal(t, 403, resp.StatusCode)
+	mockUserRepo.AssertNotCalled(t, "UpdateUserRole", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestUpdateUserRole_LastAdminCannotBeDemoted(t *testing.T) {
+	db, mockDB, _ := sqlmock.New()
+	defer db.Close()
+
+	mockUserRepo := new(mocks.MockUserRepo)
+	mockRoleRepo := new(mocks.MockRoleRepo)
+	userService := services.NewUserService(db, mockUserRepo, nil, nil, mockRoleRepo, nil)
+
+	actorID, targetID, dosenRoleID := uuid.New(), uuid.New(), uuid.New()
+	app := newUpdateRoleApp(userService, actorID)
+
+	mockUserRepo.On("GetUserByID", mock.Anything, targetID).Return(models.User{ID: targetID, RoleID: uuid.New(), RoleName: "Admin", IsActive: true}, nil)
+	mockRoleRepo.On("GetRoleByID", mock.Anything, dosenRoleID).Return(models.Role{ID: dosenRoleID, Name: "Dosen Wali"}, nil)
+	mockUserRepo.On("CountUsersByRoleForUpdate", mock.Anything, mock.Anything, "Admin").Return(1, nil)
+
+	mockDB.ExpectBegin()
+	mockDB.ExpectRollback()
+
+	resp, _ := app.Test(updateRoleRequest(targetID, models.UpdateRole{RoleID: dosenRoleID.String()}))
+	assert.Equal(t, 409, resp.StatusCode)
+	assert.NoError(t, mockDB.ExpectationsWereMet())
+}
+
+func TestUpdateUserRole_InactiveAdminCanBeDemoted(t *testing.T) {
+	db, mockDB, _ := sqlmock.New()
+	defer db.Close()
+
+	mockUserRepo := new(mocks.MockUserRepo)
+	mockRoleRepo := new(mocks.MockRoleRepo)
+	userService := services.NewUserService(db, mockUserRepo, nil, nil, mockRoleRepo, nil)
+
+	actorID, targetID, operatorRoleID := uuid.New(), uuid.New(), uuid.New()
+	app := newUpdateRoleApp(userService, actorID)
+
+	// Admin aktif lain masih ada, tapi Admin nonaktif tidak dihitung oleh CountUsersByRoleForUpdate
+	mockUserRepo.On("GetUserByID", mock.Anything, targetID).Return(models.User{ID: targetID, RoleID: uuid.New(), RoleName: "Admin", IsActive: false}, nil)
+	mockRoleRepo.On("GetRoleByID", mock.Anything, operatorRoleID).Return(models.Role{ID: operatorRoleID, Name: "Operator"}, nil)
+	mockUserRepo.On("UpdateUserRole", mock.Anything, mock.Anything, targetID, operatorRoleID).Return(nil)
+
+	mockDB.ExpectBegin()
+	mockDB.ExpectCommit()
+
+	resp, _ := app.Test(updateRoleRequest(targetID, models.UpdateRole{RoleID: operatorRoleID.String()}))
+	assert.Equal(t, 200, resp.StatusCode)
+	mockUserRepo.AssertNotCalled(t, "CountUsersByRoleForUpdate", mock.Anything, mock.Anything, mock.Anything)
+	assert.NoError(t, mockDB.ExpectationsWereMet())
+}
+
+func TestUpdateUserRole_CustomRoleWithAdminPermissionRequiresSuperAdmin(t *testing.T) {
+	mockUserRepo := new(mocks.MockUserRepo)
+	mockRoleRepo := new(mocks.MockRoleRepo)
+	mockPermRepo := new(mocks.MockPermissionRepo)
+	resolver := helpers.NewPermissionResolver(mockPermRepo, time.Minute)
+	userService := services.NewUserService(nil, mockUserRepo, nil, nil, mockRoleRepo, resolver)
+
+	actorID, targetID, operatorRoleID := uuid.New(), uuid.New(), uuid.New()
+	app := newUpdateRoleApp(userService, actorID)
+
+	mockUserRepo.On("GetUserByID", mock.Anything, targetID).Return(models.User{ID: targetID, RoleID: uuid.New(), RoleName: "Dosen Wali"}, nil)
+	mockUserRepo.On("GetUserByID", mock.Anything, actorID).Return(models.User{ID: actorID, RoleName: "Admin", IsSuperAdmin: false}, nil)
+	mockRoleRepo.On("GetRoleByID", mock.Anything, operatorRoleID).Return(models.Role{ID: operatorRoleID, Name: "Operator"}, nil)
+	mockPermRepo.On("GetPermissionNamesByRole", mock.Anything, "Operator").Return([]string{"users:read", "roles:manage"}, nil)
+
+	resp, _ := app.Test(updateRoleRequest(targetID, models.UpdateRole{RoleID: operatorRoleID.String()}))
+	assert.Equal(t, 403, resp.StatusCode)
+	mockUserRepo.AssertNotCalled(t, "UpdateUserRole", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestCreateUser_AdminRoleRequiresSuperAdmin(t *testing.T) {
+	mockUserRepo := new(mocks.MockUserRepo)
+	mockRoleRepo := new(mocks.MockRoleRepo)
+	userService := services.NewUserService(nil, mockUserRepo, nil, nil, mockRoleRepo, nil)
+
+	actorID, adminRoleID := uuid.New(), uuid.New()
+	app := fiber.New(fiber.Config{ErrorHandler: apperror.Handler})
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("user_id", actorID)
+		return c.Next()
+	})
+	app.Post("/users", userService.CreateUser)
+
+	mockRoleRepo.On("GetRoleByID", mock.Anything, adminRoleID).Return(models.Role{ID: adminRoleID, Name: models.RoleAdmin, IsSystem: true}, nil)
+	mockUserRepo.On("GetUserByID", mock.Anything, actorID).Return(models.User{ID: actorID, RoleName: models.RoleAdmin, IsSuperAdmin: false}, nil)
+
+	body, _ := json.Marshal(models.CreateUserRequest{
+		Username: "admin_baru",
+		Email:    "admin.baru@kampus.ac.id",
+		Password: "password123",
+		FullName: "Admin Baru",
+		RoleID:   adminRoleID.String(),
+		RoleName: models.RoleAdmin,
+	})
+	req := httptest.NewRequest("POST", "/users", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, _ := app.Test(req)
+	assert.Equal(t, 403, resp.StatusCode)
+	mockUserRepo.AssertNotCalled(t, "CreateUser", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestUpdateUserRole_StudentToLecturer_SwitchesProfile(t *testing.T) {
+	db, mockDB, _ := sqlmock.New()
+	defer db.Close()
+
+	mockUserRepo := new(mocks.MockUserRepo)
+	mockStudentRepo := new(mocks.MockStudentRepo)
+	mockLecturerRepo := new(mocks.MockLecturerRepo)
+	mockRoleRepo := new(mocks.MockRoleRepo)
+	userService := services.NewUserService(db, mockUserRepo, mockStudentRepo, mockLecturerRepo, mockRoleRepo, nil)
+
+	actorID, targetID, dosenRoleID := uuid.New(), uuid.New(), uuid.New()
+	app := newUpdateRoleApp(userService, actorID)
+
+	mockUserRepo.On("GetUserByID", mock.Anything, targetID).Return(models.User{ID: targetID, RoleID: uuid.New(), RoleName: "Mahasiswa"}, nil)
+	mockRoleRepo.On("GetRoleByID", mock.Anything, dosenRoleID).Return(models.Role{ID: dosenRoleID, Name: "Dosen Wali"}, nil)
+	mockUserRepo.On("UpdateUserRole", mock.Anything, mock.Anything, targetID, dosenRoleID).Return(nil)
+	mockStudentRepo.On("RetireStudent", mock.Anything, mock.Anything, targetID).Return(nil)
+	mockLecturerRepo.On("ReactivateLecturer", mock.Anything, mock.Anything, targetID).Return(false, nil)
+	mockLecturerRepo.On("CreateLecture", mock.Anything, mock.Anything, mock.MatchedBy(func(l models.Lecture) bool {
+		return l.UserID == targetID && l.LectureID == "198001012005011001"
+	})).Return(nil)
+
+	mockDB.ExpectBegin()
+	mockDB.ExpectCommit()
+
+	resp, _ := app.Test(updateRoleRequest(targetID, models.UpdateRole{
+		RoleID:  dosenRoleID.String(),
+		Lecture: &models.Lecture{LectureID: "198001012005011001", Department: "Teknik Informatika"},
+	}))
+	assert.Equal(t, 200, resp.StatusCode)
+	assert.NoError(t, mockDB.ExpectationsWereMet())
+	mockStudentRepo.AssertExpectations(t)
+	mockLecturerRepo.AssertExpectations(t)
+}
+
+func TestCreateUser_Student_AdvisorCapacityFull(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		force  bool
+		status int
+	}{
+		{"rejected", false, 409},
+		{"forced", true, 201},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			db, mockDB, _ := sqlmock.New()
+			defer db.Close()
+
+			mockUserRepo := new(mocks.MockUserRepo)
+			mockStudentRepo := new(mocks.MockStudentRepo)
+			mockLecturerRepo := new(mocks.MockLecturerRepo)
+			mockRoleRepo := new(mocks.MockRoleRepo)
+			userService := services.NewUserService(db, mockUserRepo, mockStudentRepo, mockLecturerRepo, mockRoleRepo, nil)
+
+			app := fiber.New(fiber.Config{ErrorHandler: apperror.Handler})
+			app.Post("/users", userService.CreateUser)
+
+			roleID, advisorID := uuid.New(), uuid.New()
+			max := 2
+			mockRoleRepo.On("GetRoleByID", mock.Anything, roleID).Return(models.Role{ID: roleID, Name: models.RoleMahasiswa}, nil)
+			mockUserRepo.On("CreateUser", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+			mockLecturerRepo.On("GetAdvisorLoads", mock.Anything, mock.Anything, []uuid.UUID{advisorID}).
+				Return([]models.AdvisorLoad{{LecturerID: advisorID, Advisees: 2, MaxAdvisees: &max}}, nil)
+			mockStudentRepo.On("CreateStudent", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+			mockDB.ExpectBegin()
+			if tc.force {
+				mockDB.ExpectCommit()
+			} else {
+				mockDB.ExpectRollback()
+			}
+
+			body, _ := json.Marshal(models.CreateUserRequest{
+				Username: "maba_2025",
+				Email:    "maba@kampus.ac.id",
+				Password: "password123",
+				FullName: "Maba",
+				RoleName: models.RoleMahasiswa,
+				RoleID:   roleID.String(),
+				Student:  &models.Student{StudentID: "2025001", ProgramStudy: "Teknik Informatika", AcademicYear: "2025", AdvisorID: advisorID},
+				Force:    tc.force,
+			})
+			req := httptest.NewRequest("POST", "/users", bytes.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+
+			resp, _ := app.Test(req)
+			assert.Equal(t, tc.status, resp.StatusCode)
+			assert.NoError(t, mockDB.ExpectationsWereMet())
+			if !tc.force {
+				mockStudentRepo.AssertNotCalled(t, "CreateStudent", mock.Anything, mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestUpdateUserRole_LecturerToStudent_AdvisorCapacityFull(t *testing.T) {
+	db, mockDB, _ := sqlmock.New()
+	defer db.Close()
+
+	mockUserRepo := new(mocks.MockUserRepo)
+	mockStudentRepo := new(mocks.MockStudentRepo)
+	mockLecturerRepo := new(mocks.MockLecturerRepo)
+	mockRoleRepo := new(mocks.MockRoleRepo)
+	userService := services.NewUserService(db, mockUserRepo, mockStudentRepo, mockLecturerRepo, mockRoleRepo, nil)
+
+	actorID, targetID, mahasiswaRoleID, advisorID := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	app := newUpdateRoleApp(userService, actorID)
+
+	max := 1
+	mockUserRepo.On("GetUserByID", mock.Anything, targetID).Return(models.User{ID: targetID, RoleID: uuid.New(), RoleName: models.RoleDosen}, nil)
+	mockRoleRepo.On("GetRoleByID", mock.Anything, mahasiswaRoleID).Return(models.Role{ID: mahasiswaRoleID, Name: models.RoleMahasiswa}, nil)
+	mockUserRepo.On("UpdateUserRole", mock.Anything, mock.Anything, targetID, mahasiswaRoleID).Return(nil)
+	mockLecturerRepo.On("RetireLecturer", mock.Anything, mock.Anything, targetID).Return(nil)
+	mockStudentRepo.On("ReactivateStudent", mock.Anything, mock.Anything, targetID).Return(false, nil)
+	mockLecturerRepo.On("GetAdvisorLoads", mock.Anything, mock.Anything, []uuid.UUID{advisorID}).
+		Return([]models.AdvisorLoad{{LecturerID: advisorID, Advisees: 1, MaxAdvisees: &max}}, nil)
+
+	mockDB.ExpectBegin()
+	mockDB.ExpectRollback()
+
+	resp, _ := app.Test(updateRoleRequest(targetID, models.UpdateRole{
+		RoleID:  mahasiswaRoleID.String(),
+		Student: &models.Student{StudentID: "2025001", ProgramStudy: "Teknik Informatika", AcademicYear: "2025", AdvisorID: advisorID},
+	}))
+	assert.Equal(t, 409, resp.StatusCode)
+	assert.NoError(t, mockDB.ExpectationsWereMet())
+	mockStudentRepo.AssertNotCalled(t, "CreateStudent", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func updateUserRequest(userID uuid.UUID, body map[string]interface{}) *http.Request {
+	payload, _ := json.Marshal(body)
+	req := httptest.NewRequest("PUT", "/users/"+userID.String(), bytes.NewReader(payload))
+	req.Header.Set("Content-Type", "application/json")
+	return req
+}
+
+func newUpdateUserApp(userService services.UserService, actorID uuid.UUID) *fiber.App {
+	app := fiber.New(fiber.Config{ErrorHandler: apperror.Handler})
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("user_id", actorID)
+		return c.Next()
+	})
+	app.Put("/users/:id", userService.UpdateUser)
+	return app
+}
+
+func TestUpdateUser_IgnoresRoleID(t *testing.T) {
+	db, mockDB, _ := sqlmock.New()
+	defer db.Close()
+
+	mockUserRepo := new(mocks.MockUserRepo)
+	userService := services.NewUserService(db, mockUserRepo, nil, nil, nil, nil)
+
+	userID := uuid.New()
+	mockUserRepo.On("GetUserByID", mock.Anything, userID).
+		Return(models.User{ID: userID, RoleName: models.RoleMahasiswa, IsActive: true}, nil)
+	mockUserRepo.On("UpdateUser", mock.Anything, mock.Anything, userID, models.UpdateUser{
+		Username: "budi", Email: "budi@kampus.ac.id", FullName: "Budi",
+	}).Return(nil)
+	mockDB.ExpectBegin()
+	mockDB.ExpectCommit()
+
+	// role_id di body tidak ikut disimpan; role hanya bisa diganti lewat PUT /users/:id/role
+	resp, _ := newUpdateUserApp(userService, uuid.New()).Test(updateUserRequest(userID, map[string]interface{}{
+		"username": "budi", "email": "budi@kampus.ac.id", "full_name": "Budi",
+		"role_id": "00000000-0000-0000-0000-000000000001",
+	}))
+	assert.Equal(t, 200, resp.StatusCode)
+	mockUserRepo.AssertExpectations(t)
+}
+
+func TestUpdateUser_CannotDeactivateSelf(t *testing.T) {
+	mockUserRepo := new(mocks.MockUserRepo)
+	userService := services.NewUserService(nil, mockUserRepo, nil, nil, nil, nil)
+
+	userID := uuid.New()
+	mockUserRepo.On("GetUserByID", mock.Anything, userID).
+		Return(models.User{ID: userID, RoleName: models.RoleAdmin, IsActive: true}, nil)
+
+	resp, _ := newUpdateUserApp(userService, userID).Test(updateUserRequest(userID, map[string]interface{}{
+		"username": "admin", "email": "admin@kampus.ac.id", "full_name": "Admin", "is_active": false,
+	}))
+	assert.Equal(t, 403, resp.StatusCode)
+	mockUserRepo.AssertNotCalled(t, "UpdateUser", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestUpdateUser_CannotDeactivateLastAdmin(t *testing.T) {
+	db, mockDB, _ := sqlmock.New()
+	defer db.Close()
+
+	mockUserRepo := new(mocks.MockUserRepo)
+	userService := services.NewUserService(db, mockUserRepo, nil, nil, nil, nil)
+
+	userID := uuid.New()
+	mockUserRepo.On("GetUserByID", mock.Anything, userID).
+		Return(models.User{ID: userID, RoleName: models.RoleAdmin, IsActive: true}, nil)
+	mockUserRepo.On("CountUsersByRoleForUpdate", mock.Anything, mock.Anything, models.RoleAdmin).Return(1, nil)
+	mockDB.ExpectBegin()
+	mockDB.ExpectRollback()
+
+	resp, _ := newUpdateUserApp(userService, uuid.New()).Test(updateUserRequest(userID, map[string]interface{}{
+		"username": "admin", "email": "admin@kampus.ac.id", "full_name": "Admin", "is_active": false,
+	}))
+	assert.Equal(t, 409, resp.StatusCode)
+	mockUserRepo.AssertNotCalled(t, "UpdateUser", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
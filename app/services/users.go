@@ -1,10 +1,16 @@
 package services
 
 import (
+	"context"
 	"database/sql"
+	"strings"
 	"time"
 	"uas/app/models"
 	"uas/app/repository"
+	"uas/apperror"
+	"uas/helpers"
+	"uas/i18n"
+	"uas/validation"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
@@ -17,7 +23,9 @@ type UserService interface {
 	CreateUser(c *fiber.Ctx) error
 	UpdateUser(c *fiber.Ctx) error
 	DeleteUser(c *fiber.Ctx) error
+	RestoreUser(c *fiber.Ctx) error
 	UpdateUserRole(c *fiber.Ctx) error
+	ImportUsers(c *fiber.Ctx) error
 }
 
 type userService struct {
@@ -25,6 +33,8 @@ type userService struct {
 	userRepo     repository.UserRepository    
 	studentRepo  repository.StudentRepository 
 	lecturerRepo repository.LecturerRepository
+	roleRepo     repository.RoleRepository
+	permissions  helpers.PermissionResolver
 }
 
 
@@ -33,46 +43,120 @@ func NewUserService(
 	userRepo repository.UserRepository,
 	studentRepo repository.StudentRepository,
 	lecturerRepo repository.LecturerRepository,
+	roleRepo repository.RoleRepository,
+	permissions helpers.PermissionResolver,
 ) UserService {
 	return &userService{
 		db:           db,
 		userRepo:     userRepo,
 		studentRepo:  studentRepo,
 		lecturerRepo: lecturerRepo,
+		roleRepo:     roleRepo,
+		permissions:  permissions,
 	}
 }
 
+// invalidateUser membuat access token user yang sudah terbit tidak dipercaya lagi (role/status berubah)
+func (s *userService) invalidateUser(userID uuid.UUID) {
+	if s.permissions != nil {
+		s.permissions.InvalidateUser(userID)
+	}
+}
+
+// adminPermissions adalah permission setingkat Admin: pemegangnya bisa menaikkan hak aksesnya sendiri
+// atau bertindak sebagai user lain
+var adminPermissions = []string{"users:assign_role", "roles:manage", "users:impersonate"}
+
+// isAdminLevelRole bernilai true untuk role Admin dan role lain yang memegang salah satu adminPermissions
+func isAdminLevelRole(ctx context.Context, resolver helpers.PermissionResolver, roleName string) (bool, error) {
+	if roleName == models.RoleAdmin {
+		return true, nil
+	}
+	if resolver == nil {
+		return false, nil
+	}
+
+	for _, perm := range adminPermissions {
+		ok, err := resolver.HasPermission(ctx, roleName, perm)
+		if err != nil {
+			return false, err
+		}
+		if ok {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// requireSuperAdminFor menolak pemberian role setingkat Admin jika user yang login bukan super-admin
+func (s *userService) requireSuperAdminFor(c *fiber.Ctx, role models.Role) error {
+	adminLevel, err := isAdminLevelRole(c.Context(), s.permissions, role.Name)
+	if err != nil {
+		return apperror.Internal(err)
+	}
+	if !adminLevel {
+		return nil
+	}
+
+	actorID, ok := c.Locals("user_id").(uuid.UUID)
+	if !ok {
+		return apperror.Forbidden("super_admin_required").Variant("grant_admin")
+	}
+	actor, err := s.userRepo.GetUserByID(c.Context(), actorID)
+	if err != nil && err != sql.ErrNoRows {
+		return apperror.Internal(err)
+	}
+	if !actor.IsSuperAdmin {
+		return apperror.Forbidden("super_admin_required").Variant("grant_admin")
+	}
+	return nil
+}
+
 // GetAllUsers godoc
 // @Summary      Ambil Semua User
-// @Description  Mengambil daftar semua user yang terdaftar (Admin Only)
+// @Description  Mengambil daftar user dengan pencarian (nama, username, email, NIM, kode dosen), filter, urutan, dan paginasi offset atau cursor (Admin Only)
 // @Tags         Users
 // @Accept       json
 // @Produce      json
 // @Security     Bearer
+// @Param        q              query     string  false  "Kata pencarian"
+// @Param        role           query     string  false  "Nama role"
+// @Param        is_active      query     bool    false  "Status aktif"
+// @Param        program_study  query     string  false  "Program studi (mahasiswa)"
+// @Param        academic_year  query     string  false  "Angkatan (mahasiswa)"
+// @Param        department     query     string  false  "Department (dosen, atau department program studi mahasiswa)"
+// @Param        department_id  query     string  false  "Department ID (UUID)"
+// @Param        faculty_id     query     string  false  "Faculty ID (UUID)"
+// @Param        sort           query     string  false  "full_name | username | email | role | created_at, awalan - untuk menurun"
+// @Param        order          query     string  false  "asc | desc"
+// @Param        page           query     int     false  "Halaman (mode offset)"
+// @Param        limit          query     int     false  "Jumlah per halaman (maks 100)"
+// @Param        cursor         query     string  false  "Cursor dari meta.next_cursor (mode cursor, kosong untuk halaman pertama)"
 // @Success      200  {array}   models.User
-// @Failure      500  {object}  map[string]string
+// @Failure      400  {object}  apperror.Problem
+// @Failure      500  {object}  apperror.Problem
 // @Router       /users [get]
 func (s *userService) GetAllUsers(c *fiber.Ctx) error {
-	users, err := s.userRepo.GetAllUsers(c.Context())
+	q, err := parseListQuery(c)
 	if err != nil {
-		return c.Status(500).JSON(fiber.Map{
-			"message": "Terjadi kesalahan pada server",
-			"success": false,
-		})
+		return listError(err)
+	}
+
+	users, meta, err := s.userRepo.GetAllUsers(c.Context(), q)
+	if err != nil {
+		return listError(err)
 	}
 
+	message := i18n.Message(c, "messages.users_fetched")
 	if len(users) == 0 {
-		return c.JSON(fiber.Map{
-			"message": "Data User tidak ditemukan",
-			"success": true,
-			"data":    []string{},
-		})
+		message = i18n.Message(c, "messages.users_empty")
 	}
 
 	return c.JSON(fiber.Map{
-		"message": "Data berhasil diambil",
+		"message": message,
 		"success": true,
 		"data":    users,
+		"meta":    meta,
 	})
 }
 
@@ -85,35 +169,26 @@ func (s *userService) GetAllUsers(c *fiber.Ctx) error {
 // @Security     Bearer
 // @Param        id   path      string  true  "User ID (UUID)"
 // @Success      200  {object}  models.User
-// @Failure      400  {object}  map[string]string "Format ID salah"
-// @Failure      404  {object}  map[string]string "User tidak ditemukan"
-// @Failure      500  {object}  map[string]string
+// @Failure      400  {object}  apperror.Problem "Format ID salah"
+// @Failure      404  {object}  apperror.Problem "User tidak ditemukan"
+// @Failure      500  {object}  apperror.Problem
 // @Router       /users/{id} [get]
 func (s *userService) GetUserByID(c *fiber.Ctx) error {
 	idParam := c.Params("id")
 	userID, err := uuid.Parse(idParam)
 	if err != nil {
-		return c.Status(400).JSON(fiber.Map{
-			"message": "Format ID tidak valid",
-			"success": false,
-		})
+		return invalidID("ID")
 	}
 
 	user, err := s.userRepo.GetUserByID(c.Context(), userID)
 	if err == sql.ErrNoRows {
-		return c.Status(404).JSON(fiber.Map{
-			"message": "User tidak ditemukan",
-			"success": false,
-		})
+		return apperror.NotFound("user_not_found")
 	} else if err != nil {
-		return c.Status(500).JSON(fiber.Map{
-			"message": "Terjadi kesalahan server",
-			"success": false,
-		})
+		return apperror.Internal(err)
 	}
 
 	return c.JSON(fiber.Map{
-		"message": "Data user ditemukan",
+		"message": i18n.Message(c, "messages.user_found"),
 		"success": true,
 		"data":    user,
 	})
@@ -121,53 +196,55 @@ func (s *userService) GetUserByID(c *fiber.Ctx) error {
 
 // CreateUser godoc
 // @Summary      Tambah User Baru (Admin)
-// @Description  Membuat user baru beserta data Mahasiswa atau Dosen (Transactional)
+// @Description  Membuat user baru beserta data Mahasiswa atau Dosen (Transactional). Hanya super-admin yang boleh membuat user dengan role Admin atau role yang memegang permission setingkat Admin.
 // @Tags         Users
 // @Accept       json
 // @Produce      json
 // @Security     Bearer
 // @Param        request body models.CreateUserRequest true "Data User Lengkap"
 // @Success      201  {object} models.User
-// @Failure      400  {object} map[string]string
-// @Failure      500  {object} map[string]string
+// @Failure      400  {object} apperror.Problem
+// @Failure      403  {object} apperror.Problem "Role setingkat Admin tanpa super-admin"
+// @Failure      409  {object} apperror.Problem "Username, email, atau NIM sudah terdaftar, atau kapasitas bimbingan dosen wali penuh (kirim force: true untuk mengabaikan)"
+// @Failure      422  {object}  apperror.Problem "Data tidak valid (errors berisi pesan per field)"
+// @Failure      500  {object} apperror.Problem
 // @Router       /users [post]
 func (s *userService) CreateUser(c *fiber.Ctx) error {
 	var req models.CreateUserRequest
 
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(400).JSON(fiber.Map{
-			"message": "Format data tidak valid",
-			"success": false,
-		})
+	if err := bindBody(c, &req); err != nil {
+		return err
+	}
+
+	// role_name menentukan profil yang dibuat, jadi harus sesuai dengan role_id yang terdaftar
+	roleID, _ := uuid.Parse(strings.TrimSpace(req.RoleID))
+	role, err := s.roleRepo.GetRoleByID(c.Context(), roleID)
+	if err == sql.ErrNoRows {
+		return validationError(validation.Field("role_id", "exists", "validation.role_exists"))
+	} else if err != nil {
+		return apperror.Internal(err)
+	}
+	if req.RoleName != role.Name {
+		return validationError(validation.Field("role_name", "eqfield", "validation.role_name_match", i18n.Params{"role": role.Name}))
+	}
+	if err := s.requireSuperAdminFor(c, role); err != nil {
+		return err
 	}
 
 	// Hash password
 	hashedPwd, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
 	if err != nil {
-		return c.Status(500).JSON(fiber.Map{
-			"message": "Gagal mengenkripsi password",
-			"success": false,
-		})
+		return apperror.Internal(err)
 	}
 
 	// 1. MULAI TRANSAKSI
 	tx, err := s.db.Begin()
 	if err != nil {
-		return c.Status(500).JSON(fiber.Map{
-			"message": "Gagal memulai transaksi database",
-			"success": false,
-		})
+		return apperror.Internal(err)
 	}
 	defer tx.Rollback()
 
 	userID := uuid.New()
-	roleID, err := uuid.Parse(req.RoleID)
-	if err != nil {
-		return c.Status(400).JSON(fiber.Map{
-			"message": "Format Role ID salah",
-			"success": false,
-		})
-	}
 
 	newUser := models.User{
 		ID:           userID,
@@ -182,208 +259,451 @@ func (s *userService) CreateUser(c *fiber.Ctx) error {
 		UpdatedAt:    time.Now(),
 	}
 
-	// 2. INSERT USER
-	err = s.userRepo.CreateUser(c.Context(), tx, newUser)
-	if err != nil {
-		return c.Status(500).JSON(fiber.Map{
-			"message": "Gagal menyimpan data user",
-			"success": false,
-			"error":   err.Error(),
-		})
+	// 2. INSERT USER + PROFIL MAHASISWA/DOSEN
+	if key, err := s.insertUserWithProfile(c.Context(), tx, currentUserID(c), newUser, req.Student, req.Lecture, req.Force); isUnknownUnit(err) {
+		return unknownUnitError(err)
+	} else if key == "import.advisor_capacity_full" {
+		return err
+	} else if isDuplicateKey(err) && key == "import.save_student_failed" {
+		return apperror.Conflict("nim_taken")
+	} else if isDuplicateKey(err) {
+		return apperror.Conflict("user_exists")
+	} else if err != nil {
+		return apperror.Internal(err)
 	}
 
-	// 3. INSERT STUDENT
-	if req.RoleName == "Mahasiswa" && req.Student != nil {
+	// 3. COMMIT TRANSAKSI
+	if err := tx.Commit(); err != nil {
+		return apperror.Internal(err)
+	}
+
+	return c.Status(201).JSON(fiber.Map{
+		"message": i18n.Message(c, "messages.user_created"),
+		"success": true,
+		"data":    newUser,
+	})
+}
+
+// insertUserWithProfile menyimpan user beserta profil mahasiswa/dosennya di dalam tx; actor adalah user yang membuatnya.
+// Kapasitas dosen wali dicek di tx yang sama kecuali force.
+// Dipakai CreateUser dan import massal; string yang dikembalikan adalah key katalog pesan gagalnya.
+func (s *userService) insertUserWithProfile(ctx context.Context, tx *sql.Tx, actor *uuid.UUID, newUser models.User, student *models.Student, lecture *models.Lecture, force bool) (string, error) {
+	if err := s.userRepo.CreateUser(ctx, tx, newUser); err != nil {
+		return "import.save_user_failed", err
+	}
+
+	if newUser.RoleName == models.RoleMahasiswa && student != nil {
+		if err := checkAdvisorCapacity(ctx, s.lecturerRepo, tx, student.AdvisorID, force); err != nil {
+			return "import.advisor_capacity_full", err
+		}
+
 		newStudent := models.Student{
-			ID:           uuid.New(),
-			UserID:       userID,
-			StudentID:    req.Student.StudentID,
-			ProgramStudy: req.Student.ProgramStudy,
-			AcademicYear: req.Student.AcademicYear,
-			AdvisorID:    req.Student.AdvisorID,
-			CreatedAt:    time.Now(),
+			ID:             uuid.New(),
+			UserID:         newUser.ID,
+			StudentID:      student.StudentID,
+			ProgramStudyID: student.ProgramStudyID,
+			ProgramStudy:   student.ProgramStudy,
+			AcademicYear:   student.AcademicYear,
+			AdvisorID:      student.AdvisorID,
+			CreatedBy:      actor,
+			CreatedAt:      time.Now(),
 		}
 
-		if err := s.studentRepo.CreateStudent(c.Context(), tx, newStudent); err != nil {
-			return c.Status(500).JSON(fiber.Map{
-				"message": "Gagal menyimpan data mahasiswa",
-				"success": false,
-				"error":   err.Error(),
-			})
+		if err := s.studentRepo.CreateStudent(ctx, tx, newStudent); err != nil {
+			return "import.save_student_failed", err
 		}
 	}
 
-	if req.RoleName == "Dosen Wali" && req.Lecture != nil {
+	if newUser.RoleName == models.RoleDosen && lecture != nil {
 		newLecture := models.Lecture{
-			ID:         uuid.New(),
-			UserID:     userID,
-			LectureID:  req.Lecture.LectureID,
-			Department: req.Lecture.Department,
-			CreatedAt:  time.Now(),
-		}
-				
-		if err := s.lecturerRepo.CreateLecture(c.Context(), tx, newLecture); err != nil {
-			return c.Status(500).JSON(fiber.Map{
-				"message": "Gagal menyimpan data dosen",
-				"success": false,
-				"error":   err.Error(),
-			})
+			ID:           uuid.New(),
+			UserID:       newUser.ID,
+			LectureID:    lecture.LectureID,
+			DepartmentID: lecture.DepartmentID,
+			Department:   lecture.Department,
+			CreatedAt:    time.Now(),
 		}
-	}
 
-	// 5. COMMIT TRANSAKSI
-	if err := tx.Commit(); err != nil {
-		return c.Status(500).JSON(fiber.Map{
-			"message": "Gagal commit transaksi",
-			"success": false,
-		})
+		if err := s.lecturerRepo.CreateLecture(ctx, tx, newLecture); err != nil {
+			return "import.save_lecturer_failed", err
+		}
 	}
 
-	return c.Status(201).JSON(fiber.Map{
-		"message": "User berhasil dibuat",
-		"success": true,
-		"data":    newUser,
-	})
+	return "", nil
 }
 
+// UpdateUser godoc
+// @Summary      Update Data User
+// @Description  Memperbarui data akun user (username, email, nama lengkap, status aktif). Role diganti lewat PUT /users/{id}/role. User tidak bisa menonaktifkan akunnya sendiri, dan Admin aktif terakhir tidak bisa dinonaktifkan.
+// @Tags         Users
+// @Accept       json
+// @Produce      json
+// @Security     Bearer
+// @Param        id       path      string             true  "User ID (UUID)"
+// @Param        request  body      models.UpdateUser  true  "Data yang diupdate"
+// @Success      200      {object}  models.User
+// @Failure      400      {object}  apperror.Problem
+// @Failure      403      {object}  apperror.Problem "Menonaktifkan akun sendiri"
+// @Failure      404      {object}  apperror.Problem
+// @Failure      409      {object}  apperror.Problem "Admin aktif terakhir"
+// @Failure      422  {object}  apperror.Problem "Data tidak valid (errors berisi pesan per field)"
+// @Failure      500      {object}  apperror.Problem
+// @Router       /users/{id} [put]
 func (s *userService) UpdateUser(c *fiber.Ctx) error {
 	idParam := c.Params("id")
 	userID, err := uuid.Parse(idParam)
 	if err != nil {
-		return c.Status(400).JSON(fiber.Map{
-			"message": "Format ID tidak valid",
-			"success": false,
-		})
+		return invalidID("ID")
 	}
 
 	var user models.UpdateUser
-	if err := c.BodyParser(&user); err != nil {
-		return c.Status(400).JSON(fiber.Map{
-			"message": "Format data JSON tidak valid",
-			"success": false,
-		})
+	if err := bindBody(c, &user); err != nil {
+		return err
 	}
 
-	err = s.userRepo.UpdateUser(c.Context(), userID, user)
+	actorID, ok := c.Locals("user_id").(uuid.UUID)
+	if !ok {
+		return apperror.Unauthorized("unauthorized")
+	}
+
+	target, err := s.userRepo.GetUserByID(c.Context(), userID)
 	if err == sql.ErrNoRows {
-		return c.Status(404).JSON(fiber.Map{
-			"message": "User tidak ditemukan",
-			"success": false,
-		})
+		return apperror.NotFound("user_not_found")
 	} else if err != nil {
-		return c.Status(500).JSON(fiber.Map{
-			"message": "Gagal mengupdate data user",
-			"success": false,
-			"error":   err.Error(),
-		})
+		return apperror.Internal(err)
+	}
+	deactivating := user.IsActive != nil && !*user.IsActive && target.IsActive
+
+	if deactivating && actorID == userID {
+		return apperror.Forbidden("self_deactivate")
+	}
+
+	tx, err := s.db.BeginTx(c.Context(), nil)
+	if err != nil {
+		return apperror.Internal(err)
+	}
+	defer tx.Rollback()
+
+	// Admin aktif terakhir tidak boleh dinonaktifkan
+	if deactivating && target.RoleName == models.RoleAdmin {
+		adminCount, err := s.userRepo.CountUsersByRoleForUpdate(c.Context(), tx, models.RoleAdmin)
+		if err != nil {
+			return apperror.Internal(err)
+		}
+		if adminCount <= 1 {
+			return apperror.Conflict("last_admin").Variant("deactivate")
+		}
+	}
+
+	err = s.userRepo.UpdateUser(c.Context(), tx, userID, user)
+	if err == sql.ErrNoRows {
+		return apperror.NotFound("user_not_found")
+	} else if err != nil {
+		return apperror.Internal(err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return apperror.Internal(err)
+	}
+	if user.IsActive != nil && *user.IsActive != target.IsActive {
+		s.invalidateUser(userID)
 	}
 
 	return c.JSON(fiber.Map{
-		"message": "User berhasil diupdate",
+		"message": i18n.Message(c, "messages.user_updated"),
 		"success": true,
 		"data":    user,
 	})
 }
 
 // DeleteUser godoc
-// @Summary      Hapus User
-// @Description  Menghapus data user secara permanen (atau Soft Delete tergantung implementasi repo)
+// @Summary      Hapus User (Soft Delete)
+// @Description  Menandai user terhapus: user tidak bisa login, semua sesinya dicabut, dan tidak tampil di list (kecuali dengan filter deleted=true). Profil mahasiswa/dosen dan prestasinya tetap tersimpan dan bisa dipulihkan lewat restore. Hapus permanen memakai endpoint purge.
 // @Tags         Users
 // @Accept       json
 // @Produce      json
 // @Security     Bearer
 // @Param        id   path      string  true  "User ID (UUID)"
 // @Success      200  {object}  map[string]string
-// @Failure      400  {object}  map[string]string
-// @Failure      404  {object}  map[string]string
-// @Failure      500  {object}  map[string]string
+// @Failure      400  {object}  apperror.Problem
+// @Failure      403  {object}  apperror.Problem "Menghapus akun sendiri"
+// @Failure      404  {object}  apperror.Problem
+// @Failure      409  {object}  apperror.Problem "Admin terakhir"
+// @Failure      500  {object}  apperror.Problem
 // @Router       /users/{id} [delete]
 func (s *userService) DeleteUser(c *fiber.Ctx) error {
 	idParam := c.Params("id")
 	userID, err := uuid.Parse(idParam)
 	if err != nil {
-		return c.Status(400).JSON(fiber.Map{
-			"message": "Format ID tidak valid",
-			"success": false,
-		})
+		return invalidID("ID")
 	}
 
-	err = s.userRepo.DeleteUser(c.Context(), userID)
+	actorID, ok := c.Locals("user_id").(uuid.UUID)
+	if !ok {
+		return apperror.Unauthorized("unauthorized")
+	}
+	if actorID == userID {
+		return apperror.Forbidden("self_delete")
+	}
+
+	target, err := s.userRepo.GetUserByID(c.Context(), userID)
 	if err == sql.ErrNoRows {
-		return c.Status(404).JSON(fiber.Map{
-			"message": "User tidak ditemukan",
-			"success": false,
-		})
+		return apperror.NotFound("user_not_found")
 	} else if err != nil {
-		return c.Status(500).JSON(fiber.Map{
-			"message": "Gagal menghapus data user",
-			"success": false,
-			"error":   err.Error(),
-		})
+		return apperror.Internal(err)
+	}
+
+	tx, err := s.db.BeginTx(c.Context(), nil)
+	if err != nil {
+		return apperror.Internal(err)
 	}
+	defer tx.Rollback()
+
+	// Admin terakhir tidak boleh dihapus
+	if target.RoleName == models.RoleAdmin {
+		adminCount, err := s.userRepo.CountUsersByRoleForUpdate(c.Context(), tx, models.RoleAdmin)
+		if err != nil {
+			return apperror.Internal(err)
+		}
+		if adminCount <= 1 {
+			return apperror.Conflict("last_admin")
+		}
+	}
+
+	err = s.userRepo.SoftDeleteUser(c.Context(), tx, userID, actorID)
+	if err == sql.ErrNoRows {
+		return apperror.NotFound("user_not_found")
+	} else if err != nil {
+		return apperror.Internal(err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return apperror.Internal(err)
+	}
+	s.invalidateUser(userID)
 
 	return c.JSON(fiber.Map{
-		"message": "User berhasil dihapus",
+		"message": i18n.Message(c, "messages.user_deleted"),
 		"success": true,
 	})
 }
 
-// UpdateUser godoc
-// @Summary      Update Data User
-// @Description  Memperbarui data profile user (Username, Email, Fullname, dll)
+// RestoreUser godoc
+// @Summary      Pulihkan User
+// @Description  Memulihkan user yang dihapus lewat soft delete beserta profil dan prestasinya. User perlu login ulang karena sesi lamanya sudah dicabut.
+// @Tags         Users
+// @Produce      json
+// @Security     Bearer
+// @Param        id   path      string  true  "User ID (UUID)"
+// @Success      200  {object}  models.User
+// @Failure      400  {object}  apperror.Problem
+// @Failure      404  {object}  apperror.Problem "User tidak ditemukan atau tidak sedang dihapus"
+// @Router       /users/{id}/restore [post]
+func (s *userService) RestoreUser(c *fiber.Ctx) error {
+	userID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return invalidID("ID")
+	}
+
+	err = s.userRepo.RestoreUser(c.Context(), userID)
+	if err == sql.ErrNoRows {
+		return apperror.NotFound("user_not_found").Variant("deleted")
+	} else if err != nil {
+		return apperror.Internal(err)
+	}
+
+	user, err := s.userRepo.GetUserByID(c.Context(), userID)
+	if err != nil {
+		return apperror.Internal(err)
+	}
+
+	return c.JSON(fiber.Map{
+		"message": i18n.Message(c, "messages.user_restored"),
+		"success": true,
+		"data":    user,
+	})
+}
+
+// UpdateUserRole godoc
+// @Summary      Ganti Role User
+// @Description  Mengganti role user beserta profil Mahasiswa/Dosen dalam satu transaksi. User tidak bisa mengganti role sendiri, hanya super-admin yang boleh memberikan role Admin atau role yang memegang permission setingkat Admin (users:assign_role, roles:manage, users:impersonate), dan Admin aktif terakhir tidak bisa diturunkan.
 // @Tags         Users
 // @Accept       json
 // @Produce      json
 // @Security     Bearer
 // @Param        id       path      string             true  "User ID (UUID)"
-// @Param        request  body      models.UpdateUser  true  "Data yang diupdate"
-// @Success      200      {object}  models.User
-// @Failure      400      {object}  map[string]string
-// @Failure      404      {object}  map[string]string
-// @Failure      500      {object}  map[string]string
-// @Router       /users/{id} [put]
+// @Param        request  body      models.UpdateRole  true  "Role baru (+ data profil jika diperlukan)"
+// @Success      200      {object}  map[string]string
+// @Failure      400      {object}  apperror.Problem
+// @Failure      403      {object}  apperror.Problem "Aturan eskalasi hak akses"
+// @Failure      404      {object}  apperror.Problem
+// @Failure      409      {object}  apperror.Problem "Admin terakhir, atau kapasitas bimbingan dosen wali penuh"
+// @Failure      422  {object}  apperror.Problem "Data tidak valid (errors berisi pesan per field)"
+// @Failure      500      {object}  apperror.Problem
+// @Router       /users/{id}/role [put]
 func (s *userService) UpdateUserRole(c *fiber.Ctx) error {
 	idParam := c.Params("id")
 	userID, err := uuid.Parse(idParam)
 	if err != nil {
-		return c.Status(400).JSON(fiber.Map{
-			"message": "Format User ID tidak valid",
-			"success": false,
-		})
+		return invalidID("User ID")
 	}
 
 	var req models.UpdateRole
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(400).JSON(fiber.Map{
-			"message": "Format JSON tidak valid",
-			"success": false,
+	if err := bindBody(c, &req); err != nil {
+		return err
+	}
+	roleID, _ := uuid.Parse(strings.TrimSpace(req.RoleID))
+
+	actorID, ok := c.Locals("user_id").(uuid.UUID)
+	if !ok {
+		return apperror.Unauthorized("unauthorized")
+	}
+
+	// 1. User tidak boleh mengganti role-nya sendiri
+	if actorID == userID {
+		return apperror.Forbidden("own_role_change")
+	}
+
+	target, err := s.userRepo.GetUserByID(c.Context(), userID)
+	if err == sql.ErrNoRows {
+		return apperror.NotFound("user_not_found")
+	} else if err != nil {
+		return apperror.Internal(err)
+	}
+
+	newRole, err := s.roleRepo.GetRoleByID(c.Context(), roleID)
+	if err == sql.ErrNoRows {
+		return apperror.BadRequest("role_not_found")
+	} else if err != nil {
+		return apperror.Internal(err)
+	}
+
+	if target.RoleID == newRole.ID {
+		return c.JSON(fiber.Map{
+			"message": i18n.Message(c, "messages.user_role_unchanged"),
+			"success": true,
 		})
 	}
 
-	roleID, err := uuid.Parse(req.RoleID)
+	// 2. Hanya super-admin yang boleh memberikan role Admin atau role lain dengan permission setingkat Admin
+	if err := s.requireSuperAdminFor(c, newRole); err != nil {
+		return err
+	}
+
+	tx, err := s.db.Begin()
 	if err != nil {
-		return c.Status(400).JSON(fiber.Map{
-			"message": "Format Role ID tidak valid",
-			"success": false,
-		})
+		return apperror.Internal(err)
 	}
+	defer tx.Rollback()
 
-	err = s.userRepo.UpdateUserRole(c.Context(), userID, roleID)
+	// 3. Admin aktif terakhir tidak boleh diturunkan; Admin nonaktif tidak ikut dihitung
+	if target.RoleName == models.RoleAdmin && target.IsActive {
+		adminCount, err := s.userRepo.CountUsersByRoleForUpdate(c.Context(), tx, models.RoleAdmin)
+		if err != nil {
+			return apperror.Internal(err)
+		}
+		if adminCount <= 1 {
+			return apperror.Conflict("last_admin").Variant("demote")
+		}
+	}
+
+	err = s.userRepo.UpdateUserRole(c.Context(), tx, userID, roleID)
 	if err == sql.ErrNoRows {
-		return c.Status(404).JSON(fiber.Map{
-			"message": "User tidak ditemukan",
-			"success": false,
-		})
+		return apperror.NotFound("user_not_found")
 	} else if err != nil {
-		return c.Status(500).JSON(fiber.Map{
-			"message": "Gagal update role user",
-			"success": false,
-			"error":   err.Error(),
-		})
+		return apperror.Internal(err)
 	}
 
+	// 4. Pensiunkan profil lama dan siapkan profil untuk role baru
+	if err := s.switchProfile(c, tx, target, newRole.Name, req); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return apperror.Internal(err)
+	}
+	s.invalidateUser(userID)
+
 	return c.JSON(fiber.Map{
-		"message": "Role user berhasil diperbarui",
+		"message": i18n.Message(c, "messages.user_role_updated"),
 		"success": true,
 	})
-}
\ No newline at end of file
+}
+
+// switchProfile mengganti profil students/lecturers sesuai role baru. Error yang dikembalikan siap dikirim ke klien
+func (s *userService) switchProfile(c *fiber.Ctx, tx *sql.Tx, target models.User, newRoleName string, req models.UpdateRole) error {
+	ctx := c.Context()
+
+	switch target.RoleName {
+	case models.RoleMahasiswa:
+		if err := s.studentRepo.RetireStudent(ctx, tx, target.ID); err != nil {
+			return apperror.Internal(err)
+		}
+	case models.RoleDosen:
+		if err := s.lecturerRepo.RetireLecturer(ctx, tx, target.ID); err != nil {
+			return apperror.Internal(err)
+		}
+	}
+
+	switch newRoleName {
+	case models.RoleMahasiswa:
+		reused, err := s.studentRepo.ReactivateStudent(ctx, tx, target.ID)
+		if err != nil {
+			return apperror.Internal(err)
+		}
+		if reused {
+			return nil
+		}
+		if req.Student == nil || req.Student.StudentID == "" {
+			return apperror.Validation(validation.Field("student.student_id", "required", "validation.student_profile_required"))
+		}
+
+		if err := checkAdvisorCapacity(ctx, s.lecturerRepo, tx, req.Student.AdvisorID, req.Force); err != nil {
+			return err
+		}
+
+		newStudent := models.Student{
+			ID:             uuid.New(),
+			UserID:         target.ID,
+			StudentID:      req.Student.StudentID,
+			ProgramStudyID: req.Student.ProgramStudyID,
+			ProgramStudy:   req.Student.ProgramStudy,
+			AcademicYear:   req.Student.AcademicYear,
+			AdvisorID:      req.Student.AdvisorID,
+			CreatedBy:      currentUserID(c),
+			CreatedAt:      time.Now(),
+		}
+		if err := s.studentRepo.CreateStudent(ctx, tx, newStudent); isUnknownUnit(err) {
+			return unknownUnitError(err)
+		} else if err != nil {
+			return apperror.Internal(err)
+		}
+
+	case models.RoleDosen:
+		reused, err := s.lecturerRepo.ReactivateLecturer(ctx, tx, target.ID)
+		if err != nil {
+			return apperror.Internal(err)
+		}
+		if reused {
+			return nil
+		}
+
+		newLecture := models.Lecture{
+			ID:        uuid.New(),
+			UserID:    target.ID,
+			CreatedAt: time.Now(),
+		}
+		if req.Lecture != nil {
+			newLecture.LectureID = req.Lecture.LectureID
+			newLecture.DepartmentID = req.Lecture.DepartmentID
+			newLecture.Department = req.Lecture.Department
+		}
+		if err := s.lecturerRepo.CreateLecture(ctx, tx, newLecture); isUnknownUnit(err) {
+			return unknownUnitError(err)
+		} else if err != nil {
+			return apperror.Internal(err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,31 @@
+package services
+
+import (
+	"uas/apperror"
+	"uas/i18n"
+	"uas/validation"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// bindBody mem-parsing body JSON ke req lalu memeriksa tag validate-nya: 400 jika body tidak bisa
+// dibaca, 422 dengan pesan per field jika tidak valid
+func bindBody(c *fiber.Ctx, req interface{}) error {
+	if err := c.BodyParser(req); err != nil {
+		return apperror.BadRequest("invalid_json")
+	}
+	if errs := validation.Struct(req); len(errs) > 0 {
+		return validationError(errs...)
+	}
+	return nil
+}
+
+// validationError adalah error 422 dengan daftar field yang tidak valid
+func validationError(errs ...validation.FieldError) error {
+	return apperror.Validation(errs...)
+}
+
+// invalidID adalah error 400 untuk ID yang bukan UUID; field misalnya "Role ID" atau "period_id"
+func invalidID(field string) error {
+	return apperror.BadRequest("invalid_id").WithParams(i18n.Params{"field": field})
+}
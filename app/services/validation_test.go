@@ -0,0 +1,105 @@
+package services_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"uas/app/services"
+	"uas/apperror"
+	"uas/mocks"
+	"uas/validation"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateUser_InvalidPayload_FieldErrors(t *testing.T) {
+	mockUserRepo := new(mocks.MockUserRepo)
+	mockRoleRepo := new(mocks.MockRoleRepo)
+	userService := services.NewUserService(nil, mockUserRepo, nil, nil, mockRoleRepo, nil)
+
+	app := fiber.New(fiber.Config{ErrorHandler: apperror.Handler})
+	app.Post("/users", userService.CreateUser)
+
+	body, _ := json.Marshal(map[string]interface{}{
+		"username":  "",
+		"email":     "bukan-email",
+		"password":  "password123",
+		"full_name": "Budi",
+		"role_id":   "00000000-0000-0000-0000-000000000002",
+		"role_name": "Mahasiswa",
+	})
+	req := httptest.NewRequest("POST", "/users", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, _ := app.Test(req)
+	assert.Equal(t, 422, resp.StatusCode)
+
+	var res struct {
+		Success bool                    `json:"success"`
+		Errors  []validation.FieldError `json:"errors"`
+	}
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&res))
+	assert.False(t, res.Success)
+
+	fields := map[string]string{}
+	for _, fe := range res.Errors {
+		fields[fe.Field] = fe.Rule
+	}
+	assert.Equal(t, map[string]string{
+		"username": "required",
+		"email":    "email",
+		"student":  "required_if",
+	}, fields)
+	mockUserRepo.AssertNotCalled(t, "CreateUser")
+	mockRoleRepo.AssertNotCalled(t, "GetRoleByID")
+}
+
+func TestCreateUser_MalformedJSON(t *testing.T) {
+	userService := services.NewUserService(nil, nil, nil, nil, nil, nil)
+
+	app := fiber.New(fiber.Config{ErrorHandler: apperror.Handler})
+	app.Post("/users", userService.CreateUser)
+
+	req := httptest.NewRequest("POST", "/users", bytes.NewReader([]byte(`{"username":`)))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, _ := app.Test(req)
+	assert.Equal(t, 400, resp.StatusCode)
+}
+
+func TestCreateUser_InvalidAcademicYear(t *testing.T) {
+	mockUserRepo := new(mocks.MockUserRepo)
+	userService := services.NewUserService(nil, mockUserRepo, nil, nil, nil, nil)
+
+	app := fiber.New(fiber.Config{ErrorHandler: apperror.Handler})
+	app.Post("/users", userService.CreateUser)
+
+	for _, year := range []string{"2025/2026", "angkatan 25", "25"} {
+		body, _ := json.Marshal(map[string]interface{}{
+			"username":  "maba_2025",
+			"email":     "maba@kampus.ac.id",
+			"password":  "password123",
+			"full_name": "Maba",
+			"role_id":   "00000000-0000-0000-0000-000000000001",
+			"role_name": "Mahasiswa",
+			"student":   map[string]interface{}{"student_id": "2025001", "program_study": "Teknik Informatika", "academy_year": year},
+		})
+		req := httptest.NewRequest("POST", "/users", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, _ := app.Test(req)
+		assert.Equal(t, 422, resp.StatusCode, year)
+
+		var res struct {
+			Errors []validation.FieldError `json:"errors"`
+		}
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(&res))
+		if assert.Len(t, res.Errors, 1, year) {
+			assert.Equal(t, "student.academy_year", res.Errors[0].Field)
+			assert.Equal(t, "academic_year", res.Errors[0].Rule)
+		}
+	}
+	mockUserRepo.AssertNotCalled(t, "CreateUser")
+}
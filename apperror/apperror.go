@@ -0,0 +1,154 @@
+package apperror
+
+import (
+	"strings"
+	"uas/i18n"
+	"uas/validation"
+)
+
+// Kind adalah jenis error domain. Kind juga bisa dipakai sebagai target errors.Is,
+// misalnya errors.Is(err, apperror.NotFoundKind)
+type Kind string
+
+const (
+	BadRequestKind        Kind = "bad_request"
+	UnauthorizedKind      Kind = "unauthorized"
+	ForbiddenKind         Kind = "forbidden"
+	NotFoundKind          Kind = "not_found"
+	ConflictKind          Kind = "conflict"
+	InvalidTransitionKind Kind = "invalid_transition"
+	ValidationKind        Kind = "validation"
+	TooLargeKind          Kind = "too_large"
+	UpstreamKind          Kind = "upstream"
+	InternalKind          Kind = "internal"
+)
+
+func (k Kind) Error() string { return string(k) }
+
+// statusByKind adalah satu-satunya tempat pemetaan jenis error ke status HTTP
+var statusByKind = map[Kind]int{
+	BadRequestKind:        400,
+	UnauthorizedKind:      401,
+	ForbiddenKind:         403,
+	NotFoundKind:          404,
+	ConflictKind:          409,
+	InvalidTransitionKind: 409,
+	ValidationKind:        422,
+	TooLargeKind:          413,
+	UpstreamKind:          502,
+	InternalKind:          500,
+}
+
+// Error adalah error domain yang dikembalikan handler lalu diubah menjadi problem+json oleh Handler.
+// Code stabil dan aman dipakai klien; pesan untuk manusia diambil dari katalog "errors.<Key>"
+// dalam bahasa request; Err hanya dicatat di log
+type Error struct {
+	Kind   Kind
+	Code   string
+	Key    string // key katalog tanpa awalan "errors."; kosong berarti sama dengan Code
+	Params i18n.Params
+	Fields []validation.FieldError
+	Extra  map[string]interface{}
+	Err    error
+
+	status int // diisi untuk *fiber.Error yang status-nya tidak punya Kind sendiri
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return e.Code + ": " + e.Err.Error()
+	}
+	return e.Code + ": " + e.Message(i18n.Default)
+}
+
+// Message adalah pesan error dalam bahasa lang. Key yang belum ada di katalog ditampilkan apa adanya
+func (e *Error) Message(lang i18n.Lang) string {
+	key := e.Key
+	if key == "" {
+		key = e.Code
+	}
+	return i18n.T(lang, "errors."+key, e.Params)
+}
+
+func (e *Error) Unwrap() error { return e.Err }
+
+func (e *Error) Is(target error) bool {
+	kind, ok := target.(Kind)
+	return ok && kind == e.Kind
+}
+
+// Status adalah status HTTP untuk error ini
+func (e *Error) Status() int {
+	if e.status != 0 {
+		return e.status
+	}
+	if status, ok := statusByKind[e.Kind]; ok {
+		return status
+	}
+	return 500
+}
+
+// With menambahkan field tambahan pada body problem (misalnya data yang menghalangi penghapusan)
+func (e *Error) With(key string, value interface{}) *Error {
+	if e.Extra == nil {
+		e.Extra = make(map[string]interface{})
+	}
+	e.Extra[key] = value
+	return e
+}
+
+// Variant memilih pesan lain untuk kode yang sama, yaitu key katalog "errors.<Code>.<variant>"
+func (e *Error) Variant(variant string) *Error {
+	e.Key = e.Code + "." + variant
+	return e
+}
+
+// WithParams mengisi placeholder pesan katalog, misalnya {field} pada invalid_id
+func (e *Error) WithParams(params i18n.Params) *Error {
+	e.Params = params
+	return e
+}
+
+// Wrap menyimpan penyebab asli untuk log; tidak pernah dikirim ke klien
+func (e *Error) Wrap(err error) *Error {
+	e.Err = err
+	return e
+}
+
+func New(kind Kind, code string) *Error {
+	return &Error{Kind: kind, Code: code}
+}
+
+func BadRequest(code string) *Error   { return New(BadRequestKind, code) }
+func Unauthorized(code string) *Error { return New(UnauthorizedKind, code) }
+func Forbidden(code string) *Error    { return New(ForbiddenKind, code) }
+func NotFound(code string) *Error     { return New(NotFoundKind, code) }
+func Conflict(code string) *Error     { return New(ConflictKind, code) }
+func TooLarge(code string) *Error     { return New(TooLargeKind, code) }
+
+// InvalidTransition: perpindahan status yang tidak diizinkan dari status resource saat ini
+func InvalidTransition(code string) *Error {
+	return New(InvalidTransitionKind, code)
+}
+
+// Upstream: layanan luar (identity provider, storage) gagal dihubungi
+func Upstream(code string, err error) *Error {
+	return New(UpstreamKind, code).Wrap(err)
+}
+
+// Validation: data request tidak memenuhi aturan, dengan pesan per field
+func Validation(fields ...validation.FieldError) *Error {
+	e := New(ValidationKind, "validation_failed")
+	e.Fields = fields
+	return e
+}
+
+// Internal menyembunyikan err dari klien; err hanya dicatat di log bersama request ID
+func Internal(err error) *Error {
+	return New(InternalKind, "internal_error").Wrap(err)
+}
+
+// codeFromStatus membuat kode untuk *fiber.Error, misalnya "Method Not Allowed" menjadi method_not_allowed
+func codeFromStatus(text string) string {
+	return strings.ReplaceAll(strings.ToLower(text), " ", "_")
+}
@@ -0,0 +1,126 @@
+package apperror
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"uas/i18n"
+	"uas/validation"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+const ProblemContentType = "application/problem+json"
+
+// Problem adalah body error RFC 7807. success dan message dipertahankan agar klien lama
+// yang membaca format {"message","success"} tetap berfungsi
+type Problem struct {
+	Type      string      `json:"type"`
+	Title     string      `json:"title"`
+	Status    int         `json:"status"`
+	Detail    string      `json:"detail"`
+	Instance  string      `json:"instance"`
+	Code      string      `json:"code"`
+	RequestID string      `json:"request_id"`
+	Errors    interface{} `json:"errors,omitempty"`
+	Success   bool        `json:"success"`
+	Message   string      `json:"message"`
+}
+
+// From mengubah error apa pun menjadi *Error. Error yang tidak dikenal dianggap internal
+func From(err error) *Error {
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return appErr
+	}
+
+	var fiberErr *fiber.Error
+	if errors.As(err, &fiberErr) {
+		switch fiberErr.Code {
+		case 404:
+			return NotFound("route_not_found")
+		case 413:
+			return TooLarge("payload_too_large")
+		}
+		if fiberErr.Code >= 500 {
+			return Internal(err)
+		}
+		// Pesan bawaan Fiber (bahasa Inggris) dipakai apa adanya
+		e := New(BadRequestKind, codeFromStatus(http.StatusText(fiberErr.Code)))
+		e.Key = "http_error"
+		e.Params = i18n.Params{"message": fiberErr.Message}
+		e.status = fiberErr.Code
+		return e
+	}
+
+	return Internal(err)
+}
+
+// Handler dipasang sebagai fiber.Config.ErrorHandler: semua error dari handler dan middleware
+// dikirim sebagai application/problem+json dengan kode stabil, request ID, dan pesan dalam bahasa request
+func Handler(c *fiber.Ctx, err error) error {
+	e := From(err)
+	requestID := RequestID(c)
+	lang := i18n.FromContext(c)
+	message := e.Message(lang)
+
+	if e.Kind == InternalKind || e.Kind == UpstreamKind {
+		log.Printf("[%s] %s %s: %v", requestID, c.Method(), c.Path(), e)
+	}
+
+	status := e.Status()
+	problem := Problem{
+		Type:      "about:blank",
+		Title:     http.StatusText(status),
+		Status:    status,
+		Detail:    message,
+		Instance:  c.OriginalURL(),
+		Code:      e.Code,
+		RequestID: requestID,
+		Message:   message,
+	}
+	if len(e.Fields) > 0 {
+		fields := make([]validation.FieldError, len(e.Fields))
+		for i, fe := range e.Fields {
+			fields[i] = fe.Translate(lang)
+		}
+		problem.Errors = fields
+	}
+
+	if len(e.Extra) == 0 {
+		return c.Status(status).JSON(problem, ProblemContentType)
+	}
+
+	// Field tambahan (misalnya daftar data yang menghalangi penghapusan) digabung ke body
+	body := fiber.Map{}
+	for k, v := range e.Extra {
+		body[k] = v
+	}
+	body["type"] = problem.Type
+	body["title"] = problem.Title
+	body["status"] = problem.Status
+	body["detail"] = problem.Detail
+	body["instance"] = problem.Instance
+	body["code"] = problem.Code
+	body["request_id"] = problem.RequestID
+	body["success"] = false
+	body["message"] = problem.Message
+	if problem.Errors != nil {
+		body["errors"] = problem.Errors
+	}
+	return c.Status(status).JSON(body, ProblemContentType)
+}
+
+// RequestID mengambil ID dari middleware requestid; jika tidak ada (misalnya di test), dibuat baru
+func RequestID(c *fiber.Ctx) string {
+	if id, ok := c.Locals("requestid").(string); ok && id != "" {
+		return id
+	}
+	id := c.GetRespHeader(fiber.HeaderXRequestID)
+	if id == "" {
+		id = uuid.NewString()
+		c.Set(fiber.HeaderXRequestID, id)
+	}
+	return id
+}
@@ -0,0 +1,4 @@
+ALTER TABLE roles ADD COLUMN IF NOT EXISTS is_system BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- Role bawaan dipakai langsung oleh kode (Mahasiswa, Dosen Wali, Admin) sehingga tidak boleh dihapus
+UPDATE roles SET is_system = TRUE WHERE name IN ('Admin', 'Mahasiswa', 'Dosen Wali');
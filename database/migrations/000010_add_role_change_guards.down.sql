@@ -0,0 +1,3 @@
+ALTER TABLE lecturers DROP COLUMN IF EXISTS retired_at;
+ALTER TABLE students DROP COLUMN IF EXISTS retired_at;
+ALTER TABLE users DROP COLUMN IF EXISTS is_super_admin;
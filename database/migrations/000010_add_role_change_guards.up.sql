@@ -0,0 +1,7 @@
+-- Hanya super-admin yang boleh memberikan role Admin ke user lain
+ALTER TABLE users ADD COLUMN IF NOT EXISTS is_super_admin BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- Profil mahasiswa/dosen tidak dihapus saat role user berganti, hanya dipensiunkan
+-- agar riwayat prestasi tetap utuh dan bisa dipakai lagi jika role dikembalikan
+ALTER TABLE students ADD COLUMN IF NOT EXISTS retired_at TIMESTAMP NULL;
+ALTER TABLE lecturers ADD COLUMN IF NOT EXISTS retired_at TIMESTAMP NULL;
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS student_advisor_history;
\ No newline at end of file
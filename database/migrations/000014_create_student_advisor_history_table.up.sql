@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS student_advisor_history (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    student_id UUID NOT NULL REFERENCES students(id) ON DELETE CASCADE,
+    advisor_id UUID REFERENCES lecturers(id) ON DELETE SET NULL,
+    effective_from TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    effective_to TIMESTAMP NULL,
+    changed_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    reason TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_student_advisor_history_student ON student_advisor_history(student_id, effective_from DESC);
+
+-- Hanya satu periode berjalan per mahasiswa
+CREATE UNIQUE INDEX IF NOT EXISTS idx_student_advisor_history_current ON student_advisor_history(student_id) WHERE effective_to IS NULL;
+
+-- Dosen wali saat ini menjadi periode pertama
+INSERT INTO student_advisor_history (student_id, advisor_id, effective_from)
+SELECT id, advisor_id, created_at FROM students WHERE advisor_id IS NOT NULL;
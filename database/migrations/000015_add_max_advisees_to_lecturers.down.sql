@@ -0,0 +1 @@
+ALTER TABLE lecturers DROP COLUMN IF EXISTS max_advisees;
\ No newline at end of file
@@ -0,0 +1,2 @@
+-- Kapasitas bimbingan per dosen. NULL berarti memakai default dari env ADVISOR_MAX_ADVISEES
+ALTER TABLE lecturers ADD COLUMN IF NOT EXISTS max_advisees INT NULL CHECK (max_advisees IS NULL OR max_advisees >= 0);
@@ -0,0 +1,16 @@
+ALTER TABLE users ADD COLUMN IF NOT EXISTS phone VARCHAR(20) NULL;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS photo_url VARCHAR(255) NULL;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS bio TEXT NULL;
+
+-- Permintaan ganti email menunggu konfirmasi dari alamat baru; hanya hash token yang disimpan
+CREATE TABLE IF NOT EXISTS email_change_requests (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    new_email VARCHAR(100) NOT NULL,
+    token_hash VARCHAR(64) NOT NULL UNIQUE,
+    expires_at TIMESTAMP NOT NULL,
+    confirmed_at TIMESTAMP NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_email_change_requests_user ON email_change_requests(user_id);
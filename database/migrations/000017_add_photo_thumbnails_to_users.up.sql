@@ -0,0 +1,2 @@
+-- Thumbnail foto profil hasil upload, format {"512": "/uploads/...", "256": "...", ...}
+ALTER TABLE users ADD COLUMN IF NOT EXISTS photo_thumbnails JSONB NULL;
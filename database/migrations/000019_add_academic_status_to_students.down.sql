@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS student_status_history;
+ALTER TABLE students DROP COLUMN IF EXISTS academic_status_since;
+ALTER TABLE students DROP COLUMN IF EXISTS academic_status;
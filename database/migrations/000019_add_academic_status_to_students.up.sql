@@ -0,0 +1,25 @@
+-- Status akademik mahasiswa: active, on_leave (cuti), graduated (lulus), dropped_out (keluar/DO)
+ALTER TABLE students ADD COLUMN IF NOT EXISTS academic_status VARCHAR(20) NOT NULL DEFAULT 'active'
+    CHECK (academic_status IN ('active', 'on_leave', 'graduated', 'dropped_out'));
+ALTER TABLE students ADD COLUMN IF NOT EXISTS academic_status_since DATE NOT NULL DEFAULT CURRENT_DATE;
+
+CREATE TABLE IF NOT EXISTS student_status_history (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    student_id UUID NOT NULL REFERENCES students(id) ON DELETE CASCADE,
+    status VARCHAR(20) NOT NULL,
+    effective_from DATE NOT NULL,
+    effective_to DATE NULL,
+    changed_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    reason TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_student_status_history_student ON student_status_history(student_id, effective_from DESC);
+
+-- Hanya satu periode status berjalan per mahasiswa
+CREATE UNIQUE INDEX IF NOT EXISTS idx_student_status_history_current ON student_status_history(student_id) WHERE effective_to IS NULL;
+
+-- Semua mahasiswa yang ada dianggap aktif sejak profilnya dibuat
+UPDATE students SET academic_status_since = COALESCE(created_at::date, CURRENT_DATE);
+INSERT INTO student_status_history (student_id, status, effective_from)
+SELECT id, academic_status, academic_status_since FROM students;
@@ -0,0 +1,12 @@
+ALTER TABLE students ADD COLUMN IF NOT EXISTS program_study VARCHAR(100);
+ALTER TABLE lecturers ADD COLUMN IF NOT EXISTS department VARCHAR(100);
+
+UPDATE students s SET program_study = ps.name FROM program_studies ps WHERE ps.id = s.program_study_id;
+UPDATE lecturers l SET department = d.name FROM departments d WHERE d.id = l.department_id;
+
+ALTER TABLE students DROP COLUMN IF EXISTS program_study_id;
+ALTER TABLE lecturers DROP COLUMN IF EXISTS department_id;
+
+DROP TABLE IF EXISTS program_studies;
+DROP TABLE IF EXISTS departments;
+DROP TABLE IF EXISTS faculties;
@@ -0,0 +1,72 @@
+-- Data master fakultas -> department -> program studi
+CREATE TABLE IF NOT EXISTS faculties (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    code VARCHAR(20) UNIQUE,
+    name VARCHAR(100) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_faculties_name ON faculties (LOWER(name));
+
+CREATE TABLE IF NOT EXISTS departments (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    faculty_id UUID NOT NULL REFERENCES faculties(id),
+    code VARCHAR(20) UNIQUE,
+    name VARCHAR(100) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_departments_name ON departments (LOWER(name));
+CREATE INDEX IF NOT EXISTS idx_departments_faculty ON departments(faculty_id);
+
+CREATE TABLE IF NOT EXISTS program_studies (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    department_id UUID NOT NULL REFERENCES departments(id),
+    code VARCHAR(20) UNIQUE,
+    name VARCHAR(100) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_program_studies_name ON program_studies (LOWER(name));
+CREATE INDEX IF NOT EXISTS idx_program_studies_department ON program_studies(department_id);
+
+ALTER TABLE students ADD COLUMN IF NOT EXISTS program_study_id UUID REFERENCES program_studies(id);
+ALTER TABLE lecturers ADD COLUMN IF NOT EXISTS department_id UUID REFERENCES departments(id);
+CREATE INDEX IF NOT EXISTS idx_students_program_study ON students(program_study_id);
+CREATE INDEX IF NOT EXISTS idx_lecturers_department ON lecturers(department_id);
+
+-- Pemetaan string lama. Nama dinormalisasi (trim, spasi ganda, huruf besar/kecil) dan ejaan yang
+-- paling sering dipakai menjadi nama resmi. Sebelumnya auto-assign mencocokkan program studi dengan
+-- department yang namanya sama, jadi setiap program studi dibuatkan department bernama sama.
+-- Semua department masuk fakultas "Belum Dipetakan" sampai admin memindahkannya
+INSERT INTO faculties (code, name) VALUES ('UNMAPPED', 'Belum Dipetakan');
+
+WITH names AS (
+    SELECT regexp_replace(TRIM(department), '\s+', ' ', 'g') AS name FROM lecturers
+    WHERE TRIM(COALESCE(department, '')) <> ''
+    UNION ALL
+    SELECT regexp_replace(TRIM(program_study), '\s+', ' ', 'g') FROM students
+    WHERE TRIM(COALESCE(program_study, '')) <> ''
+)
+INSERT INTO departments (faculty_id, name)
+SELECT (SELECT id FROM faculties WHERE code = 'UNMAPPED'), mode() WITHIN GROUP (ORDER BY name)
+FROM names
+GROUP BY LOWER(name);
+
+INSERT INTO program_studies (department_id, name)
+SELECT d.id, d.name FROM departments d
+WHERE EXISTS (
+    SELECT 1 FROM students s
+    WHERE LOWER(regexp_replace(TRIM(s.program_study), '\s+', ' ', 'g')) = LOWER(d.name)
+);
+
+UPDATE students s SET program_study_id = ps.id
+FROM program_studies ps
+WHERE LOWER(regexp_replace(TRIM(s.program_study), '\s+', ' ', 'g')) = LOWER(ps.name);
+
+UPDATE lecturers l SET department_id = d.id
+FROM departments d
+WHERE LOWER(regexp_replace(TRIM(l.department), '\s+', ' ', 'g')) = LOWER(d.name);
+
+ALTER TABLE students DROP COLUMN IF EXISTS program_study;
+ALTER TABLE lecturers DROP COLUMN IF EXISTS department;
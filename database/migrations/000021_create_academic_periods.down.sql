@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_achievement_references_event_date;
+ALTER TABLE achievement_references DROP COLUMN IF EXISTS event_date;
+
+DROP TABLE IF EXISTS academic_periods;
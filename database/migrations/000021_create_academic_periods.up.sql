@@ -0,0 +1,24 @@
+-- Periode akademik (semester). Rentang tanggal antar periode tidak boleh beririsan dan hanya
+-- satu periode yang aktif. Periode yang ditutup (closed_at terisi) membekukan prestasi di dalamnya
+CREATE TABLE IF NOT EXISTS academic_periods (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name VARCHAR(100) NOT NULL,
+    start_date DATE NOT NULL,
+    end_date DATE NOT NULL,
+    is_active BOOLEAN NOT NULL DEFAULT FALSE,
+    closed_at TIMESTAMP,
+    closed_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT academic_periods_date_range CHECK (end_date >= start_date),
+    CONSTRAINT academic_periods_no_overlap EXCLUDE USING gist (daterange(start_date, end_date, '[]') WITH &&)
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_academic_periods_name ON academic_periods (LOWER(name));
+CREATE UNIQUE INDEX IF NOT EXISTS idx_academic_periods_active ON academic_periods (is_active) WHERE is_active;
+
+-- Prestasi masuk ke periode berdasarkan tanggal kegiatannya. Data lama memakai tanggal dibuat
+ALTER TABLE achievement_references ADD COLUMN IF NOT EXISTS event_date DATE;
+UPDATE achievement_references SET event_date = COALESCE(created_at::date, CURRENT_DATE) WHERE event_date IS NULL;
+ALTER TABLE achievement_references ALTER COLUMN event_date SET DEFAULT CURRENT_DATE;
+ALTER TABLE achievement_references ALTER COLUMN event_date SET NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_achievement_references_event_date ON achievement_references(event_date);
@@ -0,0 +1,3 @@
+-- Bahasa pilihan user untuk pesan API dan email; NULL berarti mengikuti header Accept-Language
+ALTER TABLE users ADD COLUMN IF NOT EXISTS language VARCHAR(5) NULL
+    CONSTRAINT users_language_check CHECK (language IN ('id', 'en'));
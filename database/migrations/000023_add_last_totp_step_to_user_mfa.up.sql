@@ -0,0 +1,2 @@
+-- Time step TOTP terakhir yang diterima; kode dengan step yang sama atau lebih lama ditolak (anti replay)
+ALTER TABLE user_mfa ADD COLUMN IF NOT EXISTS last_totp_step BIGINT NULL;
@@ -0,0 +1,2 @@
+-- Normalisasi angkatan tidak bisa dikembalikan; nilai lama tidak disimpan
+SELECT 1;
@@ -0,0 +1,8 @@
+-- Angkatan sebelumnya string bebas. Spasi dibuang, string kosong menjadi NULL, dan nilai yang diawali
+-- tahun empat digit (misalnya "2025/2026" atau "2025 Ganjil") dipotong menjadi tahunnya saja.
+-- Nilai lain dibiarkan apa adanya supaya bisa diperbaiki manual
+UPDATE students SET academy_year = NULLIF(TRIM(academy_year), '')
+WHERE academy_year IS DISTINCT FROM NULLIF(TRIM(academy_year), '');
+
+UPDATE students SET academy_year = substring(academy_year FROM '^((19|20)[0-9]{2})')
+WHERE academy_year ~ '^(19|20)[0-9]{2}[^0-9]';
@@ -1,8 +1,8 @@
 -- Roles tables
-INSERT INTO roles (name, description) VALUES 
-('Admin', 'Administrator utama yang memiliki akses penuh ke seluruh sistem.'),
-('Mahasiswa', 'Mahasiswa aktif yang dapat mengajukan klaim poin prestasi.'),
-('Dosen Wali', 'Dosen pembimbing yang bertugas memverifikasi validitas prestasi mahasiswa.');
+INSERT INTO roles (name, description, is_system) VALUES 
+('Admin', 'Administrator utama yang memiliki akses penuh ke seluruh sistem.', TRUE),
+('Mahasiswa', 'Mahasiswa aktif yang dapat mengajukan klaim poin prestasi.', TRUE),
+('Dosen Wali', 'Dosen pembimbing yang bertugas memverifikasi validitas prestasi mahasiswa.', TRUE);
 
 -- Permissions tables
 INSERT INTO permissions (name, resource, action, description) VALUES 
@@ -20,16 +20,28 @@ INSERT INTO permissions (name, resource, action, description) VALUES
 ('achievements:submit', 'achievements', 'submit', 'Mengirim prestasi untuk diverifikasi'),
 ('achievements:verify', 'achievements', 'verify', 'Menyetujui prestasi mahasiswa (Status: Verified)'),
 ('achievements:reject', 'achievements', 'reject', 'Menolak prestasi mahasiswa (Status: Rejected)'),
-('reports:read',        'reports',      'read',   'Melihat dashboard statistik prestasi');
+('reports:read',        'reports',      'read',   'Melihat dashboard statistik prestasi'),
+('roles:read',          'roles',        'read',   'Melihat daftar role, permission, dan matriks akses'),
+('roles:manage',        'roles',        'manage', 'Membuat/mengubah role dan permission serta mengatur hak akses role'),
+('users:assign_role',   'users',        'assign_role', 'Mengganti role user lain'),
+('users:impersonate',   'users',        'impersonate', 'Melihat aplikasi sebagai user lain (read-only, tercatat di audit log)'),
+('api_keys:manage',     'api_keys',     'manage', 'Membuat dan mencabut API key untuk integrasi'),
+('sessions:manage',     'sessions',     'manage', 'Melihat dan mencabut sesi login user lain'),
+('lecturers:update',    'lecturers',    'update', 'Mengatur kapasitas bimbingan dosen'),
+('academic_units:read', 'academic_units', 'read', 'Melihat data master fakultas, department, dan program studi'),
+('academic_units:manage', 'academic_units', 'manage', 'Mengelola data master fakultas, department, dan program studi'),
+('academic_periods:read', 'academic_periods', 'read', 'Melihat daftar periode akademik (semester)'),
+('academic_periods:manage', 'academic_periods', 'manage', 'Mengelola, mengaktifkan, dan menutup periode akademik');
 
 -- Insert User: George Admin
-INSERT INTO users (username, email, password_hash, full_name, role_id) VALUES 
+INSERT INTO users (username, email, password_hash, full_name, role_id, is_super_admin) VALUES 
 (
     'george_admin', 
     'george@admin.unair.ac.id', 
     '$2a$10$EixZaYVK1fsbw1ZfbX3OXePaWrn95nPn6as79w0hNtGOlqAttiikO', 
     'George Administrator',
-    (SELECT id FROM roles WHERE name = 'Admin' LIMIT 1)
+    (SELECT id FROM roles WHERE name = 'Admin' LIMIT 1),
+    TRUE
 );
 
 INSERT INTO public.role_permissions (role_id, permission_id)
@@ -138,4 +150,82 @@ INSERT INTO public.role_permissions (role_id, permission_id)
 VALUES (
     (SELECT id FROM public.roles WHERE name = 'Dosen Wali'),
     (SELECT id FROM public.permissions WHERE name = 'reports:read')
-);
\ No newline at end of file
+);
+
+INSERT INTO public.role_permissions (role_id, permission_id)
+VALUES (
+    (SELECT id FROM public.roles WHERE name = 'Admin'),
+    (SELECT id FROM public.permissions WHERE name = 'roles:read')
+);
+
+INSERT INTO public.role_permissions (role_id, permission_id)
+VALUES (
+    (SELECT id FROM public.roles WHERE name = 'Admin'),
+    (SELECT id FROM public.permissions WHERE name = 'roles:manage')
+);
+
+INSERT INTO public.role_permissions (role_id, permission_id)
+VALUES (
+    (SELECT id FROM public.roles WHERE name = 'Admin'),
+    (SELECT id FROM public.permissions WHERE name = 'users:assign_role')
+);
+
+INSERT INTO public.role_permissions (role_id, permission_id)
+VALUES (
+    (SELECT id FROM public.roles WHERE name = 'Admin'),
+    (SELECT id FROM public.permissions WHERE name = 'users:impersonate')
+);
+
+INSERT INTO public.role_permissions (role_id, permission_id)
+VALUES (
+    (SELECT id FROM public.roles WHERE name = 'Admin'),
+    (SELECT id FROM public.permissions WHERE name = 'api_keys:manage')
+);
+
+INSERT INTO public.role_permissions (role_id, permission_id)
+VALUES (
+    (SELECT id FROM public.roles WHERE name = 'Admin'),
+    (SELECT id FROM public.permissions WHERE name = 'sessions:manage')
+);
+
+INSERT INTO public.role_permissions (role_id, permission_id)
+VALUES (
+    (SELECT id FROM public.roles WHERE name = 'Admin'),
+    (SELECT id FROM public.permissions WHERE name = 'lecturers:update')
+);
+
+INSERT INTO public.role_permissions (role_id, permission_id)
+VALUES (
+    (SELECT id FROM public.roles WHERE name = 'Admin'),
+    (SELECT id FROM public.permissions WHERE name = 'academic_units:read')
+);
+
+INSERT INTO public.role_permissions (role_id, permission_id)
+VALUES (
+    (SELECT id FROM public.roles WHERE name = 'Admin'),
+    (SELECT id FROM public.permissions WHERE name = 'academic_units:manage')
+);
+
+INSERT INTO public.role_permissions (role_id, permission_id)
+VALUES (
+    (SELECT id FROM public.roles WHERE name = 'Admin'),
+    (SELECT id FROM public.permissions WHERE name = 'academic_periods:read')
+);
+
+INSERT INTO public.role_permissions (role_id, permission_id)
+VALUES (
+    (SELECT id FROM public.roles WHERE name = 'Admin'),
+    (SELECT id FROM public.permissions WHERE name = 'academic_periods:manage')
+);
+
+INSERT INTO public.role_permissions (role_id, permission_id)
+VALUES (
+    (SELECT id FROM public.roles WHERE name = 'Mahasiswa'),
+    (SELECT id FROM public.permissions WHERE name = 'academic_periods:read')
+);
+
+INSERT INTO public.role_permissions (role_id, permission_id)
+VALUES (
+    (SELECT id FROM public.roles WHERE name = 'Dosen Wali'),
+    (SELECT id FROM public.permissions WHERE name = 'academic_periods:read')
+);
@@ -0,0 +1,144 @@
+package helpers
+
+import (
+	"context"
+	"sync"
+	"time"
+	"uas/app/repository"
+
+	"github.com/google/uuid"
+)
+
+// PermissionResolver menjawab "apakah role X punya permission Y" dengan cache per role.
+// Resolver juga mencatat kapan permission role atau role/status user terakhir berubah, supaya
+// access token yang terbit sebelum perubahan itu tidak lagi dipercaya
+type PermissionResolver interface {
+	HasPermission(ctx context.Context, roleName string, permission string) (bool, error)
+	PermissionsForRole(ctx context.Context, roleName string) ([]string, error)
+	Invalidate(roleName string)
+	InvalidateAll()
+	InvalidateUser(userID uuid.UUID)
+	RoleChangedSince(roleName string, t time.Time) bool
+	UserChangedSince(userID uuid.UUID, t time.Time) bool
+}
+
+// changeRetention adalah lama catatan perubahan disimpan; harus lebih lama dari umur access token
+const changeRetention = 24 * time.Hour
+
+type cachedPermissions struct {
+	names     []string
+	set       map[string]struct{}
+	expiresAt time.Time
+}
+
+type permissionResolver struct {
+	repo  repository.PermissionRepository
+	ttl   time.Duration
+	mu    sync.RWMutex
+	cache map[string]cachedPermissions
+
+	// waktu perubahan terakhir: per role, semua role, dan per user
+	roleChanged map[string]time.Time
+	allChanged  time.Time
+	userChanged map[uuid.UUID]time.Time
+}
+
+func NewPermissionResolver(repo repository.PermissionRepository, ttl time.Duration) PermissionResolver {
+	return &permissionResolver{
+		repo:        repo,
+		ttl:         ttl,
+		cache:       make(map[string]cachedPermissions),
+		roleChanged: make(map[string]time.Time),
+		userChanged: make(map[uuid.UUID]time.Time),
+	}
+}
+
+func (r *permissionResolver) HasPermission(ctx context.Context, roleName string, permission string) (bool, error) {
+	entry, err := r.load(ctx, roleName)
+	if err != nil {
+		return false, err
+	}
+
+	_, ok := entry.set[permission]
+	return ok, nil
+}
+
+func (r *permissionResolver) PermissionsForRole(ctx context.Context, roleName string) ([]string, error) {
+	entry, err := r.load(ctx, roleName)
+	if err != nil {
+		return nil, err
+	}
+	return entry.names, nil
+}
+
+func (r *permissionResolver) Invalidate(roleName string) {
+	r.mu.Lock()
+	delete(r.cache, roleName)
+	r.roleChanged[roleName] = time.Now()
+	r.mu.Unlock()
+}
+
+func (r *permissionResolver) InvalidateAll() {
+	r.mu.Lock()
+	r.cache = make(map[string]cachedPermissions)
+	r.allChanged = time.Now()
+	r.mu.Unlock()
+}
+
+// InvalidateUser dipanggil saat role user diganti atau user dinonaktifkan/dihapus
+func (r *permissionResolver) InvalidateUser(userID uuid.UUID) {
+	now := time.Now()
+	r.mu.Lock()
+	for id, at := range r.userChanged {
+		if now.Sub(at) > changeRetention {
+			delete(r.userChanged, id)
+		}
+	}
+	r.userChanged[userID] = now
+	r.mu.Unlock()
+}
+
+// RoleChangedSince mengecek apakah permission roleName berubah setelah t (biasanya iat token).
+// iat dibulatkan ke detik, jadi token yang terbit di detik yang sama dengan perubahan ikut dianggap lama
+func (r *permissionResolver) RoleChangedSince(roleName string, t time.Time) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.allChanged.After(t) || r.roleChanged[roleName].After(t)
+}
+
+// UserChangedSince mengecek apakah role/status user berubah setelah t
+func (r *permissionResolver) UserChangedSince(userID uuid.UUID, t time.Time) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.userChanged[userID].After(t)
+}
+
+func (r *permissionResolver) load(ctx context.Context, roleName string) (cachedPermissions, error) {
+	r.mu.RLock()
+	entry, ok := r.cache[roleName]
+	r.mu.RUnlock()
+
+	if ok && time.Now().Before(entry.expiresAt) {
+		return entry, nil
+	}
+
+	names, err := r.repo.GetPermissionNamesByRole(ctx, roleName)
+	if err != nil {
+		return cachedPermissions{}, err
+	}
+
+	entry = cachedPermissions{
+		names:     names,
+		set:       make(map[string]struct{}, len(names)),
+		expiresAt: time.Now().Add(r.ttl),
+	}
+	for _, name := range names {
+		entry.set[name] = struct{}{}
+	}
+
+	r.mu.Lock()
+	r.cache[roleName] = entry
+	r.mu.Unlock()
+
+	return entry, nil
+}
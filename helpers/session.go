@@ -0,0 +1,70 @@
+package helpers
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"time"
+	"uas/app/repository"
+
+	"github.com/google/uuid"
+)
+
+// SessionChecker menjawab "apakah sesi login access token ini masih aktif". Hasil aktif di-cache selama ttl,
+// jadi access token dari sesi yang dicabut ditolak paling lambat ttl setelah pencabutan
+type SessionChecker interface {
+	SessionActive(ctx context.Context, sessionID uuid.UUID, userID uuid.UUID) (bool, error)
+}
+
+type cachedSession struct {
+	userID    uuid.UUID
+	expiresAt time.Time
+}
+
+type sessionChecker struct {
+	repo  repository.SessionRepository
+	ttl   time.Duration
+	mu    sync.RWMutex
+	cache map[uuid.UUID]cachedSession
+}
+
+func NewSessionChecker(repo repository.SessionRepository, ttl time.Duration) SessionChecker {
+	return &sessionChecker{
+		repo:  repo,
+		ttl:   ttl,
+		cache: make(map[uuid.UUID]cachedSession),
+	}
+}
+
+func (s *sessionChecker) SessionActive(ctx context.Context, sessionID uuid.UUID, userID uuid.UUID) (bool, error) {
+	now := time.Now()
+
+	s.mu.RLock()
+	entry, ok := s.cache[sessionID]
+	s.mu.RUnlock()
+	if ok && now.Before(entry.expiresAt) {
+		return entry.userID == userID, nil
+	}
+
+	session, err := s.repo.GetSessionByID(ctx, sessionID)
+	if err == sql.ErrNoRows {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	if session.UserID != userID || !session.IsActive(now) {
+		return false, nil
+	}
+
+	// Hanya sesi aktif yang di-cache; sesi yang sudah dicabut tidak akan aktif lagi
+	s.mu.Lock()
+	for id, cached := range s.cache {
+		if now.After(cached.expiresAt) {
+			delete(s.cache, id)
+		}
+	}
+	s.cache[sessionID] = cachedSession{userID: session.UserID, expiresAt: now.Add(s.ttl)}
+	s.mu.Unlock()
+
+	return true, nil
+}
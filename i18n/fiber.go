@@ -0,0 +1,31 @@
+package i18n
+
+import "github.com/gofiber/fiber/v2"
+
+// FromContext menentukan bahasa response: preferensi user (claim lang pada token, diset AuthRequired
+// ke Locals "language"), lalu header Accept-Language, lalu Default. Hasilnya disimpan di Locals "lang"
+// dan dikirim lewat header Content-Language
+func FromContext(c *fiber.Ctx) Lang {
+	if lang, ok := c.Locals("lang").(Lang); ok {
+		return lang
+	}
+
+	lang := Default
+	if pref, ok := c.Locals("language").(string); ok && pref != "" {
+		if l, ok := Parse(pref); ok {
+			lang = l
+		}
+	} else if header := c.Get(fiber.HeaderAcceptLanguage); header != "" {
+		lang = Negotiate(header)
+	}
+
+	c.Locals("lang", lang)
+	c.Set(fiber.HeaderContentLanguage, string(lang))
+	c.Vary(fiber.HeaderAcceptLanguage)
+	return lang
+}
+
+// Message menerjemahkan key ke bahasa request
+func Message(c *fiber.Ctx, key string, params ...Params) string {
+	return T(FromContext(c), key, params...)
+}
@@ -0,0 +1,172 @@
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Lang adalah kode bahasa yang didukung (subtag utama BCP 47)
+type Lang string
+
+const (
+	Indonesian Lang = "id"
+	English    Lang = "en"
+
+	// Default dipakai jika klien tidak meminta bahasa yang didukung
+	Default = Indonesian
+)
+
+// Supported berisi bahasa yang punya katalog, urut sesuai prioritas
+var Supported = []Lang{Indonesian, English}
+
+// Params adalah nilai untuk placeholder {nama} pada pesan
+type Params map[string]interface{}
+
+// Key adalah nilai parameter yang ikut diterjemahkan, misalnya nama unit ("units.faculty")
+type Key string
+
+//go:embed locales/*.json
+var localeFiles embed.FS
+
+// catalogs: bahasa -> key datar ("errors.user_not_found") -> template pesan
+var catalogs = map[Lang]map[string]string{}
+
+var placeholder = regexp.MustCompile(`\{([a-z_]+)\}`)
+
+func init() {
+	for _, lang := range Supported {
+		raw, err := localeFiles.ReadFile(path.Join("locales", string(lang)+".json"))
+		if err != nil {
+			panic("i18n: katalog " + string(lang) + " tidak ada: " + err.Error())
+		}
+		var tree map[string]interface{}
+		if err := json.Unmarshal(raw, &tree); err != nil {
+			panic("i18n: katalog " + string(lang) + " tidak valid: " + err.Error())
+		}
+		catalogs[lang] = map[string]string{}
+		flatten("", tree, catalogs[lang])
+	}
+}
+
+// flatten mengubah section bersarang menjadi key bertitik
+func flatten(prefix string, tree map[string]interface{}, out map[string]string) {
+	for k, v := range tree {
+		switch v := v.(type) {
+		case string:
+			out[prefix+k] = v
+		case map[string]interface{}:
+			flatten(prefix+k+".", v, out)
+		}
+	}
+}
+
+// Lookup mengembalikan pesan dalam lang (atau bahasa Default jika key belum diterjemahkan).
+// ok == false jika key tidak ada di katalog atau ada placeholder yang tidak terisi params
+func Lookup(lang Lang, key string, params ...Params) (string, bool) {
+	template, ok := catalogs[lang][key]
+	if !ok {
+		if template, ok = catalogs[Default][key]; !ok {
+			return "", false
+		}
+	}
+
+	var p Params
+	if len(params) > 0 {
+		p = params[0]
+	}
+	complete := true
+	message := placeholder.ReplaceAllStringFunc(template, func(m string) string {
+		v, ok := p[m[1:len(m)-1]]
+		if !ok {
+			complete = false
+			return m
+		}
+		if k, ok := v.(Key); ok {
+			return T(lang, string(k))
+		}
+		return fmt.Sprint(v)
+	})
+	return message, complete
+}
+
+// T seperti Lookup, tetapi mengembalikan key itu sendiri jika tidak ada di katalog
+func T(lang Lang, key string, params ...Params) string {
+	message, ok := Lookup(lang, key, params...)
+	if !ok && message == "" {
+		return key
+	}
+	return message
+}
+
+// Has memeriksa apakah key ada di katalog bahasa Default
+func Has(key string) bool {
+	_, ok := catalogs[Default][key]
+	return ok
+}
+
+// Parse menerima tag seperti "en", "en-US", atau "id_ID"
+func Parse(tag string) (Lang, bool) {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	if i := strings.IndexAny(tag, "-_"); i >= 0 {
+		tag = tag[:i]
+	}
+	if tag == "in" { // kode lama untuk bahasa Indonesia
+		tag = "id"
+	}
+	for _, lang := range Supported {
+		if string(lang) == tag {
+			return lang, true
+		}
+	}
+	return "", false
+}
+
+// Negotiate memilih bahasa dari header Accept-Language berdasarkan bobot q.
+// Bahasa yang tidak didukung dilewati; jika tidak ada yang cocok dipakai Default
+func Negotiate(header string) Lang {
+	type candidate struct {
+		lang Lang
+		q    float64
+	}
+	var candidates []candidate
+	for _, part := range strings.Split(header, ",") {
+		fields := strings.Split(part, ";")
+		lang, ok := Parse(fields[0])
+		if !ok {
+			continue
+		}
+		q := 1.0
+		for _, f := range fields[1:] {
+			f = strings.TrimSpace(f)
+			if strings.HasPrefix(f, "q=") {
+				if v, err := strconv.ParseFloat(f[2:], 64); err == nil {
+					q = v
+				}
+			}
+		}
+		if q > 0 {
+			candidates = append(candidates, candidate{lang, q})
+		}
+	}
+	if len(candidates) == 0 {
+		return Default
+	}
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].q > candidates[j].q })
+	return candidates[0].lang
+}
+
+// Keys mengembalikan semua key katalog lang secara terurut
+func Keys(lang Lang) []string {
+	keys := make([]string, 0, len(catalogs[lang]))
+	for k := range catalogs[lang] {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
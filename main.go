@@ -2,13 +2,18 @@ package main
 
 import (
 	"log"
+	"os"
+	"time"
+	"uas/apperror"
 	"uas/config"
 	"uas/database"
 	"uas/routes"
+	"uas/utils"
 
 	_ "uas/docs"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/requestid"
 )
 
 // @title           UAS API Documentation
@@ -35,19 +40,31 @@ func main() {
 	// Menghubungkan ENV
 	config.Config();
 
+	// Kunci JWT (RS256/EdDSA) dari direktori, dimuat ulang berkala untuk rotasi
+	keysDir := os.Getenv("JWT_KEYS_DIR")
+	activeKID := os.Getenv("JWT_ACTIVE_KID")
+	if err := utils.InitSigningKeys(keysDir, activeKID); err != nil {
+		log.Fatal("Gagal memuat kunci JWT ", err)
+	}
+	if interval, err := time.ParseDuration(os.Getenv("JWT_KEYS_RELOAD_INTERVAL")); err == nil {
+		utils.StartKeyRotation(keysDir, activeKID, interval)
+	}
+
 	// Database postgre	SQL
 	postgreSQL := database.ConnectDB()
 	mongoDB := database.ConnectMongoDB()
 
 	// Inisialisasi fiber
 	app := fiber.New(fiber.Config{
-		ErrorHandler: func (c *fiber.Ctx, err error) error {
-			return c.Status(500).JSON(fiber.Map{
-				"error": err.Error(),
-			})
-		},
+		// Default Fiber 4 MB; foto profil boleh sampai 5 MB ditambah overhead multipart
+		BodyLimit: 8 * 1024 * 1024,
+		// Semua error handler/middleware dikirim sebagai problem+json (RFC 7807)
+		ErrorHandler: apperror.Handler,
 	})
 
+	// X-Request-ID untuk setiap request, ikut dicantumkan di body error dan log
+	app.Use(requestid.New())
+
 	// routes
 	routes.SetupRoutes(app, postgreSQL, mongoDB)
 
@@ -1,79 +1,137 @@
 package middleware
 
 import (
+	"os"
 	"strings"
+	"time"
 	"uas/app/repository"
+	"uas/apperror"
+	"uas/helpers"
+	"uas/i18n"
 	"uas/utils"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
 )
 
-func AuthRequired() fiber.Handler {
+// AuthRequired menerima Bearer JWT, atau API key jika apiKeys tidak nil.
+// Jika sessions tidak nil, access token yang sesinya sudah dicabut ditolak
+func AuthRequired(apiKeys repository.APIKeyRepository, sessions helpers.SessionChecker) fiber.Handler {
 	return func(c *fiber.Ctx) error {
+		if rawKey := apiKeyFromRequest(c); rawKey != "" {
+			if apiKeys == nil {
+				return apperror.Unauthorized("api_key_not_accepted")
+			}
+			return authenticateAPIKey(c, apiKeys, rawKey)
+		}
+
 		// Ambil token dari header Authorization
 		authHeader := c.Get("Authorization")
 		if authHeader == "" {
-			return c.Status(401).JSON(fiber.Map{
-				"error": "Token akses diperlukan",
-			})
+			return apperror.Unauthorized("token_required")
 		}
 
 		// Extract token dari "Bearer TOKEN"
 		tokenParts := strings.Split(authHeader, " ")
 		if len(tokenParts) != 2 || tokenParts[0] != "Bearer" {
-			return c.Status(401).JSON(fiber.Map{
-				"error": "Format token tidak valid",
-			})
+			return apperror.Unauthorized("token_malformed")
 		}
 
 		// Validasi token
 		claims, err := utils.ValidateToken(tokenParts[1])
-		if err != nil {
-			return c.Status(401).JSON(fiber.Map{
-				"error": "Token tidak valid atau expired",
-			})
+		if err != nil || claims.UserID == uuid.Nil {
+			return apperror.Unauthorized("token_invalid")
 		}
 
 		// Simpan informasi user di context
 		c.Locals("user_id", claims.UserID)
+		if claims.IssuedAt != nil {
+			c.Locals("token_issued_at", claims.IssuedAt.Time)
+		}
 		c.Locals("username", claims.Username)
 		c.Locals("role_name", claims.RoleName)
 
+		if claims.SessionID != nil {
+			if sessions != nil {
+				active, err := sessions.SessionActive(c.Context(), *claims.SessionID, claims.UserID)
+				if err != nil {
+					return apperror.Internal(err)
+				}
+				if !active {
+					return apperror.Unauthorized("session_revoked")
+				}
+			}
+			c.Locals("session_id", *claims.SessionID)
+		}
+
+		// Bahasa pilihan user didahulukan dari Accept-Language (lihat i18n.FromContext)
+		if claims.Language != "" {
+			c.Locals("language", claims.Language)
+		}
+
+		// Fast path: permission yang ikut ditanam di access token (opsional), hanya selama perm_exp belum lewat
+		if claims.Permissions != nil && claims.PermissionsExpiresAt != nil && time.Now().Before(claims.PermissionsExpiresAt.Time) {
+			c.Locals("permissions", claims.Permissions)
+		}
+
+		// Token impersonation: tandai response dan blokir aksi yang mengubah data (kecuali diizinkan lewat env)
+		if claims.ImpersonatorID != nil {
+			c.Locals("impersonator_id", *claims.ImpersonatorID)
+			c.Set("X-Impersonated-By", claims.ImpersonatorID.String())
+
+			if !isReadOnlyMethod(c.Method()) && os.Getenv("IMPERSONATION_ALLOW_WRITES") != "true" {
+				return apperror.Forbidden("impersonation_read_only")
+			}
+		}
+
 		return c.Next()
 	}
 }
 
 // Menerima parameter string 'perm' (misal: "achievement:create")
-func RequirePermission(perm string) fiber.Handler {
+func RequirePermission(resolver helpers.PermissionResolver, perm string) fiber.Handler {
     return func(c *fiber.Ctx) error {
-        // 1. Ambil User ID dari Locals (yang diset oleh AuthRequired)
-        userIDLocal := c.Locals("user_id")
-        
-        if userIDLocal == nil {
-            return c.Status(401).JSON(fiber.Map{"error": "Unauthorized: User ID not found"})
+        // 1. Ambil Role dari Locals (yang diset oleh AuthRequired)
+        roleName, ok := c.Locals("role_name").(string)
+        if !ok || (c.Locals("user_id") == nil && c.Locals("api_key_id") == nil) {
+            return apperror.Unauthorized("unauthorized")
         }
 
-        // Pastikan tipe datanya UUID
-        userID, ok := userIDLocal.(uuid.UUID)
-        if !ok {
-             return c.Status(500).JSON(fiber.Map{"error": "Internal Server Error: Invalid User ID format"})
+        // 2. Token yang terbit sebelum role/status user diganti harus di-refresh dulu.
+        //    Permission di token hanya dipakai jika permission role-nya belum berubah sejak token terbit
+        tokenPerms, hasTokenPerms := c.Locals("permissions").([]string)
+        if issuedAt, ok := c.Locals("token_issued_at").(time.Time); ok {
+            if userID, ok := c.Locals("user_id").(uuid.UUID); ok && resolver.UserChangedSince(userID, issuedAt) {
+                return apperror.Unauthorized("session_revoked")
+            }
+            if hasTokenPerms && resolver.RoleChangedSince(roleName, issuedAt) {
+                hasTokenPerms = false
+            }
         }
 
-        // 2. Cek ke Database via Repository
-        allowed, err := repository.CheckPermission(userID, perm)
-        if err != nil {
-            return c.Status(500).JSON(fiber.Map{"error": "Gagal memverifikasi izin"})
+        // 3. Cek permission di token dulu, kalau tidak ada pakai resolver (cache per role)
+        var allowed bool
+        if hasTokenPerms {
+            for _, p := range tokenPerms {
+                if p == perm {
+                    allowed = true
+                    break
+                }
+            }
+        } else {
+            var err error
+            allowed, err = resolver.HasPermission(c.Context(), roleName, perm)
+            if err != nil {
+                return apperror.Internal(err)
+            }
         }
 
-        // 3. Logika Allow/Deny
+        // 4. Logika Allow/Deny
         if !allowed {
-            return c.Status(403).JSON(fiber.Map{
-                "error": "Forbidden: Anda tidak memiliki izin '" + perm + "'",
-            })
+            return apperror.Forbidden("permission_denied").WithParams(i18n.Params{"permission": perm})
         }
 
-        // 4. Lanjut ke Controller
+        // 5. Lanjut ke Controller
         return c.Next()
     }
 }
\ No newline at end of file
@@ -0,0 +1,49 @@
+package middleware
+
+import (
+	"log"
+	"net/http"
+	"time"
+	"uas/app/models"
+	"uas/app/repository"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+func isReadOnlyMethod(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions
+}
+
+// AuditImpersonation mencatat setiap request yang dibuat dengan token impersonation,
+// termasuk request yang diblokir. Dipasang di level group sebelum AuthRequired.
+func AuditImpersonation(repo repository.AuditRepository) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		err := c.Next()
+
+		impersonatorID, ok := c.Locals("impersonator_id").(uuid.UUID)
+		if !ok {
+			return err
+		}
+
+		entry := models.AuditLog{
+			ID:         uuid.New(),
+			ActorID:    &impersonatorID,
+			Action:     models.AuditImpersonationRequest,
+			Method:     c.Method(),
+			Path:       c.OriginalURL(),
+			StatusCode: responseStatus(c, err),
+			IPAddress:  c.IP(),
+			CreatedAt:  time.Now(),
+		}
+		if userID, ok := c.Locals("user_id").(uuid.UUID); ok {
+			entry.SubjectUserID = &userID
+		}
+
+		if logErr := repo.CreateAuditLog(c.Context(), entry); logErr != nil {
+			log.Println("Gagal mencatat audit impersonation:", logErr)
+		}
+
+		return err
+	}
+}
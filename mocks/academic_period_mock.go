@@ -0,0 +1,47 @@
+package mocks
+
+import (
+	"context"
+	"uas/app/models"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockAcademicPeriodRepo struct {
+	mock.Mock
+}
+
+func (m *MockAcademicPeriodRepo) GetPeriods(ctx context.Context) ([]models.AcademicPeriod, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]models.AcademicPeriod), args.Error(1)
+}
+
+func (m *MockAcademicPeriodRepo) GetPeriodByID(ctx context.Context, id uuid.UUID) (models.AcademicPeriod, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(models.AcademicPeriod), args.Error(1)
+}
+
+func (m *MockAcademicPeriodRepo) CreatePeriod(ctx context.Context, period models.AcademicPeriod) error {
+	return m.Called(ctx, period).Error(0)
+}
+
+func (m *MockAcademicPeriodRepo) UpdatePeriod(ctx context.Context, period models.AcademicPeriod) error {
+	return m.Called(ctx, period).Error(0)
+}
+
+func (m *MockAcademicPeriodRepo) DeletePeriod(ctx context.Context, id uuid.UUID) error {
+	return m.Called(ctx, id).Error(0)
+}
+
+func (m *MockAcademicPeriodRepo) ActivatePeriod(ctx context.Context, id uuid.UUID) error {
+	return m.Called(ctx, id).Error(0)
+}
+
+func (m *MockAcademicPeriodRepo) ClosePeriod(ctx context.Context, id uuid.UUID, closedBy *uuid.UUID) error {
+	return m.Called(ctx, id, closedBy).Error(0)
+}
+
+func (m *MockAcademicPeriodRepo) ReopenPeriod(ctx context.Context, id uuid.UUID) error {
+	return m.Called(ctx, id).Error(0)
+}
@@ -0,0 +1,79 @@
+package mocks
+
+import (
+	"context"
+	"uas/app/models"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockAcademicUnitRepo struct {
+	mock.Mock
+}
+
+func (m *MockAcademicUnitRepo) GetFaculties(ctx context.Context) ([]models.Faculty, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]models.Faculty), args.Error(1)
+}
+
+func (m *MockAcademicUnitRepo) GetFacultyByID(ctx context.Context, id uuid.UUID) (models.Faculty, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(models.Faculty), args.Error(1)
+}
+
+func (m *MockAcademicUnitRepo) CreateFaculty(ctx context.Context, faculty models.Faculty) error {
+	return m.Called(ctx, faculty).Error(0)
+}
+
+func (m *MockAcademicUnitRepo) UpdateFaculty(ctx context.Context, faculty models.Faculty) error {
+	return m.Called(ctx, faculty).Error(0)
+}
+
+func (m *MockAcademicUnitRepo) DeleteFaculty(ctx context.Context, id uuid.UUID) error {
+	return m.Called(ctx, id).Error(0)
+}
+
+func (m *MockAcademicUnitRepo) GetDepartments(ctx context.Context, facultyID *uuid.UUID) ([]models.Department, error) {
+	args := m.Called(ctx, facultyID)
+	return args.Get(0).([]models.Department), args.Error(1)
+}
+
+func (m *MockAcademicUnitRepo) GetDepartmentByID(ctx context.Context, id uuid.UUID) (models.Department, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(models.Department), args.Error(1)
+}
+
+func (m *MockAcademicUnitRepo) CreateDepartment(ctx context.Context, department models.Department) error {
+	return m.Called(ctx, department).Error(0)
+}
+
+func (m *MockAcademicUnitRepo) UpdateDepartment(ctx context.Context, department models.Department) error {
+	return m.Called(ctx, department).Error(0)
+}
+
+func (m *MockAcademicUnitRepo) DeleteDepartment(ctx context.Context, id uuid.UUID) error {
+	return m.Called(ctx, id).Error(0)
+}
+
+func (m *MockAcademicUnitRepo) GetProgramStudies(ctx context.Context, departmentID *uuid.UUID) ([]models.ProgramStudy, error) {
+	args := m.Called(ctx, departmentID)
+	return args.Get(0).([]models.ProgramStudy), args.Error(1)
+}
+
+func (m *MockAcademicUnitRepo) GetProgramStudyByID(ctx context.Context, id uuid.UUID) (models.ProgramStudy, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(models.ProgramStudy), args.Error(1)
+}
+
+func (m *MockAcademicUnitRepo) CreateProgramStudy(ctx context.Context, programStudy models.ProgramStudy) error {
+	return m.Called(ctx, programStudy).Error(0)
+}
+
+func (m *MockAcademicUnitRepo) UpdateProgramStudy(ctx context.Context, programStudy models.ProgramStudy) error {
+	return m.Called(ctx, programStudy).Error(0)
+}
+
+func (m *MockAcademicUnitRepo) DeleteProgramStudy(ctx context.Context, id uuid.UUID) error {
+	return m.Called(ctx, id).Error(0)
+}
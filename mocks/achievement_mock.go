@@ -2,6 +2,7 @@ package mocks
 
 import (
 	"context"
+	"time"
 	"uas/app/models"
 
 	"github.com/stretchr/testify/mock"
@@ -16,6 +17,11 @@ func (m *MockAchievementRepo) GetStudentIDByUserID(ctx context.Context, userID s
 	return args.String(0), args.Error(1)
 }
 
+func (m *MockAchievementRepo) GetStudentAcademicStatus(ctx context.Context, studentID string) (string, error) {
+	args := m.Called(ctx, studentID)
+	return args.String(0), args.Error(1)
+}
+
 func (m *MockAchievementRepo) GetLecturerIDByUserID(ctx context.Context, userID string) (string, error) {
 	args := m.Called(ctx, userID)
 	return args.String(0), args.Error(1)
@@ -55,7 +61,19 @@ func (m *MockAchievementRepo) CreateAchievementReference(ctx context.Context, re
 func (m *MockAchievementRepo) CreateAchievementMongo(ctx context.Context, data models.AchievementMongo) (string, error) { return "mongo-id", nil }
 func (m *MockAchievementRepo) UpdateAchievement(ctx context.Context, pgID string, mongoID string, data models.AchievementMongo) error { return nil }
 func (m *MockAchievementRepo) SoftDeleteAchievement(ctx context.Context, pgID string, mongoID string) error { return nil }
+func (m *MockAchievementRepo) DeleteMongoAchievements(ctx context.Context, mongoIDs []string) (int64, error) {
+	args := m.Called(ctx, mongoIDs)
+	return int64(args.Int(0)), args.Error(1)
+}
 func (m *MockAchievementRepo) AddAttachmentToMongo(ctx context.Context, mongoID string, attachment models.Attachment) error { return nil }
-func (m *MockAchievementRepo) GetAllReferences(ctx context.Context, filterUserID string) ([]models.AchievementReference, map[string]string, map[string]string, error) { return nil, nil, nil, nil }
+func (m *MockAchievementRepo) GetAllReferences(ctx context.Context, filter models.AchievementFilter) ([]models.AchievementReference, map[string]models.AchievementOwner, error) {
+	args := m.Called(ctx, filter)
+	return args.Get(0).([]models.AchievementReference), args.Get(1).(map[string]models.AchievementOwner), args.Error(2)
+}
 func (m *MockAchievementRepo) GetAchievementReferenceWithDetail(ctx context.Context, id string) (models.AchievementResponse, error) { return models.AchievementResponse{}, nil }
-func (m *MockAchievementRepo) GetMongoDetailByID(ctx context.Context, mongoID string) (models.AchievementMongo, error) { return models.AchievementMongo{}, nil }
\ No newline at end of file
+func (m *MockAchievementRepo) GetMongoDetailByID(ctx context.Context, mongoID string) (models.AchievementMongo, error) { return models.AchievementMongo{}, nil }
+
+func (m *MockAchievementRepo) GetAcademicPeriodByDate(ctx context.Context, date time.Time) (models.AcademicPeriod, error) {
+	args := m.Called(ctx, date)
+	return args.Get(0).(models.AcademicPeriod), args.Error(1)
+}
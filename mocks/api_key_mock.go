@@ -0,0 +1,27 @@
+package mocks
+
+import (
+	"context"
+	"uas/app/models"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockAPIKeyRepo struct {
+	mock.Mock
+}
+
+func (m *MockAPIKeyRepo) GetAPIKeyByHash(ctx context.Context, keyHash string) (models.APIKey, error) {
+	args := m.Called(ctx, keyHash)
+	return args.Get(0).(models.APIKey), args.Error(1)
+}
+
+func (m *MockAPIKeyRepo) TouchAPIKey(ctx context.Context, id uuid.UUID, ip string) error {
+	args := m.Called(ctx, id, ip)
+	return args.Error(0)
+}
+
+func (m *MockAPIKeyRepo) CreateAPIKey(ctx context.Context, key models.APIKey) error { return nil }
+func (m *MockAPIKeyRepo) GetAllAPIKeys(ctx context.Context) ([]models.APIKey, error) { return nil, nil }
+func (m *MockAPIKeyRepo) RevokeAPIKey(ctx context.Context, id uuid.UUID) error { return nil }
@@ -0,0 +1,17 @@
+package mocks
+
+import (
+	"context"
+	"uas/app/models"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockAuditRepo struct {
+	mock.Mock
+}
+
+func (m *MockAuditRepo) CreateAuditLog(ctx context.Context, log models.AuditLog) error {
+	args := m.Called(ctx, log)
+	return args.Error(0)
+}
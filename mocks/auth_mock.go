@@ -23,8 +23,59 @@ func (m *MockUserRepo) CreateUser(ctx context.Context, tx *sql.Tx, user models.U
 	return args.Error(0)
 }
 
-func (m *MockUserRepo) GetAllUsers(ctx context.Context) ([]models.User, error) { return nil, nil }
-func (m *MockUserRepo) GetUserByID(ctx context.Context, id uuid.UUID) (models.User, error) { return models.User{}, nil }
-func (m *MockUserRepo) UpdateUser(ctx context.Context, id uuid.UUID, user models.UpdateUser) error { return nil }
-func (m *MockUserRepo) DeleteUser(ctx context.Context, id uuid.UUID) error { return nil }
-func (m *MockUserRepo) UpdateUserRole(ctx context.Context, userID uuid.UUID, roleID uuid.UUID) error { return nil }
\ No newline at end of file
+func (m *MockUserRepo) GetAllUsers(ctx context.Context, q models.ListQuery) ([]models.User, models.ListMeta, error) {
+	args := m.Called(ctx, q)
+	return args.Get(0).([]models.User), args.Get(1).(models.ListMeta), args.Error(2)
+}
+
+func (m *MockUserRepo) GetUserByID(ctx context.Context, id uuid.UUID) (models.User, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(models.User), args.Error(1)
+}
+
+func (m *MockUserRepo) UpdateUser(ctx context.Context, tx *sql.Tx, id uuid.UUID, user models.UpdateUser) error {
+	args := m.Called(ctx, tx, id, user)
+	return args.Error(0)
+}
+func (m *MockUserRepo) SoftDeleteUser(ctx context.Context, tx *sql.Tx, id uuid.UUID, deletedBy uuid.UUID) error {
+	args := m.Called(ctx, tx, id, deletedBy)
+	return args.Error(0)
+}
+
+func (m *MockUserRepo) RestoreUser(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockUserRepo) GetPurgePlanForUpdate(ctx context.Context, tx *sql.Tx, id uuid.UUID) (models.UserPurgePlan, error) {
+	args := m.Called(ctx, tx, id)
+	return args.Get(0).(models.UserPurgePlan), args.Error(1)
+}
+
+func (m *MockUserRepo) PurgeUser(ctx context.Context, tx *sql.Tx, plan models.UserPurgePlan) error {
+	args := m.Called(ctx, tx, plan)
+	return args.Error(0)
+}
+func (m *MockUserRepo) UpdateUserRole(ctx context.Context, tx *sql.Tx, userID uuid.UUID, roleID uuid.UUID) error {
+	args := m.Called(ctx, tx, userID, roleID)
+	return args.Error(0)
+}
+
+func (m *MockUserRepo) CountUsersByRoleForUpdate(ctx context.Context, tx *sql.Tx, roleName string) (int, error) {
+	args := m.Called(ctx, tx, roleName)
+	return args.Int(0), args.Error(1)
+}
+func (m *MockUserRepo) GetUserByEmail(ctx context.Context, email string) (models.User, error) {
+	args := m.Called(ctx, email)
+	return args.Get(0).(models.User), args.Error(1)
+}
+
+func (m *MockUserRepo) GetUserByNIM(ctx context.Context, nim string) (models.User, error) {
+	args := m.Called(ctx, nim)
+	return args.Get(0).(models.User), args.Error(1)
+}
+
+func (m *MockUserRepo) GetTakenIdentities(ctx context.Context, usernames []string, emails []string) (map[string]bool, map[string]bool, error) {
+	args := m.Called(ctx, usernames, emails)
+	return args.Get(0).(map[string]bool), args.Get(1).(map[string]bool), args.Error(2)
+}
@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"uas/app/models"
 
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/mock"
 )
 
@@ -18,6 +19,38 @@ func (m *MockLecturerRepo) CreateLecture(ctx context.Context, tx *sql.Tx, lectur
 }
 
 // Method lain (Dummy)
-func (m *MockLecturerRepo) GetAllLecturersByRole(ctx context.Context, roleName string) ([]models.GetLecture, error) { return nil, nil }
+func (m *MockLecturerRepo) GetAllLecturersByRole(ctx context.Context, roleName string, q models.ListQuery) ([]models.GetLecture, models.ListMeta, error) { return nil, models.ListMeta{}, nil }
 func (m *MockLecturerRepo) GetLecturerByID(ctx context.Context, id string) (models.GetLecture, error) { return models.GetLecture{}, nil }
-func (m *MockLecturerRepo) GetAdviseesByLecturerID(ctx context.Context, lecturerID string) ([]models.GetStudent, error) { return nil, nil }
\ No newline at end of file
+func (m *MockLecturerRepo) GetLecturerByUserID(ctx context.Context, userID string) (models.GetLecture, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).(models.GetLecture), args.Error(1)
+}
+
+func (m *MockLecturerRepo) GetAdviseesByLecturerID(ctx context.Context, lecturerID string, q models.ListQuery) ([]models.Advisee, models.ListMeta, error) {
+	args := m.Called(ctx, lecturerID, q)
+	return args.Get(0).([]models.Advisee), args.Get(1).(models.ListMeta), args.Error(2)
+}
+
+func (m *MockLecturerRepo) RetireLecturer(ctx context.Context, tx *sql.Tx, userID uuid.UUID) error {
+	args := m.Called(ctx, tx, userID)
+	return args.Error(0)
+}
+
+func (m *MockLecturerRepo) ReactivateLecturer(ctx context.Context, tx *sql.Tx, userID uuid.UUID) (bool, error) {
+	args := m.Called(ctx, tx, userID)
+	return args.Bool(0), args.Error(1)
+}
+func (m *MockLecturerRepo) GetLecturerIDsByCodes(ctx context.Context, codes []string) (map[string]uuid.UUID, error) {
+	args := m.Called(ctx, codes)
+	return args.Get(0).(map[string]uuid.UUID), args.Error(1)
+}
+
+func (m *MockLecturerRepo) GetAdvisorLoads(ctx context.Context, tx *sql.Tx, lecturerIDs []uuid.UUID) ([]models.AdvisorLoad, error) {
+	args := m.Called(ctx, tx, lecturerIDs)
+	return args.Get(0).([]models.AdvisorLoad), args.Error(1)
+}
+
+func (m *MockLecturerRepo) SetMaxAdvisees(ctx context.Context, lecturerID uuid.UUID, maxAdvisees *int) error {
+	args := m.Called(ctx, lecturerID, maxAdvisees)
+	return args.Error(0)
+}
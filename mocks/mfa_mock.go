@@ -0,0 +1,37 @@
+package mocks
+
+import (
+	"context"
+	"uas/app/models"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockMFARepo struct {
+	mock.Mock
+}
+
+func (m *MockMFARepo) GetMFAByUserID(ctx context.Context, userID uuid.UUID) (models.UserMFA, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).(models.UserMFA), args.Error(1)
+}
+
+func (m *MockMFARepo) EnableMFA(ctx context.Context, userID uuid.UUID, recoveryCodeHashes []string) error {
+	args := m.Called(ctx, userID, recoveryCodeHashes)
+	return args.Error(0)
+}
+
+func (m *MockMFARepo) UseRecoveryCode(ctx context.Context, userID uuid.UUID, codeHash string) (bool, error) {
+	args := m.Called(ctx, userID, codeHash)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockMFARepo) UseTOTPStep(ctx context.Context, userID uuid.UUID, step int64) (bool, error) {
+	args := m.Called(ctx, userID, step)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockMFARepo) SaveMFASecret(ctx context.Context, userID uuid.UUID, secret string) error { return nil }
+func (m *MockMFARepo) DisableMFA(ctx context.Context, userID uuid.UUID) error { return nil }
+func (m *MockMFARepo) ReplaceRecoveryCodes(ctx context.Context, userID uuid.UUID, recoveryCodeHashes []string) error { return nil }
@@ -0,0 +1,16 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockPermissionRepo struct {
+	mock.Mock
+}
+
+func (m *MockPermissionRepo) GetPermissionNamesByRole(ctx context.Context, roleName string) ([]string, error) {
+	args := m.Called(ctx, roleName)
+	return args.Get(0).([]string), args.Error(1)
+}
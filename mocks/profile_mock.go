@@ -0,0 +1,60 @@
+package mocks
+
+import (
+	"context"
+	"database/sql"
+	"uas/app/models"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockProfileRepo struct {
+	mock.Mock
+}
+
+func (m *MockProfileRepo) GetProfile(ctx context.Context, userID uuid.UUID) (models.Profile, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).(models.Profile), args.Error(1)
+}
+
+func (m *MockProfileRepo) UpdateProfileFields(ctx context.Context, userID uuid.UUID, fields models.ProfileFields) error {
+	args := m.Called(ctx, userID, fields)
+	return args.Error(0)
+}
+
+func (m *MockProfileRepo) UpdatePhoto(ctx context.Context, userID uuid.UUID, photoURL string, thumbnails map[string]string) error {
+	args := m.Called(ctx, userID, photoURL, thumbnails)
+	return args.Error(0)
+}
+
+func (m *MockProfileRepo) CreateEmailChange(ctx context.Context, req models.EmailChangeRequest) error {
+	args := m.Called(ctx, req)
+	return args.Error(0)
+}
+
+func (m *MockProfileRepo) GetPendingEmailChangeForUpdate(ctx context.Context, tx *sql.Tx, tokenHash string) (models.EmailChangeRequest, error) {
+	args := m.Called(ctx, tx, tokenHash)
+	return args.Get(0).(models.EmailChangeRequest), args.Error(1)
+}
+
+func (m *MockProfileRepo) ConfirmEmailChange(ctx context.Context, tx *sql.Tx, req models.EmailChangeRequest) error {
+	args := m.Called(ctx, tx, req)
+	return args.Error(0)
+}
+
+// MockMailer menyimpan email yang dikirim agar bisa diperiksa di test
+type MockMailer struct {
+	Sent []SentMail
+}
+
+type SentMail struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+func (m *MockMailer) Send(to string, subject string, body string) error {
+	m.Sent = append(m.Sent, SentMail{To: to, Subject: subject, Body: body})
+	return nil
+}
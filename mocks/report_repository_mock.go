@@ -4,6 +4,7 @@ import (
 	"context"
 	"uas/app/models"
 
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/mock"
 )
 
@@ -11,18 +12,28 @@ type MockReportRepo struct {
 	mock.Mock
 }
 
-func (m *MockReportRepo) GetStatistics(ctx context.Context) (models.DashboardStatistics, error) {
-	args := m.Called(ctx)
+func (m *MockReportRepo) GetStatistics(ctx context.Context, periodID *uuid.UUID) (models.DashboardStatistics, error) {
+	args := m.Called(ctx, periodID)
 
 	return args.Get(0).(models.DashboardStatistics), args.Error(1)
 }
 
+func (m *MockReportRepo) GetStatisticsByUnit(ctx context.Context, groupBy string, periodID *uuid.UUID) ([]models.UnitStatistics, error) {
+	args := m.Called(ctx, groupBy, periodID)
+	return args.Get(0).([]models.UnitStatistics), args.Error(1)
+}
+
 func (m *MockReportRepo) GetStudentProfile(ctx context.Context, studentID string) (models.StudentReportProfile, error) {
 	args := m.Called(ctx, studentID)
 	return args.Get(0).(models.StudentReportProfile), args.Error(1)
 }
 
-func (m *MockReportRepo) GetVerifiedAchievementsByStudentID(ctx context.Context, studentID string) ([]models.AchievementReference, error) {
-	args := m.Called(ctx, studentID)
+func (m *MockReportRepo) GetVerifiedAchievementsByStudentID(ctx context.Context, studentID string, periodID *uuid.UUID) ([]models.AchievementReference, error) {
+	args := m.Called(ctx, studentID, periodID)
 	return args.Get(0).([]models.AchievementReference), args.Error(1)
-}
\ No newline at end of file
+}
+
+func (m *MockReportRepo) GetAcademicPeriodByID(ctx context.Context, id uuid.UUID) (models.AcademicPeriod, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(models.AcademicPeriod), args.Error(1)
+}
@@ -0,0 +1,58 @@
+package mocks
+
+import (
+	"context"
+	"uas/app/models"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockRoleRepo struct {
+	mock.Mock
+}
+
+func (m *MockRoleRepo) GetAllRoles(ctx context.Context) ([]models.Role, error) { return nil, nil }
+
+func (m *MockRoleRepo) GetRoleByID(ctx context.Context, id uuid.UUID) (models.Role, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(models.Role), args.Error(1)
+}
+
+func (m *MockRoleRepo) GetRoleByName(ctx context.Context, name string) (models.Role, error) {
+	args := m.Called(ctx, name)
+	return args.Get(0).(models.Role), args.Error(1)
+}
+
+func (m *MockRoleRepo) CreateRole(ctx context.Context, role models.Role) error { return nil }
+func (m *MockRoleRepo) UpdateRole(ctx context.Context, role models.Role) error { return nil }
+
+func (m *MockRoleRepo) DeleteRole(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockRoleRepo) CountUsersByRole(ctx context.Context, roleID uuid.UUID) (int, error) {
+	args := m.Called(ctx, roleID)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockRoleRepo) GetAllPermissions(ctx context.Context) ([]models.Permission, error) { return nil, nil }
+
+func (m *MockRoleRepo) GetPermissionByID(ctx context.Context, id uuid.UUID) (models.Permission, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(models.Permission), args.Error(1)
+}
+
+func (m *MockRoleRepo) CreatePermission(ctx context.Context, perm models.Permission) error { return nil }
+func (m *MockRoleRepo) UpdatePermission(ctx context.Context, perm models.Permission) error { return nil }
+func (m *MockRoleRepo) GetRolePermissions(ctx context.Context, roleID uuid.UUID) ([]models.Permission, error) { return nil, nil }
+func (m *MockRoleRepo) SetRolePermissions(ctx context.Context, roleID uuid.UUID, permissionIDs []uuid.UUID) error { return nil }
+
+func (m *MockRoleRepo) GrantPermission(ctx context.Context, roleID uuid.UUID, permissionID uuid.UUID) error {
+	args := m.Called(ctx, roleID, permissionID)
+	return args.Error(0)
+}
+
+func (m *MockRoleRepo) RevokePermission(ctx context.Context, roleID uuid.UUID, permissionID uuid.UUID) error { return nil }
+func (m *MockRoleRepo) GetAllRolePermissionPairs(ctx context.Context) (map[uuid.UUID][]uuid.UUID, error) { return nil, nil }
@@ -0,0 +1,35 @@
+package mocks
+
+import (
+	"context"
+	"uas/app/models"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockSessionRepo struct {
+	mock.Mock
+}
+
+func (m *MockSessionRepo) CreateSession(ctx context.Context, session models.UserSession) error {
+	args := m.Called(ctx, session)
+	return args.Error(0)
+}
+
+func (m *MockSessionRepo) GetSessionByID(ctx context.Context, id uuid.UUID) (models.UserSession, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(models.UserSession), args.Error(1)
+}
+
+func (m *MockSessionRepo) GetActiveSessionsByUser(ctx context.Context, userID uuid.UUID) ([]models.UserSession, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).([]models.UserSession), args.Error(1)
+}
+
+func (m *MockSessionRepo) TouchSession(ctx context.Context, id uuid.UUID, ip string) error { return nil }
+
+func (m *MockSessionRepo) RevokeSession(ctx context.Context, id uuid.UUID, userID uuid.UUID) error {
+	args := m.Called(ctx, id, userID)
+	return args.Error(0)
+}
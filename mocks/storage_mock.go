@@ -0,0 +1,36 @@
+package mocks
+
+import (
+	"context"
+	"io"
+	"strings"
+)
+
+// MockStorage menyimpan file di memori agar isi upload bisa diperiksa di test
+type MockStorage struct {
+	Files   map[string][]byte
+	Deleted []string
+}
+
+func NewMockStorage() *MockStorage {
+	return &MockStorage{Files: make(map[string][]byte)}
+}
+
+func (m *MockStorage) Put(ctx context.Context, key string, r io.Reader) (string, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return "", err
+	}
+	m.Files[key] = data
+	return "/uploads/" + key, nil
+}
+
+func (m *MockStorage) Delete(ctx context.Context, key string) error {
+	delete(m.Files, key)
+	m.Deleted = append(m.Deleted, key)
+	return nil
+}
+
+func (m *MockStorage) KeyFromURL(url string) (string, bool) {
+	return strings.CutPrefix(url, "/uploads/")
+}
@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"uas/app/models"
 
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/mock"
 )
 
@@ -17,6 +18,89 @@ func (m *MockStudentRepo) CreateStudent(ctx context.Context, tx *sql.Tx, student
 	return args.Error(0)
 }
 
-func (m *MockStudentRepo) GetAllStudentsByRole(ctx context.Context, roleName string) ([]models.GetStudent, error) { return nil, nil }
+func (m *MockStudentRepo) GetAllStudentsByRole(ctx context.Context, roleName string, q models.ListQuery) ([]models.GetStudent, models.ListMeta, error) {
+	args := m.Called(ctx, roleName, q)
+	return args.Get(0).([]models.GetStudent), args.Get(1).(models.ListMeta), args.Error(2)
+}
+
 func (m *MockStudentRepo) GetStudentByID(ctx context.Context, id string) (models.GetStudent, error) { return models.GetStudent{}, nil }
-func (m *MockStudentRepo) UpdateStudentAdvisor(ctx context.Context, studentID string, advisorID string) error { return nil }
\ No newline at end of file
+
+func (m *MockStudentRepo) ChangeAdvisor(ctx context.Context, tx *sql.Tx, change models.AdvisorChange) error {
+	args := m.Called(ctx, tx, change)
+	return args.Error(0)
+}
+
+func (m *MockStudentRepo) GetAdvisorHistory(ctx context.Context, studentID uuid.UUID) ([]models.AdvisorHistory, error) {
+	args := m.Called(ctx, studentID)
+	return args.Get(0).([]models.AdvisorHistory), args.Error(1)
+}
+
+func (m *MockStudentRepo) GetAdvisorIDForUpdate(ctx context.Context, tx *sql.Tx, studentID uuid.UUID) (*uuid.UUID, error) {
+	args := m.Called(ctx, tx, studentID)
+	id, _ := args.Get(0).(*uuid.UUID)
+	return id, args.Error(1)
+}
+
+func (m *MockStudentRepo) GetAdviseeIDsForUpdate(ctx context.Context, tx *sql.Tx, lecturerID uuid.UUID) ([]uuid.UUID, error) {
+	args := m.Called(ctx, tx, lecturerID)
+	return args.Get(0).([]uuid.UUID), args.Error(1)
+}
+
+func (m *MockStudentRepo) CountSubmittedAchievements(ctx context.Context, tx *sql.Tx, studentIDs []uuid.UUID) (map[uuid.UUID]int, error) {
+	args := m.Called(ctx, tx, studentIDs)
+	return args.Get(0).(map[uuid.UUID]int), args.Error(1)
+}
+
+func (m *MockStudentRepo) RetireStudent(ctx context.Context, tx *sql.Tx, userID uuid.UUID) error {
+	args := m.Called(ctx, tx, userID)
+	return args.Error(0)
+}
+
+func (m *MockStudentRepo) ReactivateStudent(ctx context.Context, tx *sql.Tx, userID uuid.UUID) (bool, error) {
+	args := m.Called(ctx, tx, userID)
+	return args.Bool(0), args.Error(1)
+}
+func (m *MockStudentRepo) GetTakenNIMs(ctx context.Context, nims []string) (map[string]bool, error) {
+	args := m.Called(ctx, nims)
+	return args.Get(0).(map[string]bool), args.Error(1)
+}
+
+func (m *MockStudentRepo) GetProgramStudyIDsByNames(ctx context.Context, names []string) (map[string]uuid.UUID, error) {
+	args := m.Called(ctx, names)
+	return args.Get(0).(map[string]uuid.UUID), args.Error(1)
+}
+
+func (m *MockStudentRepo) GetUnadvisedStudentsForUpdate(ctx context.Context, tx *sql.Tx) ([]models.Student, error) {
+	args := m.Called(ctx, tx)
+	return args.Get(0).([]models.Student), args.Error(1)
+}
+
+func (m *MockStudentRepo) GetAcademicStatusForUpdate(ctx context.Context, tx *sql.Tx, studentID uuid.UUID) (models.AcademicStatus, error) {
+	args := m.Called(ctx, tx, studentID)
+	return args.Get(0).(models.AcademicStatus), args.Error(1)
+}
+
+func (m *MockStudentRepo) ChangeAcademicStatus(ctx context.Context, tx *sql.Tx, change models.AcademicStatusChange) error {
+	args := m.Called(ctx, tx, change)
+	return args.Error(0)
+}
+
+func (m *MockStudentRepo) GetAcademicStatusHistory(ctx context.Context, studentID uuid.UUID) ([]models.AcademicStatusHistory, error) {
+	args := m.Called(ctx, studentID)
+	return args.Get(0).([]models.AcademicStatusHistory), args.Error(1)
+}
+
+func (m *MockStudentRepo) GetStudentByUserID(ctx context.Context, userID string) (models.StudentWithAdvisor, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).(models.StudentWithAdvisor), args.Error(1)
+}
+
+func (m *MockStudentRepo) GetAchievementSummary(ctx context.Context, studentID string) (models.AchievementSummary, []string, error) {
+	args := m.Called(ctx, studentID)
+	return args.Get(0).(models.AchievementSummary), args.Get(1).([]string), args.Error(2)
+}
+
+func (m *MockStudentRepo) GetRecentRejections(ctx context.Context, studentID string, limit int) ([]models.RejectionNote, error) {
+	args := m.Called(ctx, studentID, limit)
+	return args.Get(0).([]models.RejectionNote), args.Error(1)
+}
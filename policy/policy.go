@@ -0,0 +1,241 @@
+package policy
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"uas/app/models"
+	"uas/i18n"
+)
+
+type Action string
+
+const (
+	ActionRead   Action = "read"
+	ActionUpdate Action = "update"
+	ActionDelete Action = "delete"
+	ActionSubmit Action = "submit"
+	ActionVerify Action = "verify"
+	ActionReject Action = "reject"
+)
+
+type ResourceKind string
+
+const (
+	KindAchievement   ResourceKind = "achievement"
+	KindStudentReport ResourceKind = "student_report"
+)
+
+// Subject adalah user yang meminta akses. Atribut lain (student/lecturer id) dicari saat dibutuhkan
+type Subject struct {
+	UserID string
+	Role   string
+}
+
+// Resource adalah objek yang diakses beserta atributnya
+type Resource struct {
+	Kind           ResourceKind
+	OwnerStudentID string // students.id pemilik resource
+	Status         string // status prestasi (kosong jika tidak relevan)
+	Frozen         bool   // periode akademik resource sudah ditutup: hanya boleh dibaca
+}
+
+func Achievement(ref models.AchievementReference) Resource {
+	return Resource{Kind: KindAchievement, OwnerStudentID: ref.StudentID, Status: ref.Status, Frozen: ref.PeriodClosed}
+}
+
+func StudentReport(studentID string) Resource {
+	return Resource{Kind: KindStudentReport, OwnerStudentID: studentID}
+}
+
+// Decision hasil evaluasi. StateViolation true berarti subjek berhak, tapi status resource tidak mengizinkan aksi.
+// Code adalah kode error stabil untuk klien jika Allowed == false; Reason adalah varian pesannya
+// di katalog (errors.<Code>.<Reason>) dengan Params sebagai isi placeholder
+type Decision struct {
+	Allowed        bool
+	StateViolation bool
+	Code           string
+	Reason         string
+	Params         i18n.Params
+}
+
+// AttributeSource menyediakan atribut relasi subjek-resource (dipenuhi oleh AchievementRepository)
+type AttributeSource interface {
+	GetStudentIDByUserID(ctx context.Context, userID string) (string, error)
+	GetLecturerIDByUserID(ctx context.Context, userID string) (string, error)
+	CheckStudentAdvisorRelationship(ctx context.Context, lecturerID string, studentID string) (bool, error)
+}
+
+// Condition adalah satu syarat relasi subjek-resource
+type Condition struct {
+	Name  string
+	check func(ctx context.Context, ev *evaluation) (bool, error)
+}
+
+// Rule: aksi diizinkan jika salah satu AnyOf terpenuhi dan status resource ada di Statuses (jika diisi)
+type Rule struct {
+	Kind     ResourceKind
+	Action   Action
+	AnyOf    []Condition
+	Statuses []string
+	Reason   string // varian pesan penolakan di katalog: errors.access_denied.<Reason>
+}
+
+var (
+	IsAdmin = Condition{Name: "admin", check: func(ctx context.Context, ev *evaluation) (bool, error) {
+		return ev.subject.Role == models.RoleAdmin, nil
+	}}
+
+	// IsService: klien mesin (API key). Cakupannya sudah dibatasi scope permission di middleware
+	IsService = Condition{Name: "service", check: func(ctx context.Context, ev *evaluation) (bool, error) {
+		return ev.subject.Role == models.RoleService, nil
+	}}
+
+	IsOwner = Condition{Name: "owner", check: func(ctx context.Context, ev *evaluation) (bool, error) {
+		studentID, err := ev.studentID(ctx)
+		if err != nil || studentID == "" {
+			return false, err
+		}
+		return studentID == ev.resource.OwnerStudentID, nil
+	}}
+
+	IsAdvisor = Condition{Name: "advisor", check: func(ctx context.Context, ev *evaluation) (bool, error) {
+		lecturerID, err := ev.lecturerID(ctx)
+		if err != nil || lecturerID == "" {
+			return false, err
+		}
+		return ev.attrs.CheckStudentAdvisorRelationship(ctx, lecturerID, ev.resource.OwnerStudentID)
+	}}
+)
+
+// DefaultRules adalah kebijakan akses kepemilikan aplikasi
+var DefaultRules = []Rule{
+	{Kind: KindAchievement, Action: ActionRead, AnyOf: []Condition{IsAdmin, IsService, IsOwner, IsAdvisor},
+		Reason: "achievement_read"},
+	{Kind: KindAchievement, Action: ActionUpdate, AnyOf: []Condition{IsOwner}, Statuses: []string{"draft"},
+		Reason: "achievement_update"},
+	{Kind: KindAchievement, Action: ActionDelete, AnyOf: []Condition{IsOwner}, Statuses: []string{"draft"},
+		Reason: "achievement_delete"},
+	{Kind: KindAchievement, Action: ActionSubmit, AnyOf: []Condition{IsOwner}, Statuses: []string{"draft"},
+		Reason: "achievement_submit"},
+	{Kind: KindAchievement, Action: ActionVerify, AnyOf: []Condition{IsAdvisor}, Statuses: []string{"submitted"},
+		Reason: "not_advisor"},
+	{Kind: KindAchievement, Action: ActionReject, AnyOf: []Condition{IsAdvisor}, Statuses: []string{"submitted"},
+		Reason: "not_advisor"},
+	{Kind: KindStudentReport, Action: ActionRead, AnyOf: []Condition{IsAdmin, IsService, IsOwner, IsAdvisor},
+		Reason: "student_report"},
+}
+
+type Engine struct {
+	attrs AttributeSource
+	rules map[ResourceKind]map[Action]Rule
+}
+
+func NewEngine(attrs AttributeSource, rules ...Rule) *Engine {
+	if len(rules) == 0 {
+		rules = DefaultRules
+	}
+
+	e := &Engine{attrs: attrs, rules: make(map[ResourceKind]map[Action]Rule)}
+	for _, rule := range rules {
+		if e.rules[rule.Kind] == nil {
+			e.rules[rule.Kind] = make(map[Action]Rule)
+		}
+		e.rules[rule.Kind][rule.Action] = rule
+	}
+	return e
+}
+
+// Authorize mengevaluasi (subject, action, resource). Error hanya dikembalikan jika atribut gagal dimuat
+func (e *Engine) Authorize(ctx context.Context, subject Subject, action Action, resource Resource) (Decision, error) {
+	rule, ok := e.rules[resource.Kind][action]
+	if !ok {
+		return Decision{Code: "access_denied", Reason: "unknown_action",
+			Params: i18n.Params{"action": action, "resource": resource.Kind}}, nil
+	}
+
+	ev := &evaluation{attrs: e.attrs, subject: subject, resource: resource}
+
+	matched := false
+	for _, cond := range rule.AnyOf {
+		ok, err := cond.check(ctx, ev)
+		if err != nil {
+			return Decision{}, fmt.Errorf("gagal mengevaluasi syarat %s: %w", cond.Name, err)
+		}
+		if ok {
+			matched = true
+			break
+		}
+	}
+	if !matched {
+		return Decision{Code: "access_denied", Reason: rule.Reason}, nil
+	}
+
+	if resource.Frozen && action != ActionRead {
+		return Decision{
+			StateViolation: true,
+			Code:           "period_closed",
+			Reason:         "achievement",
+		}, nil
+	}
+
+	if len(rule.Statuses) > 0 && !contains(rule.Statuses, resource.Status) {
+		return Decision{
+			StateViolation: true,
+			Code:           "invalid_status_transition",
+			Reason:         "action",
+			Params: i18n.Params{
+				"action":   action,
+				"statuses": strings.Join(rule.Statuses, "', '"),
+				"status":   resource.Status,
+			},
+		}, nil
+	}
+
+	return Decision{Allowed: true}, nil
+}
+
+// evaluation menyimpan atribut yang sudah dimuat supaya tiap atribut hanya di-query sekali per evaluasi
+type evaluation struct {
+	attrs    AttributeSource
+	subject  Subject
+	resource Resource
+
+	studentLoaded  bool
+	student        string
+	lecturerLoaded bool
+	lecturer       string
+}
+
+func (ev *evaluation) studentID(ctx context.Context) (string, error) {
+	if !ev.studentLoaded {
+		id, err := ev.attrs.GetStudentIDByUserID(ctx, ev.subject.UserID)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return "", err
+		}
+		ev.student, ev.studentLoaded = id, true
+	}
+	return ev.student, nil
+}
+
+func (ev *evaluation) lecturerID(ctx context.Context) (string, error) {
+	if !ev.lecturerLoaded {
+		id, err := ev.attrs.GetLecturerIDByUserID(ctx, ev.subject.UserID)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return "", err
+		}
+		ev.lecturer, ev.lecturerLoaded = id, true
+	}
+	return ev.lecturer, nil
+}
+
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
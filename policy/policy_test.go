@@ -0,0 +1,121 @@
+package policy
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"uas/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// Data dunia kecil: mhs-1 (std-1) dibimbing dosen-1 (lec-1), mhs-2 (std-2) dibimbing dosen lain
+func newTestEngine() *Engine {
+	repo := new(mocks.MockAchievementRepo)
+
+	repo.On("GetStudentIDByUserID", mock.Anything, "mhs-1").Return("std-1", nil).Maybe()
+	repo.On("GetStudentIDByUserID", mock.Anything, "mhs-2").Return("std-2", nil).Maybe()
+	repo.On("GetStudentIDByUserID", mock.Anything, mock.Anything).Return("", sql.ErrNoRows).Maybe()
+
+	repo.On("GetLecturerIDByUserID", mock.Anything, "dosen-1").Return("lec-1", nil).Maybe()
+	repo.On("GetLecturerIDByUserID", mock.Anything, mock.Anything).Return("", sql.ErrNoRows).Maybe()
+
+	repo.On("CheckStudentAdvisorRelationship", mock.Anything, "lec-1", "std-1").Return(true, nil).Maybe()
+	repo.On("CheckStudentAdvisorRelationship", mock.Anything, mock.Anything, mock.Anything).Return(false, nil).Maybe()
+
+	return NewEngine(repo)
+}
+
+var (
+	admin    = Subject{UserID: "admin-1", Role: "Admin"}
+	owner    = Subject{UserID: "mhs-1", Role: "Mahasiswa"}
+	stranger = Subject{UserID: "mhs-2", Role: "Mahasiswa"}
+	advisor  = Subject{UserID: "dosen-1", Role: "Dosen Wali"}
+	otherLec = Subject{UserID: "dosen-2", Role: "Dosen Wali"}
+	service  = Subject{UserID: "key-1", Role: "Service"}
+)
+
+func achievementIn(status string) Resource {
+	return Resource{Kind: KindAchievement, OwnerStudentID: "std-1", Status: status}
+}
+
+// frozenIn adalah prestasi yang periode akademiknya sudah ditutup
+func frozenIn(status string) Resource {
+	resource := achievementIn(status)
+	resource.Frozen = true
+	return resource
+}
+
+func TestPolicy_Rules(t *testing.T) {
+	cases := []struct {
+		name      string
+		subject   Subject
+		action    Action
+		resource  Resource
+		allowed   bool
+		stateDeny bool
+	}{
+		// achievement:read
+		{"read/admin", admin, ActionRead, achievementIn("verified"), true, false},
+		{"read/owner", owner, ActionRead, achievementIn("draft"), true, false},
+		{"read/advisor", advisor, ActionRead, achievementIn("submitted"), true, false},
+		{"read/service", service, ActionRead, achievementIn("verified"), true, false},
+		{"read/other student", stranger, ActionRead, achievementIn("draft"), false, false},
+		{"read/other lecturer", otherLec, ActionRead, achievementIn("submitted"), false, false},
+
+		// achievement:update, delete, submit (pemilik + draft)
+		{"update/owner draft", owner, ActionUpdate, achievementIn("draft"), true, false},
+		{"update/owner submitted", owner, ActionUpdate, achievementIn("submitted"), false, true},
+		{"update/other student", stranger, ActionUpdate, achievementIn("draft"), false, false},
+		{"update/service", service, ActionUpdate, achievementIn("draft"), false, false},
+		{"update/admin", admin, ActionUpdate, achievementIn("draft"), false, false},
+		{"delete/owner draft", owner, ActionDelete, achievementIn("draft"), true, false},
+		{"delete/owner verified", owner, ActionDelete, achievementIn("verified"), false, true},
+		{"delete/advisor", advisor, ActionDelete, achievementIn("draft"), false, false},
+		{"submit/owner draft", owner, ActionSubmit, achievementIn("draft"), true, false},
+		{"submit/owner rejected", owner, ActionSubmit, achievementIn("rejected"), false, true},
+		{"submit/other student", stranger, ActionSubmit, achievementIn("draft"), false, false},
+
+		// achievement:verify, reject (dosen wali + submitted)
+		{"verify/advisor submitted", advisor, ActionVerify, achievementIn("submitted"), true, false},
+		{"verify/advisor draft", advisor, ActionVerify, achievementIn("draft"), false, true},
+		{"verify/other lecturer", otherLec, ActionVerify, achievementIn("submitted"), false, false},
+		{"verify/owner", owner, ActionVerify, achievementIn("submitted"), false, false},
+		{"verify/service", service, ActionVerify, achievementIn("submitted"), false, false},
+		{"verify/admin", admin, ActionVerify, achievementIn("submitted"), false, false},
+		{"reject/advisor submitted", advisor, ActionReject, achievementIn("submitted"), true, false},
+		{"reject/advisor verified", advisor, ActionReject, achievementIn("verified"), false, true},
+		{"reject/other lecturer", otherLec, ActionReject, achievementIn("submitted"), false, false},
+
+		// periode akademik ditutup: hanya boleh dibaca
+		{"frozen/read owner", owner, ActionRead, frozenIn("draft"), true, false},
+		{"frozen/update owner draft", owner, ActionUpdate, frozenIn("draft"), false, true},
+		{"frozen/verify advisor submitted", advisor, ActionVerify, frozenIn("submitted"), false, true},
+		{"frozen/update other student", stranger, ActionUpdate, frozenIn("draft"), false, false},
+
+		// student_report:read
+		{"report/admin", admin, ActionRead, StudentReport("std-1"), true, false},
+		{"report/owner", owner, ActionRead, StudentReport("std-1"), true, false},
+		{"report/advisor", advisor, ActionRead, StudentReport("std-1"), true, false},
+		{"report/service", service, ActionRead, StudentReport("std-1"), true, false},
+		{"report/other student", stranger, ActionRead, StudentReport("std-1"), false, false},
+		{"report/other lecturer", otherLec, ActionRead, StudentReport("std-1"), false, false},
+
+		// aksi yang tidak terdaftar selalu ditolak
+		{"report/update unknown", admin, ActionUpdate, StudentReport("std-1"), false, false},
+	}
+
+	engine := newTestEngine()
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			decision, err := engine.Authorize(context.Background(), tc.subject, tc.action, tc.resource)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.allowed, decision.Allowed)
+			assert.Equal(t, tc.stateDeny, decision.StateViolation)
+			if !tc.allowed {
+				assert.NotEmpty(t, decision.Reason)
+			}
+		})
+	}
+}
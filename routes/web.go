@@ -2,9 +2,14 @@ package routes
 
 import (
 	"database/sql"
+	"os"
+	"strings"
+	"time"
 	"uas/app/repository"
 	"uas/app/services"
+	"uas/helpers"
 	"uas/middleware"
+	"uas/utils"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/swagger"
@@ -19,66 +24,200 @@ func SetupRoutes(app *fiber.App, postgreSQL *sql.DB, mongoDB *mongo.Database) {
 	lecturerRepo := repository.NewLecturerRepository(postgreSQL)
 	achRepo := repository.NewAchievementRepository(postgreSQL, mongoDB)
 	reportRepo := repository.NewReportRepository(postgreSQL)
+	mfaRepo := repository.NewMFARepository(postgreSQL)
+	permissionRepo := repository.NewPermissionRepository(postgreSQL)
+	roleRepo := repository.NewRoleRepository(postgreSQL)
+	auditRepo := repository.NewAuditRepository(postgreSQL)
+	apiKeyRepo := repository.NewAPIKeyRepository(postgreSQL)
+	sessionRepo := repository.NewSessionRepository(postgreSQL)
+	profileRepo := repository.NewProfileRepository(postgreSQL)
+	academicUnitRepo := repository.NewAcademicUnitRepository(postgreSQL)
+	academicPeriodRepo := repository.NewAcademicPeriodRepository(postgreSQL)
+
+	// Cache permission per role (TTL dari env PERMISSION_CACHE_TTL, default 5 menit)
+	permissionTTL, err := time.ParseDuration(os.Getenv("PERMISSION_CACHE_TTL"))
+	if err != nil {
+		permissionTTL = 5 * time.Minute
+	}
+	permissionResolver := helpers.NewPermissionResolver(permissionRepo, permissionTTL)
+
+	// Status sesi login di-cache sebentar (env SESSION_CHECK_TTL, default 30 detik): access token dari sesi
+	// yang dicabut ditolak paling lambat setelah TTL ini
+	sessionTTL, err := time.ParseDuration(os.Getenv("SESSION_CHECK_TTL"))
+	if err != nil {
+		sessionTTL = 30 * time.Second
+	}
+	sessionChecker := helpers.NewSessionChecker(sessionRepo, sessionTTL)
+
+	// Storage file upload (lampiran prestasi dan foto profil), disajikan sebagai file statis di /uploads
+	storage := utils.NewStorageFromEnv()
+	app.Static(storage.URLPrefix, storage.Dir)
 
 	// Insialisasi Service
-	authService := services.NewAuthService(userRepo)
-	userService := services.NewUserService(postgreSQL, userRepo, studentRepo, lecturerRepo)
-	studentService := services.NewStudentService(studentRepo)
-	lecturerService := services.NewLecturerService(lecturerRepo)
-	achService := services.NewAchievementService(achRepo)
+	authService := services.NewAuthService(userRepo, mfaRepo, permissionResolver, auditRepo, sessionRepo)
+	userService := services.NewUserService(postgreSQL, userRepo, studentRepo, lecturerRepo, roleRepo, permissionResolver)
+	studentService := services.NewStudentService(postgreSQL, studentRepo, lecturerRepo, achRepo)
+	lecturerService := services.NewLecturerService(lecturerRepo, achRepo)
+	achService := services.NewAchievementService(achRepo, storage)
 	reportService := services.NewReportService(reportRepo, achRepo)
+	roleService := services.NewRoleService(roleRepo, permissionResolver)
+	apiKeyService := services.NewAPIKeyService(apiKeyRepo, roleRepo, permissionResolver)
+	userPurgeService := services.NewUserPurgeService(postgreSQL, userRepo, achRepo, auditRepo, storage)
+	profileService := services.NewProfileService(postgreSQL, profileRepo, userRepo, auditRepo, utils.NewMailerFromEnv(), storage)
+	academicUnitService := services.NewAcademicUnitService(academicUnitRepo)
+	academicPeriodService := services.NewAcademicPeriodService(academicPeriodRepo)
+
+	// JWKS (Public) untuk service lain yang memverifikasi token kita
+	app.Get("/.well-known/jwks.json", authService.GetJWKS)
 
 	// Definisi Route
 	api := app.Group("/api/v1")
 
+	// Semua request dengan token impersonation dicatat ke audit_logs
+	api.Use(middleware.AuditImpersonation(auditRepo))
+	api.Use(middleware.AuditAPIKey(auditRepo))
+
 	// Auth Routes (Public)
 	auth := api.Group("/auth")
 	auth.Post("/login", authService.Login)
 	auth.Post("/refresh", authService.Refresh)
-	auth.Get("/profile", middleware.AuthRequired(), authService.GetProfile)
-
-	// Protected Routes (Perlu Login)
-	protected := api.Group("", middleware.AuthRequired())
+	auth.Get("/profile", middleware.AuthRequired(nil, sessionChecker), profileService.GetProfile)
+	auth.Put("/profile", middleware.AuthRequired(nil, sessionChecker), profileService.UpdateProfile)
+	auth.Post("/profile/email/confirm", profileService.ConfirmEmailChange)
+	auth.Post("/profile/photo", middleware.AuthRequired(nil, sessionChecker), profileService.UploadPhoto)
+	auth.Delete("/profile/photo", middleware.AuthRequired(nil, sessionChecker), profileService.DeletePhoto)
+	auth.Get("/sessions", middleware.AuthRequired(nil, sessionChecker), authService.GetSessions)
+	auth.Delete("/sessions/:id", middleware.AuthRequired(nil, sessionChecker), authService.RevokeSession)
+
+	// MFA (TOTP)
+	auth.Post("/mfa/enroll", authService.EnrollMFA)
+	auth.Post("/mfa/verify", authService.VerifyMFA)
+	auth.Post("/mfa/setup", middleware.AuthRequired(nil, sessionChecker), authService.SetupMFA)
+	auth.Post("/mfa/activate", middleware.AuthRequired(nil, sessionChecker), authService.ActivateMFA)
+	auth.Post("/mfa/disable", middleware.AuthRequired(nil, sessionChecker), authService.DisableMFA)
+	auth.Post("/mfa/recovery-codes", middleware.AuthRequired(nil, sessionChecker), authService.RegenerateRecoveryCodes)
+
+	// SSO OpenID Connect (aktif jika OIDC_ISSUER diset)
+	if issuer := os.Getenv("OIDC_ISSUER"); issuer != "" {
+		oidcProvider := utils.NewOIDCProvider(utils.OIDCConfig{
+			Issuer:       issuer,
+			ClientID:     os.Getenv("OIDC_CLIENT_ID"),
+			ClientSecret: os.Getenv("OIDC_CLIENT_SECRET"),
+			RedirectURL:  os.Getenv("OIDC_REDIRECT_URL"),
+			Scopes:       strings.Fields(os.Getenv("OIDC_SCOPES")),
+		})
+		oidcService := services.NewOIDCService(postgreSQL, oidcProvider, userRepo, studentRepo, lecturerRepo, roleRepo, sessionRepo, mfaRepo, permissionResolver, services.OIDCOptionsFromEnv())
+		auth.Get("/oidc/login", oidcService.Login)
+		auth.Get("/oidc/callback", oidcService.Callback)
+	}
+
+	// Protected Routes (Perlu Login atau API key)
+	protected := api.Group("", middleware.AuthRequired(apiKeyRepo, sessionChecker))
 
 	// Users (Admin)
-	protected.Post("/users", middleware.RequirePermission("users:create"), userService.CreateUser)
-	protected.Get("/users", middleware.RequirePermission("users:read"), userService.GetAllUsers)
-	protected.Get("/users/:id", middleware.RequirePermission("users:read"), userService.GetUserByID)
-	protected.Put("/users/:id", middleware.RequirePermission("users:update"), userService.UpdateUser)
-	protected.Delete("/users/:id", middleware.RequirePermission("users:delete"), userService.DeleteUser)
-	protected.Put("/users/:id/role", userService.UpdateUserRole)
+	protected.Post("/users", middleware.RequirePermission(permissionResolver, "users:create"), userService.CreateUser)
+	protected.Post("/users/import", middleware.RequirePermission(permissionResolver, "users:create"), userService.ImportUsers)
+	protected.Get("/users", middleware.RequirePermission(permissionResolver, "users:read"), userService.GetAllUsers)
+	protected.Get("/users/:id", middleware.RequirePermission(permissionResolver, "users:read"), userService.GetUserByID)
+	protected.Put("/users/:id", middleware.RequirePermission(permissionResolver, "users:update"), userService.UpdateUser)
+	protected.Delete("/users/:id", middleware.RequirePermission(permissionResolver, "users:delete"), userService.DeleteUser)
+	protected.Post("/users/:id/restore", middleware.RequirePermission(permissionResolver, "users:delete"), userService.RestoreUser)
+	protected.Delete("/users/:id/purge", middleware.RequirePermission(permissionResolver, "users:delete"), userPurgeService.PurgeUser)
+	protected.Post("/users/:id/impersonate", middleware.RequirePermission(permissionResolver, "users:impersonate"), authService.Impersonate)
+	protected.Put("/users/:id/role", middleware.RequirePermission(permissionResolver, "users:assign_role"), userService.UpdateUserRole)
+	protected.Get("/users/:id/sessions", middleware.RequirePermission(permissionResolver, "sessions:manage"), authService.GetUserSessions)
+	protected.Delete("/users/:id/sessions/:sessionId", middleware.RequirePermission(permissionResolver, "sessions:manage"), authService.RevokeUserSession)
+
+	// Roles & Permissions (Admin)
+	protected.Get("/roles", middleware.RequirePermission(permissionResolver, "roles:read"), roleService.GetRoles)
+	protected.Get("/roles/matrix", middleware.RequirePermission(permissionResolver, "roles:read"), roleService.GetPermissionMatrix)
+	protected.Get("/roles/:id", middleware.RequirePermission(permissionResolver, "roles:read"), roleService.GetRoleByID)
+	protected.Post("/roles", middleware.RequirePermission(permissionResolver, "roles:manage"), roleService.CreateRole)
+	protected.Put("/roles/:id", middleware.RequirePermission(permissionResolver, "roles:manage"), roleService.UpdateRole)
+	protected.Delete("/roles/:id", middleware.RequirePermission(permissionResolver, "roles:manage"), roleService.DeleteRole)
+	protected.Put("/roles/:id/permissions", middleware.RequirePermission(permissionResolver, "roles:manage"), roleService.SetRolePermissions)
+	protected.Post("/roles/:id/permissions/:permissionId", middleware.RequirePermission(permissionResolver, "roles:manage"), roleService.GrantPermission)
+	protected.Delete("/roles/:id/permissions/:permissionId", middleware.RequirePermission(permissionResolver, "roles:manage"), roleService.RevokePermission)
+	protected.Get("/permissions", middleware.RequirePermission(permissionResolver, "roles:read"), roleService.GetPermissions)
+	protected.Post("/permissions", middleware.RequirePermission(permissionResolver, "roles:manage"), roleService.CreatePermission)
+	protected.Put("/permissions/:id", middleware.RequirePermission(permissionResolver, "roles:manage"), roleService.UpdatePermission)
+
+	// API Keys (Admin)
+	protected.Post("/api-keys", middleware.RequirePermission(permissionResolver, "api_keys:manage"), apiKeyService.CreateAPIKey)
+	protected.Get("/api-keys", middleware.RequirePermission(permissionResolver, "api_keys:manage"), apiKeyService.GetAPIKeys)
+	protected.Delete("/api-keys/:id", middleware.RequirePermission(permissionResolver, "api_keys:manage"), apiKeyService.RevokeAPIKey)
 
 	// Students (Admin)
-	protected.Get("/students", middleware.RequirePermission("students:read"), studentService.GetStudents)
-	protected.Get("/students/:id", middleware.RequirePermission("students:read"), studentService.GetStudentByID)
-	protected.Put("/students/:id/advisor", middleware.RequirePermission("students:update"), studentService.UpdateStudentAdvisor)
+	protected.Get("/students", middleware.RequirePermission(permissionResolver, "students:read"), studentService.GetStudents)
+	protected.Post("/students/auto-assign-advisors", middleware.RequirePermission(permissionResolver, "students:update"), studentService.AutoAssignAdvisors)
+	protected.Get("/students/me", studentService.GetMyStudentProfile)
+	protected.Get("/students/me/summary", studentService.GetMyStudentSummary)
+	protected.Get("/students/:id", middleware.RequirePermission(permissionResolver, "students:read"), studentService.GetStudentByID)
+	protected.Put("/students/:id/advisor", middleware.RequirePermission(permissionResolver, "students:update"), studentService.UpdateStudentAdvisor)
+	protected.Get("/students/:id/advisor-history", middleware.RequirePermission(permissionResolver, "students:read"), studentService.GetAdvisorHistory)
+	protected.Post("/students/:id/status", middleware.RequirePermission(permissionResolver, "students:update"), studentService.ChangeAcademicStatus)
+	protected.Get("/students/:id/status-history", middleware.RequirePermission(permissionResolver, "students:read"), studentService.GetAcademicStatusHistory)
 
 	// Lectures (Admin)
-	protected.Get("/lecturers", middleware.RequirePermission("lecturers:read"), lecturerService.GetLecturers)
-	protected.Get("/lecturers/:id/advisees", middleware.RequirePermission("lecturers:read"), lecturerService.GetLecturerAdvisees)
+	protected.Get("/lecturers", middleware.RequirePermission(permissionResolver, "lecturers:read"), lecturerService.GetLecturers)
+	protected.Get("/lecturers/load", middleware.RequirePermission(permissionResolver, "lecturers:read"), lecturerService.GetAdvisorLoadReport)
+	// Dosen Wali yang login (404 jika user bukan dosen); didaftarkan sebelum /lecturers/:id
+	protected.Get("/lecturers/me", lecturerService.GetMyLecturerProfile)
+	protected.Get("/lecturers/me/advisees", lecturerService.GetMyAdvisees)
+	protected.Get("/lecturers/:id", middleware.RequirePermission(permissionResolver, "lecturers:read"), lecturerService.GetLecturerByID)
+	protected.Put("/lecturers/:id/capacity", middleware.RequirePermission(permissionResolver, "lecturers:update"), lecturerService.UpdateLecturerCapacity)
+	protected.Get("/lecturers/:id/advisees", middleware.RequirePermission(permissionResolver, "lecturers:read"), lecturerService.GetLecturerAdvisees)
+	protected.Post("/lecturers/:id/advisees/reassign", middleware.RequirePermission(permissionResolver, "students:update"), studentService.ReassignAdvisees)
+
+	// Master Data Unit Akademik (Admin)
+	protected.Get("/faculties", middleware.RequirePermission(permissionResolver, "academic_units:read"), academicUnitService.GetFaculties)
+	protected.Get("/faculties/:id", middleware.RequirePermission(permissionResolver, "academic_units:read"), academicUnitService.GetFacultyByID)
+	protected.Post("/faculties", middleware.RequirePermission(permissionResolver, "academic_units:manage"), academicUnitService.CreateFaculty)
+	protected.Put("/faculties/:id", middleware.RequirePermission(permissionResolver, "academic_units:manage"), academicUnitService.UpdateFaculty)
+	protected.Delete("/faculties/:id", middleware.RequirePermission(permissionResolver, "academic_units:manage"), academicUnitService.DeleteFaculty)
+	protected.Get("/departments", middleware.RequirePermission(permissionResolver, "academic_units:read"), academicUnitService.GetDepartments)
+	protected.Get("/departments/:id", middleware.RequirePermission(permissionResolver, "academic_units:read"), academicUnitService.GetDepartmentByID)
+	protected.Post("/departments", middleware.RequirePermission(permissionResolver, "academic_units:manage"), academicUnitService.CreateDepartment)
+	protected.Put("/departments/:id", middleware.RequirePermission(permissionResolver, "academic_units:manage"), academicUnitService.UpdateDepartment)
+	protected.Delete("/departments/:id", middleware.RequirePermission(permissionResolver, "academic_units:manage"), academicUnitService.DeleteDepartment)
+	protected.Get("/program-studies", middleware.RequirePermission(permissionResolver, "academic_units:read"), academicUnitService.GetProgramStudies)
+	protected.Get("/program-studies/:id", middleware.RequirePermission(permissionResolver, "academic_units:read"), academicUnitService.GetProgramStudyByID)
+	protected.Post("/program-studies", middleware.RequirePermission(permissionResolver, "academic_units:manage"), academicUnitService.CreateProgramStudy)
+	protected.Put("/program-studies/:id", middleware.RequirePermission(permissionResolver, "academic_units:manage"), academicUnitService.UpdateProgramStudy)
+	protected.Delete("/program-studies/:id", middleware.RequirePermission(permissionResolver, "academic_units:manage"), academicUnitService.DeleteProgramStudy)
+
+	// Periode Akademik (baca: semua role, kelola: Admin)
+	protected.Get("/academic-periods", middleware.RequirePermission(permissionResolver, "academic_periods:read"), academicPeriodService.GetAcademicPeriods)
+	protected.Get("/academic-periods/:id", middleware.RequirePermission(permissionResolver, "academic_periods:read"), academicPeriodService.GetAcademicPeriodByID)
+	protected.Post("/academic-periods", middleware.RequirePermission(permissionResolver, "academic_periods:manage"), academicPeriodService.CreateAcademicPeriod)
+	protected.Put("/academic-periods/:id", middleware.RequirePermission(permissionResolver, "academic_periods:manage"), academicPeriodService.UpdateAcademicPeriod)
+	protected.Delete("/academic-periods/:id", middleware.RequirePermission(permissionResolver, "academic_periods:manage"), academicPeriodService.DeleteAcademicPeriod)
+	protected.Post("/academic-periods/:id/activate", middleware.RequirePermission(permissionResolver, "academic_periods:manage"), academicPeriodService.ActivateAcademicPeriod)
+	protected.Post("/academic-periods/:id/close", middleware.RequirePermission(permissionResolver, "academic_periods:manage"), academicPeriodService.CloseAcademicPeriod)
+	protected.Post("/academic-periods/:id/reopen", middleware.RequirePermission(permissionResolver, "academic_periods:manage"), academicPeriodService.ReopenAcademicPeriod)
 
 	// Achievements (Mahasiswa)
-	protected.Post("/achievements", middleware.RequirePermission("achievements:create"), achService.CreateAchievement)
-	protected.Put("/achievements/:id", middleware.RequirePermission("achievements:update"), achService.UpdateAchievement)
-	protected.Delete("/achievements/:id", middleware.RequirePermission("achievements:delete"), achService.DeleteAchievement)
-	protected.Post("/achievements/:id/submit", middleware.RequirePermission("achievements:update"), achService.SubmitAchievement)
-	protected.Post("/achievements/:id/attachments", middleware.RequirePermission("achievements:update"), achService.UploadAttachment)
+	protected.Post("/achievements", middleware.RequirePermission(permissionResolver, "achievements:create"), achService.CreateAchievement)
+	protected.Put("/achievements/:id", middleware.RequirePermission(permissionResolver, "achievements:update"), achService.UpdateAchievement)
+	protected.Delete("/achievements/:id", middleware.RequirePermission(permissionResolver, "achievements:delete"), achService.DeleteAchievement)
+	protected.Post("/achievements/:id/submit", middleware.RequirePermission(permissionResolver, "achievements:update"), achService.SubmitAchievement)
+	protected.Post("/achievements/:id/attachments", middleware.RequirePermission(permissionResolver, "achievements:update"), achService.UploadAttachment)
 
 	// Achievements (Dosen Wali)
-	protected.Post("/achievements/:id/verify", middleware.RequirePermission("achievements:verify"), achService.VerifyAchievement)
-	protected.Post("/achievements/:id/reject", middleware.RequirePermission("achievements:reject"), achService.RejectAchievement)
+	protected.Post("/achievements/:id/verify", middleware.RequirePermission(permissionResolver, "achievements:verify"), achService.VerifyAchievement)
+	protected.Post("/achievements/:id/reject", middleware.RequirePermission(permissionResolver, "achievements:reject"), achService.RejectAchievement)
 
 	// Achievements (Admin)
-	protected.Get("/achievements/:id", middleware.RequirePermission("achievements:read"), achService.GetAchievementDetail)
-	protected.Get("/achievements/:id/history", middleware.RequirePermission("achievements:read"), achService.GetAchievementHistory)
+	protected.Get("/achievements/:id", middleware.RequirePermission(permissionResolver, "achievements:read"), achService.GetAchievementDetail)
+	protected.Get("/achievements/:id/history", middleware.RequirePermission(permissionResolver, "achievements:read"), achService.GetAchievementHistory)
 	
 	// Achievements (All Role)
-	protected.Get("/achievements", middleware.RequirePermission("achievements:read"), achService.GetAllAchievements)
+	protected.Get("/achievements", middleware.RequirePermission(permissionResolver, "achievements:read"), achService.GetAllAchievements)
 
 	// Reports & Analitycs 
 	reports := protected.Group("/reports")
-	reports.Get("/statistics", middleware.RequirePermission("reports:read"), reportService.GetSystemStatistics)
-	reports.Get("/student/:id", middleware.RequirePermission("reports:read"), reportService.GetStudentReport)
+	reports.Get("/statistics", middleware.RequirePermission(permissionResolver, "reports:read"), reportService.GetSystemStatistics)
+	reports.Get("/student/:id", middleware.RequirePermission(permissionResolver, "reports:read"), reportService.GetStudentReport)
 
 	app.Get("/swagger/*", swagger.HandlerDefault)
 }
\ No newline at end of file
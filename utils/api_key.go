@@ -0,0 +1,57 @@
+package utils
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"net"
+	"strings"
+)
+
+// APIKeyPrefix menandai string sebagai API key aplikasi ini (memudahkan secret scanning)
+const APIKeyPrefix = "uas_"
+
+// GenerateAPIKey membuat API key acak. prefix (12 karakter pertama) disimpan apa adanya untuk identifikasi di UI
+func GenerateAPIKey() (key string, prefix string, err error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", err
+	}
+
+	key = APIKeyPrefix + base64.RawURLEncoding.EncodeToString(buf)
+	return key, key[:12], nil
+}
+
+// IPAllowed mengecek ip terhadap daftar IP/CIDR. Daftar kosong berarti semua IP diizinkan
+func IPAllowed(ip string, allowlist []string) bool {
+	if len(allowlist) == 0 {
+		return true
+	}
+
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return false
+	}
+
+	for _, entry := range allowlist {
+		entry = strings.TrimSpace(entry)
+		if strings.Contains(entry, "/") {
+			if _, network, err := net.ParseCIDR(entry); err == nil && network.Contains(parsed) {
+				return true
+			}
+			continue
+		}
+		if allowed := net.ParseIP(entry); allowed != nil && allowed.Equal(parsed) {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidIPOrCIDR dipakai saat membuat API key untuk menolak entri allowlist yang salah ketik
+func ValidIPOrCIDR(entry string) bool {
+	if strings.Contains(entry, "/") {
+		_, _, err := net.ParseCIDR(entry)
+		return err == nil
+	}
+	return net.ParseIP(entry) != nil
+}
@@ -0,0 +1,231 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+
+	// Registrasi decoder GIF dan PNG untuk image.Decode
+	_ "image/gif"
+	_ "image/png"
+)
+
+const (
+	MaxPhotoBytes    = 5 << 20 // 5 MB
+	MinPhotoSide     = 128
+	maxPhotoPixels   = 40_000_000 // cegah decompression bomb
+	photoJPEGQuality = 85
+)
+
+// PhotoSizes adalah ukuran sisi (px) foto profil persegi yang dihasilkan, dari yang terbesar
+var PhotoSizes = []int{512, 256, 128, 64}
+
+var (
+	ErrUnsupportedImage = errors.New("format foto harus JPEG, PNG, atau GIF")
+	ErrImageTooSmall    = errors.New("foto minimal 128x128 piksel")
+	ErrImageTooLarge    = errors.New("resolusi foto terlalu besar")
+)
+
+// ProcessPhoto memvalidasi foto, menerapkan orientasi EXIF, memotong persegi di tengah, lalu
+// menghasilkan JPEG untuk setiap ukuran. Karena gambar di-encode ulang, seluruh metadata EXIF
+// (lokasi GPS, kamera, dll.) ikut terbuang. Ukuran yang lebih besar dari foto asli tidak diperbesar
+func ProcessPhoto(data []byte, sizes []int) (map[int][]byte, error) {
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil || (format != "jpeg" && format != "png" && format != "gif") {
+		return nil, ErrUnsupportedImage
+	}
+	if cfg.Width < MinPhotoSide || cfg.Height < MinPhotoSide {
+		return nil, ErrImageTooSmall
+	}
+	if cfg.Width*cfg.Height > maxPhotoPixels {
+		return nil, ErrImageTooLarge
+	}
+
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrUnsupportedImage
+	}
+
+	square := cropSquare(src)
+	if format == "jpeg" {
+		square = applyOrientation(square, jpegOrientation(data))
+	}
+
+	out := make(map[int][]byte, len(sizes))
+	for _, size := range sizes {
+		side := size
+		if side > square.Rect.Dx() {
+			side = square.Rect.Dx()
+		}
+
+		var buf bytes.Buffer
+		if err := jpeg.Encode(&buf, resizeArea(square, side), &jpeg.Options{Quality: photoJPEGQuality}); err != nil {
+			return nil, err
+		}
+		out[size] = buf.Bytes()
+	}
+	return out, nil
+}
+
+// cropSquare memotong persegi terbesar di tengah gambar dan meratakan transparansi ke latar putih
+func cropSquare(src image.Image) *image.RGBA {
+	b := src.Bounds()
+	side := b.Dx()
+	if b.Dy() < side {
+		side = b.Dy()
+	}
+	offset := image.Pt(b.Min.X+(b.Dx()-side)/2, b.Min.Y+(b.Dy()-side)/2)
+
+	dst := image.NewRGBA(image.Rect(0, 0, side, side))
+	draw.Draw(dst, dst.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.Draw(dst, dst.Bounds(), src, offset, draw.Over)
+	return dst
+}
+
+// applyOrientation memutar/membalik gambar persegi sesuai tag Orientation EXIF (1-8)
+func applyOrientation(src *image.RGBA, orientation int) *image.RGBA {
+	if orientation < 2 || orientation > 8 {
+		return src
+	}
+
+	n := src.Rect.Dx()
+	dst := image.NewRGBA(src.Rect)
+	for y := 0; y < n; y++ {
+		for x := 0; x < n; x++ {
+			var sx, sy int
+			switch orientation {
+			case 2: // cermin horizontal
+				sx, sy = n-1-x, y
+			case 3: // putar 180
+				sx, sy = n-1-x, n-1-y
+			case 4: // cermin vertikal
+				sx, sy = x, n-1-y
+			case 5: // transpose
+				sx, sy = y, x
+			case 6: // putar 90 searah jarum jam
+				sx, sy = y, n-1-x
+			case 7: // transverse
+				sx, sy = n-1-y, n-1-x
+			case 8: // putar 90 berlawanan jarum jam
+				sx, sy = n-1-y, x
+			}
+			copy(dst.Pix[dst.PixOffset(x, y):dst.PixOffset(x, y)+4], src.Pix[src.PixOffset(sx, sy):src.PixOffset(sx, sy)+4])
+		}
+	}
+	return dst
+}
+
+// resizeArea mengecilkan gambar persegi dengan rata-rata area (box filter) sehingga tetap halus tanpa aliasing
+func resizeArea(src *image.RGBA, side int) *image.RGBA {
+	n := src.Rect.Dx()
+	if side == n {
+		return src
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, side, side))
+	for y := 0; y < side; y++ {
+		y0, y1 := y*n/side, (y+1)*n/side
+		if y1 == y0 {
+			y1 = y0 + 1
+		}
+		for x := 0; x < side; x++ {
+			x0, x1 := x*n/side, (x+1)*n/side
+			if x1 == x0 {
+				x1 = x0 + 1
+			}
+
+			var r, g, b, a, count int
+			for sy := y0; sy < y1; sy++ {
+				i := src.PixOffset(x0, sy)
+				for sx := x0; sx < x1; sx++ {
+					r += int(src.Pix[i])
+					g += int(src.Pix[i+1])
+					b += int(src.Pix[i+2])
+					a += int(src.Pix[i+3])
+					i += 4
+					count++
+				}
+			}
+
+			j := dst.PixOffset(x, y)
+			dst.Pix[j] = uint8(r / count)
+			dst.Pix[j+1] = uint8(g / count)
+			dst.Pix[j+2] = uint8(b / count)
+			dst.Pix[j+3] = uint8(a / count)
+		}
+	}
+	return dst
+}
+
+// jpegOrientation membaca tag Orientation (0x0112) dari segmen APP1 Exif. Mengembalikan 1 jika tidak ada
+func jpegOrientation(data []byte) int {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return 1
+	}
+
+	for i := 2; i+4 <= len(data); {
+		if data[i] != 0xFF {
+			return 1
+		}
+		marker := data[i+1]
+		if marker == 0xD8 || (marker >= 0xD0 && marker <= 0xD7) || marker == 0x01 || marker == 0xFF {
+			i++
+			continue
+		}
+		if marker == 0xDA || marker == 0xD9 { // awal data gambar, tidak ada EXIF lagi
+			return 1
+		}
+
+		length := int(binary.BigEndian.Uint16(data[i+2 : i+4]))
+		if length < 2 || i+2+length > len(data) {
+			return 1
+		}
+		segment := data[i+4 : i+2+length]
+		if marker == 0xE1 && len(segment) > 6 && string(segment[:6]) == "Exif\x00\x00" {
+			return exifOrientation(segment[6:])
+		}
+		i += 2 + length
+	}
+	return 1
+}
+
+// exifOrientation mencari tag Orientation di IFD0 header TIFF
+func exifOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 1
+	}
+
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+	if order.Uint16(tiff[2:4]) != 42 {
+		return 1
+	}
+
+	ifd := int(order.Uint32(tiff[4:8]))
+	if ifd < 8 || ifd+2 > len(tiff) {
+		return 1
+	}
+
+	entries := int(order.Uint16(tiff[ifd : ifd+2]))
+	for e := 0; e < entries; e++ {
+		off := ifd + 2 + e*12
+		if off+12 > len(tiff) {
+			return 1
+		}
+		if order.Uint16(tiff[off:off+2]) == 0x0112 && order.Uint16(tiff[off+2:off+4]) == 3 {
+			return int(order.Uint16(tiff[off+8 : off+10]))
+		}
+	}
+	return 1
+}
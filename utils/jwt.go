@@ -6,49 +6,156 @@ import (
 	"uas/app/models"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 )
 
-var JwtSecret = []byte(os.Getenv("JWT_SECRET"))
+// MFATokenTTL adalah masa berlaku token challenge MFA
+const MFATokenTTL = 5 * time.Minute
 
-func GenerateToken(user models.User) (string, error) {
+// RefreshTokenTTL adalah masa berlaku refresh token, sekaligus umur maksimal sesi login
+const RefreshTokenTTL = 7 * 24 * time.Hour
+
+// ImpersonationTokenTTL adalah masa berlaku default token impersonation (bisa diganti lewat IMPERSONATION_TOKEN_TTL)
+const ImpersonationTokenTTL = 10 * time.Minute
+
+// TokenTypeAccess adalah claim type untuk access token. Hanya token dengan type ini yang diterima ValidateToken;
+// refresh token, token challenge MFA, dan token alur SSO ditandatangani dengan kunci yang sama tetapi type-nya berbeda
+const TokenTypeAccess = "access"
+
+// EmbeddedPermissionsTTL adalah lama permission yang ditanam di access token dipercaya middleware
+// (env JWT_EMBED_PERMISSIONS_TTL, default 1 menit). Setelah itu permission dicek lewat resolver,
+// sehingga user yang diturunkan tidak memakai permission lamanya sepanjang umur token
+func EmbeddedPermissionsTTL() time.Duration {
+	if ttl, err := time.ParseDuration(os.Getenv("JWT_EMBED_PERMISSIONS_TTL")); err == nil && ttl > 0 {
+		return ttl
+	}
+	return time.Minute
+}
+
+// GenerateToken membuat access token. permissions boleh nil; jika diisi, middleware
+// memakai daftar ini langsung tanpa lookup ke resolver.
+func GenerateToken(user models.User, permissions []string) (string, error) {
+	return generateAccessToken(user, permissions, nil)
+}
+
+// GenerateSessionToken membuat access token yang terikat ke sesi login (claim sid)
+func GenerateSessionToken(user models.User, permissions []string, sessionID uuid.UUID) (string, error) {
+	return generateAccessToken(user, permissions, &sessionID)
+}
+
+func generateAccessToken(user models.User, permissions []string, sessionID *uuid.UUID) (string, error) {
 	claims := models.JWTClaims{
+		PermissionsExpiresAt: permissionsExpiry(permissions),
 		UserID: user.ID,
 		Username: user.Username,
 		RoleName: user.RoleName,
+		Permissions: permissions,
+		SessionID: sessionID,
+		Language: user.Language,
+		TokenType: TokenTypeAccess,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(15 * time.Minute)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(JwtSecret)
+	return signToken(claims)
+}
+
+// GenerateImpersonationToken membuat access token atas nama target yang ditandai dengan ID admin aslinya.
+// Tidak ada refresh token untuk impersonation, jadi sesi otomatis berakhir setelah ttl.
+func GenerateImpersonationToken(target models.User, impersonatorID uuid.UUID, permissions []string, ttl time.Duration) (string, error) {
+	claims := models.JWTClaims{
+		UserID: target.ID,
+		Username: target.Username,
+		RoleName: target.RoleName,
+		Permissions: permissions,
+		PermissionsExpiresAt: permissionsExpiry(permissions),
+		ImpersonatorID: &impersonatorID,
+		TokenType: TokenTypeAccess,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(ttl)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+		},
+	}
+
+	return signToken(claims)
 }
 
-func GenerateRefreshToken(user models.User) (string, error) {
+func permissionsExpiry(permissions []string) *jwt.NumericDate {
+	if permissions == nil {
+		return nil
+	}
+	return jwt.NewNumericDate(time.Now().Add(EmbeddedPermissionsTTL()))
+}
+
+// GenerateRefreshToken membuat refresh token untuk sesi login sessionID (disimpan di claim sid)
+func GenerateRefreshToken(user models.User, sessionID uuid.UUID) (string, error) {
     claims := jwt.MapClaims{
         "userId": user.ID,
         "role":   user.RoleName,
-        "exp":    time.Now().Add(7 * 24 * time.Hour).Unix(),
+        "sid":    sessionID,
+        "exp":    time.Now().Add(RefreshTokenTTL).Unix(),
         "type":   "refresh",
     }
 
-    token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-    return token.SignedString(JwtSecret)
+    return signToken(claims)
 }
 
+// ValidateToken memvalidasi access token. Token lain yang ditandatangani kunci yang sama (refresh, MFA, SSO) ditolak
 func ValidateToken(tokenString string) (*models.JWTClaims, error) { 
-    token, err := jwt.ParseWithClaims(tokenString, &models.JWTClaims{},func(token *jwt.Token) (interface {}, error) { 
-        return JwtSecret, nil 
-    }) 
+    token, err := jwt.ParseWithClaims(tokenString, &models.JWTClaims{}, keyFunc) 
  
     if err != nil { 
         return nil, err 
     } 
  
     if claims, ok := token.Claims.(*models.JWTClaims); ok && token.Valid { 
+        if claims.TokenType != TokenTypeAccess {
+            return nil, jwt.ErrTokenInvalidClaims
+        }
         return claims, nil 
     } 
  
     return nil, jwt.ErrInvalidKey 
-}
\ No newline at end of file
+}
+
+// ParseToken memvalidasi tanda tangan token (refresh/MFA) dan mengembalikan claims dalam bentuk map
+func ParseToken(tokenString string) (*jwt.Token, error) {
+	return jwt.Parse(tokenString, keyFunc)
+}
+
+// GenerateMFAToken membuat token challenge berumur pendek untuk tahap kedua login.
+// purpose berisi "login" (verifikasi kode) atau "enroll" (wajib daftar MFA dulu).
+func GenerateMFAToken(user models.User, purpose string) (string, error) {
+	claims := jwt.MapClaims{
+		"userId":  user.ID,
+		"purpose": purpose,
+		"exp":     time.Now().Add(MFATokenTTL).Unix(),
+		"type":    "mfa",
+	}
+
+	return signToken(claims)
+}
+
+// ValidateMFAToken memvalidasi token challenge MFA dan mengembalikan user ID serta purpose-nya
+func ValidateMFAToken(tokenString string) (uuid.UUID, string, error) {
+	token, err := ParseToken(tokenString)
+	if err != nil || !token.Valid {
+		return uuid.Nil, "", jwt.ErrTokenInvalidClaims
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || claims["type"] != "mfa" {
+		return uuid.Nil, "", jwt.ErrTokenInvalidClaims
+	}
+
+	userIDStr, _ := claims["userId"].(string)
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		return uuid.Nil, "", jwt.ErrTokenInvalidClaims
+	}
+
+	purpose, _ := claims["purpose"].(string)
+	return userID, purpose, nil
+}
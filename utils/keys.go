@@ -0,0 +1,253 @@
+package utils
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// signingKey adalah satu kunci JWT. Kunci tanpa private key hanya dipakai untuk verifikasi
+// (misalnya kunci lama yang sudah dirotasi tapi token-nya belum expired).
+type signingKey struct {
+	kid     string
+	method  jwt.SigningMethod
+	private crypto.Signer
+	public  crypto.PublicKey
+}
+
+type keyring struct {
+	active *signingKey
+	keys   map[string]*signingKey
+}
+
+var (
+	keysMu      sync.RWMutex
+	currentKeys *keyring
+)
+
+// InitSigningKeys memuat kunci dari direktori (file *.pem, nama file = kid).
+// activeKID menentukan kunci penandatangan; jika kosong dipakai kunci private dengan nama terakhir (urut abjad).
+// Jika dir kosong, dibuat kunci Ed25519 sementara yang hilang saat server restart.
+func InitSigningKeys(dir string, activeKID string) error {
+	var ring *keyring
+	var err error
+
+	if dir == "" {
+		log.Println("PERINGATAN: JWT_KEYS_DIR tidak diset, memakai kunci JWT sementara (token invalid setelah restart)")
+		ring, err = ephemeralKeyring()
+	} else {
+		ring, err = loadKeyring(dir, activeKID)
+	}
+	if err != nil {
+		return err
+	}
+
+	keysMu.Lock()
+	currentKeys = ring
+	keysMu.Unlock()
+	return nil
+}
+
+// StartKeyRotation memuat ulang direktori kunci secara berkala agar kunci baru/pensiun terbaca tanpa restart
+func StartKeyRotation(dir string, activeKID string, interval time.Duration) {
+	if dir == "" || interval <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			ring, err := loadKeyring(dir, activeKID)
+			if err != nil {
+				log.Println("Gagal memuat ulang kunci JWT:", err)
+				continue
+			}
+
+			keysMu.Lock()
+			currentKeys = ring
+			keysMu.Unlock()
+		}
+	}()
+}
+
+func getKeyring() *keyring {
+	keysMu.RLock()
+	ring := currentKeys
+	keysMu.RUnlock()
+	if ring != nil {
+		return ring
+	}
+
+	// Belum diinisialisasi (misalnya saat unit test): pakai kunci sementara
+	keysMu.Lock()
+	defer keysMu.Unlock()
+	if currentKeys == nil {
+		currentKeys, _ = ephemeralKeyring()
+	}
+	return currentKeys
+}
+
+func ephemeralKeyring() (*keyring, error) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+
+	key := &signingKey{
+		kid:     fmt.Sprintf("ephemeral-%d", time.Now().Unix()),
+		method:  jwt.SigningMethodEdDSA,
+		private: priv,
+		public:  pub,
+	}
+	return &keyring{active: key, keys: map[string]*signingKey{key.kid: key}}, nil
+}
+
+func loadKeyring(dir string, activeKID string) (*keyring, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.pem"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+
+	ring := &keyring{keys: make(map[string]*signingKey)}
+	for _, file := range files {
+		kid := strings.TrimSuffix(filepath.Base(file), ".pem")
+
+		raw, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("gagal membaca kunci %s: %w", kid, err)
+		}
+
+		key, err := parsePEMKey(kid, raw)
+		if err != nil {
+			return nil, fmt.Errorf("gagal parsing kunci %s: %w", kid, err)
+		}
+
+		ring.keys[kid] = key
+		if key.private != nil && activeKID == "" {
+			ring.active = key
+		}
+	}
+
+	if activeKID != "" {
+		ring.active = ring.keys[activeKID]
+	}
+
+	if ring.active == nil || ring.active.private == nil {
+		return nil, fmt.Errorf("tidak ada kunci private aktif di %s", dir)
+	}
+	return ring, nil
+}
+
+func parsePEMKey(kid string, raw []byte) (*signingKey, error) {
+	block, _ := pem.Decode(raw)
+	if block == nil {
+		return nil, fmt.Errorf("format PEM tidak valid")
+	}
+
+	var parsed interface{}
+	var err error
+
+	switch block.Type {
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		parsed, err = x509.ParsePKIXPublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("tipe PEM %q tidak didukung", block.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	switch k := parsed.(type) {
+	case *rsa.PrivateKey:
+		return &signingKey{kid: kid, method: jwt.SigningMethodRS256, private: k, public: &k.PublicKey}, nil
+	case *rsa.PublicKey:
+		return &signingKey{kid: kid, method: jwt.SigningMethodRS256, public: k}, nil
+	case ed25519.PrivateKey:
+		return &signingKey{kid: kid, method: jwt.SigningMethodEdDSA, private: k, public: k.Public()}, nil
+	case ed25519.PublicKey:
+		return &signingKey{kid: kid, method: jwt.SigningMethodEdDSA, public: k}, nil
+	}
+	return nil, fmt.Errorf("algoritma kunci tidak didukung (gunakan RSA atau Ed25519)")
+}
+
+// signToken menandatangani claims dengan kunci aktif dan mencantumkan kid di header
+func signToken(claims jwt.Claims) (string, error) {
+	key := getKeyring().active
+
+	token := jwt.NewWithClaims(key.method, claims)
+	token.Header["kid"] = key.kid
+	return token.SignedString(key.private)
+}
+
+// keyFunc memilih kunci verifikasi berdasarkan kid di header token
+func keyFunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+
+	key, ok := getKeyring().keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("kid %q tidak dikenal", kid)
+	}
+
+	if token.Method.Alg() != key.method.Alg() {
+		return nil, fmt.Errorf("algoritma %s tidak sesuai dengan kunci %s", token.Method.Alg(), kid)
+	}
+	return key.public, nil
+}
+
+// JWKS mengembalikan seluruh kunci publik verifikasi dalam format JSON Web Key Set (RFC 7517)
+func JWKS() map[string]interface{} {
+	ring := getKeyring()
+
+	kids := make([]string, 0, len(ring.keys))
+	for kid := range ring.keys {
+		kids = append(kids, kid)
+	}
+	sort.Strings(kids)
+
+	keys := make([]map[string]string, 0, len(kids))
+	for _, kid := range kids {
+		key := ring.keys[kid]
+		jwk := map[string]string{
+			"kid": kid,
+			"use": "sig",
+			"alg": key.method.Alg(),
+		}
+
+		switch pub := key.public.(type) {
+		case *rsa.PublicKey:
+			jwk["kty"] = "RSA"
+			jwk["n"] = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+			jwk["e"] = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk["kty"] = "OKP"
+			jwk["crv"] = "Ed25519"
+			jwk["x"] = base64.RawURLEncoding.EncodeToString(pub)
+		}
+
+		keys = append(keys, jwk)
+	}
+
+	return map[string]interface{}{"keys": keys}
+}
@@ -0,0 +1,79 @@
+package utils
+
+import (
+	"fmt"
+	"log"
+	"net"
+	"net/smtp"
+	"os"
+	"strings"
+)
+
+// Mailer mengirim email teks biasa
+type Mailer interface {
+	Send(to string, subject string, body string) error
+}
+
+// SMTPConfig adalah konfigurasi server SMTP. Username kosong berarti tanpa autentikasi
+type SMTPConfig struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+}
+
+type smtpMailer struct {
+	cfg SMTPConfig
+}
+
+func NewSMTPMailer(cfg SMTPConfig) Mailer {
+	if cfg.Port == "" {
+		cfg.Port = "587"
+	}
+	return &smtpMailer{cfg: cfg}
+}
+
+func (m *smtpMailer) Send(to string, subject string, body string) error {
+	// Cegah header injection lewat alamat atau subjek
+	if strings.ContainsAny(to+subject, "\r\n") {
+		return fmt.Errorf("alamat atau subjek email tidak valid")
+	}
+
+	msg := "From: " + m.cfg.From + "\r\n" +
+		"To: " + to + "\r\n" +
+		"Subject: " + subject + "\r\n" +
+		"MIME-Version: 1.0\r\n" +
+		"Content-Type: text/plain; charset=UTF-8\r\n" +
+		"\r\n" + body
+
+	var auth smtp.Auth
+	if m.cfg.Username != "" {
+		auth = smtp.PlainAuth("", m.cfg.Username, m.cfg.Password, m.cfg.Host)
+	}
+	return smtp.SendMail(net.JoinHostPort(m.cfg.Host, m.cfg.Port), auth, m.cfg.From, []string{to}, []byte(msg))
+}
+
+// logMailer hanya menulis email ke log, dipakai saat SMTP belum dikonfigurasi (development)
+type logMailer struct{}
+
+func (logMailer) Send(to string, subject string, body string) error {
+	log.Printf("MAIL (SMTP_HOST kosong, tidak dikirim) ke=%s subjek=%q\n%s", to, subject, body)
+	return nil
+}
+
+// NewMailerFromEnv membuat mailer SMTP dari SMTP_HOST, SMTP_PORT, SMTP_USERNAME, SMTP_PASSWORD, dan SMTP_FROM.
+// Jika SMTP_HOST kosong, email hanya ditulis ke log
+func NewMailerFromEnv() Mailer {
+	host := os.Getenv("SMTP_HOST")
+	if host == "" {
+		return logMailer{}
+	}
+	return NewSMTPMailer(SMTPConfig{
+		Host:     host,
+		Port:     os.Getenv("SMTP_PORT"),
+		Username: os.Getenv("SMTP_USERNAME"),
+		Password: os.Getenv("SMTP_PASSWORD"),
+		From:     os.Getenv("SMTP_FROM"),
+	})
+}
@@ -0,0 +1,370 @@
+package utils
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// OIDCFlowTTL adalah batas waktu antara redirect ke IdP dan callback
+const OIDCFlowTTL = 10 * time.Minute
+
+// jwksRefreshInterval membatasi fetch ulang JWKS IdP saat ada kid yang belum dikenal
+const jwksRefreshInterval = time.Minute
+
+// OIDCConfig berisi konfigurasi client OpenID Connect (authorization code + PKCE)
+type OIDCConfig struct {
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+}
+
+// OIDCProvider adalah client untuk satu IdP. Discovery dan JWKS diambil saat pertama dipakai,
+// jadi server tetap bisa start walaupun IdP sedang tidak bisa dihubungi.
+type OIDCProvider struct {
+	cfg    OIDCConfig
+	client *http.Client
+
+	mu            sync.Mutex
+	authEndpoint  string
+	tokenEndpoint string
+	jwksURI       string
+	keys          map[string]interface{}
+	keysFetchedAt time.Time
+}
+
+type oidcDiscovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+type jsonWebKey struct {
+	Kid string `json:"kid"`
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	Crv string `json:"crv"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+func NewOIDCProvider(cfg OIDCConfig) *OIDCProvider {
+	if len(cfg.Scopes) == 0 {
+		cfg.Scopes = []string{"openid", "email", "profile"}
+	}
+	return &OIDCProvider{
+		cfg:    cfg,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// discover membaca /.well-known/openid-configuration milik issuer (sekali, lalu di-cache)
+func (p *OIDCProvider) discover(ctx context.Context) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.tokenEndpoint != "" {
+		return nil
+	}
+
+	wellKnown := strings.TrimSuffix(p.cfg.Issuer, "/") + "/.well-known/openid-configuration"
+
+	var doc oidcDiscovery
+	if err := p.getJSON(ctx, wellKnown, &doc); err != nil {
+		return fmt.Errorf("gagal discovery OIDC: %w", err)
+	}
+
+	if strings.TrimSuffix(doc.Issuer, "/") != strings.TrimSuffix(p.cfg.Issuer, "/") {
+		return fmt.Errorf("issuer discovery %q tidak sesuai dengan %q", doc.Issuer, p.cfg.Issuer)
+	}
+	if doc.AuthorizationEndpoint == "" || doc.TokenEndpoint == "" || doc.JWKSURI == "" {
+		return errors.New("dokumen discovery OIDC tidak lengkap")
+	}
+
+	p.authEndpoint = doc.AuthorizationEndpoint
+	p.tokenEndpoint = doc.TokenEndpoint
+	p.jwksURI = doc.JWKSURI
+	return nil
+}
+
+// AuthCodeURL membuat URL redirect ke IdP dengan state, nonce, dan code challenge S256
+func (p *OIDCProvider) AuthCodeURL(ctx context.Context, state, nonce, codeVerifier string) (string, error) {
+	if err := p.discover(ctx); err != nil {
+		return "", err
+	}
+
+	params := url.Values{}
+	params.Set("response_type", "code")
+	params.Set("client_id", p.cfg.ClientID)
+	params.Set("redirect_uri", p.cfg.RedirectURL)
+	params.Set("scope", strings.Join(p.cfg.Scopes, " "))
+	params.Set("state", state)
+	params.Set("nonce", nonce)
+	params.Set("code_challenge", PKCEChallenge(codeVerifier))
+	params.Set("code_challenge_method", "S256")
+
+	separator := "?"
+	if strings.Contains(p.authEndpoint, "?") {
+		separator = "&"
+	}
+	return p.authEndpoint + separator + params.Encode(), nil
+}
+
+// Exchange menukar authorization code dengan token di token endpoint dan mengembalikan id_token mentah
+func (p *OIDCProvider) Exchange(ctx context.Context, code, codeVerifier string) (string, error) {
+	if err := p.discover(ctx); err != nil {
+		return "", err
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", p.cfg.RedirectURL)
+	form.Set("client_id", p.cfg.ClientID)
+	form.Set("code_verifier", codeVerifier)
+	if p.cfg.ClientSecret != "" {
+		form.Set("client_secret", p.cfg.ClientSecret)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.tokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("gagal menghubungi token endpoint: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var body struct {
+		IDToken          string `json:"id_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&body); err != nil {
+		return "", fmt.Errorf("respons token endpoint tidak valid: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("token endpoint menolak code: %s %s", body.Error, body.ErrorDescription)
+	}
+	if body.IDToken == "" {
+		return "", errors.New("respons token endpoint tidak berisi id_token")
+	}
+	return body.IDToken, nil
+}
+
+// VerifyIDToken memvalidasi tanda tangan id_token dengan JWKS IdP, lalu iss, aud, exp, dan nonce
+func (p *OIDCProvider) VerifyIDToken(ctx context.Context, rawIDToken, nonce string) (jwt.MapClaims, error) {
+	if err := p.discover(ctx); err != nil {
+		return nil, err
+	}
+
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(rawIDToken, claims,
+		func(token *jwt.Token) (interface{}, error) {
+			kid, _ := token.Header["kid"].(string)
+			return p.publicKey(ctx, kid)
+		},
+		jwt.WithValidMethods([]string{"RS256", "RS384", "RS512", "ES256", "ES384", "EdDSA"}),
+		jwt.WithIssuer(p.cfg.Issuer),
+		jwt.WithAudience(p.cfg.ClientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(30*time.Second),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("id_token tidak valid: %w", err)
+	}
+
+	if tokenNonce, _ := claims["nonce"].(string); tokenNonce == "" || tokenNonce != nonce {
+		return nil, errors.New("nonce id_token tidak sesuai")
+	}
+	return claims, nil
+}
+
+// publicKey mencari kunci berdasarkan kid. Kid yang belum dikenal memicu fetch ulang JWKS
+// (IdP sedang rotasi kunci), dibatasi sekali per jwksRefreshInterval.
+func (p *OIDCProvider) publicKey(ctx context.Context, kid string) (interface{}, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if key, ok := p.keys[kid]; ok {
+		return key, nil
+	}
+
+	if p.keys != nil && time.Since(p.keysFetchedAt) < jwksRefreshInterval {
+		return nil, fmt.Errorf("kid %q tidak dikenal", kid)
+	}
+
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := p.getJSON(ctx, p.jwksURI, &set); err != nil {
+		return nil, fmt.Errorf("gagal mengambil JWKS IdP: %w", err)
+	}
+
+	keys := make(map[string]interface{}, len(set.Keys))
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, err := jwk.publicKey()
+		if err != nil {
+			continue
+		}
+		keys[jwk.Kid] = key
+	}
+	p.keys = keys
+	p.keysFetchedAt = time.Now()
+
+	if key, ok := p.keys[kid]; ok {
+		return key, nil
+	}
+	return nil, fmt.Errorf("kid %q tidak dikenal", kid)
+}
+
+func (p *OIDCProvider) getJSON(ctx context.Context, target string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s mengembalikan status %d", target, resp.StatusCode)
+	}
+	return json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(out)
+}
+
+func (k jsonWebKey) publicKey() (interface{}, error) {
+	decode := base64.RawURLEncoding.DecodeString
+
+	switch k.Kty {
+	case "RSA":
+		n, err := decode(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decode(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		default:
+			return nil, fmt.Errorf("kurva %s tidak didukung", k.Crv)
+		}
+		x, err := decode(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decode(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}, nil
+	case "OKP":
+		if k.Crv != "Ed25519" {
+			return nil, fmt.Errorf("kurva %s tidak didukung", k.Crv)
+		}
+		x, err := decode(k.X)
+		if err != nil || len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("kunci Ed25519 tidak valid")
+		}
+		return ed25519.PublicKey(x), nil
+	}
+	return nil, fmt.Errorf("kty %s tidak didukung", k.Kty)
+}
+
+// RandomURLToken membuat string acak base64url (untuk state, nonce, dan code verifier PKCE)
+func RandomURLToken(size int) (string, error) {
+	buf := make([]byte, size)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// PKCEChallenge menghitung code_challenge S256 dari code verifier (RFC 7636)
+func PKCEChallenge(codeVerifier string) string {
+	sum := sha256.Sum256([]byte(codeVerifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// oidcFlowAudience membedakan token alur SSO dari access token yang ditandatangani kunci yang sama
+const oidcFlowAudience = "uas:oidc-flow"
+
+// GenerateOIDCFlowToken menyimpan state, nonce, dan code verifier di token bertanda tangan
+// yang dikirim sebagai cookie, sehingga server tidak perlu menyimpan sesi login.
+// Token ini tidak bisa dipakai sebagai access token (type dan audience-nya berbeda)
+func GenerateOIDCFlowToken(state, nonce, codeVerifier string) (string, error) {
+	claims := jwt.MapClaims{
+		"aud":      oidcFlowAudience,
+		"state":    state,
+		"nonce":    nonce,
+		"verifier": codeVerifier,
+		"exp":      time.Now().Add(OIDCFlowTTL).Unix(),
+		"type":     "oidc_flow",
+	}
+
+	return signToken(claims)
+}
+
+// ValidateOIDCFlowToken mengembalikan nonce dan code verifier jika token valid dan state-nya cocok
+func ValidateOIDCFlowToken(tokenString, state string) (string, string, error) {
+	token, err := jwt.Parse(tokenString, keyFunc, jwt.WithAudience(oidcFlowAudience))
+	if err != nil || !token.Valid {
+		return "", "", jwt.ErrTokenInvalidClaims
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || claims["type"] != "oidc_flow" {
+		return "", "", jwt.ErrTokenInvalidClaims
+	}
+
+	expected, _ := claims["state"].(string)
+	if expected == "" || expected != state {
+		return "", "", errors.New("state tidak sesuai")
+	}
+
+	nonce, _ := claims["nonce"].(string)
+	verifier, _ := claims["verifier"].(string)
+	return nonce, verifier, nil
+}
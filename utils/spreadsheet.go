@@ -0,0 +1,183 @@
+package utils
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ErrUnsupportedSpreadsheet: ekstensi file bukan .csv atau .xlsx
+var ErrUnsupportedSpreadsheet = errors.New("format file harus .csv atau .xlsx")
+
+// ReadSpreadsheet membaca file CSV atau XLSX (sheet pertama) menjadi baris-baris sel teks.
+// Format ditentukan dari ekstensi nama file.
+func ReadSpreadsheet(filename string, data []byte) ([][]string, error) {
+	switch strings.ToLower(path.Ext(filename)) {
+	case ".csv":
+		return readCSV(data)
+	case ".xlsx":
+		return readXLSX(data)
+	}
+	return nil, ErrUnsupportedSpreadsheet
+}
+
+func readCSV(data []byte) ([][]string, error) {
+	// Buang BOM UTF-8 yang biasa ditambahkan Excel saat menyimpan CSV
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	// Excel versi Indonesia sering menyimpan CSV dengan pemisah titik koma
+	if firstLine, _, _ := bytes.Cut(data, []byte("\n")); bytes.Count(firstLine, []byte(";")) > bytes.Count(firstLine, []byte(",")) {
+		reader.Comma = ';'
+	}
+
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("CSV tidak valid: %w", err)
+	}
+	return rows, nil
+}
+
+type xlsxSharedStrings struct {
+	Items []xlsxRichText `xml:"si"`
+}
+
+// xlsxRichText menampung teks biasa (<t>) maupun rich text (<r><t>)
+type xlsxRichText struct {
+	Text string `xml:"t"`
+	Runs []struct {
+		Text string `xml:"t"`
+	} `xml:"r"`
+}
+
+func (t xlsxRichText) String() string {
+	if len(t.Runs) == 0 {
+		return t.Text
+	}
+	var sb strings.Builder
+	for _, run := range t.Runs {
+		sb.WriteString(run.Text)
+	}
+	return sb.String()
+}
+
+type xlsxWorksheet struct {
+	Rows []struct {
+		Index int `xml:"r,attr"`
+		Cells []struct {
+			Ref    string       `xml:"r,attr"`
+			Type   string       `xml:"t,attr"`
+			Value  string       `xml:"v"`
+			Inline xlsxRichText `xml:"is"`
+		} `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+func readXLSX(data []byte) ([][]string, error) {
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, errors.New("XLSX tidak valid")
+	}
+
+	files := make(map[string]*zip.File, len(archive.File))
+	var sheets []string
+	for _, f := range archive.File {
+		files[f.Name] = f
+		if strings.HasPrefix(f.Name, "xl/worksheets/") && strings.HasSuffix(f.Name, ".xml") {
+			sheets = append(sheets, f.Name)
+		}
+	}
+	if len(sheets) == 0 {
+		return nil, errors.New("XLSX tidak memiliki worksheet")
+	}
+
+	// Sheet pertama: sheet1.xml jika ada, selain itu nama terkecil
+	sheetName := "xl/worksheets/sheet1.xml"
+	if files[sheetName] == nil {
+		sort.Strings(sheets)
+		sheetName = sheets[0]
+	}
+
+	var shared xlsxSharedStrings
+	if f := files["xl/sharedStrings.xml"]; f != nil {
+		if err := decodeZipXML(f, &shared); err != nil {
+			return nil, fmt.Errorf("sharedStrings XLSX tidak valid: %w", err)
+		}
+	}
+
+	var sheet xlsxWorksheet
+	if err := decodeZipXML(files[sheetName], &sheet); err != nil {
+		return nil, fmt.Errorf("worksheet XLSX tidak valid: %w", err)
+	}
+
+	rows := make([][]string, 0, len(sheet.Rows))
+	for _, row := range sheet.Rows {
+		// Baris kosong tidak ditulis di XLSX; isi celahnya agar nomor baris tetap sama dengan di Excel
+		for row.Index > 0 && len(rows) < row.Index-1 {
+			rows = append(rows, nil)
+		}
+
+		var cells []string
+		for _, cell := range row.Cells {
+			col := len(cells)
+			if cell.Ref != "" {
+				col = xlsxColumnIndex(cell.Ref)
+			}
+
+			var value string
+			switch cell.Type {
+			case "s":
+				idx, err := strconv.Atoi(cell.Value)
+				if err != nil || idx < 0 || idx >= len(shared.Items) {
+					return nil, errors.New("referensi shared string XLSX tidak valid")
+				}
+				value = shared.Items[idx].String()
+			case "inlineStr":
+				value = cell.Inline.String()
+			default:
+				value = cell.Value
+			}
+
+			for len(cells) <= col {
+				cells = append(cells, "")
+			}
+			cells[col] = value
+		}
+		rows = append(rows, cells)
+	}
+	return rows, nil
+}
+
+// xlsxColumnIndex mengubah referensi sel seperti "AB12" menjadi indeks kolom berbasis 0
+func xlsxColumnIndex(ref string) int {
+	col := 0
+	for _, ch := range ref {
+		if ch < 'A' || ch > 'Z' {
+			break
+		}
+		col = col*26 + int(ch-'A'+1)
+	}
+	return col - 1
+}
+
+func decodeZipXML(f *zip.File, out interface{}) error {
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	// Batasi ukuran hasil dekompresi agar file zip bom tidak menghabiskan memori
+	return xml.NewDecoder(io.LimitReader(rc, 50<<20)).Decode(out)
+}
@@ -0,0 +1,114 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Storage menyimpan file upload (lampiran prestasi, foto profil) dan mengembalikan URL publiknya
+type Storage interface {
+	Put(ctx context.Context, key string, r io.Reader) (string, error)
+	Delete(ctx context.Context, key string) error
+	// KeyFromURL mengembalikan key dari URL yang dibuat storage ini; false jika URL milik pihak lain
+	KeyFromURL(url string) (string, bool)
+}
+
+// LocalStorage menyimpan file di direktori lokal yang disajikan sebagai file statis di URLPrefix
+type LocalStorage struct {
+	Dir       string
+	URLPrefix string
+}
+
+func NewLocalStorage(dir string, urlPrefix string) *LocalStorage {
+	return &LocalStorage{Dir: dir, URLPrefix: strings.TrimSuffix(urlPrefix, "/")}
+}
+
+// NewStorageFromEnv memakai UPLOAD_DIR (default ./uploads) yang disajikan di /uploads
+func NewStorageFromEnv() *LocalStorage {
+	dir := os.Getenv("UPLOAD_DIR")
+	if dir == "" {
+		dir = "./uploads"
+	}
+	return NewLocalStorage(dir, "/uploads")
+}
+
+// cleanKey menolak key yang keluar dari direktori storage (misalnya "../")
+func cleanKey(key string) (string, error) {
+	cleaned := path.Clean("/" + strings.ReplaceAll(key, "\\", "/"))[1:]
+	if cleaned == "" || cleaned != key {
+		return "", fmt.Errorf("key storage tidak valid: %q", key)
+	}
+	return cleaned, nil
+}
+
+// SafeFileName mengambil nama dasar file dari client agar tidak bisa keluar dari direktori upload
+func SafeFileName(name string) string {
+	base := path.Base(strings.ReplaceAll(name, "\\", "/"))
+	if base == "." || base == "/" || base == ".." {
+		return "file"
+	}
+	return base
+}
+
+func (s *LocalStorage) Put(ctx context.Context, key string, r io.Reader) (string, error) {
+	key, err := cleanKey(key)
+	if err != nil {
+		return "", err
+	}
+
+	target := filepath.Join(s.Dir, filepath.FromSlash(key))
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return "", err
+	}
+
+	// Tulis ke file sementara lalu rename agar file setengah jadi tidak pernah tersaji
+	tmp, err := os.CreateTemp(filepath.Dir(target), ".upload-*")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return "", err
+	}
+	if err := tmp.Close(); err != nil {
+		return "", err
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return "", err
+	}
+	if err := os.Rename(tmp.Name(), target); err != nil {
+		return "", err
+	}
+
+	return s.URLPrefix + "/" + key, nil
+}
+
+func (s *LocalStorage) Delete(ctx context.Context, key string) error {
+	key, err := cleanKey(key)
+	if err != nil {
+		return err
+	}
+	err = os.Remove(filepath.Join(s.Dir, filepath.FromSlash(key)))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+func (s *LocalStorage) KeyFromURL(url string) (string, bool) {
+	key, ok := strings.CutPrefix(url, s.URLPrefix+"/")
+	if !ok {
+		return "", false
+	}
+	if _, err := cleanKey(key); err != nil {
+		return "", false
+	}
+	return key, true
+}
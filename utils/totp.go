@@ -0,0 +1,130 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Parameter TOTP mengikuti default RFC 6238 yang didukung semua aplikasi authenticator
+const (
+	totpDigits = 6
+	totpPeriod = 30
+	totpSkew   = 1
+)
+
+var base32NoPad = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// GenerateTOTPSecret membuat secret acak 160-bit dalam format base32
+func GenerateTOTPSecret() (string, error) {
+	buf := make([]byte, 20)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base32NoPad.EncodeToString(buf), nil
+}
+
+// GenerateTOTPCode menghitung kode TOTP untuk waktu t
+func GenerateTOTPCode(secret string, t time.Time) (string, error) {
+	key, err := base32NoPad.DecodeString(strings.ToUpper(strings.TrimSpace(secret)))
+	if err != nil {
+		return "", fmt.Errorf("secret TOTP tidak valid: %w", err)
+	}
+	return hotp(key, uint64(t.Unix()/totpPeriod)), nil
+}
+
+// ValidateTOTPCode mengecek kode dengan toleransi satu periode sebelum/sesudah
+func ValidateTOTPCode(secret string, code string, t time.Time) bool {
+	_, ok := MatchTOTPCode(secret, code, t)
+	return ok
+}
+
+// MatchTOTPCode seperti ValidateTOTPCode, tetapi juga mengembalikan time step (counter) kode yang cocok.
+// Step disimpan agar kode yang sama tidak bisa dipakai ulang selama masih dalam jendela validnya
+func MatchTOTPCode(secret string, code string, t time.Time) (int64, bool) {
+	code = strings.ReplaceAll(strings.TrimSpace(code), " ", "")
+	if len(code) != totpDigits {
+		return 0, false
+	}
+
+	key, err := base32NoPad.DecodeString(strings.ToUpper(strings.TrimSpace(secret)))
+	if err != nil {
+		return 0, false
+	}
+
+	counter := t.Unix() / totpPeriod
+	for i := -totpSkew; i <= totpSkew; i++ {
+		step := counter + int64(i)
+		expected := hotp(key, uint64(step))
+		if subtle.ConstantTimeCompare([]byte(expected), []byte(code)) == 1 {
+			return step, true
+		}
+	}
+	return 0, false
+}
+
+// TOTPProvisioningURI membuat URI otpauth:// untuk dirender sebagai QR code
+func TOTPProvisioningURI(issuer string, account string, secret string) string {
+	label := url.PathEscape(issuer + ":" + account)
+
+	params := url.Values{}
+	params.Set("secret", secret)
+	params.Set("issuer", issuer)
+	params.Set("algorithm", "SHA1")
+	params.Set("digits", fmt.Sprint(totpDigits))
+	params.Set("period", fmt.Sprint(totpPeriod))
+
+	return "otpauth://totp/" + label + "?" + params.Encode()
+}
+
+// NormalizeRecoveryCode menyamakan format input kode pemulihan sebelum di-hash
+func NormalizeRecoveryCode(code string) string {
+	return strings.ToLower(strings.TrimSpace(code))
+}
+
+// GenerateRecoveryCodes membuat n kode pemulihan format xxxxx-xxxxx
+func GenerateRecoveryCodes(n int) ([]string, error) {
+	codes := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		buf := make([]byte, 5)
+		if _, err := rand.Read(buf); err != nil {
+			return nil, err
+		}
+		raw := hex.EncodeToString(buf)
+		codes = append(codes, raw[:5]+"-"+raw[5:])
+	}
+	return codes, nil
+}
+
+// HashToken menghasilkan hash SHA-256 (hex) untuk token/kode yang disimpan di database
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func hotp(key []byte, counter uint64) string {
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], counter)
+
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	mod := uint32(1)
+	for i := 0; i < totpDigits; i++ {
+		mod *= 10
+	}
+	return fmt.Sprintf("%0*d", totpDigits, value%mod)
+}
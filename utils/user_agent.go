@@ -0,0 +1,52 @@
+package utils
+
+import "strings"
+
+// DeviceFromUserAgent meringkas User-Agent menjadi label singkat seperti "Chrome di Windows"
+func DeviceFromUserAgent(userAgent string) string {
+	ua := strings.ToLower(userAgent)
+	if ua == "" {
+		return "Perangkat tidak dikenal"
+	}
+
+	// Urutan penting: Edge & Opera juga menyebut Chrome, Chrome juga menyebut Safari
+	browser := "Browser lain"
+	switch {
+	case strings.Contains(ua, "edg/"):
+		browser = "Edge"
+	case strings.Contains(ua, "opr/") || strings.Contains(ua, "opera"):
+		browser = "Opera"
+	case strings.Contains(ua, "firefox/"):
+		browser = "Firefox"
+	case strings.Contains(ua, "chrome/") || strings.Contains(ua, "crios/"):
+		browser = "Chrome"
+	case strings.Contains(ua, "safari/"):
+		browser = "Safari"
+	case strings.Contains(ua, "postman"):
+		browser = "Postman"
+	case strings.Contains(ua, "curl/"):
+		browser = "curl"
+	case strings.Contains(ua, "okhttp") || strings.Contains(ua, "dart:io"):
+		browser = "Aplikasi mobile"
+	}
+
+	// iPhone/iPad juga menyebut "Mac OS X", dan Android menyebut "Linux"
+	os := ""
+	switch {
+	case strings.Contains(ua, "iphone") || strings.Contains(ua, "ipad"):
+		os = "iOS"
+	case strings.Contains(ua, "android"):
+		os = "Android"
+	case strings.Contains(ua, "windows"):
+		os = "Windows"
+	case strings.Contains(ua, "mac os x") || strings.Contains(ua, "macintosh"):
+		os = "macOS"
+	case strings.Contains(ua, "linux"):
+		os = "Linux"
+	}
+
+	if os == "" {
+		return browser
+	}
+	return browser + " di " + os
+}
@@ -0,0 +1,365 @@
+package validation
+
+import (
+	"fmt"
+	"net/mail"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"uas/i18n"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+// FieldError adalah satu aturan yang dilanggar sebuah field. Field memakai nama JSON-nya;
+// field bersarang ditulis "student.student_id", elemen slice "to_lecturer_ids[1]"
+// Message ditulis dalam bahasa Default; Translate membuat ulang pesan dari Key untuk bahasa lain
+type FieldError struct {
+	Field   string      `json:"field"`
+	Rule    string      `json:"rule"`
+	Message string      `json:"message"`
+	Key     string      `json:"-"`
+	Params  i18n.Params `json:"-"`
+}
+
+type Errors []FieldError
+
+func (e Errors) Error() string {
+	messages := make([]string, len(e))
+	for i, fe := range e {
+		messages[i] = fe.Message
+	}
+	return strings.Join(messages, "; ")
+}
+
+// Field membuat FieldError untuk aturan yang diperiksa di luar tag (misalnya butuh data dari database).
+// key adalah key katalog pesan; placeholder {field} selalu terisi nama field
+func Field(field, rule, key string, params ...i18n.Params) FieldError {
+	p := i18n.Params{}
+	if len(params) > 0 {
+		for k, v := range params[0] {
+			p[k] = v
+		}
+	}
+	p["field"] = field
+	return FieldError{Field: field, Rule: rule, Message: i18n.T(i18n.Default, key, p), Key: key, Params: p}
+}
+
+// Translate mengembalikan salinan fe dengan pesan dalam bahasa lang
+func (fe FieldError) Translate(lang i18n.Lang) FieldError {
+	if fe.Key != "" {
+		fe.Message = i18n.T(lang, fe.Key, fe.Params)
+	}
+	return fe
+}
+
+// ruleError membuat FieldError untuk aturan tag; pesannya "validation.<key>"
+func ruleError(name, rule, key string, params i18n.Params) FieldError {
+	return Field(name, rule, "validation."+key, params)
+}
+
+var nimPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9.\-]{4,19}$`)
+
+var academicYearPattern = regexp.MustCompile(`^(19|20)[0-9]{2}$`)
+
+// IsEmail hanya menerima alamat polos, tanpa nama tampilan seperti "Budi <budi@kampus.ac.id>"
+func IsEmail(s string) bool {
+	addr, err := mail.ParseAddress(s)
+	return err == nil && addr.Address == s
+}
+
+// IsNIM: NIM terdiri dari 5-20 karakter huruf, angka, titik, atau strip (misalnya 434221001 atau A11.2025.00001)
+func IsNIM(s string) bool {
+	return nimPattern.MatchString(s)
+}
+
+// IsAcademicYear: angkatan ditulis sebagai tahun masuk empat digit, misalnya 2025
+func IsAcademicYear(s string) bool {
+	return academicYearPattern.MatchString(s)
+}
+
+// Struct memeriksa tag `validate` pada struct (boleh pointer), termasuk struct bersarang yang tidak nil.
+// Aturan dipisah koma dan diperiksa berurutan; pelanggaran pertama per field yang dilaporkan.
+//
+//	required                 tidak boleh kosong (string berisi spasi saja dianggap kosong)
+//	required_if=Field nilai  wajib jika Field bernilai tertentu
+//	required_without=Field   wajib jika Field kosong
+//	omitempty                aturan berikutnya dilewati jika kosong
+//	email, uuid, date, nim   format alamat email, UUID, tanggal YYYY-MM-DD, NIM
+//	academic_year            angkatan berupa tahun empat digit (YYYY)
+//	nospace                  tanpa spasi
+//	min=n, max=n             panjang string/slice atau nilai angka
+//	oneof=a b c              salah satu nilai
+//	excludes=s               tidak mengandung s
+//	gtefield=Field           tidak lebih kecil dari Field
+//	dive                     aturan berikutnya diperiksa untuk setiap elemen slice
+func Struct(v interface{}) Errors {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return nil
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil
+	}
+
+	var errs Errors
+	validateStruct(rv, "", &errs)
+	return errs
+}
+
+func validateStruct(rv reflect.Value, prefix string, errs *Errors) {
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		sf := rt.Field(i)
+		if sf.PkgPath != "" {
+			continue
+		}
+		fv := rv.Field(i)
+
+		if sf.Anonymous {
+			if nested, ok := structValue(fv); ok {
+				validateStruct(nested, prefix, errs)
+			}
+			continue
+		}
+
+		name := prefix + jsonName(sf)
+		if tag := sf.Tag.Get("validate"); tag != "" && tag != "-" {
+			if fe, ok := checkRules(rv, fv, name, strings.Split(tag, ",")); !ok {
+				*errs = append(*errs, fe)
+				continue
+			}
+		}
+
+		if nested, ok := structValue(fv); ok {
+			validateStruct(nested, name+".", errs)
+		}
+	}
+}
+
+// structValue mengembalikan struct (atau isi pointer ke struct) yang perlu diperiksa lebih dalam
+func structValue(fv reflect.Value) (reflect.Value, bool) {
+	if fv.Kind() == reflect.Ptr {
+		if fv.IsNil() {
+			return reflect.Value{}, false
+		}
+		fv = fv.Elem()
+	}
+	if fv.Kind() != reflect.Struct || fv.Type() == reflect.TypeOf(time.Time{}) {
+		return reflect.Value{}, false
+	}
+	return fv, true
+}
+
+func jsonName(sf reflect.StructField) string {
+	name := strings.Split(sf.Tag.Get("json"), ",")[0]
+	if name == "" || name == "-" {
+		return sf.Name
+	}
+	return name
+}
+
+// checkRules menjalankan aturan satu field; ok == false berarti fe berisi pelanggaran pertamanya
+func checkRules(parent, fv reflect.Value, name string, rules []string) (fe FieldError, ok bool) {
+	for i, rule := range rules {
+		rule = strings.TrimSpace(rule)
+		key, param := rule, ""
+		if eq := strings.Index(rule, "="); eq >= 0 {
+			key, param = rule[:eq], rule[eq+1:]
+		}
+
+		switch key {
+		case "":
+			continue
+		case "omitempty":
+			if isEmpty(fv) {
+				return FieldError{}, true
+			}
+			continue
+		case "required":
+			if isEmpty(fv) {
+				return ruleError(name, key, key, nil), false
+			}
+			continue
+		case "required_if":
+			other, want := splitParam(param)
+			ov, otherName := siblingValue(parent, other)
+			if ov.IsValid() && fmt.Sprint(deref(ov).Interface()) == want && isEmpty(fv) {
+				return ruleError(name, key, key, i18n.Params{"other": otherName, "value": want}), false
+			}
+			continue
+		case "required_without":
+			ov, otherName := siblingValue(parent, param)
+			if ov.IsValid() && isEmpty(ov) && isEmpty(fv) {
+				return ruleError(name, key, key, i18n.Params{"other": otherName}), false
+			}
+			continue
+		case "dive":
+			elems := deref(fv)
+			if elems.Kind() != reflect.Slice && elems.Kind() != reflect.Array {
+				continue
+			}
+			for j := 0; j < elems.Len(); j++ {
+				if fe, ok := checkRules(parent, elems.Index(j), fmt.Sprintf("%s[%d]", name, j), rules[i+1:]); !ok {
+					return fe, false
+				}
+			}
+			return FieldError{}, true
+		}
+
+		// Aturan format hanya berlaku untuk nilai yang terisi; kosong urusan required
+		if isEmpty(fv) {
+			continue
+		}
+		if messageKey, params := checkValue(parent, deref(fv), key, param); messageKey != "" {
+			return ruleError(name, key, messageKey, params), false
+		}
+	}
+	return FieldError{}, true
+}
+
+// checkValue mengembalikan key pesan (tanpa awalan "validation.") dan params jika aturan dilanggar
+func checkValue(parent, v reflect.Value, key, param string) (string, i18n.Params) {
+	s := ""
+	if v.Kind() == reflect.String {
+		s = strings.TrimSpace(v.String())
+	}
+
+	switch key {
+	case "email":
+		if !IsEmail(s) {
+			return "email", nil
+		}
+	case "uuid":
+		if _, err := uuid.Parse(s); err != nil {
+			return "uuid", nil
+		}
+	case "date":
+		if _, err := time.Parse("2006-01-02", s); err != nil {
+			return "date", nil
+		}
+	case "nim":
+		if !IsNIM(s) {
+			return "nim", nil
+		}
+	case "academic_year":
+		if !IsAcademicYear(s) {
+			return "academic_year", nil
+		}
+	case "nospace":
+		if strings.ContainsAny(v.String(), " \t\n") {
+			return "nospace", nil
+		}
+	case "excludes":
+		if strings.Contains(v.String(), param) {
+			return "excludes", i18n.Params{"value": param}
+		}
+	case "oneof":
+		allowed := strings.Fields(param)
+		for _, a := range allowed {
+			if fmt.Sprint(v.Interface()) == a {
+				return "", nil
+			}
+		}
+		return "oneof", i18n.Params{"values": strings.Join(allowed, ", ")}
+	case "min", "max":
+		return checkBound(v, key, param)
+	case "gtefield":
+		other, otherName := siblingValue(parent, param)
+		if other.IsValid() && !isEmpty(other) && less(v, deref(other)) {
+			return "gtefield", i18n.Params{"other": otherName}
+		}
+	default:
+		panic("validation: aturan tidak dikenal " + key)
+	}
+	return "", nil
+}
+
+// checkBound: pesan dibedakan untuk panjang string (min_length), jumlah item (min_items), dan angka (min)
+func checkBound(v reflect.Value, key, param string) (string, i18n.Params) {
+	n, err := strconv.Atoi(param)
+	if err != nil {
+		panic("validation: parameter " + key + " harus angka")
+	}
+
+	var got int64
+	suffix := ""
+	switch v.Kind() {
+	case reflect.String:
+		got, suffix = int64(utf8.RuneCountInString(strings.TrimSpace(v.String()))), "_length"
+	case reflect.Slice, reflect.Array, reflect.Map:
+		got, suffix = int64(v.Len()), "_items"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		got = v.Int()
+	default:
+		return "", nil
+	}
+
+	if (key == "min" && got < int64(n)) || (key == "max" && got > int64(n)) {
+		return key + suffix, i18n.Params{"n": n}
+	}
+	return "", nil
+}
+
+// splitParam memisahkan "Field nilai" pada required_if; nilai boleh mengandung spasi
+func splitParam(param string) (string, string) {
+	parts := strings.SplitN(strings.TrimSpace(param), " ", 2)
+	if len(parts) < 2 {
+		return parts[0], ""
+	}
+	return parts[0], strings.TrimSpace(parts[1])
+}
+
+// siblingValue mencari field lain (nama Go) pada struct yang sama beserta nama JSON-nya
+func siblingValue(parent reflect.Value, field string) (reflect.Value, string) {
+	sf, ok := parent.Type().FieldByName(field)
+	if !ok {
+		panic("validation: field " + field + " tidak ada di " + parent.Type().Name())
+	}
+	return parent.FieldByIndex(sf.Index), jsonName(sf)
+}
+
+func deref(v reflect.Value) reflect.Value {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return v
+		}
+		v = v.Elem()
+	}
+	return v
+}
+
+// isEmpty: pointer yang tidak nil dianggap terisi, walaupun isinya nilai nol
+func isEmpty(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Invalid:
+		return true
+	case reflect.Ptr, reflect.Interface:
+		return v.IsNil()
+	case reflect.String:
+		return strings.TrimSpace(v.String()) == ""
+	case reflect.Slice, reflect.Map:
+		return v.Len() == 0
+	}
+	return v.IsZero()
+}
+
+func less(a, b reflect.Value) bool {
+	switch a.Kind() {
+	case reflect.String:
+		return strings.TrimSpace(a.String()) < strings.TrimSpace(b.String())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return a.Int() < b.Int()
+	}
+	if at, ok := a.Interface().(time.Time); ok {
+		bt, _ := b.Interface().(time.Time)
+		return at.Before(bt)
+	}
+	return false
+}